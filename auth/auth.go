@@ -0,0 +1,165 @@
+// Package auth implement the online mode authentication.
+//
+// After the client sent the shared secret, the server computes the
+// ServerHash and asks the session server whether the player has joined
+// with the same hash. See https://wiki.vg/Protocol_Encryption#Server
+//
+// The client side is Join, which tells the session server the player is
+// joining with the hash, before sending the shared secret.
+package auth
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SessionURL is the URL of the session server
+var SessionURL = "https://sessionserver.mojang.com"
+
+// ErrNotJoined is returned by HasJoined when the session server doesn't
+// know the player joining with the server hash.
+// It means the client isn't authenticated by Mojang.
+var ErrNotJoined = errors.New("auth: player has not joined")
+
+// ErrJoinForbidden is returned by Join when the session server refuses the
+// player joining, because the access token is invalid or expired, or the
+// account isn't allowed to play online.
+var ErrJoinForbidden = errors.New("auth: joining is forbidden by the session server")
+
+// client is used to request the session server, the timeout keeps
+// HasJoined and Join from blocking the login forever when it's unreachable.
+var client = http.Client{Timeout: 30 * time.Second}
+
+// Profile is the player's profile returned by the session server
+type Profile struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Properties []Property `json:"properties"`
+}
+
+// Property is the signed property of a profile
+type Property struct {
+	Name      string `json:"name"`
+	Value     string `json:"value"`               // base64 encoded
+	Signature string `json:"signature,omitempty"` // base64 encoded
+}
+
+// Textures return the "textures" property, which contains the skin and cape.
+// It can be sent to the clients as is, in the PlayerInfo packet.
+func (p Profile) Textures() (Property, bool) {
+	for _, v := range p.Properties {
+		if v.Name == "textures" {
+			return v, true
+		}
+	}
+	return Property{}, false
+}
+
+// ServerHash compute the server hash, which is a SHA-1 digest of the
+// server ID, shared secret and public key, in the format of Java's
+// new BigInteger(digest).toString(16).
+// That is, the digest is treated as a signed number, and the result could
+// be negative and has no leading zeros.
+func ServerHash(serverID string, sharedSecret, publicKey []byte) string {
+	h := sha1.New()
+	h.Write([]byte(serverID))
+	h.Write(sharedSecret)
+	h.Write(publicKey)
+	hash := h.Sum(nil)
+
+	n := new(big.Int).SetBytes(hash)
+	if hash[0]&0x80 != 0 { // negative, two's complement
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(hash)*8)))
+	}
+	return n.Text(16)
+}
+
+// HasJoined ask the session server whether the player has joined with the serverHash.
+// The ip is optional, if it's not nil, the session server check that the
+// player joined from it, which is what prevent-proxy-connections does.
+func HasJoined(username, serverHash string, ip net.IP) (Profile, error) {
+	var p Profile
+	query := url.Values{
+		"username": {username},
+		"serverId": {serverHash},
+	}
+	if ip != nil {
+		query.Set("ip", ip.String())
+	}
+
+	req, err := http.NewRequest(http.MethodGet, SessionURL+"/session/minecraft/hasJoined?"+query.Encode(), nil)
+	if err != nil {
+		return p, fmt.Errorf("auth: make request error: %v", err)
+	}
+	req.Header.Set("User-agent", "go-mc")
+	resp, err := client.Do(req)
+	if err != nil {
+		return p, fmt.Errorf("auth: request fail: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent:
+		return p, ErrNotJoined
+	default:
+		body, _ := ioutil.ReadAll(resp.Body)
+		return p, fmt.Errorf("auth: session server response %s: %s", resp.Status, body)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		return p, fmt.Errorf("auth: parse resp fail: %v", err)
+	}
+	return p, nil
+}
+
+// Join tell the session server the player of the profile is joining a
+// server with the serverHash, using the access token of the player's account.
+// Only the ID and the Name of the profile are needed.
+func Join(accessToken string, profile Profile, serverHash string) error {
+	body, err := json.Marshal(struct {
+		AccessToken     string `json:"accessToken"`
+		SelectedProfile string `json:"selectedProfile"`
+		ServerID        string `json:"serverId"`
+	}{
+		AccessToken:     accessToken,
+		SelectedProfile: strings.ReplaceAll(profile.ID.String(), "-", ""),
+		ServerID:        serverHash,
+	})
+	if err != nil {
+		return fmt.Errorf("auth: create request fail: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, SessionURL+"/session/minecraft/join", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("auth: make request error: %v", err)
+	}
+	req.Header.Set("User-agent", "go-mc")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("auth: request fail: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusForbidden {
+			return fmt.Errorf("%w: %s", ErrJoinForbidden, body)
+		}
+		return fmt.Errorf("auth: session server response %s: %s", resp.Status, body)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestServerHash(t *testing.T) {
+	// https://wiki.vg/Protocol_Encryption#Server
+	for _, v := range []struct{ name, hash string }{
+		{"Notch", "4ed1f46bbe04bc756bcb17c0c7ce3e4632f06a48"},
+		{"jeb_", "-7c9d5b0044c130109a5d7b5fb5c317c02b4e28c1"}, // negative
+		{"simon", "88e16a1019277b15d58faf0541e11910eb756f6"},  // leading zero
+	} {
+		if hash := ServerHash(v.name, nil, nil); hash != v.hash {
+			t.Errorf("server hash of %q should be %s, get %s", v.name, v.hash, hash)
+		}
+	}
+	// the shared secret and public key are digested after the server ID, in order
+	if hash := ServerHash("je", []byte("b"), []byte("_")); hash != "-7c9d5b0044c130109a5d7b5fb5c317c02b4e28c1" {
+		t.Errorf("server hash of the split jeb_ is %s", hash)
+	}
+	if hash := ServerHash("", []byte("jeb_"), nil); hash != "-7c9d5b0044c130109a5d7b5fb5c317c02b4e28c1" {
+		t.Errorf("server hash of the secret jeb_ is %s", hash)
+	}
+}
+
+func TestHasJoined(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/session/minecraft/hasJoined" || q.Get("username") != "Notch" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if q.Get("serverId") != "-7c9d5b0044c130109a5d7b5fb5c317c02b4e28c1" || q.Get("ip") == "10.0.0.1" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = w.Write([]byte(`{
+			"id": "069a79f444e94726a5befca90e38aaf5",
+			"name": "Notch",
+			"properties": [{"name": "textures", "value": "e30=", "signature": "c2ln"}]
+		}`))
+	}))
+	defer s.Close()
+	defer func(u string) { SessionURL = u }(SessionURL)
+	SessionURL = s.URL
+
+	p, err := HasJoined("Notch", "-7c9d5b0044c130109a5d7b5fb5c317c02b4e28c1", net.ParseIP("127.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "Notch" || p.ID.String() != "069a79f4-44e9-4726-a5be-fca90e38aaf5" {
+		t.Errorf("wrong profile: %+v", p)
+	}
+	if tex, ok := p.Textures(); !ok || tex.Value != "e30=" || tex.Signature != "c2ln" {
+		t.Errorf("wrong textures: %+v", tex)
+	}
+
+	if _, err := HasJoined("Notch", "4ed1f46bbe04bc756bcb17c0c7ce3e4632f06a48", nil); err != ErrNotJoined {
+		t.Errorf("wrong server hash should be ErrNotJoined, get %v", err)
+	}
+	if _, err := HasJoined("Notch", "-7c9d5b0044c130109a5d7b5fb5c317c02b4e28c1", net.ParseIP("10.0.0.1")); err != ErrNotJoined {
+		t.Errorf("wrong ip should be ErrNotJoined, get %v", err)
+	}
+}
+
+func TestJoin(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			AccessToken     string `json:"accessToken"`
+			SelectedProfile string `json:"selectedProfile"`
+			ServerID        string `json:"serverId"`
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/session/minecraft/join" ||
+			json.NewDecoder(r.Body).Decode(&req) != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if req.AccessToken != "token" || req.SelectedProfile != "069a79f444e94726a5befca90e38aaf5" ||
+			req.ServerID != "-7c9d5b0044c130109a5d7b5fb5c317c02b4e28c1" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":"ForbiddenOperationException"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer s.Close()
+	defer func(u string) { SessionURL = u }(SessionURL)
+	SessionURL = s.URL
+
+	notch := Profile{ID: uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5"), Name: "Notch"}
+	if err := Join("token", notch, "-7c9d5b0044c130109a5d7b5fb5c317c02b4e28c1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Join("expired", notch, "-7c9d5b0044c130109a5d7b5fb5c317c02b4e28c1"); !errors.Is(err, ErrJoinForbidden) {
+		t.Errorf("join with an invalid token should be ErrJoinForbidden, get %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"errors"
+	"fmt"
+)
+
+// KeyPair is the RSA key pair of an online-mode server, generated at startup.
+// The public key is sent to the clients in the Encryption Request, which
+// encrypt the shared secret and the verify token with it.
+type KeyPair struct {
+	Private *rsa.PrivateKey
+	Public  []byte // the DER of the public key in PKIX, as sent in the Encryption Request
+}
+
+// ErrVerifyTokenMismatch is returned by DecryptResponse when the verify token
+// received isn't the one sent, which means the client didn't use our key.
+var ErrVerifyTokenMismatch = errors.New("auth: verify token mismatch")
+
+// GenerateKeyPair generate a 1024 bits RSA key pair, as the vanilla server does
+func GenerateKeyPair() (*KeyPair, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		return nil, fmt.Errorf("auth: generate key fail: %v", err)
+	}
+	public, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("auth: marshal public key fail: %v", err)
+	}
+	return &KeyPair{Private: key, Public: public}, nil
+}
+
+// NewVerifyToken generate a random verify token for the Encryption Request
+func NewVerifyToken() ([]byte, error) {
+	token := make([]byte, 4)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("auth: generate verify token fail: %v", err)
+	}
+	return token, nil
+}
+
+// DecryptResponse decrypt the shared secret and the verify token of an
+// Encryption Response with the private key, and check the token is the
+// verifyToken sent in the request. The returned shared secret is the key
+// and IV of the AES/CFB8 encryption.
+func (k *KeyPair) DecryptResponse(encryptedSecret, encryptedToken, verifyToken []byte) (sharedSecret []byte, err error) {
+	token, err := rsa.DecryptPKCS1v15(rand.Reader, k.Private, encryptedToken)
+	if err != nil {
+		return nil, fmt.Errorf("auth: decrypt verify token fail: %v", err)
+	}
+	if subtle.ConstantTimeCompare(token, verifyToken) != 1 {
+		return nil, ErrVerifyTokenMismatch
+	}
+	sharedSecret, err = rsa.DecryptPKCS1v15(rand.Reader, k.Private, encryptedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("auth: decrypt shared secret fail: %v", err)
+	}
+	if len(sharedSecret) != 16 {
+		return nil, fmt.Errorf("auth: shared secret of %d bytes, not 16", len(sharedSecret))
+	}
+	return sharedSecret, nil
+}
+
+// EncryptResponse encrypt the shared secret and the verify token with the
+// publicKey of an Encryption Request, for the Encryption Response of a client.
+func EncryptResponse(publicKey, sharedSecret, verifyToken []byte) (encryptedSecret, encryptedToken []byte, err error) {
+	key, err := x509.ParsePKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("auth: decode public key fail: %v", err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("auth: public key is %T, not RSA", key)
+	}
+	if encryptedSecret, err = rsa.EncryptPKCS1v15(rand.Reader, rsaKey, sharedSecret); err != nil {
+		return nil, nil, fmt.Errorf("auth: encrypt shared secret fail: %v", err)
+	}
+	if encryptedToken, err = rsa.EncryptPKCS1v15(rand.Reader, rsaKey, verifyToken); err != nil {
+		return nil, nil, fmt.Errorf("auth: encrypt verify token fail: %v", err)
+	}
+	return encryptedSecret, encryptedToken, nil
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"testing"
+)
+
+func TestKeyPair_DecryptResponse(t *testing.T) {
+	keys, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x509.ParsePKIXPublicKey(keys.Public); err != nil {
+		t.Fatalf("the public key isn't PKIX: %v", err)
+	}
+	token, err := NewVerifyToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		t.Fatal(err)
+	}
+
+	// the client side
+	cryptSecret, cryptToken, err := EncryptResponse(keys.Public, secret, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the server side
+	got, err := keys.DecryptResponse(cryptSecret, cryptToken, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("shared secret: got %x, want %x", got, secret)
+	}
+
+	if _, err := keys.DecryptResponse(cryptSecret, cryptToken, []byte("abcd")); !errors.Is(err, ErrVerifyTokenMismatch) {
+		t.Errorf("wrong verify token: got %v, want ErrVerifyTokenMismatch", err)
+	}
+	other, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.DecryptResponse(cryptSecret, cryptToken, token); err == nil {
+		t.Error("decrypted with another key")
+	}
+	if _, _, err := EncryptResponse([]byte("not a key"), secret, token); err == nil {
+		t.Error("encrypted with an invalid public key")
+	}
+}
@@ -0,0 +1,166 @@
+package microsoft
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Step is the step of the login
+type Step int
+
+const (
+	// StepDeviceCode is reported with UserCode and VerificationURI,
+	// the user should open the URI and enter the code to continue.
+	StepDeviceCode Step = iota
+	StepXboxLive
+	StepXSTS
+	StepMinecraft
+	StepProfile
+	// StepDone is the last state when succeed, with Session set.
+	StepDone
+	// StepFailed is the last state when failed, with Err set.
+	StepFailed
+)
+
+// AuthState is the state of the login reported by DeviceCodeLogin
+type AuthState struct {
+	Step Step
+
+	UserCode        string
+	VerificationURI string
+	Message         string // the message from Microsoft telling the user what to do
+	ExpiresAt       time.Time
+
+	Session *Session
+	Err     error
+}
+
+// oauthResp is the response of the device code and token endpoints
+type oauthResp struct {
+	// device code
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	Interval        int    `json:"interval"`
+	Message         string `json:"message"`
+	// token
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+
+	ExpiresIn int `json:"expires_in"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (o oauthResp) err() error {
+	if o.Error == "" {
+		return nil
+	}
+	return fmt.Errorf("%s: %s", o.Error, o.ErrorDescription)
+}
+
+func (o oauthResp) token() Token {
+	return Token{
+		AccessToken:  o.AccessToken,
+		RefreshToken: o.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(o.ExpiresIn) * time.Second),
+	}
+}
+
+// DeviceCodeLogin login a Microsoft account by the device code flow.
+//
+// The device code is requested before it returns. The steps are reported
+// to the returned channel, starting from StepDeviceCode which contains
+// the code the user should enter, and ending with StepDone or StepFailed,
+// then the channel is closed. The Session of StepDone can be cached and
+// refreshed later, so the user don't need to login again.
+func DeviceCodeLogin(ctx context.Context, clientID string) (<-chan AuthState, error) {
+	var dc oauthResp
+	err := postForm(ctx, MSAuthURL+"/devicecode", url.Values{
+		"client_id": {clientID},
+		"scope":     {Scope},
+	}, &dc)
+	if err != nil {
+		return nil, fmt.Errorf("request device code fail: %v", err)
+	}
+	if err := dc.err(); err != nil {
+		return nil, fmt.Errorf("request device code fail: %v", err)
+	}
+
+	states := make(chan AuthState, 1)
+	states <- AuthState{
+		Step:            StepDeviceCode,
+		UserCode:        dc.UserCode,
+		VerificationURI: dc.VerificationURI,
+		Message:         dc.Message,
+		ExpiresAt:       time.Now().Add(time.Duration(dc.ExpiresIn) * time.Second),
+	}
+	go func() {
+		defer close(states)
+		s, err := deviceCodeLogin(ctx, clientID, dc, states)
+		last := AuthState{Step: StepDone, Session: s}
+		if err != nil {
+			last = AuthState{Step: StepFailed, Err: err}
+		}
+		select {
+		case states <- last:
+		case <-ctx.Done():
+		}
+	}()
+	return states, nil
+}
+
+func deviceCodeLogin(ctx context.Context, clientID string, dc oauthResp, states chan<- AuthState) (*Session, error) {
+	interval := time.Duration(dc.Interval) * time.Second
+	for {
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		var resp oauthResp
+		err := postForm(ctx, MSAuthURL+"/token", url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"client_id":   {clientID},
+			"device_code": {dc.DeviceCode},
+		}, &resp)
+		if err != nil {
+			return nil, fmt.Errorf("request token fail: %v", err)
+		}
+
+		switch resp.Error {
+		case "":
+			s := &Session{MSA: resp.token()}
+			if err := s.login(ctx, states); err != nil {
+				return nil, err
+			}
+			return s, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		default: // authorization_declined, expired_token, bad_verification_code
+			return nil, fmt.Errorf("request token fail: %v", resp.err())
+		}
+	}
+}
+
+func refreshMSA(ctx context.Context, clientID, refreshToken string) (Token, error) {
+	var resp oauthResp
+	err := postForm(ctx, MSAuthURL+"/token", url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {clientID},
+		"refresh_token": {refreshToken},
+		"scope":         {Scope},
+	}, &resp)
+	if err != nil {
+		return Token{}, err
+	}
+	if err := resp.err(); err != nil {
+		return Token{}, err
+	}
+	return resp.token(), nil
+}
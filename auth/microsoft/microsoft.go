@@ -0,0 +1,213 @@
+// Package microsoft implement the login of Microsoft accounts.
+//
+// Since Mojang accounts were migrated, a Minecraft access token can only be
+// got by the following chain:
+// Microsoft OAuth token -> Xbox Live token -> XSTS token -> Minecraft token.
+// The OAuth token is got by the device code flow, so it works without a browser
+// embedded in the program. A clientID of an Azure application is required.
+package microsoft
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The URLs of the services in the chain
+var (
+	MSAuthURL     = "https://login.microsoftonline.com/consumers/oauth2/v2.0"
+	XBLAuthURL    = "https://user.auth.xboxlive.com/user/authenticate"
+	XSTSAuthURL   = "https://xsts.auth.xboxlive.com/xsts/authorize"
+	MinecraftURL  = "https://api.minecraftservices.com"
+	Scope         = "XboxLive.signin offline_access"
+	refreshMargin = time.Minute
+)
+
+var client http.Client
+
+// Token is an access token with its expire time
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Valid report whether the token exists and is not going to expire
+func (t Token) Valid() bool {
+	return t.AccessToken != "" && time.Now().Add(refreshMargin).Before(t.ExpiresAt)
+}
+
+// Profile is the Minecraft profile of the account
+type Profile struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// Session is the tokens and profile of a logged in account.
+// It can be cached by SaveSession and LoadSession, and be refreshed by Refresh.
+type Session struct {
+	MSA       Token   `json:"msa"`       // the Microsoft OAuth token
+	Minecraft Token   `json:"minecraft"` // the Minecraft access token
+	Profile   Profile `json:"profile"`
+}
+
+// Refresh refreshes the session if the Minecraft token is going to expire.
+// The Microsoft token is refreshed by its refresh token, then the new
+// Minecraft token and profile are got by the Xbox Live chain.
+func (s *Session) Refresh(ctx context.Context, clientID string) error {
+	if s.Minecraft.Valid() {
+		return nil
+	}
+	if !s.MSA.Valid() {
+		if s.MSA.RefreshToken == "" {
+			return fmt.Errorf("no refresh token")
+		}
+		msa, err := refreshMSA(ctx, clientID, s.MSA.RefreshToken)
+		if err != nil {
+			return fmt.Errorf("refresh microsoft token fail: %v", err)
+		}
+		s.MSA = msa
+	}
+	return s.login(ctx, nil)
+}
+
+// login gets the Minecraft token and profile by the Microsoft token,
+// and report the steps to states if it isn't nil.
+func (s *Session) login(ctx context.Context, states chan<- AuthState) error {
+	report := func(step Step) {
+		if states != nil {
+			select {
+			case states <- AuthState{Step: step}:
+			case <-ctx.Done():
+			}
+		}
+	}
+
+	report(StepXboxLive)
+	xbl, err := xboxLiveAuth(ctx, s.MSA.AccessToken)
+	if err != nil {
+		return fmt.Errorf("xbox live auth fail: %v", err)
+	}
+
+	report(StepXSTS)
+	xsts, err := xstsAuth(ctx, xbl.Token)
+	if err != nil {
+		return fmt.Errorf("xsts auth fail: %v", err)
+	}
+
+	report(StepMinecraft)
+	s.Minecraft, err = minecraftAuth(ctx, xsts)
+	if err != nil {
+		return fmt.Errorf("minecraft auth fail: %v", err)
+	}
+
+	report(StepProfile)
+	s.Profile, err = GetProfile(ctx, s.Minecraft.AccessToken)
+	if err != nil {
+		return fmt.Errorf("get profile fail: %v", err)
+	}
+	return nil
+}
+
+// LoadSession read the session saved by SaveSession
+func LoadSession(path string) (s Session, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &s)
+	return
+}
+
+// SaveSession write the session to file.
+// The file contains the tokens, so it's only readable by the owner.
+func SaveSession(path string, s Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// GetProfile get the Minecraft profile of the access token
+func GetProfile(ctx context.Context, accessToken string) (p Profile, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, MinecraftURL+"/minecraft/profile", nil)
+	if err != nil {
+		return p, fmt.Errorf("make request error: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	err = do(req, &p)
+	return
+}
+
+// postForm post the form and decode the JSON response.
+// The response is decoded even if the status code isn't 200,
+// because the OAuth errors are reported in it.
+func postForm(ctx context.Context, url string, form url.Values, resp interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("make request error: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rawResp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request fail: %v", err)
+	}
+	defer rawResp.Body.Close()
+
+	if err := json.NewDecoder(rawResp.Body).Decode(resp); err != nil {
+		return fmt.Errorf("parse resp fail: %v", err)
+	}
+	return nil
+}
+
+// postJSON post the payload as JSON and decode the JSON response
+func postJSON(ctx context.Context, url string, payload, resp interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload fail: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("make request error: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	return do(req, resp)
+}
+
+func do(req *http.Request, resp interface{}) error {
+	rawResp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request fail: %v", err)
+	}
+	defer rawResp.Body.Close()
+
+	if rawResp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(rawResp.Body)
+		return &StatusError{Code: rawResp.StatusCode, Body: string(body)}
+	}
+	if err := json.NewDecoder(rawResp.Body).Decode(resp); err != nil {
+		return fmt.Errorf("parse resp fail: %v", err)
+	}
+	return nil
+}
+
+// StatusError is returned when a service response with a status code other than 200
+type StatusError struct {
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("[%d] %s", e.Code, e.Body)
+}
@@ -0,0 +1,189 @@
+package microsoft
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeServices start a server acting as all of the services in the chain
+func fakeServices(t *testing.T) func() {
+	pending := 1
+	mux := http.NewServeMux()
+	writeJSON := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("/oauth/devicecode", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("client_id") != "client" || r.FormValue("scope") != Scope {
+			t.Errorf("wrong device code request: %v", r.Form)
+		}
+		writeJSON(w, map[string]interface{}{
+			"device_code": "device", "user_code": "ABCD1234", "verification_uri": "https://microsoft.com/devicelogin",
+			"interval": 0, "expires_in": 900, "message": "enter the code",
+		})
+	})
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		switch r.FormValue("grant_type") {
+		case "urn:ietf:params:oauth:grant-type:device_code":
+			if pending > 0 {
+				pending--
+				w.WriteHeader(http.StatusBadRequest)
+				writeJSON(w, map[string]string{"error": "authorization_pending"})
+				return
+			}
+			writeJSON(w, map[string]interface{}{"access_token": "msa", "refresh_token": "refresh", "expires_in": 3600})
+		case "refresh_token":
+			if r.FormValue("refresh_token") != "refresh" {
+				w.WriteHeader(http.StatusBadRequest)
+				writeJSON(w, map[string]string{"error": "invalid_grant", "error_description": "bad refresh token"})
+				return
+			}
+			writeJSON(w, map[string]interface{}{"access_token": "msa2", "refresh_token": "refresh2", "expires_in": 3600})
+		}
+	})
+	mux.HandleFunc("/xbl", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"RpsTicket":"d=msa`) {
+			t.Errorf("wrong xbox live request: %s", body)
+		}
+		writeJSON(w, map[string]interface{}{"Token": "xbl", "DisplayClaims": map[string]interface{}{"xui": []map[string]string{{"uhs": "hash"}}}})
+	})
+	mux.HandleFunc("/xsts", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(body), `"child"`) {
+			w.WriteHeader(http.StatusUnauthorized)
+			writeJSON(w, map[string]interface{}{"XErr": 2148916238})
+			return
+		}
+		writeJSON(w, map[string]interface{}{"Token": "xsts", "DisplayClaims": map[string]interface{}{"xui": []map[string]string{{"uhs": "hash"}}}})
+	})
+	mux.HandleFunc("/mc/authentication/login_with_xbox", func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ IdentityToken string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.IdentityToken != "XBL3.0 x=hash;xsts" {
+			t.Errorf("wrong identity token: %q", req.IdentityToken)
+		}
+		writeJSON(w, map[string]interface{}{"access_token": "minecraft", "expires_in": 86400})
+	})
+	mux.HandleFunc("/mc/minecraft/profile", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer minecraft" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, map[string]string{"id": "069a79f444e94726a5befca90e38aaf5", "name": "Notch"})
+	})
+	s := httptest.NewServer(mux)
+
+	urls := []*string{&MSAuthURL, &XBLAuthURL, &XSTSAuthURL, &MinecraftURL}
+	old := make([]string, len(urls))
+	for i, u := range urls {
+		old[i] = *u
+	}
+	MSAuthURL, XBLAuthURL, XSTSAuthURL, MinecraftURL = s.URL+"/oauth", s.URL+"/xbl", s.URL+"/xsts", s.URL+"/mc"
+	return func() {
+		s.Close()
+		for i, u := range urls {
+			*u = old[i]
+		}
+	}
+}
+
+func TestDeviceCodeLogin(t *testing.T) {
+	defer fakeServices(t)()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	states, err := DeviceCodeLogin(ctx, "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var steps []Step
+	var last AuthState
+	for s := range states {
+		if s.Step == StepDeviceCode && (s.UserCode != "ABCD1234" || s.VerificationURI != "https://microsoft.com/devicelogin") {
+			t.Errorf("wrong device code state: %+v", s)
+		}
+		steps = append(steps, s.Step)
+		last = s
+	}
+	want := []Step{StepDeviceCode, StepXboxLive, StepXSTS, StepMinecraft, StepProfile, StepDone}
+	if len(steps) != len(want) {
+		t.Fatalf("steps = %v, want %v (%v)", steps, want, last.Err)
+	}
+	for i := range want {
+		if steps[i] != want[i] {
+			t.Fatalf("steps = %v, want %v", steps, want)
+		}
+	}
+
+	s := last.Session
+	if s.Profile.Name != "Notch" || s.Profile.ID.String() != "069a79f4-44e9-4726-a5be-fca90e38aaf5" {
+		t.Errorf("wrong profile: %+v", s.Profile)
+	}
+	if s.MSA.RefreshToken != "refresh" || s.Minecraft.AccessToken != "minecraft" || !s.Minecraft.Valid() {
+		t.Errorf("wrong tokens: %+v", s)
+	}
+}
+
+func TestSession_Refresh(t *testing.T) {
+	defer fakeServices(t)()
+	ctx := context.Background()
+
+	expired := time.Now().Add(-time.Hour)
+	s := Session{
+		MSA:       Token{AccessToken: "msa", RefreshToken: "refresh", ExpiresAt: expired},
+		Minecraft: Token{AccessToken: "minecraft", ExpiresAt: expired},
+	}
+	if err := s.Refresh(ctx, "client"); err != nil {
+		t.Fatal(err)
+	}
+	if s.MSA.AccessToken != "msa2" || s.MSA.RefreshToken != "refresh2" || !s.Minecraft.Valid() || s.Profile.Name != "Notch" {
+		t.Errorf("session not refreshed: %+v", s)
+	}
+
+	s.MSA.RefreshToken = "revoked"
+	s.MSA.ExpiresAt, s.Minecraft.ExpiresAt = expired, expired
+	if err := s.Refresh(ctx, "client"); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("refresh by a revoked token should fail with invalid_grant, get %v", err)
+	}
+}
+
+func TestXSTSAuth_error(t *testing.T) {
+	defer fakeServices(t)()
+	_, err := xstsAuth(context.Background(), "child")
+	if err == nil || !strings.Contains(err.Error(), "Family") {
+		t.Errorf("XErr should be explained, get %v", err)
+	}
+}
+
+func TestSaveSession(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-mc-msa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "session.json")
+
+	s := Session{
+		MSA:     Token{AccessToken: "msa", RefreshToken: "refresh", ExpiresAt: time.Now().Add(time.Hour).Round(time.Second)},
+		Profile: Profile{Name: "Notch"},
+	}
+	if err := SaveSession(path, s); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadSession(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.MSA.RefreshToken != s.MSA.RefreshToken || !got.MSA.ExpiresAt.Equal(s.MSA.ExpiresAt) || got.Profile != s.Profile {
+		t.Errorf("load session = %+v, want %+v", got, s)
+	}
+}
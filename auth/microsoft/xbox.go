@@ -0,0 +1,86 @@
+package microsoft
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type xblResp struct {
+	Token         string `json:"Token"`
+	DisplayClaims struct {
+		Xui []struct {
+			Uhs string `json:"uhs"` // the user hash
+		} `json:"xui"`
+	} `json:"DisplayClaims"`
+}
+
+func (x xblResp) uhs() string {
+	if len(x.DisplayClaims.Xui) == 0 {
+		return ""
+	}
+	return x.DisplayClaims.Xui[0].Uhs
+}
+
+func xboxLiveAuth(ctx context.Context, msaToken string) (resp xblResp, err error) {
+	err = postJSON(ctx, XBLAuthURL, map[string]interface{}{
+		"Properties": map[string]interface{}{
+			"AuthMethod": "RPS",
+			"SiteName":   "user.auth.xboxlive.com",
+			"RpsTicket":  "d=" + msaToken,
+		},
+		"RelyingParty": "http://auth.xboxlive.com",
+		"TokenType":    "JWT",
+	}, &resp)
+	return
+}
+
+// xstsErrors are the known XErr of the XSTS service
+var xstsErrors = map[int64]string{
+	2148916233: "the account doesn't have an Xbox account",
+	2148916235: "Xbox Live is not available in the country of the account",
+	2148916236: "the account needs adult verification",
+	2148916237: "the account needs adult verification",
+	2148916238: "the account is a child and must be added to a Family",
+}
+
+func xstsAuth(ctx context.Context, xblToken string) (resp xblResp, err error) {
+	err = postJSON(ctx, XSTSAuthURL, map[string]interface{}{
+		"Properties": map[string]interface{}{
+			"SandboxId":  "RETAIL",
+			"UserTokens": []string{xblToken},
+		},
+		"RelyingParty": "rp://api.minecraftservices.com/",
+		"TokenType":    "JWT",
+	}, &resp)
+
+	var se *StatusError
+	if errors.As(err, &se) {
+		var xerr struct{ XErr int64 }
+		if json.Unmarshal([]byte(se.Body), &xerr) == nil {
+			if msg, ok := xstsErrors[xerr.XErr]; ok {
+				err = fmt.Errorf("%s (XErr %d)", msg, xerr.XErr)
+			}
+		}
+	}
+	return
+}
+
+func minecraftAuth(ctx context.Context, xsts xblResp) (Token, error) {
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	err := postJSON(ctx, MinecraftURL+"/authentication/login_with_xbox", map[string]string{
+		"identityToken": "XBL3.0 x=" + xsts.uhs() + ";" + xsts.Token,
+	}, &resp)
+	if err != nil {
+		return Token{}, err
+	}
+	return Token{
+		AccessToken: resp.AccessToken,
+		ExpiresAt:   time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second),
+	}, nil
+}
@@ -0,0 +1,49 @@
+package bot
+
+import "time"
+
+// EnableAutoRespawn makes the bot respawn once it dies.
+// It's safe to call before joining a server, and calling it again does nothing.
+func (c *Client) EnableAutoRespawn() {
+	if c.autoRespawn {
+		return
+	}
+	c.autoRespawn = true
+	c.OnDeath(func(DeathEvent) error {
+		// Whether the server shows the respawn screen or not,
+		// the vanilla client sends the request to perform the respawn.
+		return c.Respawn()
+	})
+}
+
+// EnableAntiAFK makes the bot swing its arm every interval,
+// so the server doesn't kick it for idling.
+// A zero or negative interval disables it.
+// It's safe to call before joining a server.
+func (c *Client) EnableAntiAFK(interval time.Duration) {
+	c.antiAFK.interval = interval
+	c.antiAFK.last = time.Time{}
+}
+
+type antiAFK struct {
+	interval time.Duration
+	last     time.Time // the last time of swinging arm
+}
+
+// antiAFKTick is called by HandleGame every tick
+func (c *Client) antiAFKTick() error {
+	a := &c.antiAFK
+	if a.interval <= 0 {
+		return nil
+	}
+	now := time.Now()
+	if a.last.IsZero() {
+		a.last = now
+		return nil
+	}
+	if now.Sub(a.last) < a.interval {
+		return nil
+	}
+	a.last = now
+	return c.SwingArm(0)
+}
@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/Tnze/go-mc/chat"
+	"github.com/Tnze/go-mc/data"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// sentPackets return the IDs of the packets have been written to buf
+func sentPackets(t *testing.T, buf *bytes.Buffer) (ids []int32) {
+	for buf.Len() > 0 {
+		p, err := pk.RecvPacket(buf, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, p.ID)
+	}
+	return
+}
+
+func TestClient_EnableAutoRespawn(t *testing.T) {
+	var buf bytes.Buffer
+	c := NewClient()
+	c.conn = &mcnet.Conn{Writer: &buf}
+	c.EntityID = 10
+	c.EnableAutoRespawn()
+	c.EnableAutoRespawn()
+
+	for _, p := range []pk.Packet{
+		pk.Marshal(data.UpdateHealth, pk.Float(0), pk.VarInt(20), pk.Float(5)),
+		pk.Marshal(data.CombatEvent, pk.VarInt(2), pk.VarInt(10), pk.Int(-1), chat.Text("Steve fell")),
+	} {
+		if _, err := c.handlePacket(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var respawns int
+	for _, id := range sentPackets(t, &buf) {
+		if id == data.ClientStatus {
+			respawns++
+		}
+	}
+	if respawns != 1 {
+		t.Errorf("should respawn once, get %d", respawns)
+	}
+}
+
+func TestClient_EnableAntiAFK(t *testing.T) {
+	var buf bytes.Buffer
+	c := NewClient()
+	c.conn = &mcnet.Conn{Writer: &buf}
+
+	if err := c.antiAFKTick(); err != nil || buf.Len() != 0 {
+		t.Fatalf("anti-AFK should be disabled by default")
+	}
+	c.EnableAntiAFK(time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if err := c.antiAFKTick(); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	if ids := sentPackets(t, &buf); len(ids) != 1 || ids[0] != data.AnimationServerbound {
+		t.Errorf("want one swing after the interval, get %v", ids)
+	}
+}
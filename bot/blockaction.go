@@ -0,0 +1,277 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Tnze/go-mc/bot/world"
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// The status of PlayerDigging
+const (
+	digStart  = 0
+	digCancel = 1
+	digFinish = 2
+)
+
+// blockActions are the BreakBlock and PlaceBlock waiting for the server
+type blockActions struct {
+	mu      sync.Mutex
+	digging map[digKey]chan<- digAck
+	changes map[world.BlockPos]chan<- world.BlockStatus
+}
+
+type digKey struct {
+	pos    world.BlockPos
+	status int
+}
+
+type digAck struct {
+	state      world.BlockStatus
+	successful bool
+}
+
+// BreakBlock dig the block at (x, y, z) until it's broken,
+// from the face towards the player.
+//
+// The blocks which are broken instantly, such as in creative mode, are
+// reported by the server at once. Otherwise BreakBlock waits for the time of
+// digging, given by DigTime, before finishing. If ctx is done while waiting,
+// the digging is canceled. The digging is also canceled with an error if
+// DigTime is nil, for the bot doesn't know the hardness of blocks.
+//
+// It waits for the server, so it must not be called in the goroutine
+// running HandleGame, such as in handlers or Delegate.
+func (c *Client) BreakBlock(ctx context.Context, x, y, z int) error {
+	var (
+		b    data.Block
+		ok   bool
+		face world.Face
+	)
+	if err := c.inGame(ctx, func() {
+		b, ok = c.Wd.Block(x, y, z)
+		face = c.faceToward(x, y, z)
+	}); err != nil {
+		return err
+	}
+	switch {
+	case !ok:
+		return errors.New("bot: break block fail: the chunk is not loaded")
+	case isAir(b):
+		return errors.New("bot: break block fail: no block there")
+	}
+
+	pos := world.BlockPos{X: x, Y: y, Z: z}
+	ack, err := c.dig(ctx, pos, digStart, face)
+	if err != nil {
+		return err
+	}
+	if broken(ack.state) {
+		return nil
+	}
+
+	if c.DigTime == nil {
+		_ = c.playerAction(digCancel, x, y, z, int(face))
+		return fmt.Errorf("bot: break block fail: %s isn't broken instantly, and DigTime is not set", b.Name)
+	}
+	timer := time.NewTimer(c.DigTime(b))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		_ = c.playerAction(digCancel, x, y, z, int(face))
+		return ctx.Err()
+	}
+
+	ack, err = c.dig(ctx, pos, digFinish, face)
+	if err != nil {
+		return err
+	}
+	if !broken(ack.state) {
+		return errors.New("bot: break block fail: the block is not broken")
+	}
+	return nil
+}
+
+// dig send the PlayerDigging and wait for the acknowledgement
+func (c *Client) dig(ctx context.Context, pos world.BlockPos, status int, face world.Face) (digAck, error) {
+	key := digKey{pos, status}
+	ch := make(chan digAck, 1)
+	ba := &c.blockActions
+	ba.mu.Lock()
+	if ba.digging == nil {
+		ba.digging = make(map[digKey]chan<- digAck)
+	}
+	ba.digging[key] = ch
+	ba.mu.Unlock()
+	defer func() {
+		ba.mu.Lock()
+		delete(ba.digging, key)
+		ba.mu.Unlock()
+	}()
+
+	if err := c.playerAction(status, pos.X, pos.Y, pos.Z, int(face)); err != nil {
+		return digAck{}, err
+	}
+	select {
+	case ack := <-ch:
+		if !ack.successful {
+			return ack, errors.New("bot: break block fail: rejected by the server")
+		}
+		return ack, nil
+	case <-ctx.Done():
+		if status == digStart {
+			_ = c.playerAction(digCancel, pos.X, pos.Y, pos.Z, int(face))
+		}
+		return digAck{}, ctx.Err()
+	}
+}
+
+// PlaceBlock place a block on the face of the block at (x, y, z),
+// with the item in the hand; 0: main hand, 1: off hand.
+// It returns after the server sends the block next to the face,
+// and fails if the block is not changed.
+//
+// It waits for the server, so it must not be called in the goroutine
+// running HandleGame, such as in handlers or Delegate.
+func (c *Client) PlaceBlock(ctx context.Context, x, y, z int, face world.Face, hand int) error {
+	dx, dy, dz := face.Offset()
+	pos := world.BlockPos{X: x + dx, Y: y + dy, Z: z + dz}
+
+	var before world.BlockStatus
+	if err := c.inGame(ctx, func() {
+		before = c.Wd.GetBlockStatus(pos.X, pos.Y, pos.Z)
+	}); err != nil {
+		return err
+	}
+
+	ch := make(chan world.BlockStatus, 1)
+	ba := &c.blockActions
+	ba.mu.Lock()
+	if ba.changes == nil {
+		ba.changes = make(map[world.BlockPos]chan<- world.BlockStatus)
+	}
+	ba.changes[pos] = ch
+	ba.mu.Unlock()
+	defer func() {
+		ba.mu.Lock()
+		delete(ba.changes, pos)
+		ba.mu.Unlock()
+	}()
+
+	// click the center of the face
+	cx, cy, cz := 0.5+float32(dx)/2, 0.5+float32(dy)/2, 0.5+float32(dz)/2
+	if err := c.UseBlock(hand, x, y, z, int(face), cx, cy, cz, false); err != nil {
+		return err
+	}
+	select {
+	case state := <-ch:
+		if state == before {
+			return errors.New("bot: place block fail: rejected by the server")
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// inGame run f in the goroutine running HandleGame and wait for it
+func (c *Client) inGame(ctx context.Context, f func()) error {
+	done := make(chan struct{})
+	task := func() error {
+		f()
+		close(done)
+		return nil
+	}
+	select {
+	case c.Delegate <- task:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	<-done // the task is run as soon as it's received
+	return nil
+}
+
+// faceToward return the face of the block at (x, y, z) towards the player's eyes
+func (c *Client) faceToward(x, y, z int) world.Face {
+	dx := c.X - (float64(x) + 0.5)
+	dy := c.Y + playerEyeHeight - (float64(y) + 0.5)
+	dz := c.Z - (float64(z) + 0.5)
+	switch ax, ay, az := math.Abs(dx), math.Abs(dy), math.Abs(dz); {
+	case ay >= ax && ay >= az:
+		if dy > 0 {
+			return world.Top
+		}
+		return world.Bottom
+	case ax >= az:
+		if dx > 0 {
+			return world.East
+		}
+		return world.West
+	default:
+		if dz > 0 {
+			return world.South
+		}
+		return world.North
+	}
+}
+
+func isAir(b data.Block) bool {
+	switch b.Name {
+	case "minecraft:air", "minecraft:cave_air", "minecraft:void_air":
+		return true
+	}
+	return false
+}
+
+// broken report whether the state is air, after a block is broken
+func broken(s world.BlockStatus) bool {
+	b, ok := data.BlockByStateID(int(s))
+	return ok && isAir(b)
+}
+
+func handleAcknowledgePlayerDiggingPacket(c *Client, p pk.Packet) error {
+	var (
+		pos        pk.Position
+		state      pk.VarInt
+		status     pk.VarInt
+		successful pk.Boolean
+	)
+	if err := p.Scan(&pos, &state, &status, &successful); err != nil {
+		return err
+	}
+	// the state is the block the server have, which corrects the local world
+	if err := c.updateBlock(pos.X, pos.Y, pos.Z, world.BlockStatus(state)); err != nil {
+		return err
+	}
+
+	key := digKey{world.BlockPos{X: pos.X, Y: pos.Y, Z: pos.Z}, int(status)}
+	ba := &c.blockActions
+	ba.mu.Lock()
+	ch, ok := ba.digging[key]
+	delete(ba.digging, key)
+	ba.mu.Unlock()
+	if ok {
+		ch <- digAck{world.BlockStatus(state), bool(successful)} // buffered
+	}
+	return nil
+}
+
+// blockChanged notify PlaceBlock waiting for the block
+func (c *Client) blockChanged(x, y, z int, state world.BlockStatus) {
+	pos := world.BlockPos{X: x, Y: y, Z: z}
+	ba := &c.blockActions
+	ba.mu.Lock()
+	ch, ok := ba.changes[pos]
+	delete(ba.changes, pos)
+	ba.mu.Unlock()
+	if ok {
+		ch <- state // buffered
+	}
+}
@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Tnze/go-mc/bot/world"
+	"github.com/Tnze/go-mc/data"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// readDigging read a PlayerDigging sent by the client
+func readDigging(t *testing.T, s *mcnet.Conn) (status int, pos pk.Position, face int) {
+	t.Helper()
+	p, err := s.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		st pk.VarInt
+		f  pk.Byte
+	)
+	if p.ID != data.PlayerDigging {
+		t.Fatalf("packet 0x%02X is not PlayerDigging", p.ID)
+	}
+	if err := p.Scan(&st, &pos, &f); err != nil {
+		t.Fatal(err)
+	}
+	return int(st), pos, int(f)
+}
+
+func TestClient_BreakBlock(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	c := NewClient()
+	c.conn = mcnet.WrapConn(client)
+	c.DisablePhysics = true
+	c.DigTime = func(b data.Block) time.Duration { return 2 * tickInterval }
+	c.X, c.Y, c.Z = 1.5, 66, 1.5
+	c.Wd.LoadChunk(0, 0, &world.Chunk{})
+	c.Wd.SetBlockStatus(1, 64, 1, stone)
+	c.Wd.SetBlockStatus(2, 64, 1, stone)
+	s := mcnet.WrapConn(server)
+
+	go c.HandleGame()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// dig, and finish after the time of digging
+	errs := make(chan error, 1)
+	go func() { errs <- c.BreakBlock(ctx, 1, 64, 1) }()
+	status, pos, face := readDigging(t, s)
+	if status != digStart || pos != (pk.Position{X: 1, Y: 64, Z: 1}) || face != int(world.Top) {
+		t.Fatalf("wrong start digging: %d %v %d", status, pos, face)
+	}
+	started := time.Now()
+	if err := s.WritePacket(pk.Marshal(data.AcknowledgePlayerDigging,
+		pos, pk.VarInt(stone), pk.VarInt(digStart), pk.Boolean(true))); err != nil {
+		t.Fatal(err)
+	}
+	status, pos, _ = readDigging(t, s)
+	if status != digFinish {
+		t.Fatalf("wrong finish digging: %d %v", status, pos)
+	}
+	if d := time.Since(started); d < 2*tickInterval {
+		t.Errorf("finish digging after %v, earlier than DigTime", d)
+	}
+	if err := s.WritePacket(pk.Marshal(data.AcknowledgePlayerDigging,
+		pos, pk.VarInt(0), pk.VarInt(digFinish), pk.Boolean(true))); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if s := c.Wd.GetBlockStatus(1, 64, 1); s != 0 {
+		t.Errorf("the block is %d after broken", s)
+	}
+
+	// broken instantly
+	go func() { errs <- c.BreakBlock(ctx, 2, 64, 1) }()
+	status, pos, _ = readDigging(t, s)
+	if err := s.WritePacket(pk.Marshal(data.AcknowledgePlayerDigging,
+		pos, pk.VarInt(0), pk.VarInt(status), pk.Boolean(true))); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+
+	// rejected
+	c.Delegate <- func() error { c.Wd.SetBlockStatus(2, 64, 1, stone); return nil }
+	go func() { errs <- c.BreakBlock(ctx, 2, 64, 1) }()
+	status, pos, _ = readDigging(t, s)
+	if err := s.WritePacket(pk.Marshal(data.AcknowledgePlayerDigging,
+		pos, pk.VarInt(stone), pk.VarInt(status), pk.Boolean(false))); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err == nil {
+		t.Error("BreakBlock should fail when the server rejects")
+	}
+
+	// not broken instantly, and the time of digging is unknown
+	c.DigTime = nil
+	go func() { errs <- c.BreakBlock(ctx, 2, 64, 1) }()
+	_, pos, _ = readDigging(t, s)
+	if err := s.WritePacket(pk.Marshal(data.AcknowledgePlayerDigging,
+		pos, pk.VarInt(stone), pk.VarInt(digStart), pk.Boolean(true))); err != nil {
+		t.Fatal(err)
+	}
+	if status, _, _ := readDigging(t, s); status != digCancel {
+		t.Errorf("digging should be canceled without DigTime, get status %d", status)
+	}
+	if err := <-errs; err == nil {
+		t.Error("BreakBlock should fail without DigTime")
+	}
+
+	// nothing to break
+	if err := c.BreakBlock(ctx, 1, 64, 1); err == nil {
+		t.Error("breaking air should fail")
+	}
+}
+
+func TestClient_PlaceBlock(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	c := NewClient()
+	c.conn = mcnet.WrapConn(client)
+	c.DisablePhysics = true
+	c.Wd.LoadChunk(0, 0, &world.Chunk{})
+	c.Wd.SetBlockStatus(3, 64, 1, stone)
+	s := mcnet.WrapConn(server)
+
+	go c.HandleGame()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, placed := range []pk.VarInt{stone, 0} {
+		errs := make(chan error, 1)
+		go func() { errs <- c.PlaceBlock(ctx, 3, 64, 1, world.Top, 0) }()
+
+		p, err := s.ReadPacket()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var (
+			hand, face                pk.VarInt
+			pos                       pk.Position
+			cursorX, cursorY, cursorZ pk.Float
+			inside                    pk.Boolean
+		)
+		if err := p.Scan(&hand, &pos, &face, &cursorX, &cursorY, &cursorZ, &inside); err != nil {
+			t.Fatal(err)
+		}
+		if p.ID != data.PlayerBlockPlacement || pos != (pk.Position{X: 3, Y: 64, Z: 1}) ||
+			face != pk.VarInt(world.Top) || cursorX != 0.5 || cursorY != 1 || cursorZ != 0.5 {
+			t.Fatalf("wrong block placement: %v %v %v %v %v", pos, face, cursorX, cursorY, cursorZ)
+		}
+
+		if err := s.WritePacket(pk.Marshal(data.BlockChange, pk.Position{X: 3, Y: 65, Z: 1}, placed)); err != nil {
+			t.Fatal(err)
+		}
+		err = <-errs
+		if placed == stone && err != nil {
+			t.Errorf("place block: %v", err)
+		}
+		if placed == 0 && err == nil {
+			t.Error("PlaceBlock should fail if the block is not changed")
+		}
+		c.Delegate <- func() error { c.Wd.SetBlockStatus(3, 65, 1, 0); return nil }
+	}
+}
@@ -0,0 +1,127 @@
+package bot
+
+import (
+	"bytes"
+
+	"github.com/Tnze/go-mc/chat"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
+)
+
+// BossBar is a boss bar displayed at the top of the screen.
+// Besides the bosses, the servers use them to show the minigame states.
+type BossBar struct {
+	UUID     uuid.UUID
+	Title    chat.Message
+	Health   float32 // from 0 to 1
+	Color    int32   // 0: pink, 1: blue, 2: red, 3: green, 4: yellow, 5: purple, 6: white
+	Division int32   // 0: no division, 1: 6 notches, 2: 10 notches, 3: 12 notches, 4: 20 notches
+	Flags    byte    // see BossBarDarkenSky and others
+}
+
+// The flags of BossBar
+const (
+	BossBarDarkenSky = 0x01
+	BossBarDragonBar = 0x02 // plays the end music
+	BossBarCreateFog = 0x04
+)
+
+// The actions of BossBarEvent
+const (
+	BossBarAdd = iota
+	BossBarRemove
+	BossBarUpdateHealth
+	BossBarUpdateTitle
+	BossBarUpdateStyle // the Color and Division
+	BossBarUpdateFlags
+)
+
+// BossBarEvent is fired when a boss bar is added, removed or updated.
+// The Bar is the state after the action, or the last state for BossBarRemove.
+type BossBarEvent struct {
+	Action int
+	Bar    BossBar
+}
+
+// OnBossBar subscribe BossBarEvent
+func (c *Client) OnBossBar(f func(BossBarEvent) error) (unsubscribe func()) {
+	return c.bus.bossBar.add(f)
+}
+
+// BossBars return the boss bars displayed now, by their UUIDs.
+// The returned map is a copy.
+// It should only be called in HandleGame or a Delegate.
+func (c *Client) BossBars() map[uuid.UUID]BossBar {
+	bars := make(map[uuid.UUID]BossBar, len(c.bossBars))
+	for id, bar := range c.bossBars {
+		bars[id] = bar
+	}
+	return bars
+}
+
+func handleBossBarPacket(c *Client, p pk.Packet) error {
+	var (
+		id     pk.UUID
+		action pk.VarInt
+	)
+	r := bytes.NewReader(p.Data)
+	if err := decodeFields(r, &id, &action); err != nil {
+		return err
+	}
+	bar, ok := c.bossBars[uuid.UUID(id)]
+	if !ok && action != BossBarAdd {
+		return nil // not added, ignore it
+	}
+	bar.UUID = uuid.UUID(id)
+
+	var (
+		health          pk.Float
+		color, division pk.VarInt
+		flags           pk.UnsignedByte
+	)
+	switch action {
+	case BossBarAdd:
+		if err := decodeFields(r, &bar.Title, &health, &color, &division, &flags); err != nil {
+			return err
+		}
+		bar.Health = float32(health)
+		bar.Color, bar.Division = int32(color), int32(division)
+		bar.Flags = byte(flags)
+	case BossBarRemove:
+	case BossBarUpdateHealth:
+		if err := health.Decode(r); err != nil {
+			return err
+		}
+		bar.Health = float32(health)
+	case BossBarUpdateTitle:
+		var title chat.Message
+		if err := title.Decode(r); err != nil {
+			return err
+		}
+		bar.Title = title
+	case BossBarUpdateStyle:
+		if err := decodeFields(r, &color, &division); err != nil {
+			return err
+		}
+		bar.Color, bar.Division = int32(color), int32(division)
+	case BossBarUpdateFlags:
+		if err := flags.Decode(r); err != nil {
+			return err
+		}
+		bar.Flags = byte(flags)
+	default:
+		return nil
+	}
+
+	if action == BossBarRemove {
+		delete(c.bossBars, bar.UUID)
+	} else {
+		if c.bossBars == nil {
+			c.bossBars = make(map[uuid.UUID]BossBar)
+		}
+		c.bossBars[bar.UUID] = bar
+	}
+	return c.bus.bossBar.fire(func(f interface{}) error {
+		return f.(func(BossBarEvent) error)(BossBarEvent{Action: int(action), Bar: bar})
+	})
+}
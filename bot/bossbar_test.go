@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
+)
+
+func TestClient_BossBars(t *testing.T) {
+	c := NewClient()
+	var events []BossBarEvent
+	c.OnBossBar(func(e BossBarEvent) error {
+		events = append(events, e)
+		return nil
+	})
+	handle := func(p pk.Packet) {
+		t.Helper()
+		if _, err := c.handlePacket(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	id := uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5")
+	handle(pk.Marshal(data.BossBar, pk.UUID(id), pk.VarInt(BossBarAdd),
+		pk.Chat(`{"text":"Ender Dragon"}`), pk.Float(1), pk.VarInt(5), pk.VarInt(0),
+		pk.UnsignedByte(BossBarDarkenSky|BossBarDragonBar),
+	))
+	bar, ok := c.BossBars()[id]
+	if !ok {
+		t.Fatal("the boss bar isn't added")
+	}
+	if bar.Title.ClearString() != "Ender Dragon" || bar.Health != 1 || bar.Color != 5 || bar.Flags != BossBarDarkenSky|BossBarDragonBar {
+		t.Errorf("wrong boss bar: %+v", bar)
+	}
+
+	handle(pk.Marshal(data.BossBar, pk.UUID(id), pk.VarInt(BossBarUpdateHealth), pk.Float(0.25)))
+	handle(pk.Marshal(data.BossBar, pk.UUID(id), pk.VarInt(BossBarUpdateTitle), pk.Chat(`"Round 2"`)))
+	handle(pk.Marshal(data.BossBar, pk.UUID(id), pk.VarInt(BossBarUpdateStyle), pk.VarInt(2), pk.VarInt(4)))
+	handle(pk.Marshal(data.BossBar, pk.UUID(id), pk.VarInt(BossBarUpdateFlags), pk.UnsignedByte(BossBarCreateFog)))
+	bar = c.BossBars()[id]
+	if bar.Health != 0.25 || bar.Title.ClearString() != "Round 2" || bar.Color != 2 || bar.Division != 4 || bar.Flags != BossBarCreateFog {
+		t.Errorf("wrong updated boss bar: %+v", bar)
+	}
+
+	// the updates of unknown bars are ignored
+	handle(pk.Marshal(data.BossBar, pk.UUID{}, pk.VarInt(BossBarUpdateHealth), pk.Float(0.5)))
+	handle(pk.Marshal(data.BossBar, pk.UUID(id), pk.VarInt(BossBarRemove)))
+	if len(c.BossBars()) != 0 {
+		t.Errorf("boss bars left: %v", c.BossBars())
+	}
+
+	actions := []int{BossBarAdd, BossBarUpdateHealth, BossBarUpdateTitle, BossBarUpdateStyle, BossBarUpdateFlags, BossBarRemove}
+	if len(events) != len(actions) {
+		t.Fatalf("%d events, want %d", len(events), len(actions))
+	}
+	for i, e := range events {
+		if e.Action != actions[i] || e.Bar.UUID != id {
+			t.Errorf("event %d: action %d of %v", i, e.Action, e.Bar.UUID)
+		}
+	}
+	if last := events[len(events)-1].Bar; last.Health != 0.25 {
+		t.Errorf("the removed bar isn't the last state: %+v", last)
+	}
+}
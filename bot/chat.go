@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// MaxChatLength is the max length of a chat message the server accepts,
+// counted in UTF-16 code units
+const MaxChatLength = 256
+
+// DefaultChatInterval is the default of Client.ChatInterval
+const DefaultChatInterval = time.Second
+
+type chatQueue struct {
+	mu   sync.Mutex
+	msgs []string
+	last time.Time // the last time of sending
+}
+
+// Chat send chat as chat message or command at textbox.
+//
+// The messages are queued and sent by HandleGame, one per ChatInterval,
+// so the server doesn't kick the bot for spamming. Messages longer than
+// MaxChatLength are split into several, preferably at spaces, but commands
+// which start with '/' can't be split and Chat returns an error for them.
+// It's safe to be called from multiple goroutines.
+func (c *Client) Chat(msg string) error {
+	if msg == "" {
+		return errors.New("bot: empty chat message")
+	}
+	var msgs []string
+	if strings.HasPrefix(msg, "/") {
+		if chatLen(msg) > MaxChatLength {
+			return errors.New("bot: command too long")
+		}
+		msgs = []string{msg}
+	} else {
+		msgs = splitChat(msg, MaxChatLength)
+	}
+
+	q := &c.chatQueue
+	q.mu.Lock()
+	q.msgs = append(q.msgs, msgs...)
+	q.mu.Unlock()
+	return nil
+}
+
+// chatTick is called by HandleGame every tick
+func (c *Client) chatTick() error {
+	q := &c.chatQueue
+	q.mu.Lock()
+	now := time.Now()
+	if len(q.msgs) == 0 || now.Sub(q.last) < c.ChatInterval {
+		q.mu.Unlock()
+		return nil
+	}
+	msg := q.msgs[0]
+	q.msgs = q.msgs[1:]
+	q.last = now
+	q.mu.Unlock()
+
+	return c.SendPacket(pk.Marshal(
+		data.ChatMessageServerbound,
+		pk.String(msg),
+	))
+}
+
+// chatLen return the length of s as the server counts
+func chatLen(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeLen(r)
+	}
+	return n
+}
+
+// runeLen return the number of UTF-16 code units of r
+func runeLen(r rune) int {
+	if r >= 0x10000 {
+		return 2 // surrogate pair
+	}
+	return 1
+}
+
+// splitChat split msg into parts no longer than max.
+// It splits at the last space of each part if possible, which is dropped.
+func splitChat(msg string, max int) (parts []string) {
+	for chatLen(msg) > max {
+		// find the longest prefix in the limit
+		end, n := len(msg), 0
+		for i, r := range msg {
+			if n += runeLen(r); n > max {
+				end = i
+				break
+			}
+		}
+		if sp := strings.LastIndexByte(msg[:end+1], ' '); sp > 0 {
+			parts = append(parts, msg[:sp])
+			msg = msg[sp+1:]
+		} else {
+			parts = append(parts, msg[:end])
+			msg = msg[end:]
+		}
+	}
+	if msg != "" {
+		parts = append(parts, msg)
+	}
+	return
+}
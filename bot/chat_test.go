@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tnze/go-mc/data"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+func TestSplitChat(t *testing.T) {
+	for _, tc := range []struct {
+		msg  string
+		max  int
+		want []string
+	}{
+		{"hello", 10, []string{"hello"}},
+		{"hello world", 5, []string{"hello", "world"}},
+		{"hello world", 8, []string{"hello", "world"}},
+		{"hello world foo", 11, []string{"hello world", "foo"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"ab 😀😀", 4, []string{"ab", "😀😀"}}, // an emoji is 2 UTF-16 units
+		{"😀😀😀", 3, []string{"😀", "😀", "😀"}},
+	} {
+		got := splitChat(tc.msg, tc.max)
+		if strings.Join(got, "|") != strings.Join(tc.want, "|") {
+			t.Errorf("splitChat(%q, %d) = %q, want %q", tc.msg, tc.max, got, tc.want)
+		}
+		for _, part := range got {
+			if chatLen(part) > tc.max {
+				t.Errorf("part %q is longer than %d", part, tc.max)
+			}
+		}
+	}
+}
+
+func TestClient_Chat(t *testing.T) {
+	var buf bytes.Buffer
+	c := NewClient()
+	c.conn = &mcnet.Conn{Writer: &buf}
+	c.ChatInterval = time.Hour
+
+	long := strings.Repeat("word ", 60) // 300 characters
+	if err := c.Chat(long); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Chat("/say " + long); err == nil {
+		t.Error("commands longer than the limit should be rejected")
+	}
+
+	// one message per interval
+	for i := 0; i < 3; i++ {
+		if err := c.chatTick(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p, err := pk.RecvPacket(&buf, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg pk.String
+	if err := p.Scan(&msg); err != nil || p.ID != data.ChatMessageServerbound {
+		t.Fatalf("wrong chat packet: %v %v", p, err)
+	}
+	if len(msg) > MaxChatLength || !strings.HasPrefix(long, string(msg)) {
+		t.Errorf("first part: %q", msg)
+	}
+	if buf.Len() != 0 {
+		t.Error("the second part is sent before the interval")
+	}
+
+	c.chatQueue.last = time.Now().Add(-time.Hour)
+	if err := c.chatTick(); err != nil {
+		t.Fatal(err)
+	}
+	if ids := sentPackets(t, &buf); len(ids) != 1 {
+		t.Errorf("the second part should be sent after the interval, get %v", ids)
+	}
+}
@@ -1,14 +1,20 @@
 package bot
 
 import (
+	"sync"
+	"time"
+
 	"github.com/Tnze/go-mc/bot/world"
-	"github.com/Tnze/go-mc/bot/world/entity"
 	"github.com/Tnze/go-mc/bot/world/entity/player"
+	"github.com/Tnze/go-mc/data"
 	"github.com/Tnze/go-mc/net"
+	"github.com/google/uuid"
 )
 
 // Client is used to access Minecraft server
 type Client struct {
+	ping int64 // latency in nanoseconds, see Ping. Keep it first for 64-bit alignment of atomic.
+
 	conn *net.Conn
 	Auth
 
@@ -22,6 +28,51 @@ type Client struct {
 	// Do not send at the same goroutine!
 	Delegate chan func() error
 	Events   eventBroker
+
+	// Brand is sent to the server on the "minecraft:brand" channel
+	// when the server sends its brand. Empty for not sending.
+	Brand string
+
+	// ChatInterval is the min interval of sending the chat messages queued by Chat
+	ChatInterval time.Duration
+
+	// DigTime decides how long BreakBlock digs the block.
+	// If it's nil, BreakBlock only breaks the blocks broken instantly.
+	DigTime func(b data.Block) time.Duration
+
+	// DisableKeepAlive stops the bot responding the keep alive packets.
+	// Then you should response them by yourself, or get kicked by the server.
+	DisableKeepAlive bool
+	// DisablePhysics stops the bot simulating gravity and collision,
+	// and sending the movement packets every tick.
+	DisablePhysics bool
+	physics        physics // see WalkTo
+	sendMu         sync.Mutex
+
+	// Logger receives the logs of the bot and its connection.
+	// Nil means no logs.
+	Logger net.Logger
+
+	handlers map[int32][]PacketHandler // see AddHandler
+	bus      eventBus                  // see OnChatMessage and others
+	dead     bool                      // the DeathEvent is fired, see died
+
+	inventories  inventories  // see Inventory
+	commands     *CommandNode // see Commands
+	tabCompletes tabCompletes // see TabComplete
+
+	autoRespawn bool    // see EnableAutoRespawn
+	antiAFK     antiAFK // see EnableAntiAFK
+
+	time         worldTime    // see WorldTime
+	blockActions blockActions // see BreakBlock
+	chatQueue    chatQueue    // see Chat
+	joinGame     *JoinGame    // see JoinGame
+
+	bossBars   map[uuid.UUID]BossBar          // see BossBars
+	scoreboard Scoreboard                     // see Scoreboard
+	teams      map[string]*Team               // see Teams
+	playerList map[uuid.UUID]*PlayerListEntry // see PlayerList
 }
 
 // NewClient init and return a new Client.
@@ -37,16 +88,23 @@ func NewClient() (c *Client) {
 	//init Client
 	c.settings = DefaultSettings
 	c.Name = "Steve"
+	c.Brand = DefaultBrand
+	c.ChatInterval = DefaultChatInterval
 	c.Delegate = make(chan func() error)
 
 	c.Wd = world.World{
-		Entities: make(map[int32]entity.Entity),
+		Entities: make(map[int32]*world.Entity),
 		Chunks:   make(map[world.ChunkLoc]*world.Chunk),
 	}
 
 	return
 }
 
+// logging report whether c.Logger should be called
+func (c *Client) logging() bool {
+	return c.Logger != nil && c.Logger != net.NopLogger
+}
+
 //PlayInfo content player info in server.
 type PlayInfo struct {
 	Gamemode         int    //游戏模式
@@ -0,0 +1,240 @@
+package bot
+
+import (
+	"bytes"
+	"fmt"
+
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// CommandNodeType is the type of CommandNode
+type CommandNodeType byte
+
+// Types of CommandNode
+const (
+	RootNode CommandNodeType = iota
+	LiteralNode
+	ArgumentNode
+)
+
+// CommandNode is a node of the command tree sent by the server.
+// A command is a path from the root, following Children or Redirect.
+// Redirect may point back to an ancestor, so the tree can have loops.
+type CommandNode struct {
+	Type       CommandNodeType
+	Name       string // the literal, or the name of the argument
+	Executable bool   // whether the command can end at this node
+	Children   []*CommandNode
+	Redirect   *CommandNode
+
+	// Parser of the argument, like "brigadier:integer"
+	Parser string
+	// Properties of the parser:
+	// NumberRange for brigadier:double, brigadier:float, brigadier:integer and brigadier:long,
+	// int for brigadier:string (0: single word, 1: quotable phrase, 2: greedy phrase),
+	// byte flags for minecraft:entity and minecraft:score_holder,
+	// bool for minecraft:range (whether decimals are allowed), or nil for the others.
+	Properties interface{}
+	// Suggestions is the type of suggestions of the argument like "minecraft:ask_server".
+	// Empty if not set.
+	Suggestions string
+}
+
+// NumberRange is the bounds of a number argument
+type NumberRange struct {
+	Min, Max       float64
+	HasMin, HasMax bool
+}
+
+// Commands return the root of the command tree, or nil if it's not received.
+func (c *Client) Commands() *CommandNode {
+	return c.commands
+}
+
+func handleDeclareCommandsPacket(c *Client, p pk.Packet) error {
+	root, err := decodeCommands(p)
+	if err != nil {
+		return fmt.Errorf("bot: decode commands fail: %v", err)
+	}
+	c.commands = root
+	return nil
+}
+
+func decodeCommands(p pk.Packet) (*CommandNode, error) {
+	r := bytes.NewReader(p.Data)
+	var count pk.VarInt
+	if err := count.Decode(r); err != nil {
+		return nil, err
+	}
+	if count < 1 || int(count) > r.Len() { // each node has at least 2 bytes
+		return nil, fmt.Errorf("invalid nodes count %d", count)
+	}
+
+	nodes := make([]*CommandNode, count)
+	for i := range nodes {
+		nodes[i] = new(CommandNode)
+	}
+	children := make([][]pk.VarInt, count)
+	redirects := make([]pk.VarInt, count)
+	for i, n := range nodes {
+		var err error
+		if children[i], redirects[i], err = decodeCommandNode(r, n); err != nil {
+			return nil, fmt.Errorf("node[%d]: %v", i, err)
+		}
+	}
+	var root pk.VarInt
+	if err := root.Decode(r); err != nil {
+		return nil, err
+	}
+
+	// link the nodes
+	node := func(i pk.VarInt) (*CommandNode, error) {
+		if i < 0 || int(i) >= len(nodes) {
+			return nil, fmt.Errorf("node index %d out of range", i)
+		}
+		return nodes[i], nil
+	}
+	for i, n := range nodes {
+		for _, ci := range children[i] {
+			child, err := node(ci)
+			if err != nil {
+				return nil, err
+			}
+			n.Children = append(n.Children, child)
+		}
+		if redirects[i] >= 0 {
+			var err error
+			if n.Redirect, err = node(redirects[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return node(root)
+}
+
+// decodeCommandNode decode a node, and return the indexes of its children and redirect.
+// The redirect is -1 if not present.
+func decodeCommandNode(r pk.DecodeReader, n *CommandNode) (children []pk.VarInt, redirect pk.VarInt, err error) {
+	var flags pk.Byte
+	if err = flags.Decode(r); err != nil {
+		return
+	}
+	n.Type = CommandNodeType(flags & 0x03)
+	n.Executable = flags&0x04 != 0
+
+	var count pk.VarInt
+	if err = count.Decode(r); err != nil {
+		return
+	}
+	if count < 0 {
+		return nil, 0, fmt.Errorf("negative children count %d", count)
+	}
+	for i := 0; i < int(count); i++ {
+		var child pk.VarInt
+		if err = child.Decode(r); err != nil {
+			return
+		}
+		children = append(children, child)
+	}
+
+	redirect = -1
+	if flags&0x08 != 0 {
+		if err = redirect.Decode(r); err != nil {
+			return
+		}
+	}
+
+	switch n.Type {
+	case RootNode:
+		return
+	case LiteralNode, ArgumentNode:
+		var name pk.String
+		if err = name.Decode(r); err != nil {
+			return
+		}
+		n.Name = string(name)
+	default:
+		return nil, 0, fmt.Errorf("unknown node type %d", n.Type)
+	}
+	if n.Type == LiteralNode {
+		return
+	}
+
+	var parser pk.Identifier
+	if err = parser.Decode(r); err != nil {
+		return
+	}
+	n.Parser = string(parser)
+	if n.Properties, err = decodeParserProperties(r, n.Parser); err != nil {
+		return
+	}
+	if flags&0x10 != 0 {
+		var suggestions pk.Identifier
+		if err = suggestions.Decode(r); err != nil {
+			return
+		}
+		n.Suggestions = string(suggestions)
+	}
+	return
+}
+
+func decodeParserProperties(r pk.DecodeReader, parser string) (interface{}, error) {
+	switch parser {
+	case "brigadier:double", "brigadier:float", "brigadier:integer", "brigadier:long":
+		var flags pk.Byte
+		if err := flags.Decode(r); err != nil {
+			return nil, err
+		}
+		var (
+			nr  NumberRange
+			err error
+		)
+		if flags&0x01 != 0 {
+			if nr.Min, err = decodeNumber(r, parser); err != nil {
+				return nil, err
+			}
+			nr.HasMin = true
+		}
+		if flags&0x02 != 0 {
+			if nr.Max, err = decodeNumber(r, parser); err != nil {
+				return nil, err
+			}
+			nr.HasMax = true
+		}
+		return nr, nil
+	case "brigadier:string":
+		var t pk.VarInt
+		err := t.Decode(r)
+		return int(t), err
+	case "minecraft:entity", "minecraft:score_holder":
+		var flags pk.Byte
+		err := flags.Decode(r)
+		return byte(flags), err
+	case "minecraft:range":
+		var decimals pk.Boolean
+		err := decimals.Decode(r)
+		return bool(decimals), err
+	}
+	return nil, nil
+}
+
+func decodeNumber(r pk.DecodeReader, parser string) (float64, error) {
+	switch parser {
+	case "brigadier:double":
+		var v pk.Double
+		err := v.Decode(r)
+		return float64(v), err
+	case "brigadier:float":
+		var v pk.Float
+		err := v.Decode(r)
+		return float64(v), err
+	case "brigadier:integer":
+		var v pk.Int
+		err := v.Decode(r)
+		return float64(v), err
+	default: // brigadier:long
+		var v pk.Long
+		err := v.Decode(r)
+		return float64(v), err
+	}
+}
@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+func TestClient_Commands(t *testing.T) {
+	c := NewClient()
+	// root -> "tp" -> <count: integer 1..64> (executable)
+	// root -> "execute" (redirect to root)
+	p := pk.Marshal(data.DeclareCommands,
+		pk.VarInt(4),
+		// 0: root
+		pk.Byte(0x00), pk.VarInt(2), pk.VarInt(1), pk.VarInt(3),
+		// 1: literal "tp"
+		pk.Byte(0x01), pk.VarInt(1), pk.VarInt(2), pk.String("tp"),
+		// 2: argument "count", executable, with suggestions
+		pk.Byte(0x02|0x04|0x10), pk.VarInt(0), pk.String("count"),
+		pk.Identifier("brigadier:integer"), pk.Byte(0x03), pk.Int(1), pk.Int(64),
+		pk.Identifier("minecraft:ask_server"),
+		// 3: literal "execute", redirect to root
+		pk.Byte(0x01|0x08), pk.VarInt(0), pk.VarInt(0), pk.String("execute"),
+		pk.VarInt(0), // root index
+	)
+	if _, err := c.handlePacket(p); err != nil {
+		t.Fatal(err)
+	}
+
+	root := c.Commands()
+	if root == nil || root.Type != RootNode || len(root.Children) != 2 {
+		t.Fatalf("wrong root: %+v", root)
+	}
+	tp := root.Children[0]
+	if tp.Type != LiteralNode || tp.Name != "tp" || tp.Executable || len(tp.Children) != 1 {
+		t.Errorf("wrong literal node: %+v", tp)
+	}
+	count := tp.Children[0]
+	if count.Type != ArgumentNode || count.Name != "count" || !count.Executable ||
+		count.Parser != "brigadier:integer" || count.Suggestions != "minecraft:ask_server" {
+		t.Errorf("wrong argument node: %+v", count)
+	}
+	if nr, ok := count.Properties.(NumberRange); !ok || !nr.HasMin || !nr.HasMax || nr.Min != 1 || nr.Max != 64 {
+		t.Errorf("wrong properties: %+v", count.Properties)
+	}
+	if execute := root.Children[1]; execute.Name != "execute" || execute.Redirect != root {
+		t.Errorf("execute should redirect to root: %+v", execute)
+	}
+
+	bad := pk.Marshal(data.DeclareCommands,
+		pk.VarInt(1), pk.Byte(0x00), pk.VarInt(1), pk.VarInt(5), pk.VarInt(0))
+	if _, err := c.handlePacket(bad); err == nil {
+		t.Error("child index out of range should be an error")
+	}
+}
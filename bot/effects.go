@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"bytes"
+
+	"github.com/Tnze/go-mc/bot/world"
+	"github.com/Tnze/go-mc/bot/world/entity"
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// SoundEvent is fired when the server plays a sound, by the Sound Effect
+// or the Named Sound Effect packet
+type SoundEvent struct {
+	// ID is the sound ID, or -1 for the named sound
+	ID       int32
+	Name     string // like "minecraft:entity.zombie.ambient"
+	Category int32  // 0: master, 1: music, 2: record, 3: weather, 4: block and so on
+	X, Y, Z  float64
+	Volume   float32 // 1 is 100%, can be more
+	Pitch    float32 // between 0.5 and 2
+}
+
+// The IDs of the particles with data
+const (
+	ParticleBlock       = 3  // with BlockState
+	ParticleDust        = 14 // with Dust
+	ParticleFallingDust = 23 // with BlockState
+	ParticleItem        = 34 // with Item
+)
+
+// ParticleEvent is fired when the server displays particles
+type ParticleEvent struct {
+	Particle     int32 // the particle ID
+	LongDistance bool  // visible up to 65536 blocks, instead of 256
+	X, Y, Z      float64
+	// The offsets are multiplied by random numbers, and added to X, Y, Z
+	OffsetX, OffsetY, OffsetZ float32
+	Speed                     float32 // the particle data, which is speed for most particles
+	Count                     int32
+
+	// The data of the particle, depends on the Particle ID
+	BlockState world.BlockStatus // ParticleBlock and ParticleFallingDust
+	Item       entity.Slot       // ParticleItem
+	Dust       DustColor         // ParticleDust
+}
+
+// DustColor is the color of the dust particles, each in 0 to 1
+type DustColor struct {
+	Red, Green, Blue float32
+	Scale            float32 // in 0.01 to 4
+}
+
+// OnSound subscribe SoundEvent
+func (c *Client) OnSound(f func(SoundEvent) error) (unsubscribe func()) {
+	return c.bus.sound.add(f)
+}
+
+// OnParticle subscribe ParticleEvent
+func (c *Client) OnParticle(f func(ParticleEvent) error) (unsubscribe func()) {
+	return c.bus.particle.add(f)
+}
+
+func handleSoundEffect(c *Client, p pk.Packet) error {
+	var (
+		SoundID       pk.VarInt
+		SoundCategory pk.VarInt
+		x, y, z       pk.Int
+		Volume, Pitch pk.Float
+	)
+	err := p.Scan(&SoundID, &SoundCategory, &x, &y, &z, &Volume, &Pitch)
+	if err != nil {
+		return err
+	}
+	var name string // unknown for the sounds out of data.SoundNames
+	if SoundID >= 0 && int(SoundID) < len(data.SoundNames) {
+		name = data.SoundNames[SoundID]
+	}
+	return c.playSound(SoundEvent{
+		ID:       int32(SoundID),
+		Name:     name,
+		Category: int32(SoundCategory),
+		X:        float64(x) / 8,
+		Y:        float64(y) / 8,
+		Z:        float64(z) / 8,
+		Volume:   float32(Volume),
+		Pitch:    float32(Pitch),
+	})
+}
+
+func handleNamedSoundEffect(c *Client, p pk.Packet) error {
+	var (
+		SoundName     pk.Identifier
+		SoundCategory pk.VarInt
+		x, y, z       pk.Int
+		Volume, Pitch pk.Float
+	)
+	err := p.Scan(&SoundName, &SoundCategory, &x, &y, &z, &Volume, &Pitch)
+	if err != nil {
+		return err
+	}
+	return c.playSound(SoundEvent{
+		ID:       -1,
+		Name:     string(SoundName),
+		Category: int32(SoundCategory),
+		X:        float64(x) / 8,
+		Y:        float64(y) / 8,
+		Z:        float64(z) / 8,
+		Volume:   float32(Volume),
+		Pitch:    float32(Pitch),
+	})
+}
+
+func (c *Client) playSound(e SoundEvent) error {
+	if c.Events.SoundPlay != nil {
+		err := c.Events.SoundPlay(e.Name, int(e.Category), e.X, e.Y, e.Z, e.Volume, e.Pitch)
+		if err != nil {
+			return err
+		}
+	}
+	return c.bus.sound.fire(func(f interface{}) error {
+		return f.(func(SoundEvent) error)(e)
+	})
+}
+
+func handleParticlePacket(c *Client, p pk.Packet) error {
+	var (
+		id                        pk.Int
+		longDistance              pk.Boolean
+		x, y, z                   pk.Double
+		offsetX, offsetY, offsetZ pk.Float
+		speed                     pk.Float
+		count                     pk.Int
+	)
+	r := bytes.NewReader(p.Data)
+	if err := decodeFields(r, &id, &longDistance, &x, &y, &z, &offsetX, &offsetY, &offsetZ, &speed, &count); err != nil {
+		return err
+	}
+	e := ParticleEvent{
+		Particle:     int32(id),
+		LongDistance: bool(longDistance),
+		X:            float64(x),
+		Y:            float64(y),
+		Z:            float64(z),
+		OffsetX:      float32(offsetX),
+		OffsetY:      float32(offsetY),
+		OffsetZ:      float32(offsetZ),
+		Speed:        float32(speed),
+		Count:        int32(count),
+	}
+	switch id {
+	case ParticleBlock, ParticleFallingDust:
+		var state pk.VarInt
+		if err := state.Decode(r); err != nil {
+			return err
+		}
+		e.BlockState = world.BlockStatus(state)
+	case ParticleDust:
+		var red, green, blue, scale pk.Float
+		if err := decodeFields(r, &red, &green, &blue, &scale); err != nil {
+			return err
+		}
+		e.Dust = DustColor{float32(red), float32(green), float32(blue), float32(scale)}
+	case ParticleItem:
+		if err := e.Item.Decode(r); err != nil {
+			return err
+		}
+	}
+	return c.bus.particle.fire(func(f interface{}) error {
+		return f.(func(ParticleEvent) error)(e)
+	})
+}
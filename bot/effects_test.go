@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/Tnze/go-mc/bot/world"
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+func TestClient_OnSound(t *testing.T) {
+	c := NewClient()
+	var sounds []SoundEvent
+	c.OnSound(func(e SoundEvent) error {
+		sounds = append(sounds, e)
+		return nil
+	})
+	var played string
+	c.Events.SoundPlay = func(name string, category int, x, y, z float64, volume, pitch float32) error {
+		played = name
+		return nil
+	}
+
+	_, err := c.handlePacket(pk.Marshal(data.NamedSoundEffect,
+		pk.Identifier("minecraft:entity.zombie.ambient"), pk.VarInt(5),
+		pk.Int(12), pk.Int(-520), pk.Int(4), pk.Float(1), pk.Float(0.5),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SoundEvent{
+		ID:       -1,
+		Name:     "minecraft:entity.zombie.ambient",
+		Category: 5,
+		X:        1.5, Y: -65, Z: 0.5,
+		Volume: 1, Pitch: 0.5,
+	}
+	if len(sounds) != 1 || sounds[0] != want {
+		t.Errorf("sound events: %+v", sounds)
+	}
+	if played != want.Name {
+		t.Errorf("SoundPlay is called with %q", played)
+	}
+
+	// the sound IDs out of range have no name
+	for _, id := range []pk.VarInt{-1, pk.VarInt(len(data.SoundNames))} {
+		sounds = nil
+		_, err := c.handlePacket(pk.Marshal(data.SoundEffect,
+			id, pk.VarInt(0), pk.Int(0), pk.Int(0), pk.Int(0), pk.Float(1), pk.Float(1),
+		))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sounds) != 1 || sounds[0].ID != int32(id) || sounds[0].Name != "" {
+			t.Errorf("sound events of ID %d: %+v", id, sounds)
+		}
+	}
+}
+
+func TestClient_OnParticle(t *testing.T) {
+	c := NewClient()
+	var particles []ParticleEvent
+	c.OnParticle(func(e ParticleEvent) error {
+		particles = append(particles, e)
+		return nil
+	})
+	handle := func(p pk.Packet) {
+		t.Helper()
+		if _, err := c.handlePacket(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the block crack of stone
+	handle(pk.Marshal(data.Particle,
+		pk.Int(ParticleBlock), pk.Boolean(false),
+		pk.Double(1.5), pk.Double(64), pk.Double(-2.5),
+		pk.Float(0.25), pk.Float(0), pk.Float(0.25),
+		pk.Float(0.1), pk.Int(20),
+		pk.VarInt(1),
+	))
+	// red dust
+	handle(pk.Marshal(data.Particle,
+		pk.Int(ParticleDust), pk.Boolean(true),
+		pk.Double(0), pk.Double(0), pk.Double(0),
+		pk.Float(0), pk.Float(0), pk.Float(0),
+		pk.Float(0), pk.Int(1),
+		pk.Float(1), pk.Float(0), pk.Float(0), pk.Float(2),
+	))
+	if len(particles) != 2 {
+		t.Fatalf("%d particle events", len(particles))
+	}
+	if e := particles[0]; e.Particle != ParticleBlock || e.BlockState != world.BlockStatus(1) ||
+		e.X != 1.5 || e.Y != 64 || e.Z != -2.5 || e.OffsetX != 0.25 || e.Speed != 0.1 || e.Count != 20 {
+		t.Errorf("wrong block particle: %+v", e)
+	}
+	if e := particles[1]; !e.LongDistance || e.Dust != (DustColor{Red: 1, Scale: 2}) {
+		t.Errorf("wrong dust particle: %+v", e)
+	}
+
+	// the data of block particle is missing
+	_, err := c.handlePacket(pk.Marshal(data.Particle,
+		pk.Int(ParticleFallingDust), pk.Boolean(false),
+		pk.Double(0), pk.Double(0), pk.Double(0),
+		pk.Float(0), pk.Float(0), pk.Float(0),
+		pk.Float(0), pk.Int(1),
+	))
+	if err == nil {
+		t.Error("the particle without data is handled")
+	}
+}
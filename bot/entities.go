@@ -0,0 +1,251 @@
+package bot
+
+import (
+	"github.com/Tnze/go-mc/bot/world"
+	"github.com/Tnze/go-mc/bot/world/entity"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
+)
+
+// EntitySpawnEvent is fired when an entity around the player is spawned.
+// The Entity is added to World().Entities before the event.
+type EntitySpawnEvent struct {
+	Entity *world.Entity
+}
+
+// EntityDestroyEvent is fired when an entity is removed from the world,
+// for it's dead or too far away from the player.
+// The Entity is removed from World().Entities before the event.
+type EntityDestroyEvent struct {
+	Entity *world.Entity
+}
+
+// OnEntitySpawn subscribe EntitySpawnEvent
+func (c *Client) OnEntitySpawn(f func(EntitySpawnEvent) error) (unsubscribe func()) {
+	return c.bus.entitySpawn.add(f)
+}
+
+// OnEntityDestroy subscribe EntityDestroyEvent
+func (c *Client) OnEntityDestroy(f func(EntityDestroyEvent) error) (unsubscribe func()) {
+	return c.bus.entityDestroy.add(f)
+}
+
+// The units of the movement packets
+const (
+	velocityUnit = 8000     // the velocity is in 1/8000 blocks per tick
+	deltaUnit    = 32 * 128 // the relative move is in 1/4096 blocks
+)
+
+func (c *Client) spawnEntity(e *world.Entity) error {
+	c.Wd.Entities[int32(e.EntityID)] = e
+	return c.bus.entitySpawn.fire(func(f interface{}) error {
+		return f.(func(EntitySpawnEvent) error)(EntitySpawnEvent{Entity: e})
+	})
+}
+
+func handleSpawnObjectPacket(c *Client, p pk.Packet) error {
+	var (
+		eid              pk.VarInt
+		id               pk.UUID
+		typ              pk.VarInt
+		x, y, z          pk.Double
+		pitch, yaw       pk.Angle
+		data             pk.Int
+		velX, velY, velZ pk.Short
+	)
+	if err := p.Scan(&eid, &id, &typ, &x, &y, &z, &pitch, &yaw, &data, &velX, &velY, &velZ); err != nil {
+		return err
+	}
+	return c.spawnEntity(&world.Entity{
+		Entity: entity.Entity{EntityID: int(eid)},
+		Kind:   world.ObjectEntity,
+		UUID:   uuid.UUID(id),
+		Type:   int32(typ),
+		Data:   int32(data),
+		X:      float64(x),
+		Y:      float64(y),
+		Z:      float64(z),
+		Yaw:    float32(yaw.Degrees()),
+		Pitch:  float32(pitch.Degrees()),
+		VelX:   float64(velX) / velocityUnit,
+		VelY:   float64(velY) / velocityUnit,
+		VelZ:   float64(velZ) / velocityUnit,
+	})
+}
+
+func handleSpawnLivingEntityPacket(c *Client, p pk.Packet) error {
+	var (
+		eid                   pk.VarInt
+		id                    pk.UUID
+		typ                   pk.VarInt
+		x, y, z               pk.Double
+		yaw, pitch, headPitch pk.Angle
+		velX, velY, velZ      pk.Short
+	)
+	if err := p.Scan(&eid, &id, &typ, &x, &y, &z, &yaw, &pitch, &headPitch, &velX, &velY, &velZ); err != nil {
+		return err
+	}
+	return c.spawnEntity(&world.Entity{
+		Entity: entity.Entity{EntityID: int(eid)},
+		Kind:   world.LivingEntity,
+		UUID:   uuid.UUID(id),
+		Type:   int32(typ),
+		X:      float64(x),
+		Y:      float64(y),
+		Z:      float64(z),
+		Yaw:    float32(yaw.Degrees()),
+		Pitch:  float32(pitch.Degrees()),
+		VelX:   float64(velX) / velocityUnit,
+		VelY:   float64(velY) / velocityUnit,
+		VelZ:   float64(velZ) / velocityUnit,
+	})
+}
+
+func handleSpawnPlayerPacket(c *Client, p pk.Packet) error {
+	var (
+		eid        pk.VarInt
+		id         pk.UUID
+		x, y, z    pk.Double
+		yaw, pitch pk.Angle
+	)
+	if err := p.Scan(&eid, &id, &x, &y, &z, &yaw, &pitch); err != nil {
+		return err
+	}
+	return c.spawnEntity(&world.Entity{
+		Entity: entity.Entity{EntityID: int(eid)},
+		Kind:   world.PlayerEntity,
+		UUID:   uuid.UUID(id),
+		X:      float64(x),
+		Y:      float64(y),
+		Z:      float64(z),
+		Yaw:    float32(yaw.Degrees()),
+		Pitch:  float32(pitch.Degrees()),
+	})
+}
+
+func handleSpawnExperienceOrbPacket(c *Client, p pk.Packet) error {
+	var (
+		eid     pk.VarInt
+		x, y, z pk.Double
+		count   pk.Short
+	)
+	if err := p.Scan(&eid, &x, &y, &z, &count); err != nil {
+		return err
+	}
+	return c.spawnEntity(&world.Entity{
+		Entity: entity.Entity{EntityID: int(eid)},
+		Kind:   world.ExperienceOrbEntity,
+		Data:   int32(count),
+		X:      float64(x),
+		Y:      float64(y),
+		Z:      float64(z),
+	})
+}
+
+func handleDestroyEntitiesPacket(c *Client, p pk.Packet) error {
+	var ids []pk.VarInt
+	if err := p.Scan(pk.Ary{Len: new(pk.VarInt), Ary: &ids}); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		e, ok := c.Wd.Entities[int32(id)]
+		if !ok {
+			continue
+		}
+		delete(c.Wd.Entities, int32(id))
+		if err := c.bus.entityDestroy.fire(func(f interface{}) error {
+			return f.(func(EntityDestroyEvent) error)(EntityDestroyEvent{Entity: e})
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func handleEntityRelativeMovePacket(c *Client, p pk.Packet) error {
+	var (
+		eid        pk.VarInt
+		dx, dy, dz pk.Short
+		onGround   pk.Boolean
+	)
+	if err := p.Scan(&eid, &dx, &dy, &dz, &onGround); err != nil {
+		return err
+	}
+	if e, ok := c.Wd.Entities[int32(eid)]; ok {
+		e.X += float64(dx) / deltaUnit
+		e.Y += float64(dy) / deltaUnit
+		e.Z += float64(dz) / deltaUnit
+		e.OnGround = bool(onGround)
+	}
+	return nil
+}
+
+func handleEntityLookAndRelativeMovePacket(c *Client, p pk.Packet) error {
+	var (
+		eid        pk.VarInt
+		dx, dy, dz pk.Short
+		yaw, pitch pk.Angle
+		onGround   pk.Boolean
+	)
+	if err := p.Scan(&eid, &dx, &dy, &dz, &yaw, &pitch, &onGround); err != nil {
+		return err
+	}
+	if e, ok := c.Wd.Entities[int32(eid)]; ok {
+		e.X += float64(dx) / deltaUnit
+		e.Y += float64(dy) / deltaUnit
+		e.Z += float64(dz) / deltaUnit
+		e.Yaw, e.Pitch = float32(yaw.Degrees()), float32(pitch.Degrees())
+		e.OnGround = bool(onGround)
+	}
+	return nil
+}
+
+func handleEntityLookPacket(c *Client, p pk.Packet) error {
+	var (
+		eid        pk.VarInt
+		yaw, pitch pk.Angle
+		onGround   pk.Boolean
+	)
+	if err := p.Scan(&eid, &yaw, &pitch, &onGround); err != nil {
+		return err
+	}
+	if e, ok := c.Wd.Entities[int32(eid)]; ok {
+		e.Yaw, e.Pitch = float32(yaw.Degrees()), float32(pitch.Degrees())
+		e.OnGround = bool(onGround)
+	}
+	return nil
+}
+
+func handleEntityTeleportPacket(c *Client, p pk.Packet) error {
+	var (
+		eid        pk.VarInt
+		x, y, z    pk.Double
+		yaw, pitch pk.Angle
+		onGround   pk.Boolean
+	)
+	if err := p.Scan(&eid, &x, &y, &z, &yaw, &pitch, &onGround); err != nil {
+		return err
+	}
+	if e, ok := c.Wd.Entities[int32(eid)]; ok {
+		e.X, e.Y, e.Z = float64(x), float64(y), float64(z)
+		e.Yaw, e.Pitch = float32(yaw.Degrees()), float32(pitch.Degrees())
+		e.OnGround = bool(onGround)
+	}
+	return nil
+}
+
+func handleEntityVelocityPacket(c *Client, p pk.Packet) error {
+	var (
+		eid              pk.VarInt
+		velX, velY, velZ pk.Short
+	)
+	if err := p.Scan(&eid, &velX, &velY, &velZ); err != nil {
+		return err
+	}
+	if e, ok := c.Wd.Entities[int32(eid)]; ok {
+		e.VelX = float64(velX) / velocityUnit
+		e.VelY = float64(velY) / velocityUnit
+		e.VelZ = float64(velZ) / velocityUnit
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/Tnze/go-mc/bot/world"
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
+)
+
+func TestClient_entities(t *testing.T) {
+	c := NewClient()
+	var spawned, destroyed []*world.Entity
+	c.OnEntitySpawn(func(e EntitySpawnEvent) error {
+		spawned = append(spawned, e.Entity)
+		return nil
+	})
+	c.OnEntityDestroy(func(e EntityDestroyEvent) error {
+		destroyed = append(destroyed, e.Entity)
+		return nil
+	})
+	handle := func(p pk.Packet) {
+		t.Helper()
+		if _, err := c.handlePacket(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	zombie := uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5")
+	handle(pk.Marshal(data.SpawnLivingEntity,
+		pk.VarInt(10), pk.UUID(zombie), pk.VarInt(102),
+		pk.Double(1.5), pk.Double(64), pk.Double(-2.5),
+		pk.Angle(64), pk.Angle(0), pk.Angle(0),
+		pk.Short(800), pk.Short(0), pk.Short(-8000),
+	))
+	handle(pk.Marshal(data.SpawnExperienceOrb, pk.VarInt(11), pk.Double(0), pk.Double(70), pk.Double(0), pk.Short(7)))
+	if len(spawned) != 2 || len(c.World().Entities) != 2 {
+		t.Fatalf("spawned %d, tracked %d entities", len(spawned), len(c.World().Entities))
+	}
+	e := c.World().Entities[10]
+	if e.Kind != world.LivingEntity || e.UUID != zombie || e.Type != 102 || e.Yaw != 90 ||
+		e.X != 1.5 || e.Y != 64 || e.Z != -2.5 || e.VelX != 0.1 || e.VelZ != -1 {
+		t.Errorf("wrong spawned entity: %+v", e)
+	}
+	if orb := c.World().Entities[11]; orb.Kind != world.ExperienceOrbEntity || orb.Data != 7 {
+		t.Errorf("wrong experience orb: %+v", orb)
+	}
+
+	// moved 0.5 blocks east and 1/4096 block down
+	handle(pk.Marshal(data.EntityRelativeMove, pk.VarInt(10), pk.Short(2048), pk.Short(-1), pk.Short(0), pk.Boolean(true)))
+	if e.X != 2 || e.Y != 64-1.0/4096 || e.Z != -2.5 || !e.OnGround {
+		t.Errorf("wrong position after relative move: %v %v %v", e.X, e.Y, e.Z)
+	}
+	handle(pk.Marshal(data.EntityTeleport, pk.VarInt(10), pk.Double(8), pk.Double(65), pk.Double(8), pk.Angle(128), pk.Angle(0), pk.Boolean(false)))
+	if e.X != 8 || e.Y != 65 || e.Z != 8 || e.Yaw != 180 || e.OnGround {
+		t.Errorf("wrong position after teleport: %+v", e)
+	}
+	handle(pk.Marshal(data.EntityVelocity, pk.VarInt(10), pk.Short(0), pk.Short(4000), pk.Short(0)))
+	if e.VelX != 0 || e.VelY != 0.5 || e.VelZ != 0 {
+		t.Errorf("wrong velocity: %v %v %v", e.VelX, e.VelY, e.VelZ)
+	}
+	// the movement of unknown entities is ignored
+	handle(pk.Marshal(data.EntityRelativeMove, pk.VarInt(99), pk.Short(1), pk.Short(1), pk.Short(1), pk.Boolean(true)))
+
+	handle(pk.Marshal(data.DestroyEntities, pk.Ary{Len: pk.VarInt(3), Ary: []pk.VarInt{10, 11, 99}}))
+	if len(c.World().Entities) != 0 {
+		t.Errorf("%d entities left after destroyed", len(c.World().Entities))
+	}
+	if len(destroyed) != 2 || destroyed[0] != e {
+		t.Errorf("destroyed events: %v", destroyed)
+	}
+}
@@ -0,0 +1,232 @@
+package bot
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/Tnze/go-mc/bot/world"
+	"github.com/Tnze/go-mc/chat"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
+)
+
+// The typed events are decoded from packets and fired by HandleGame.
+//
+// Subscribe them by the On* methods of Client. Handlers are called in the
+// goroutine running HandleGame, in the subscription order, so they must not
+// block for long, or the bot will read nothing including the keep alive
+// packets. Do the slow works in another goroutine.
+// A non-nil error returned by a handler stops HandleGame.
+//
+// The returned unsubscribe function can be called from any goroutine,
+// including the handler itself.
+
+// ChatMessageEvent is fired when receive a chat message
+type ChatMessageEvent struct {
+	Sender   uuid.UUID
+	Message  chat.Message
+	Position byte // 0: chat box, 1: system message, 2: game info above the hotbar
+}
+
+// PlayerJoinEvent is fired when a player is added to the player list
+type PlayerJoinEvent struct {
+	UUID        uuid.UUID
+	Name        string
+	Gamemode    int
+	Ping        int // in milliseconds
+	DisplayName *chat.Message
+}
+
+// BlockUpdateEvent is fired when a block is changed
+type BlockUpdateEvent struct {
+	X, Y, Z int
+	State   world.BlockStatus
+}
+
+// HealthChangedEvent is fired when the player's health or food is changed
+type HealthChangedEvent struct {
+	Health         float32
+	Food           int32
+	FoodSaturation float32
+}
+
+// DeathEvent is fired when the player dies
+type DeathEvent struct {
+	Message chat.Message // the death message, empty if the health packet comes first
+}
+
+type eventBus struct {
+	chatMessage   listeners
+	playerJoin    listeners
+	playerLeave   listeners
+	blockUpdate   listeners
+	healthChanged listeners
+	death         listeners
+	timeUpdate    listeners
+	entitySpawn   listeners
+	entityDestroy listeners
+	sound         listeners
+	particle      listeners
+	bossBar       listeners
+	reconnect     listeners
+	plugin        pluginChannels // see OnPluginMessage
+}
+
+// OnChatMessage subscribe ChatMessageEvent
+func (c *Client) OnChatMessage(f func(ChatMessageEvent) error) (unsubscribe func()) {
+	return c.bus.chatMessage.add(f)
+}
+
+// OnPlayerJoin subscribe PlayerJoinEvent
+func (c *Client) OnPlayerJoin(f func(PlayerJoinEvent) error) (unsubscribe func()) {
+	return c.bus.playerJoin.add(f)
+}
+
+// OnBlockUpdate subscribe BlockUpdateEvent
+func (c *Client) OnBlockUpdate(f func(BlockUpdateEvent) error) (unsubscribe func()) {
+	return c.bus.blockUpdate.add(f)
+}
+
+// OnHealthChanged subscribe HealthChangedEvent
+func (c *Client) OnHealthChanged(f func(HealthChangedEvent) error) (unsubscribe func()) {
+	return c.bus.healthChanged.add(f)
+}
+
+// OnDeath subscribe DeathEvent
+func (c *Client) OnDeath(f func(DeathEvent) error) (unsubscribe func()) {
+	return c.bus.death.add(f)
+}
+
+// listeners is a list of handlers of the same event type
+type listeners struct {
+	mu sync.Mutex
+	l  []*listener
+}
+
+type listener struct{ f interface{} }
+
+func (ls *listeners) add(f interface{}) (unsubscribe func()) {
+	l := &listener{f}
+	ls.mu.Lock()
+	ls.l = append(ls.l, l)
+	ls.mu.Unlock()
+	return func() {
+		ls.mu.Lock()
+		defer ls.mu.Unlock()
+		for i, v := range ls.l {
+			if v == l {
+				// copy, so the firing loop isn't affected
+				ls.l = append(ls.l[:i:i], ls.l[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (ls *listeners) fire(call func(f interface{}) error) error {
+	ls.mu.Lock()
+	l := ls.l
+	ls.mu.Unlock()
+	for _, v := range l {
+		if err := call(v.f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func handleBlockChangePacket(c *Client, p pk.Packet) error {
+	var (
+		pos   pk.Position
+		state pk.VarInt
+	)
+	if err := p.Scan(&pos, &state); err != nil {
+		return err
+	}
+	return c.updateBlock(pos.X, pos.Y, pos.Z, world.BlockStatus(state))
+}
+
+func handleMultiBlockChangePacket(c *Client, p pk.Packet) error {
+	var (
+		chunkX, chunkZ pk.Int
+		count          pk.VarInt
+	)
+	r := bytes.NewReader(p.Data)
+	if err := decodeFields(r, &chunkX, &chunkZ, &count); err != nil {
+		return err
+	}
+	for i := 0; i < int(count); i++ {
+		var (
+			horizontal, y pk.UnsignedByte
+			state         pk.VarInt
+		)
+		if err := decodeFields(r, &horizontal, &y, &state); err != nil {
+			return err
+		}
+		x := int(chunkX)<<4 | int(horizontal>>4)
+		z := int(chunkZ)<<4 | int(horizontal&0x0F)
+		if err := c.updateBlock(x, int(y), z, world.BlockStatus(state)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) updateBlock(x, y, z int, state world.BlockStatus) error {
+	c.Wd.SetBlockStatus(x, y, z, state)
+	c.blockChanged(x, y, z, state)
+	e := BlockUpdateEvent{X: x, Y: y, Z: z, State: state}
+	return c.bus.blockUpdate.fire(func(f interface{}) error {
+		return f.(func(BlockUpdateEvent) error)(e)
+	})
+}
+
+func handleCombatEventPacket(c *Client, p pk.Packet) error {
+	var (
+		event    pk.VarInt
+		playerID pk.VarInt
+		entityID pk.Int
+		msg      chat.Message
+	)
+	if err := p.Scan(&event); err != nil {
+		return err
+	}
+	if event != 2 { // entity dead
+		return nil
+	}
+	if err := p.Scan(&event, &playerID, &entityID, &msg); err != nil {
+		return err
+	}
+	if int(playerID) != c.EntityID {
+		return nil
+	}
+	return c.died(DeathEvent{Message: msg})
+}
+
+// died fire the DeathEvent once for each death.
+// Both the CombatEvent and the UpdateHealth packets tell the death,
+// and the one comes first fires the event.
+func (c *Client) died(e DeathEvent) error {
+	if c.dead {
+		return nil
+	}
+	c.dead = true
+	return c.bus.death.fire(func(f interface{}) error {
+		return f.(func(DeathEvent) error)(e)
+	})
+}
+
+// isMe report whether the id is the player's UUID
+func (c *Client) isMe(id uuid.UUID) bool {
+	most, least := pk.UUID(id).Longs()
+	return c.Player.UUID == [2]int64{most, least}
+}
+
+func decodeFields(r pk.DecodeReader, fields ...pk.FieldDecoder) error {
+	for _, f := range fields {
+		if err := f.Decode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package bot
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/Tnze/go-mc/bot/world"
+	"github.com/Tnze/go-mc/chat"
+	"github.com/Tnze/go-mc/data"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
+)
+
+func TestClient_OnChatMessage(t *testing.T) {
+	c := NewClient()
+	sender := uuid.New()
+	p := pk.Marshal(data.ChatMessageClientbound, chat.Text("hello"), pk.Byte(1), pk.UUID(sender))
+
+	var events []ChatMessageEvent
+	unsubscribe := c.OnChatMessage(func(e ChatMessageEvent) error {
+		events = append(events, e)
+		return nil
+	})
+	if _, err := c.handlePacket(p); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("event should be fired once, get %d", len(events))
+	}
+	if e := events[0]; e.Sender != sender || e.Position != 1 || e.Message.ClearString() != "hello" {
+		t.Errorf("wrong event: %+v", e)
+	}
+
+	unsubscribe()
+	if _, err := c.handlePacket(p); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Errorf("event should not be fired after unsubscribe")
+	}
+}
+
+func TestClient_OnPlayerJoin(t *testing.T) {
+	c := NewClient()
+	id := uuid.New()
+	p := pk.Marshal(data.PlayerInfo,
+		pk.VarInt(0), pk.VarInt(1), // add 1 player
+		pk.UUID(id), pk.String("Tnze"),
+		pk.VarInt(1), pk.String("textures"), pk.String("e30="), pk.Boolean(true), pk.String("sig"),
+		pk.VarInt(1), pk.VarInt(42), pk.Boolean(true), chat.Text("[Admin] Tnze"),
+	)
+
+	var e PlayerJoinEvent
+	c.OnPlayerJoin(func(pje PlayerJoinEvent) error {
+		e = pje
+		return nil
+	})
+	if _, err := c.handlePacket(p); err != nil {
+		t.Fatal(err)
+	}
+	if e.UUID != id || e.Name != "Tnze" || e.Gamemode != 1 || e.Ping != 42 ||
+		e.DisplayName == nil || e.DisplayName.ClearString() != "[Admin] Tnze" {
+		t.Errorf("wrong event: %+v", e)
+	}
+}
+
+func TestClient_OnBlockUpdate(t *testing.T) {
+	c := NewClient()
+	c.Wd.LoadChunk(-1, 0, &world.Chunk{})
+
+	var events []BlockUpdateEvent
+	c.OnBlockUpdate(func(e BlockUpdateEvent) error {
+		events = append(events, e)
+		return nil
+	})
+	packets := []pk.Packet{
+		pk.Marshal(data.BlockChange, pk.Position{X: -1, Y: 64, Z: 2}, pk.VarInt(1)),
+		pk.Marshal(data.MultiBlockChange, pk.Int(-1), pk.Int(0), pk.VarInt(1),
+			pk.UnsignedByte(0xE3), pk.UnsignedByte(65), pk.VarInt(9)),
+	}
+	for _, p := range packets {
+		if _, err := c.handlePacket(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []BlockUpdateEvent{{-1, 64, 2, 1}, {-2, 65, 3, 9}}
+	if len(events) != len(want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+	for i, e := range want {
+		if events[i] != e {
+			t.Errorf("events[%d] = %v, want %v", i, events[i], e)
+		}
+		if s := c.Wd.GetBlockStatus(e.X, e.Y, e.Z); s != e.State {
+			t.Errorf("block at (%d, %d, %d) is %d, want %d", e.X, e.Y, e.Z, s, e.State)
+		}
+	}
+}
+
+func TestClient_OnDeath(t *testing.T) {
+	c := NewClient()
+	c.EntityID = 10
+
+	var deaths []DeathEvent
+	c.OnDeath(func(e DeathEvent) error {
+		deaths = append(deaths, e)
+		return nil
+	})
+	for _, id := range []int{11, 10} {
+		p := pk.Marshal(data.CombatEvent, pk.VarInt(2), pk.VarInt(id), pk.Int(-1), chat.Text("Steve fell"))
+		if _, err := c.handlePacket(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(deaths) != 1 || deaths[0].Message.ClearString() != "Steve fell" {
+		t.Errorf("wrong death events: %v", deaths)
+	}
+}
+
+func TestClient_OnDeath_health(t *testing.T) {
+	c := NewClient()
+	c.conn = &mcnet.Conn{Writer: ioutil.Discard}
+	c.EntityID = 10
+
+	var deaths []DeathEvent
+	c.OnDeath(func(e DeathEvent) error {
+		deaths = append(deaths, e)
+		return nil
+	})
+	health := func(v float32) pk.Packet {
+		return pk.Marshal(data.UpdateHealth, pk.Float(v), pk.VarInt(20), pk.Float(5))
+	}
+	for _, p := range []pk.Packet{
+		health(0),
+		pk.Marshal(data.CombatEvent, pk.VarInt(2), pk.VarInt(10), pk.Int(-1), chat.Text("Steve fell")),
+		health(20), // respawn
+		health(0),
+	} {
+		if _, err := c.handlePacket(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(deaths) != 2 {
+		t.Errorf("DeathEvent should be fired once for each death, get %d", len(deaths))
+	}
+}
@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"errors"
+
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// ErrStopHandle can be returned by a packet handler to stop the handling
+// of the packet. The following handlers, including the default handler of
+// the bot, will not run, and HandleGame continues with the next packet.
+// Any other error returned by a handler stops HandleGame.
+var ErrStopHandle = errors.New("bot: stop handling the packet")
+
+// PacketHandler handles the packets of a specific ID
+type PacketHandler func(p pk.Packet) error
+
+// AddHandler register a handler for the packets of the id.
+// Handlers of the same id run in the registration order,
+// before the default handler of the bot.
+// AddHandler should be called before HandleGame or from a Delegate.
+func (c *Client) AddHandler(id int32, h PacketHandler) {
+	if c.handlers == nil {
+		c.handlers = make(map[int32][]PacketHandler)
+	}
+	c.handlers[id] = append(c.handlers[id], h)
+}
+
+// runHandlers run the registered handlers of the packet.
+// It returns stop == true if one of the handlers returned ErrStopHandle.
+func (c *Client) runHandlers(p pk.Packet) (stop bool, err error) {
+	for _, h := range c.handlers[p.ID] {
+		if err := h(p); err != nil {
+			if errors.Is(err, ErrStopHandle) {
+				return true, nil
+			}
+			return false, err
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+func TestClient_AddHandler(t *testing.T) {
+	const id = 0x7F // not handled by the bot
+	c := NewClient()
+
+	var order []int
+	handler := func(i int, err error) PacketHandler {
+		return func(p pk.Packet) error {
+			order = append(order, i)
+			return err
+		}
+	}
+	c.AddHandler(id, handler(1, nil))
+	c.AddHandler(id, handler(2, nil))
+	if _, err := c.handlePacket(pk.Packet{ID: id}); err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+		t.Errorf("handlers should run in registration order, get %v", order)
+	}
+
+	order = nil
+	c.AddHandler(id+1, handler(1, ErrStopHandle))
+	c.AddHandler(id+1, handler(2, nil))
+	if disconnect, err := c.handlePacket(pk.Packet{ID: id + 1}); err != nil || disconnect {
+		t.Errorf("ErrStopHandle should not be returned, get %v, %v", disconnect, err)
+	}
+	if len(order) != 1 {
+		t.Errorf("ErrStopHandle should stop the following handlers, get %v", order)
+	}
+
+	fail := errors.New("handler fail")
+	c.AddHandler(id+2, handler(1, fail))
+	if _, err := c.handlePacket(pk.Packet{ID: id + 2}); !errors.Is(err, fail) {
+		t.Errorf("handler error should be returned, get %v", err)
+	}
+}
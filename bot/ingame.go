@@ -5,13 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"io"
 	"io/ioutil"
+	"time"
 
 	"github.com/Tnze/go-mc/bot/world"
 	"github.com/Tnze/go-mc/bot/world/entity"
 	"github.com/Tnze/go-mc/chat"
 	"github.com/Tnze/go-mc/data"
 	"github.com/Tnze/go-mc/nbt"
+	"github.com/Tnze/go-mc/net"
 	pk "github.com/Tnze/go-mc/net/packet"
 )
 
@@ -27,31 +30,89 @@ import (
 
 // HandleGame receive server packet and response them correctly.
 // Note that HandleGame will block if you don't receive from Events.
+//
+// Packets are read in another goroutine, which also response the keep alive
+// packets unless DisableKeepAlive is set. So the slow handlers only delay
+// the following packets, but don't get the bot timed out.
+//
+// When the server kicks the player, HandleGame returns a *net.DisconnectError
+// with the reason.
 func (c *Client) HandleGame() error {
+	q := newPacketQueue()
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		c.readPackets(q)
+	}()
+	defer c.stopReading(stopped)
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case task := <-c.Delegate:
 			if err := task(); err != nil {
 				return err
 			}
+			continue
+		case <-ticker.C:
+			if err := c.tick(); err != nil {
+				return err
+			}
+			continue
 		default:
-			//Read packets
-			p, err := c.conn.ReadPacket()
-			if err != nil {
-				return fmt.Errorf("bot: read packet fail: %w", err)
+		}
+
+		p, ok, err := q.pop()
+		if err != nil {
+			if c.logging() {
+				c.Logger.Errorf("bot: read packet fail: %v", err)
 			}
-			//handle packets
-			disconnect, err := c.handlePacket(p)
-			if err != nil {
-				return fmt.Errorf("handle packet 0x%X error: %w", p.ID, err)
+			return fmt.Errorf("bot: read packet fail: %w", err)
+		}
+		if !ok {
+			// wait for packets or tasks
+			select {
+			case task := <-c.Delegate:
+				if err := task(); err != nil {
+					return err
+				}
+			case <-ticker.C:
+				if err := c.tick(); err != nil {
+					return err
+				}
+			case <-q.notify:
 			}
-			if disconnect {
-				return nil
+			continue
+		}
+		//handle packets
+		disconnect, err := c.handlePacket(p)
+		if disconnect {
+			return err // the *net.DisconnectError
+		}
+		if err != nil {
+			if c.logging() {
+				c.Logger.Warnf("bot: handle packet 0x%02X of %d bytes fail: %v", p.ID, len(p.Data), err)
 			}
+			return fmt.Errorf("handle packet 0x%X error: %w", p.ID, err)
 		}
 	}
 }
 
+// tick do the works of every game tick
+func (c *Client) tick() error {
+	if err := c.physicsTick(); err != nil {
+		return fmt.Errorf("bot: physics tick fail: %w", err)
+	}
+	if err := c.antiAFKTick(); err != nil {
+		return fmt.Errorf("bot: anti-AFK fail: %w", err)
+	}
+	if err := c.chatTick(); err != nil {
+		return fmt.Errorf("bot: send chat fail: %w", err)
+	}
+	return nil
+}
+
 func (c *Client) handlePacket(p pk.Packet) (disconnect bool, err error) {
 	if c.Events.ReceivePacket != nil {
 		pass, err := c.Events.ReceivePacket(p)
@@ -62,6 +123,9 @@ func (c *Client) handlePacket(p pk.Packet) (disconnect bool, err error) {
 			return false, nil
 		}
 	}
+	if stop, err := c.runHandlers(p); err != nil || stop {
+		return false, err
+	}
 
 	switch p.ID {
 	case data.JoinGame:
@@ -71,14 +135,14 @@ func (c *Client) handlePacket(p pk.Packet) (disconnect bool, err error) {
 			err = c.Events.GameStart()
 		}
 	case data.PluginMessageClientbound:
-		err = handlePluginPacket(c, p)
+		err = handlePluginMessagePacket(c, p)
 	case data.ServerDifficulty:
 		err = handleServerDifficultyPacket(c, p)
 	case data.SpawnPosition:
 		err = handleSpawnPositionPacket(c, p)
 	case data.PlayerAbilitiesClientbound:
 		err = handlePlayerAbilitiesPacket(c, p)
-		_ = c.conn.WritePacket(
+		_ = c.SendPacket(
 			//ClientSettings packet (serverbound)
 			pk.Marshal(
 				data.ClientSettings,
@@ -97,37 +161,81 @@ func (c *Client) handlePacket(p pk.Packet) (disconnect bool, err error) {
 	case data.PlayerPositionAndLookClientbound:
 		err = handlePlayerPositionAndLookPacket(c, p)
 		sendPlayerPositionAndLookPacket(c) // to confirm the position
+	case data.TabComplete:
+		err = handleTabCompletePacket(c, p)
+	case data.DeclareCommands:
+		err = handleDeclareCommandsPacket(c, p)
 	case data.DeclareRecipes:
 		// handleDeclareRecipesPacket(g, reader)
+	case data.SpawnObject:
+		err = handleSpawnObjectPacket(c, p)
+	case data.SpawnLivingEntity:
+		err = handleSpawnLivingEntityPacket(c, p)
+	case data.SpawnPlayer:
+		err = handleSpawnPlayerPacket(c, p)
+	case data.SpawnExperienceOrb:
+		err = handleSpawnExperienceOrbPacket(c, p)
+	case data.DestroyEntities:
+		err = handleDestroyEntitiesPacket(c, p)
 	case data.EntityLookAndRelativeMove:
-		// err = handleEntityLookAndRelativeMove(g, reader)
+		err = handleEntityLookAndRelativeMovePacket(c, p)
 	case data.EntityHeadLook:
 		// handleEntityHeadLookPacket(g, reader)
 	case data.EntityRelativeMove:
-		// err = handleEntityRelativeMovePacket(g, reader)
+		err = handleEntityRelativeMovePacket(c, p)
+	case data.EntityLook:
+		err = handleEntityLookPacket(c, p)
+	case data.EntityTeleport:
+		err = handleEntityTeleportPacket(c, p)
+	case data.EntityVelocity:
+		err = handleEntityVelocityPacket(c, p)
 	case data.KeepAliveClientbound:
-		err = handleKeepAlivePacket(c, p)
+		// responded by readPackets
 	case data.Entity:
 		//handleEntityPacket(g, reader)
-	case data.SpawnPlayer:
-		// err = handleSpawnPlayerPacket(g, reader)
 	case data.WindowItems:
 		err = handleWindowItemsPacket(c, p)
 	case data.UpdateHealth:
 		err = handleUpdateHealthPacket(c, p)
+	case data.SetExperience:
+		err = handleSetExperiencePacket(c, p)
+	case data.TimeUpdate:
+		err = handleTimeUpdatePacket(c, p)
 	case data.ChatMessageClientbound:
 		err = handleChatMessagePacket(c, p)
+	case data.AcknowledgePlayerDigging:
+		err = handleAcknowledgePlayerDiggingPacket(c, p)
 	case data.BlockChange:
-		////err = handleBlockChangePacket(c, p)
+		err = handleBlockChangePacket(c, p)
 	case data.MultiBlockChange:
-		////err = handleMultiBlockChangePacket(c, p)
+		err = handleMultiBlockChangePacket(c, p)
+	case data.PlayerInfo:
+		err = handlePlayerInfoPacket(c, p)
+	case data.CombatEvent:
+		err = handleCombatEventPacket(c, p)
 	case data.DisconnectPlay:
 		err = handleDisconnectPacket(c, p)
 		disconnect = true
 	case data.SetSlot:
 		err = handleSetSlotPacket(c, p)
+	case data.CloseWindowClientbound:
+		err = handleCloseWindowPacket(c, p)
+	case data.WindowConfirmationClientbound:
+		err = handleWindowConfirmationPacket(c, p)
 	case data.SoundEffect:
 		err = handleSoundEffect(c, p)
+	case data.ScoreboardObjective:
+		err = handleScoreboardObjectivePacket(c, p)
+	case data.UpdateScore:
+		err = handleUpdateScorePacket(c, p)
+	case data.DisplayScoreboard:
+		err = handleDisplayScoreboardPacket(c, p)
+	case data.Teams:
+		err = handleTeamsPacket(c, p)
+	case data.BossBar:
+		err = handleBossBarPacket(c, p)
+	case data.Particle:
+		err = handleParticlePacket(c, p)
 	case data.NamedSoundEffect:
 		err = handleNamedSoundEffect(c, p)
 	default:
@@ -136,68 +244,23 @@ func (c *Client) handlePacket(p pk.Packet) (disconnect bool, err error) {
 	return
 }
 
-func handleSoundEffect(c *Client, p pk.Packet) error {
-	var (
-		SoundID       pk.VarInt
-		SoundCategory pk.VarInt
-		x, y, z       pk.Int
-		Volume, Pitch pk.Float
-	)
-	err := p.Scan(&SoundID, &SoundCategory, &x, &y, &z, &Volume, &Pitch)
-	if err != nil {
-		return err
-	}
-
-	if c.Events.SoundPlay != nil {
-		err = c.Events.SoundPlay(
-			data.SoundNames[SoundID], int(SoundCategory),
-			float64(x)/8, float64(y)/8, float64(z)/8,
-			float32(Volume), float32(Pitch))
-	}
-
-	return nil
-}
-
-func handleNamedSoundEffect(c *Client, p pk.Packet) error {
-	var (
-		SoundName     pk.String
-		SoundCategory pk.VarInt
-		x, y, z       pk.Int
-		Volume, Pitch pk.Float
-	)
-	err := p.Scan(&SoundName, &SoundCategory, &x, &y, &z, &Volume, &Pitch)
-	if err != nil {
-		return err
-	}
-
-	if c.Events.SoundPlay != nil {
-		err = c.Events.SoundPlay(
-			string(SoundName), int(SoundCategory),
-			float64(x)/8, float64(y)/8, float64(z)/8,
-			float32(Volume), float32(Pitch))
-	}
-
-	return nil
-}
-
+// handleDisconnectPacket return the *net.DisconnectError of p,
+// after calling Events.Disconnect
 func handleDisconnectPacket(c *Client, p pk.Packet) error {
-	var reason chat.Message
-
-	err := p.Scan(&reason)
+	e, err := net.DecodeDisconnect(p, data.Play)
 	if err != nil {
 		return err
 	}
 
 	if c.Events.Disconnect != nil {
-		return c.Events.Disconnect(reason)
+		if err := c.Events.Disconnect(e.Reason); err != nil {
+			return err
+		}
 	}
-	return nil
+	return e
 }
 
 func handleSetSlotPacket(c *Client, p pk.Packet) error {
-	if c.Events.WindowsItemChange == nil {
-		return nil
-	}
 	var (
 		windowID pk.Byte
 		slotI    pk.Short
@@ -206,7 +269,11 @@ func handleSetSlotPacket(c *Client, p pk.Packet) error {
 	if err := p.Scan(&windowID, &slotI, &slot); err != nil && !errors.Is(err, nbt.ErrEND) {
 		return err
 	}
+	c.setSlot(int(windowID), int(slotI), slot)
 
+	if c.Events.WindowsItemChange == nil {
+		return nil
+	}
 	return c.Events.WindowsItemChange(byte(windowID), int(slotI), slot)
 }
 
@@ -287,9 +354,15 @@ func handleChatMessagePacket(c *Client, p pk.Packet) (err error) {
 
 	if c.Events.ChatMsg != nil {
 		err = c.Events.ChatMsg(s, byte(pos), uuid.UUID(sender))
+		if err != nil {
+			return err
+		}
 	}
 
-	return err
+	e := ChatMessageEvent{Sender: uuid.UUID(sender), Message: s, Position: byte(pos)}
+	return c.bus.chatMessage.fire(func(f interface{}) error {
+		return f.(func(ChatMessageEvent) error)(e)
+	})
 }
 
 func handleUpdateHealthPacket(c *Client, p pk.Packet) (err error) {
@@ -314,6 +387,13 @@ func handleUpdateHealthPacket(c *Client, p pk.Packet) (err error) {
 			return
 		}
 	}
+	e := HealthChangedEvent{Health: c.Health, Food: c.Food, FoodSaturation: c.FoodSaturation}
+	err = c.bus.healthChanged.fire(func(f interface{}) error {
+		return f.(func(HealthChangedEvent) error)(e)
+	})
+	if err != nil {
+		return
+	}
 	if c.Health < 1 { //player is dead
 		sendPlayerPositionAndLookPacket(c)
 		if c.Events.Die != nil {
@@ -322,44 +402,29 @@ func handleUpdateHealthPacket(c *Client, p pk.Packet) (err error) {
 				return
 			}
 		}
+		err = c.died(DeathEvent{})
+	} else {
+		c.dead = false
 	}
 	return
 }
 
 func handleJoinGamePacket(c *Client, p pk.Packet) error {
-	var (
-		eid        pk.Int
-		gamemode   pk.UnsignedByte
-		previousGm pk.UnsignedByte
-		worldCount pk.VarInt
-		worldNames pk.Identifier
-		_          pk.NBT
-		//dimensionCodec pk.NBT
-		dimension    pk.Int
-		worldName    pk.Identifier
-		hashedSeed   pk.Long
-		maxPlayers   pk.UnsignedByte
-		viewDistance pk.VarInt
-		rdi          pk.Boolean // Reduced Debug Info
-		ers          pk.Boolean // Enable respawn screen
-		isDebug      pk.Boolean
-		isFlat       pk.Boolean
-	)
-	err := p.Scan(&eid, &gamemode, &previousGm, &worldCount, &worldNames, &dimension, &worldName,
-		&hashedSeed, &maxPlayers, &rdi, &ers, &isDebug, &isFlat)
+	jg, err := DecodeJoinGame(p, ProtocolVersion)
 	if err != nil {
 		return err
 	}
+	c.joinGame = jg
 
-	c.EntityID = int(eid)
-	c.Gamemode = int(gamemode & 0x7)
-	c.Hardcore = gamemode&0x8 != 0
-	c.Dimension = int(dimension)
-	c.WorldName = string(worldName)
-	c.ViewDistance = int(viewDistance)
-	c.ReducedDebugInfo = bool(rdi)
-	c.IsDebug = bool(isDebug)
-	c.IsFlat = bool(isFlat)
+	c.EntityID = jg.EntityID
+	c.Gamemode = jg.Gamemode
+	c.Hardcore = jg.Hardcore
+	c.Dimension = vanillaDimensionIDs[jg.Dimension.Name]
+	c.WorldName = jg.WorldName
+	c.ViewDistance = jg.ViewDistance
+	c.ReducedDebugInfo = jg.ReducedDebugInfo
+	c.IsDebug = jg.IsDebug
+	c.IsFlat = jg.IsFlat
 
 	return nil
 }
@@ -383,20 +448,6 @@ func (p *pluginMessageData) Decode(r pk.DecodeReader) error {
 	return nil
 }
 
-func handlePluginPacket(c *Client, p pk.Packet) error {
-	var (
-		Channel pk.Identifier
-		Data    pluginMessageData
-	)
-	if err := p.Scan(&Channel, &Data); err != nil {
-		return err
-	}
-	if c.Events.PluginMessage != nil {
-		return c.Events.PluginMessage(string(Channel), []byte(Data))
-	}
-	return nil
-}
-
 func handleServerDifficultyPacket(c *Client, p pk.Packet) error {
 	var difficulty pk.Byte
 	err := p.Scan(&difficulty)
@@ -456,7 +507,7 @@ func handleChunkDataPacket(c *Client, p pk.Packet) error {
 		FullChunk      pk.Boolean
 		IgnoreOldData  pk.Boolean
 		PrimaryBitMask pk.VarInt
-		Heightmaps     struct{}
+		Heightmaps     map[string][]int64
 		Biomes         = biomesData{fullChunk: (*bool)(&FullChunk)}
 		Data           chunkData
 		BlockEntities  blockEntities
@@ -468,10 +519,44 @@ func handleChunkDataPacket(c *Client, p pk.Packet) error {
 	if err != nil {
 		return fmt.Errorf("decode chunk column fail: %w", err)
 	}
+	chunk.Heightmaps = Heightmaps
+	chunk.BlockEntities = make(map[world.BlockPos]world.BlockEntity, len(BlockEntities))
+	for _, v := range BlockEntities {
+		pos, e := v.blockEntity()
+		chunk.BlockEntities[pos] = e
+	}
 
-	c.Wd.LoadChunk(int(X), int(Z), chunk)
+	if FullChunk {
+		chunk.Biomes = append([]int32(nil), Biomes.data[:]...)
+		c.Wd.LoadChunk(int(X), int(Z), chunk)
+		return nil
+	}
 
-	return err
+	// Not a full chunk, only the sections in the mask are changed
+	old, ok := c.Wd.Chunks[world.ChunkLoc{X: int(X), Z: int(Z)}]
+	if !ok {
+		return nil
+	}
+	for y := range chunk.Sections {
+		if PrimaryBitMask&(1<<uint(y)) != 0 {
+			old.Sections[y] = chunk.Sections[y]
+		}
+	}
+	for k, v := range chunk.Heightmaps {
+		if old.Heightmaps == nil {
+			old.Heightmaps = make(map[string][]int64)
+		}
+		old.Heightmaps[k] = v
+	}
+	for pos, e := range chunk.BlockEntities {
+		if PrimaryBitMask&(1<<uint(pos.Y>>4)) != 0 {
+			if old.BlockEntities == nil {
+				old.BlockEntities = make(map[world.BlockPos]world.BlockEntity)
+			}
+			old.BlockEntities[pos] = e
+		}
+	}
+	return nil
 }
 
 type biomesData struct {
@@ -494,7 +579,18 @@ func (b *biomesData) Decode(r pk.DecodeReader) error {
 
 type chunkData []byte
 type blockEntities []blockEntitie
-type blockEntitie struct {
+type blockEntitie map[string]interface{}
+
+// blockEntity split the position out of the NBT of the block entity
+func (b blockEntitie) blockEntity() (pos world.BlockPos, e world.BlockEntity) {
+	coord := func(k string) int {
+		v, _ := b[k].(int32)
+		return int(v)
+	}
+	pos = world.BlockPos{X: coord("x"), Y: coord("y"), Z: coord("z")}
+	e.ID, _ = b["id"].(string)
+	e.Data = b
+	return
 }
 
 // Decode implement net.packet.FieldDecoder
@@ -503,8 +599,11 @@ func (c *chunkData) Decode(r pk.DecodeReader) error {
 	if err := Size.Decode(r); err != nil {
 		return err
 	}
+	if err := checkLength(r, int(Size), "chunk data size"); err != nil {
+		return err
+	}
 	*c = make([]byte, Size)
-	if _, err := r.Read(*c); err != nil {
+	if _, err := io.ReadFull(r, *c); err != nil {
 		return err
 	}
 	return nil
@@ -516,6 +615,10 @@ func (b *blockEntities) Decode(r pk.DecodeReader) error {
 	if err := nobe.Decode(r); err != nil {
 		return err
 	}
+	// each block entity has at least one byte
+	if err := checkLength(r, int(nobe), "number of block entities"); err != nil {
+		return err
+	}
 	*b = make(blockEntities, nobe)
 	decoder := nbt.NewDecoder(r)
 	for i := 0; i < int(nobe); i++ {
@@ -526,6 +629,18 @@ func (b *blockEntities) Decode(r pk.DecodeReader) error {
 	return nil
 }
 
+// checkLength return an error if the length n sent by the server is negative,
+// or more than the bytes left in the packet, before allocating for it.
+func checkLength(r pk.DecodeReader, n int, name string) error {
+	if n < 0 {
+		return fmt.Errorf("negative %s %d", name, n)
+	}
+	if l, ok := r.(interface{ Len() int }); ok && n > l.Len() {
+		return fmt.Errorf("%s %d is more than the %d bytes left", name, n, l.Len())
+	}
+	return nil
+}
+
 func handlePlayerPositionAndLookPacket(c *Client, p pk.Packet) error {
 	var (
 		x, y, z    pk.Double
@@ -564,9 +679,10 @@ func handlePlayerPositionAndLookPacket(c *Client, p pk.Packet) error {
 	} else {
 		c.Pitch += float32(pitch)
 	}
+	c.resetPhysics()
 
 	//Confirm
-	return c.conn.WritePacket(pk.Marshal(
+	return c.SendPacket(pk.Marshal(
 		data.TeleportConfirm,
 		pk.VarInt(TeleportID),
 	))
@@ -578,17 +694,13 @@ func handleKeepAlivePacket(c *Client, p pk.Packet) error {
 		return err
 	}
 	//Response
-	return c.conn.WritePacket(pk.Marshal(
+	return c.SendPacket(pk.Marshal(
 		data.KeepAliveServerbound,
 		KeepAliveID,
 	))
 }
 
 func handleWindowItemsPacket(c *Client, p pk.Packet) (err error) {
-	if c.Events.WindowsItem == nil {
-		return nil
-	}
-
 	r := bytes.NewReader(p.Data)
 	var (
 		windowID pk.Byte
@@ -608,12 +720,16 @@ func handleWindowItemsPacket(c *Client, p pk.Packet) (err error) {
 		}
 		slots = append(slots, slot)
 	}
+	c.setWindowItems(int(windowID), slots)
 
+	if c.Events.WindowsItem == nil {
+		return nil
+	}
 	return c.Events.WindowsItem(byte(windowID), slots)
 }
 
 func sendPlayerPositionAndLookPacket(c *Client) {
-	c.conn.WritePacket(pk.Marshal(
+	c.SendPacket(pk.Marshal(
 		data.PlayerPositionAndLookServerbound,
 		pk.Double(c.X),
 		pk.Double(c.Y),
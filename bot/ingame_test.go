@@ -0,0 +1,171 @@
+package bot
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Tnze/go-mc/bot/world"
+	"github.com/Tnze/go-mc/data"
+	"github.com/Tnze/go-mc/nbt"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+type rawField []byte
+
+func (r rawField) Encode() []byte { return r }
+
+func nbtField(t *testing.T, v interface{}) rawField {
+	var buf bytes.Buffer
+	if err := nbt.Marshal(&buf, v); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// sectionData encode a section with a palette of air and the state,
+// and the block at offset 0 is the state.
+func sectionData(state int32) rawField {
+	data := append(pk.Short(1).Encode(), pk.UnsignedByte(4).Encode()...)
+	data = append(data, pk.VarInt(2).Encode()...)
+	data = append(data, pk.VarInt(0).Encode()...)
+	data = append(data, pk.VarInt(state).Encode()...)
+	data = append(data, pk.VarInt(256).Encode()...)
+	data = append(data, pk.Long(1).Encode()...)
+	return append(data, make([]byte, 255*8)...)
+}
+
+func TestHandleChunkDataPacket(t *testing.T) {
+	stone, _ := data.BlockByName("minecraft:stone")
+	dirt, _ := data.BlockByName("minecraft:dirt")
+	c := NewClient()
+
+	var biomes []pk.FieldEncoder
+	for i := 0; i < 1024; i++ {
+		biomes = append(biomes, pk.Int(1))
+	}
+	full := pk.Marshal(data.ChunkData,
+		append([]pk.FieldEncoder{
+			pk.Int(1), pk.Int(2), pk.Boolean(true), pk.Boolean(false), pk.VarInt(1),
+			nbtField(t, map[string]interface{}{"MOTION_BLOCKING": make([]int64, 37)}),
+		}, append(biomes,
+			pk.ByteArray(sectionData(int32(stone.DefaultState))),
+			pk.VarInt(1), nbtField(t, map[string]interface{}{"id": "minecraft:chest", "x": int32(16), "y": int32(0), "z": int32(32)}),
+		)...)...,
+	)
+	if _, err := c.handlePacket(full); err != nil {
+		t.Fatal(err)
+	}
+
+	w := c.World()
+	if b, ok := w.Block(16, 0, 32); !ok || b.Name != "minecraft:stone" {
+		t.Errorf("want stone at (16, 0, 32), get %+v %v", b, ok)
+	}
+	if b, ok := w.Block(17, 0, 32); !ok || b.Name != "minecraft:air" {
+		t.Errorf("want air at (17, 0, 32), get %+v %v", b, ok)
+	}
+	if _, ok := w.Block(0, 0, 0); ok {
+		t.Error("block of unloaded chunk should not be ok")
+	}
+	if e, ok := w.BlockEntity(16, 0, 32); !ok || e.ID != "minecraft:chest" {
+		t.Errorf("want a chest block entity, get %+v %v", e, ok)
+	}
+	chunk := w.Chunks[world.ChunkLoc{X: 1, Z: 2}]
+	if len(chunk.Biomes) != 1024 || chunk.Biomes[0] != 1 {
+		t.Errorf("wrong biomes")
+	}
+	if len(chunk.Heightmaps["MOTION_BLOCKING"]) != 37 {
+		t.Errorf("wrong heightmaps: %v", chunk.Heightmaps)
+	}
+
+	// a non-full chunk only changes the sections in the mask
+	partial := pk.Marshal(data.ChunkData,
+		pk.Int(1), pk.Int(2), pk.Boolean(false), pk.Boolean(false), pk.VarInt(2),
+		nbtField(t, map[string]interface{}{}),
+		pk.ByteArray(sectionData(int32(dirt.DefaultState))),
+		pk.VarInt(0),
+	)
+	if _, err := c.handlePacket(partial); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := w.Block(16, 0, 32); b.Name != "minecraft:stone" {
+		t.Errorf("section 0 should be kept, get %+v", b)
+	}
+	if b, _ := w.Block(16, 16, 32); b.Name != "minecraft:dirt" {
+		t.Errorf("section 1 should be updated, get %+v", b)
+	}
+	if _, ok := w.BlockEntity(16, 0, 32); !ok {
+		t.Error("block entity of section 0 should be kept")
+	}
+}
+
+func TestHandleChunkDataPacket_badLength(t *testing.T) {
+	c := NewClient()
+	head := []pk.FieldEncoder{
+		pk.Int(1), pk.Int(2), pk.Boolean(false), pk.Boolean(false), pk.VarInt(0),
+		nbtField(t, map[string]interface{}{}),
+	}
+	for _, tail := range [][]pk.FieldEncoder{
+		{pk.VarInt(-1)},                // negative size of data
+		{pk.VarInt(1 << 30)},           // data larger than the packet
+		{pk.VarInt(0), pk.VarInt(-1)},  // negative number of block entities
+		{pk.VarInt(0), pk.VarInt(1e9)}, // more block entities than bytes
+	} {
+		p := pk.Marshal(data.ChunkData, append(append([]pk.FieldEncoder{}, head...), tail...)...)
+		if _, err := c.handlePacket(p); err == nil {
+			t.Errorf("chunk data with bad length % x should be error", p.Data)
+		}
+	}
+}
+
+func TestHandleJoinGamePacket(t *testing.T) {
+	dimension := func(name string, logicalHeight int32, hasCeiling bool) *nbt.Compound {
+		ceiling := nbt.Byte(0)
+		if hasCeiling {
+			ceiling = 1
+		}
+		return nbt.NewCompound().
+			Put("name", nbt.String(name)).
+			Put("ambient_light", nbt.Float(0.1)).
+			Put("logical_height", nbt.Int(logicalHeight)).
+			Put("has_ceiling", ceiling).
+			Put("has_skylight", 1-ceiling)
+	}
+	codec := nbt.NewCompound().Put("dimension", nbt.NewList(
+		dimension("minecraft:overworld", 256, false),
+		dimension("minecraft:the_nether", 128, true),
+	))
+
+	c := NewClient()
+	p := pk.Marshal(data.JoinGame,
+		pk.Int(42), pk.UnsignedByte(0x8|1), pk.Byte(-1),
+		pk.Ary{Len: pk.VarInt(0), Ary: []pk.Identifier{"minecraft:overworld", "minecraft:the_nether"}},
+		nbtField(t, codec), pk.Identifier("minecraft:the_nether"), pk.Identifier("minecraft:the_nether"),
+		pk.Long(123), pk.UnsignedByte(20), pk.VarInt(10),
+		pk.Boolean(false), pk.Boolean(true), pk.Boolean(false), pk.Boolean(true),
+	)
+	if _, err := c.handlePacket(p); err != nil {
+		t.Fatal(err)
+	}
+
+	jg := c.JoinGame()
+	if jg == nil {
+		t.Fatal("JoinGame is nil after joined")
+	}
+	if jg.EntityID != 42 || jg.Gamemode != 1 || jg.PreviousGamemode != -1 ||
+		jg.HashedSeed != 123 || jg.MaxPlayers != 20 || !jg.EnableRespawnScreen || !jg.IsFlat {
+		t.Errorf("wrong join game: %+v", jg)
+	}
+	if len(jg.WorldNames) != 2 || jg.WorldNames[1] != "minecraft:the_nether" {
+		t.Errorf("wrong world names: %v", jg.WorldNames)
+	}
+	if !c.IsHardcore() || c.ViewDistance != 10 || c.Dimension != -1 || c.EntityID != 42 {
+		t.Errorf("wrong play info: %+v", c.PlayInfo)
+	}
+	if d := c.DimensionType(); d.Name != "minecraft:the_nether" || d.MinY != 0 || d.Height != 256 ||
+		d.Logical != 128 || !d.HasCeiling || d.HasSkylight || d.AmbientLight != 0.1 {
+		t.Errorf("wrong dimension type: %+v", d)
+	}
+	if len(jg.DimensionTypes) != 2 {
+		t.Errorf("want 2 dimension types, get %d", len(jg.DimensionTypes))
+	}
+}
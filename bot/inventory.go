@@ -0,0 +1,133 @@
+package bot
+
+import (
+	"github.com/Tnze/go-mc/bot/world/entity"
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// PlayerInventoryID is the window ID of the player's inventory, which is always open.
+const PlayerInventoryID = 0
+
+// The modes of ClickSlot, see https://wiki.vg/Protocol#Click_Window
+const (
+	ClickPickup    = iota // left or right click
+	ClickQuickMove        // shift click
+	ClickSwap             // number key, button is the hotbar slot
+	ClickClone            // middle click
+	ClickThrow            // Q key, button 0 for one item and 1 for the stack
+	ClickDrag             // dragging
+	ClickPickupAll        // double click
+)
+
+// Inventory is the contents of a window, kept by HandleGame
+// from the WindowItems and SetSlot packets.
+type Inventory struct {
+	ID    int
+	Slots []entity.Slot
+
+	actionNum int16 // the last action number of ClickSlot
+}
+
+type inventories struct {
+	windows map[int]*Inventory
+	cursor  entity.Slot // the item carried by the mouse
+}
+
+// Inventory return the contents of the window.
+// The player's inventory has the ID PlayerInventoryID.
+// Nil is returned if the window is not open or its items is not received.
+func (c *Client) Inventory(windowID int) *Inventory {
+	return c.inventories.windows[windowID]
+}
+
+// Cursor return the item carried by the mouse
+func (c *Client) Cursor() entity.Slot {
+	return c.inventories.cursor
+}
+
+// ClickSlot click the slot of the window.
+// See the Click* constants for mode, and the meaning of button in each mode.
+//
+// The clicked item sent to the server is the current item in the slot.
+// If the server disagrees, it rejects the click and sends the real contents.
+func (c *Client) ClickSlot(windowID, slot, button, mode int) error {
+	inv := c.inventory(windowID)
+	inv.actionNum++
+	var item entity.Slot
+	if slot >= 0 && slot < len(inv.Slots) {
+		item = inv.Slots[slot]
+	}
+	return c.SendPacket(pk.Marshal(
+		data.ClickWindow,
+		pk.UnsignedByte(windowID),
+		pk.Short(slot),
+		pk.Byte(button),
+		pk.Short(inv.actionNum),
+		pk.VarInt(mode),
+		item,
+	))
+}
+
+// inventory return the window, the Inventory is created if not exist
+func (c *Client) inventory(windowID int) *Inventory {
+	if c.inventories.windows == nil {
+		c.inventories.windows = make(map[int]*Inventory)
+	}
+	inv, ok := c.inventories.windows[windowID]
+	if !ok {
+		inv = &Inventory{ID: windowID}
+		c.inventories.windows[windowID] = inv
+	}
+	return inv
+}
+
+func (c *Client) setWindowItems(windowID int, slots []entity.Slot) {
+	c.inventory(windowID).Slots = slots
+}
+
+func (c *Client) setSlot(windowID, slot int, item entity.Slot) {
+	if windowID == -1 && slot == -1 {
+		c.inventories.cursor = item
+		return
+	}
+	if slot < 0 {
+		return
+	}
+	inv := c.inventory(windowID)
+	for slot >= len(inv.Slots) {
+		inv.Slots = append(inv.Slots, entity.Slot{})
+	}
+	inv.Slots[slot] = item
+}
+
+func handleCloseWindowPacket(c *Client, p pk.Packet) error {
+	var windowID pk.UnsignedByte
+	if err := p.Scan(&windowID); err != nil {
+		return err
+	}
+	if windowID != PlayerInventoryID {
+		delete(c.inventories.windows, int(windowID))
+	}
+	return nil
+}
+
+// handleWindowConfirmationPacket apologize to the server when a click is rejected,
+// or the server will ignore the following clicks.
+func handleWindowConfirmationPacket(c *Client, p pk.Packet) error {
+	var (
+		windowID  pk.Byte
+		actionNum pk.Short
+		accepted  pk.Boolean
+	)
+	if err := p.Scan(&windowID, &actionNum, &accepted); err != nil {
+		return err
+	}
+	if accepted {
+		return nil
+	}
+	return c.SendPacket(pk.Marshal(
+		data.ConfirmTransactionServerbound,
+		windowID, actionNum, pk.Boolean(true),
+	))
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Tnze/go-mc/bot/world/entity"
+	"github.com/Tnze/go-mc/data"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+func TestClient_Inventory(t *testing.T) {
+	var buf bytes.Buffer
+	c := NewClient()
+	c.conn = &mcnet.Conn{Writer: &buf}
+
+	stone := entity.Slot{Present: true, ItemID: 1, Count: 64}
+	sword := entity.Slot{Present: true, ItemID: 603, Count: 1, NBT: map[string]interface{}{"Damage": int32(3)}}
+	for _, p := range []pk.Packet{
+		pk.Marshal(data.WindowItems, pk.Byte(0), pk.Short(3), stone, entity.Slot{}, sword),
+		pk.Marshal(data.SetSlot, pk.Byte(0), pk.Short(1), stone),
+		pk.Marshal(data.SetSlot, pk.Byte(-1), pk.Short(-1), sword), // the cursor
+	} {
+		if _, err := c.handlePacket(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inv := c.Inventory(PlayerInventoryID)
+	if inv == nil || len(inv.Slots) != 3 {
+		t.Fatalf("wrong inventory: %+v", inv)
+	}
+	if s := inv.Slots[1]; s.ItemID != 1 || s.Count != 64 {
+		t.Errorf("slot 1 should be set to stone, get %+v", s)
+	}
+	if s := inv.Slots[2]; s.ItemID != 603 || s.NBT.(map[string]interface{})["Damage"] != int32(3) {
+		t.Errorf("slot 2 should be the sword with NBT, get %+v", s)
+	}
+	if s := c.Cursor(); s.ItemID != 603 {
+		t.Errorf("cursor should be the sword, get %+v", s)
+	}
+
+	for i := 1; i <= 2; i++ {
+		if err := c.ClickSlot(PlayerInventoryID, 2, 0, ClickPickup); err != nil {
+			t.Fatal(err)
+		}
+		p, err := pk.RecvPacket(&buf, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var (
+			windowID  pk.UnsignedByte
+			slot      pk.Short
+			button    pk.Byte
+			actionNum pk.Short
+			mode      pk.VarInt
+			item      entity.Slot
+		)
+		if p.ID != data.ClickWindow {
+			t.Fatalf("want ClickWindow packet, get %#x", p.ID)
+		}
+		if err := p.Scan(&windowID, &slot, &button, &actionNum, &mode, &item); err != nil {
+			t.Fatal(err)
+		}
+		if windowID != 0 || slot != 2 || button != 0 || mode != ClickPickup || item.ItemID != 603 {
+			t.Errorf("wrong click: %v %v %v %v %+v", windowID, slot, button, mode, item)
+		}
+		if int(actionNum) != i {
+			t.Errorf("action number should be %d, get %d", i, actionNum)
+		}
+	}
+
+	// the rejected click must be confirmed
+	p := pk.Marshal(data.WindowConfirmationClientbound, pk.Byte(0), pk.Short(2), pk.Boolean(false))
+	if _, err := c.handlePacket(p); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := pk.RecvPacket(&buf, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		windowID  pk.Byte
+		actionNum pk.Short
+		accepted  pk.Boolean
+	)
+	if resp.ID != data.ConfirmTransactionServerbound || resp.Scan(&windowID, &actionNum, &accepted) != nil ||
+		windowID != 0 || actionNum != 2 || !accepted {
+		t.Errorf("wrong confirmation: %v", resp)
+	}
+}
@@ -0,0 +1,183 @@
+package bot
+
+import (
+	"fmt"
+
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// JoinGame is the decoded Join Game packet,
+// which the server sends when the player joins the world.
+type JoinGame struct {
+	EntityID            int
+	Hardcore            bool
+	Gamemode            int
+	PreviousGamemode    int // -1 if none
+	WorldNames          []string
+	Dimension           DimensionType // the type of the dimension the player joins
+	WorldName           string
+	HashedSeed          int64
+	MaxPlayers          int
+	ViewDistance        int
+	ReducedDebugInfo    bool
+	EnableRespawnScreen bool
+	IsDebug             bool
+	IsFlat              bool
+
+	// DimensionTypes are all the dimension types in the dimension codec
+	DimensionTypes []DimensionType
+}
+
+// DimensionType is a dimension type of the dimension codec
+type DimensionType struct {
+	Name      string
+	MinY      int // the lowest Y of blocks
+	Height    int // the number of blocks high, from MinY
+	Logical   int // the logical height, below which portals and chorus fruits teleport to
+	FixedTime *int64
+
+	AmbientLight float32
+	Infiniburn   string
+
+	PiglinSafe, Natural, RespawnAnchorWorks bool
+	HasSkylight, HasCeiling, HasRaids       bool
+	BedWorks, Shrunk, Ultrawarm             bool
+}
+
+// dimensionTypeNBT is a dimension type as stored in the NBT,
+// where the booleans are bytes
+type dimensionTypeNBT struct {
+	Name               string  `nbt:"name"`
+	PiglinSafe         byte    `nbt:"piglin_safe"`
+	Natural            byte    `nbt:"natural"`
+	AmbientLight       float32 `nbt:"ambient_light"`
+	FixedTime          *int64  `nbt:"fixed_time"`
+	Infiniburn         string  `nbt:"infiniburn"`
+	RespawnAnchorWorks byte    `nbt:"respawn_anchor_works"`
+	HasSkylight        byte    `nbt:"has_skylight"`
+	BedWorks           byte    `nbt:"bed_works"`
+	HasRaids           byte    `nbt:"has_raids"`
+	LogicalHeight      int32   `nbt:"logical_height"`
+	Shrunk             byte    `nbt:"shrunk"`
+	Ultrawarm          byte    `nbt:"ultrawarm"`
+	HasCeiling         byte    `nbt:"has_ceiling"`
+}
+
+func (d dimensionTypeNBT) dimensionType() DimensionType {
+	return DimensionType{
+		Name: d.Name,
+		// the dimensions are always 256 blocks high from 0 before 1.17
+		MinY:      0,
+		Height:    256,
+		Logical:   int(d.LogicalHeight),
+		FixedTime: d.FixedTime,
+
+		AmbientLight: d.AmbientLight,
+		Infiniburn:   d.Infiniburn,
+
+		PiglinSafe:         d.PiglinSafe != 0,
+		Natural:            d.Natural != 0,
+		RespawnAnchorWorks: d.RespawnAnchorWorks != 0,
+		HasSkylight:        d.HasSkylight != 0,
+		HasCeiling:         d.HasCeiling != 0,
+		HasRaids:           d.HasRaids != 0,
+		BedWorks:           d.BedWorks != 0,
+		Shrunk:             d.Shrunk != 0,
+		Ultrawarm:          d.Ultrawarm != 0,
+	}
+}
+
+// DecodeJoinGame decode a Join Game packet of the protocol version.
+// Only the protocol of 1.16 and 1.16.1 is supported for now.
+func DecodeJoinGame(p pk.Packet, protocol int) (*JoinGame, error) {
+	switch protocol {
+	case 735, 736: // 1.16, 1.16.1
+		return decodeJoinGame116(p)
+	default:
+		return nil, fmt.Errorf("bot: decode join game of unsupported protocol %d", protocol)
+	}
+}
+
+func decodeJoinGame116(p pk.Packet) (*JoinGame, error) {
+	var (
+		eid        pk.Int
+		gamemode   pk.UnsignedByte
+		previousGm pk.Byte
+		worldNames []pk.Identifier
+		codec      struct {
+			Dimension []dimensionTypeNBT `nbt:"dimension"`
+		}
+		dimension    pk.Identifier
+		worldName    pk.Identifier
+		hashedSeed   pk.Long
+		maxPlayers   pk.UnsignedByte
+		viewDistance pk.VarInt
+		rdi          pk.Boolean // Reduced Debug Info
+		ers          pk.Boolean // Enable respawn screen
+		isDebug      pk.Boolean
+		isFlat       pk.Boolean
+	)
+	err := p.Scan(&eid, &gamemode, &previousGm,
+		pk.Ary{Len: new(pk.VarInt), Ary: &worldNames},
+		pk.NBT{V: &codec}, &dimension, &worldName,
+		&hashedSeed, &maxPlayers, &viewDistance, &rdi, &ers, &isDebug, &isFlat)
+	if err != nil {
+		return nil, fmt.Errorf("bot: decode join game fail: %v", err)
+	}
+
+	jg := &JoinGame{
+		EntityID:            int(eid),
+		Hardcore:            gamemode&0x8 != 0,
+		Gamemode:            int(gamemode & 0x7),
+		PreviousGamemode:    int(previousGm),
+		WorldName:           string(worldName),
+		HashedSeed:          int64(hashedSeed),
+		MaxPlayers:          int(maxPlayers),
+		ViewDistance:        int(viewDistance),
+		ReducedDebugInfo:    bool(rdi),
+		EnableRespawnScreen: bool(ers),
+		IsDebug:             bool(isDebug),
+		IsFlat:              bool(isFlat),
+	}
+	for _, name := range worldNames {
+		jg.WorldNames = append(jg.WorldNames, string(name))
+	}
+	// default to the height of vanilla, if the dimension isn't in the codec
+	jg.Dimension = DimensionType{Name: string(dimension), Height: 256, Logical: 256}
+	for _, d := range codec.Dimension {
+		dt := d.dimensionType()
+		jg.DimensionTypes = append(jg.DimensionTypes, dt)
+		if dt.Name == string(dimension) {
+			jg.Dimension = dt
+		}
+	}
+	return jg, nil
+}
+
+// vanillaDimensionIDs are the IDs of the vanilla dimensions before 1.16,
+// which PlayInfo.Dimension still reports
+var vanillaDimensionIDs = map[string]int{
+	"minecraft:the_nether": -1,
+	"minecraft:overworld":  0,
+	"minecraft:the_end":    1,
+}
+
+// JoinGame return the Join Game packet the server sent,
+// or nil if the bot hasn't joined the game.
+func (c *Client) JoinGame() *JoinGame {
+	return c.joinGame
+}
+
+// IsHardcore report whether the world is in hardcore mode
+func (c *Client) IsHardcore() bool {
+	return c.Hardcore
+}
+
+// DimensionType return the type of the dimension the bot is in.
+// It's the zero value if the bot hasn't joined the game.
+func (c *Client) DimensionType() DimensionType {
+	if c.joinGame == nil {
+		return DimensionType{}
+	}
+	return c.joinGame.Dimension
+}
@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// packetQueue is an unbounded queue of the received packets,
+// so the reader never waits for the handlers.
+type packetQueue struct {
+	mu      sync.Mutex
+	packets []pk.Packet
+	err     error // the read error, after all packets are handled
+	notify  chan struct{}
+}
+
+func newPacketQueue() *packetQueue {
+	return &packetQueue{notify: make(chan struct{}, 1)}
+}
+
+func (q *packetQueue) push(p pk.Packet, err error) {
+	q.mu.Lock()
+	if err != nil {
+		q.err = err
+	} else {
+		q.packets = append(q.packets, p)
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pop return the first packet, ok is false if the queue is empty.
+// The read error is returned when all the packets before it are popped.
+func (q *packetQueue) pop() (p pk.Packet, ok bool, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.packets) == 0 {
+		return p, q.err != nil, q.err
+	}
+	p = q.packets[0]
+	q.packets[0] = pk.Packet{}
+	q.packets = q.packets[1:]
+	return p, true, nil
+}
+
+// readPackets read packets until error, and response the keep alive packets
+// immediately, so slow handlers won't cause a timeout disconnect.
+// It returns when the connection is closed or the read is interrupted by stopReading.
+func (c *Client) readPackets(q *packetQueue) {
+	for {
+		p, err := c.conn.ReadPacket()
+		if err == nil && p.ID == data.KeepAliveClientbound && !c.DisableKeepAlive {
+			err = handleKeepAlivePacket(c, p)
+		}
+		q.push(p, err)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// stopReading interrupt the blocking read of readPackets and wait for it to
+// return, so no more packets are read or responded after HandleGame returns.
+func (c *Client) stopReading(stopped <-chan struct{}) {
+	_ = c.conn.SetReadDeadline(time.Now())
+	<-stopped
+	_ = c.conn.SetReadDeadline(time.Time{})
+}
+
+// Ping return the latest latency of the connection.
+// It's measured by the server from the keep alive packets, and reported
+// by the player list. Zero is returned before the server reports it.
+func (c *Client) Ping() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.ping))
+}
+
+func (c *Client) setPing(ms int) {
+	atomic.StoreInt64(&c.ping, int64(time.Duration(ms)*time.Millisecond))
+}
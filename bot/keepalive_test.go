@@ -0,0 +1,106 @@
+package bot
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Tnze/go-mc/data"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
+)
+
+func TestClient_keepAlive(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	c := NewClient()
+	c.conn = mcnet.WrapConn(client)
+	s := mcnet.WrapConn(server)
+
+	// A handler blocks until the keep alive is responded
+	responded := make(chan struct{})
+	c.AddHandler(0x7F, func(p pk.Packet) error {
+		select {
+		case <-responded:
+			return nil
+		case <-time.After(5 * time.Second):
+			t.Error("keep alive is not responded while the handler is running")
+			return nil
+		}
+	})
+
+	errs := make(chan error, 1)
+	go func() { errs <- c.HandleGame() }()
+
+	if err := s.WritePacket(pk.Marshal(0x7F)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WritePacket(pk.Marshal(data.KeepAliveClientbound, pk.Long(42))); err != nil {
+		t.Fatal(err)
+	}
+	p, err := s.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var id pk.Long
+	if err := p.Scan(&id); err != nil || p.ID != data.KeepAliveServerbound || id != 42 {
+		t.Errorf("wrong keep alive response: %v, id %d, %v", p, id, err)
+	}
+	close(responded)
+
+	server.Close()
+	if err := <-errs; err == nil {
+		t.Error("HandleGame should return an error when the connection is closed")
+	}
+}
+
+func TestClient_keepAliveStopped(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+	c := NewClient()
+	c.conn = mcnet.WrapConn(client)
+	s := mcnet.WrapConn(server)
+
+	errs := make(chan error, 1)
+	go func() { errs <- c.HandleGame() }()
+	stop := errors.New("stop")
+	c.Delegate <- func() error { return stop }
+	if err := <-errs; err != stop {
+		t.Fatalf("HandleGame should return the task error, get %v", err)
+	}
+
+	// nothing is reading the connection after HandleGame returns
+	if err := s.SetWriteDeadline(time.Now().Add(100 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WritePacket(pk.Marshal(data.KeepAliveClientbound, pk.Long(42))); err == nil {
+		t.Error("the keep alive packet is read after HandleGame returned")
+	}
+}
+
+func TestClient_Ping(t *testing.T) {
+	c := NewClient()
+	me := uuid.New()
+	c.Player.UUID = [2]int64{
+		int64(binary.BigEndian.Uint64(me[:8])),
+		int64(binary.BigEndian.Uint64(me[8:])),
+	}
+	if c.Ping() != 0 {
+		t.Errorf("ping should be zero before reported, get %v", c.Ping())
+	}
+
+	p := pk.Marshal(data.PlayerInfo, pk.VarInt(2), pk.VarInt(2),
+		pk.UUID(uuid.New()), pk.VarInt(10),
+		pk.UUID(me), pk.VarInt(123),
+	)
+	if _, err := c.handlePacket(p); err != nil {
+		t.Fatal(err)
+	}
+	if c.Ping() != 123*time.Millisecond {
+		t.Errorf("ping should be 123ms, get %v", c.Ping())
+	}
+}
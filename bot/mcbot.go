@@ -7,17 +7,23 @@ package bot
 import (
 	"fmt"
 	"net"
+	"strconv"
 
+	"github.com/Tnze/go-mc/auth"
+	"github.com/Tnze/go-mc/bot/world"
+	"github.com/Tnze/go-mc/data"
 	mcnet "github.com/Tnze/go-mc/net"
 	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
 )
 
-// ProtocolVersion , the protocol version number of minecraft net protocol
-const ProtocolVersion = 736
+// ProtocolVersion , the protocol version number of minecraft net protocol.
+// It's the version of the packet IDs in package data.
+const ProtocolVersion = data.ProtocolVersion
 
 // JoinServer connect a Minecraft server for playing the game.
 func (c *Client) JoinServer(addr string, port int) (err error) {
-	conn, err := net.Dial("tcp", fmt.Sprintf("%s:%d", addr, port))
+	conn, err := net.Dial("tcp", net.JoinHostPort(addr, strconv.Itoa(port)))
 	if err != nil {
 		err = fmt.Errorf("bot: connect server fail: %v", err)
 		return
@@ -39,77 +45,27 @@ func (c *Client) JoinServerWithDialer(d Dialer, addr string) (err error) {
 func (c *Client) join(conn net.Conn) (err error) {
 	//Set Conn
 	c.conn = mcnet.WrapConn(conn)
+	c.conn.Logger = c.Logger
 
 	//Get Addr
-	strform := c.conn.Socket.RemoteAddr().String()
-	var addr string
-	var port int
-	fmt.Sscanf(strform, "%s:%d", &addr, &port)
-
-	//Handshake
-	err = c.conn.WritePacket(
-		//Handshake Packet
-		pk.Marshal(
-			0x00,                       //Handshake packet ID
-			pk.VarInt(ProtocolVersion), //Protocol version
-			pk.String(addr),            //Server's address
-			pk.UnsignedShort(port),
-			pk.Byte(2),
-		))
-	if err != nil {
-		err = fmt.Errorf("bot: send handshake packect fail: %v", err)
-		return
-	}
+	host, portStr, _ := net.SplitHostPort(conn.RemoteAddr().String())
+	port, _ := strconv.Atoi(portStr)
 
 	//Login
-	err = c.conn.WritePacket(
-		//LoginStart Packet
-		pk.Marshal(0, pk.String(c.Name)))
-	if err != nil {
-		err = fmt.Errorf("bot: send login start packect fail: %v", err)
-		return
-	}
-
-	for {
-		//Recive Packet
-		var pack pk.Packet
-		pack, err = c.conn.ReadPacket()
-		if err != nil {
-			err = fmt.Errorf("bot: recv packet for Login fail: %v", err)
-			return
-		}
+	id, _ := uuid.Parse(c.Auth.UUID) // zero in offline mode
+	_, profile, err := mcnet.Login(c.conn, auth.Profile{ID: id, Name: c.Name}, mcnet.LoginOptions{
+		Host:        host,
+		Port:        port,
+		Protocol:    ProtocolVersion,
+		AccessToken: c.AsTk,
 
-		//Handle Packet
-		switch pack.ID {
-		case 0x00: //Disconnect
-			var reason pk.String
-			err = pack.Scan(&reason)
-			if err != nil {
-				err = fmt.Errorf("bot: read Disconnect message fail: %v", err)
-			} else {
-				err = fmt.Errorf("bot: connect disconnected by server: %s", reason)
-			}
-			return
-		case 0x01: //Encryption Request
-			if err := handleEncryptionRequest(c, pack); err != nil {
-				return fmt.Errorf("bot: encryption fail: %v", err)
-			}
-		case 0x02: //Login Success
-			// uuid, l := pk.UnpackString(pack.Data)
-			// name, _ := unpackString(pack.Data[l:])
-			return //switches the connection state to PLAY.
-		case 0x03: //Set Compression
-			var threshold pk.VarInt
-			if err := pack.Scan(&threshold); err != nil {
-				return fmt.Errorf("bot: set compression fail: %v", err)
-			}
-			c.conn.SetThreshold(int(threshold))
-		case 0x04: //Login Plugin Request
-			if err := handlePluginPacket(c, pack); err != nil {
-				return fmt.Errorf("bot: handle plugin packet fail: %v", err)
-			}
-		}
+		OnPluginRequest: c.loginPluginRequest,
+	})
+	if err != nil {
+		return fmt.Errorf("bot: login fail: %w", err)
 	}
+	c.Player.UUID[0], c.Player.UUID[1] = pk.UUID(profile.ID).Longs()
+	return nil
 }
 
 // A Dialer is a means to establish a connection.
@@ -118,6 +74,12 @@ type Dialer interface {
 	Dial(network, addr string) (c net.Conn, err error)
 }
 
+// World return the world where the player is.
+// It should only be accessed in HandleGame or a Delegate.
+func (c *Client) World() *world.World {
+	return &c.Wd
+}
+
 // Conn return the MCConn of the Client.
 // Only used when you want to handle the packets by yourself
 func (c *Client) Conn() *mcnet.Conn {
@@ -0,0 +1,251 @@
+package bot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tnze/go-mc/auth"
+	"github.com/Tnze/go-mc/chat"
+	"github.com/Tnze/go-mc/data"
+	"github.com/Tnze/go-mc/internal/servertest"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+func TestClient_JoinServer(t *testing.T) {
+	s := servertest.New()
+	defer s.Close()
+	s.Threshold = 64
+
+	c := NewClient()
+	c.Name = "Steve"
+	errs := make(chan error, 1)
+	go func() { errs <- c.JoinServer(s.Host(), s.Port()) }()
+	conn := s.Accept(t)
+	defer conn.Close()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if conn.Protocol != ProtocolVersion || conn.Name != "Steve" {
+		t.Errorf("wrong login: protocol %d, name %q", conn.Protocol, conn.Name)
+	}
+	if most, least := conn.UUID.Longs(); c.Player.UUID != [2]int64{most, least} {
+		t.Errorf("wrong UUID: %v", c.Player.UUID)
+	}
+	if st := c.Conn().State(); st != data.Play {
+		t.Errorf("the state after login is %v", st)
+	}
+
+	chats := make(chan string, 1)
+	c.OnChatMessage(func(e ChatMessageEvent) error {
+		chats <- e.Message.ClearString()
+		return nil
+	})
+	go c.HandleGame()
+
+	conn.JoinGame(t)
+	conn.Chat(t, "hello")
+	select {
+	case msg := <-chats:
+		if msg != "hello" {
+			t.Errorf("received chat %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the chat message is not received")
+	}
+	conn.KeepAlive(t, 42)
+
+	if err := c.Chat("hi"); err != nil {
+		t.Fatal(err)
+	}
+	var msg pk.String
+	if err := conn.Expect(t, data.ChatMessageServerbound).Scan(&msg); err != nil || msg != "hi" {
+		t.Errorf("the client sent chat %q, %v", msg, err)
+	}
+	if c.EntityID != 1 {
+		t.Errorf("the entity ID is %d after joined", c.EntityID)
+	}
+}
+
+func TestClient_OnLoginPluginRequest(t *testing.T) {
+	type result struct {
+		response   []byte
+		understood bool
+	}
+	results := make(map[string]result)
+	s := servertest.New()
+	defer s.Close()
+	s.Login = func(c *servertest.Conn) error {
+		for i, channel := range []string{"test:echo", "test:unknown"} {
+			response, understood, err := c.LoginPluginRequest(i, channel, []byte("ping"))
+			if err != nil {
+				return err
+			}
+			results[channel] = result{response, understood}
+		}
+		return nil
+	}
+
+	c := NewClient()
+	c.OnLoginPluginRequest(func(channel string, data []byte) ([]byte, bool) {
+		return nil, false // not this one, try the next
+	})
+	c.OnLoginPluginRequest(func(channel string, data []byte) ([]byte, bool) {
+		if channel != "test:echo" {
+			return nil, false
+		}
+		return data, true
+	})
+	errs := make(chan error, 1)
+	go func() { errs <- c.JoinServer(s.Host(), s.Port()) }()
+	conn := s.Accept(t)
+	defer conn.Close()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+
+	if r := results["test:echo"]; !r.understood || string(r.response) != "ping" {
+		t.Errorf("response of test:echo: %q, %v", r.response, r.understood)
+	}
+	if r := results["test:unknown"]; r.understood || len(r.response) != 0 {
+		t.Errorf("response of test:unknown: %q, %v", r.response, r.understood)
+	}
+}
+
+func TestClient_EnableVelocityForwarding(t *testing.T) {
+	var response []byte
+	s := servertest.New()
+	defer s.Close()
+	s.Login = func(c *servertest.Conn) (err error) {
+		var understood bool
+		response, understood, err = c.LoginPluginRequest(0, mcnet.VelocityChannel, []byte{mcnet.VelocityForwardingMax})
+		if err == nil && !understood {
+			err = errors.New("velocity forwarding not understood")
+		}
+		return
+	}
+
+	c := NewClient()
+	c.EnableVelocityForwarding([]byte("secret"), "127.0.0.1")
+	errs := make(chan error, 1)
+	go func() { errs <- c.JoinServer(s.Host(), s.Port()) }()
+	conn := s.Accept(t)
+	defer conn.Close()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+
+	want := mcnet.VelocityForwarding{
+		Secret:  []byte("secret"),
+		Address: "127.0.0.1",
+		Profile: auth.Profile{ID: OfflineUUID("Steve"), Name: "Steve"},
+	}.Data(mcnet.VelocityForwardingDefault)
+	if !bytes.Equal(response, want) {
+		t.Errorf("forwarding data:\ngot  [% x]\nwant [% x]", response, want)
+	}
+}
+
+// testLogger records the logs of the levels
+type testLogger struct {
+	mu   sync.Mutex
+	logs []string
+}
+
+func (l *testLogger) logf(level, format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.logs = append(l.logs, level+" "+fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Debugf(format string, args ...interface{}) { l.logf("debug", format, args...) }
+func (l *testLogger) Infof(format string, args ...interface{})  { l.logf("info", format, args...) }
+func (l *testLogger) Warnf(format string, args ...interface{})  { l.logf("warn", format, args...) }
+func (l *testLogger) Errorf(format string, args ...interface{}) { l.logf("error", format, args...) }
+
+func (l *testLogger) has(log string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, v := range l.logs {
+		if strings.HasPrefix(v, log) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestClient_Logger(t *testing.T) {
+	s := servertest.New()
+	defer s.Close()
+
+	logger := new(testLogger)
+	c := NewClient()
+	c.Logger = logger
+	errs := make(chan error, 1)
+	go func() { errs <- c.JoinServer(s.Host(), s.Port()) }()
+	conn := s.Accept(t)
+	defer conn.Close()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if !logger.has("info net: state changed from login to play") {
+		t.Errorf("the login isn't logged: %q", logger.logs)
+	}
+
+	go func() { errs <- c.HandleGame() }()
+	conn.Send(t, pk.Marshal(data.TimeUpdate, pk.Long(0))) // the time of day is missing
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Fatal("the broken packet is handled")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("HandleGame doesn't return")
+	}
+	if !logger.has(fmt.Sprintf("debug net: read play packet 0x%02X of 8 bytes", data.TimeUpdate)) ||
+		!logger.has(fmt.Sprintf("warn bot: handle packet 0x%02X of 8 bytes fail", data.TimeUpdate)) {
+		t.Errorf("the broken packet isn't logged: %q", logger.logs)
+	}
+}
+
+func TestClient_HandleGame_kicked(t *testing.T) {
+	s := servertest.New()
+	defer s.Close()
+
+	c := NewClient()
+	var reason string
+	c.Events.Disconnect = func(msg chat.Message) error {
+		reason = msg.ClearString()
+		return nil
+	}
+	errs := make(chan error, 1)
+	go func() { errs <- c.JoinServer(s.Host(), s.Port()) }()
+	conn := s.Accept(t)
+	defer conn.Close()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+
+	go func() { errs <- c.HandleGame() }()
+	conn.Send(t, pk.Marshal(data.DisconnectPlay, pk.Chat(`{"text":"You are banned"}`)))
+	var err error
+	select {
+	case err = <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("HandleGame doesn't return")
+	}
+	var de *mcnet.DisconnectError
+	if !errors.As(err, &de) {
+		t.Fatalf("HandleGame returns %v", err)
+	}
+	if de.State != data.Play || de.Reason.ClearString() != "You are banned" || de.RawReason != `{"text":"You are banned"}` {
+		t.Errorf("wrong disconnect error: %+v", de)
+	}
+	if reason != "You are banned" {
+		t.Errorf("Events.Disconnect is called with %q", reason)
+	}
+}
@@ -12,7 +12,7 @@ import (
 // hand could be one of 0: main hand, 1: off hand.
 // It's just animation.
 func (c *Client) SwingArm(hand int) error {
-	return c.conn.WritePacket(pk.Marshal(
+	return c.SendPacket(pk.Marshal(
 		data.AnimationServerbound,
 		pk.VarInt(hand),
 	))
@@ -20,7 +20,7 @@ func (c *Client) SwingArm(hand int) error {
 
 // Respawn the player when it was dead.
 func (c *Client) Respawn() error {
-	return c.conn.WritePacket(pk.Marshal(
+	return c.SendPacket(pk.Marshal(
 		data.ClientStatus,
 		pk.VarInt(0),
 	))
@@ -29,7 +29,7 @@ func (c *Client) Respawn() error {
 // UseItem use the item player handing.
 // hand could be one of 0: main hand, 1: off hand
 func (c *Client) UseItem(hand int) error {
-	return c.conn.WritePacket(pk.Marshal(
+	return c.SendPacket(pk.Marshal(
 		data.UseItem,
 		pk.VarInt(hand),
 	))
@@ -41,7 +41,7 @@ func (c *Client) UseItem(hand int) error {
 // the entity being attacked/used is visible without obstruction
 // and within a 4-unit radius of the player's position.
 func (c *Client) UseEntity(entityID int32, hand int) error {
-	return c.conn.WritePacket(pk.Marshal(
+	return c.SendPacket(pk.Marshal(
 		data.UseEntity,
 		pk.VarInt(entityID),
 		pk.VarInt(0),
@@ -52,7 +52,7 @@ func (c *Client) UseEntity(entityID int32, hand int) error {
 // AttackEntity used by player to left-clicks another entity.
 // The attack version of UseEntity. Has the same limit.
 func (c *Client) AttackEntity(entityID int32, hand int) error {
-	return c.conn.WritePacket(pk.Marshal(
+	return c.SendPacket(pk.Marshal(
 		data.UseEntity,
 		pk.VarInt(entityID),
 		pk.VarInt(1),
@@ -61,7 +61,7 @@ func (c *Client) AttackEntity(entityID int32, hand int) error {
 
 // UseEntityAt is a variety of UseEntity with target location
 func (c *Client) UseEntityAt(entityID int32, x, y, z float32, hand int) error {
-	return c.conn.WritePacket(pk.Marshal(
+	return c.SendPacket(pk.Marshal(
 		data.UseEntity,
 		pk.VarInt(entityID),
 		pk.VarInt(2),
@@ -70,21 +70,9 @@ func (c *Client) UseEntityAt(entityID int32, x, y, z float32, hand int) error {
 	))
 }
 
-// Chat send chat as chat message or command at textbox.
-func (c *Client) Chat(msg string) error {
-	if len(msg) > 256 {
-		return errors.New("message too long")
-	}
-
-	return c.conn.WritePacket(pk.Marshal(
-		data.ChatMessageServerbound,
-		pk.String(msg),
-	))
-}
-
 // PluginMessage is used by mods and plugins to send their data.
 func (c *Client) PluginMessage(channal string, msg []byte) error {
-	return c.conn.WritePacket(pk.Marshal(
+	return c.SendPacket(pk.Marshal(
 		data.PluginMessageServerbound,
 		pk.Identifier(channal),
 		pluginMessageData(msg),
@@ -102,7 +90,7 @@ func (c *Client) PluginMessage(channal string, msg []byte) error {
 //
 // insideBlock is true when the player's head is inside of a block's collision.
 func (c *Client) UseBlock(hand, locX, locY, locZ, face int, cursorX, cursorY, cursorZ float32, insideBlock bool) error {
-	return c.conn.WritePacket(pk.Marshal(
+	return c.SendPacket(pk.Marshal(
 		data.PlayerBlockPlacement,
 		pk.VarInt(hand),
 		pk.Position{X: locX, Y: locY, Z: locZ},
@@ -119,7 +107,7 @@ func (c *Client) SelectItem(slot int) error {
 		return errors.New("invalid slot: " + strconv.Itoa(slot))
 	}
 
-	return c.conn.WritePacket(pk.Marshal(
+	return c.SendPacket(pk.Marshal(
 		data.HeldItemChangeServerbound,
 		pk.Short(slot),
 	))
@@ -136,14 +124,14 @@ func (c *Client) SelectItem(slot int) error {
 // use the currently selected slot. After finding the appropriate slot,
 // the server swaps the items and then change player's selected slot (cause the HeldItemChange event).
 func (c *Client) PickItem(slot int) error {
-	return c.conn.WritePacket(pk.Marshal(
+	return c.SendPacket(pk.Marshal(
 		data.PickItem,
 		pk.VarInt(slot),
 	))
 }
 
 func (c *Client) playerAction(status, locX, locY, locZ, face int) error {
-	return c.conn.WritePacket(pk.Marshal(
+	return c.SendPacket(pk.Marshal(
 		data.PlayerDigging,
 		pk.VarInt(status),
 		pk.Position{X: locX, Y: locY, Z: locZ},
@@ -185,6 +173,9 @@ func (c *Client) Disconnect() error {
 }
 
 // SendPacket send the packet to server.
+// It's safe to be called from multiple goroutines.
 func (c *Client) SendPacket(packet pk.Packet) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
 	return c.conn.WritePacket(packet)
 }
@@ -0,0 +1,268 @@
+package bot
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Tnze/go-mc/bot/world"
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// Constants of the vanilla player movement, in blocks and ticks
+const (
+	tickInterval = time.Second / 20
+
+	playerWidth     = 0.6
+	playerHeight    = 1.8
+	playerEyeHeight = 1.62
+
+	gravity      = 0.08
+	airDrag      = 0.98
+	walkSpeed    = 4.317 / 20
+	jumpVelocity = 0.42
+)
+
+// physics is the state of the client-side movement
+type physics struct {
+	mu         sync.Mutex
+	walkTarget *[2]float64 // x, z
+	lookTarget *[3]float64 // x, y, z
+
+	ready bool    // the position is set by the server
+	vy    float64 // the vertical velocity
+	jump  bool    // jump in the next tick
+
+	lastX, lastY, lastZ float64
+	lastYaw, lastPitch  float32
+	anySent             bool
+}
+
+// WalkTo let the player walk to (x, z) with gravity and collision,
+// and jump if it's blocked by one block.
+// It returns immediately, the player moves in the following ticks of HandleGame.
+// Call WalkTo again to change the destination.
+func (c *Client) WalkTo(x, z float64) {
+	c.physics.mu.Lock()
+	c.physics.walkTarget = &[2]float64{x, z}
+	c.physics.mu.Unlock()
+}
+
+// Walking report whether the player is walking to the destination of WalkTo.
+func (c *Client) Walking() bool {
+	c.physics.mu.Lock()
+	defer c.physics.mu.Unlock()
+	return c.physics.walkTarget != nil
+}
+
+// LookAt let the player look at the position (x, y, z) in the next tick.
+// Without LookAt, the player looks at the direction it walking to.
+func (c *Client) LookAt(x, y, z float64) {
+	c.physics.mu.Lock()
+	c.physics.lookTarget = &[3]float64{x, y, z}
+	c.physics.mu.Unlock()
+}
+
+// resetPhysics is called when the server set the player's position
+func (c *Client) resetPhysics() {
+	c.physics.ready = true
+	c.physics.vy = 0
+	c.physics.jump = false
+}
+
+// physicsTick is called 20 times a second by HandleGame.
+// Movement is simulated only when the chunk where the player in is loaded.
+func (c *Client) physicsTick() error {
+	p := &c.physics
+	if c.DisablePhysics || !p.ready {
+		return nil
+	}
+	if _, ok := c.Wd.Chunks[world.ChunkLoc{X: int(math.Floor(c.X)) >> 4, Z: int(math.Floor(c.Z)) >> 4}]; !ok {
+		return nil
+	}
+
+	p.mu.Lock()
+	walk, look := p.walkTarget, p.lookTarget
+	p.lookTarget = nil
+	p.mu.Unlock()
+
+	var dx, dz float64
+	if walk != nil {
+		tx, tz := walk[0]-c.X, walk[1]-c.Z
+		dist := math.Hypot(tx, tz)
+		if dist < 1e-3 {
+			p.mu.Lock()
+			if p.walkTarget == walk {
+				p.walkTarget = nil
+			}
+			p.mu.Unlock()
+		} else {
+			speed := math.Min(walkSpeed, dist)
+			dx, dz = tx/dist*speed, tz/dist*speed
+			c.Yaw, c.Pitch = lookAngle(tx, 0, tz)
+		}
+	}
+	if look != nil {
+		c.Yaw, c.Pitch = lookAngle(look[0]-c.X, look[1]-(c.Y+playerEyeHeight), look[2]-c.Z)
+	}
+
+	if p.jump {
+		p.vy, p.jump = jumpVelocity, false
+	}
+	mx, my, mz := collide(&c.Wd, c.X, c.Y, c.Z, dx, p.vy, dz)
+	c.X, c.Y, c.Z = c.X+mx, c.Y+my, c.Z+mz
+	c.OnGround = p.vy < 0 && my != p.vy
+	if my != p.vy {
+		p.vy = 0
+	}
+	p.jump = c.OnGround && (mx != dx || mz != dz)
+	p.vy = (p.vy - gravity) * airDrag
+
+	return c.sendMovement()
+}
+
+// sendMovement send the packet of what is changed since the last tick
+func (c *Client) sendMovement() error {
+	p := &c.physics
+	moved := !p.anySent || c.X != p.lastX || c.Y != p.lastY || c.Z != p.lastZ
+	rotated := !p.anySent || c.Yaw != p.lastYaw || c.Pitch != p.lastPitch
+	p.lastX, p.lastY, p.lastZ = c.X, c.Y, c.Z
+	p.lastYaw, p.lastPitch = c.Yaw, c.Pitch
+	p.anySent = true
+
+	switch {
+	case moved && rotated:
+		return c.SendPacket(pk.Marshal(
+			data.PlayerPositionAndLookServerbound,
+			pk.Double(c.X), pk.Double(c.Y), pk.Double(c.Z),
+			pk.Float(c.Yaw), pk.Float(c.Pitch),
+			pk.Boolean(c.OnGround),
+		))
+	case moved:
+		return c.SendPacket(pk.Marshal(
+			data.PlayerPosition,
+			pk.Double(c.X), pk.Double(c.Y), pk.Double(c.Z),
+			pk.Boolean(c.OnGround),
+		))
+	case rotated:
+		return c.SendPacket(pk.Marshal(
+			data.PlayerLook,
+			pk.Float(c.Yaw), pk.Float(c.Pitch),
+			pk.Boolean(c.OnGround),
+		))
+	default:
+		return c.SendPacket(pk.Marshal(
+			data.Player,
+			pk.Boolean(c.OnGround),
+		))
+	}
+}
+
+// lookAngle return the yaw and pitch of looking at the direction
+func lookAngle(dx, dy, dz float64) (yaw, pitch float32) {
+	yaw = float32(-math.Atan2(dx, dz) / math.Pi * 180)
+	pitch = float32(-math.Atan2(dy, math.Hypot(dx, dz)) / math.Pi * 180)
+	return
+}
+
+// collide clip the movement (dx, dy, dz) of the player at (x, y, z) against
+// the blocks in the world, axis by axis in the order of Y, X, Z as vanilla.
+func collide(w *world.World, x, y, z, dx, dy, dz float64) (float64, float64, float64) {
+	player := data.Box{
+		MinX: x - playerWidth/2, MinY: y, MinZ: z - playerWidth/2,
+		MaxX: x + playerWidth/2, MaxY: y + playerHeight, MaxZ: z + playerWidth/2,
+	}
+	boxes := blockBoxes(w, expand(player, dx, dy, dz))
+
+	for _, b := range boxes {
+		dy = clipY(b, player, dy)
+	}
+	player.MinY, player.MaxY = player.MinY+dy, player.MaxY+dy
+	for _, b := range boxes {
+		dx = clipX(b, player, dx)
+	}
+	player.MinX, player.MaxX = player.MinX+dx, player.MaxX+dx
+	for _, b := range boxes {
+		dz = clipZ(b, player, dz)
+	}
+	return dx, dy, dz
+}
+
+// expand the box by the movement
+func expand(b data.Box, dx, dy, dz float64) data.Box {
+	if dx < 0 {
+		b.MinX += dx
+	} else {
+		b.MaxX += dx
+	}
+	if dy < 0 {
+		b.MinY += dy
+	} else {
+		b.MaxY += dy
+	}
+	if dz < 0 {
+		b.MinZ += dz
+	} else {
+		b.MaxZ += dz
+	}
+	return b
+}
+
+// blockBoxes return the collision boxes of the blocks intersecting the area, in world coordinates.
+func blockBoxes(w *world.World, area data.Box) (boxes []data.Box) {
+	// Fences are 1.5 blocks high, so check one more block below
+	for y := int(math.Floor(area.MinY)) - 1; y < int(math.Ceil(area.MaxY)); y++ {
+		for x := int(math.Floor(area.MinX)); x < int(math.Ceil(area.MaxX)); x++ {
+			for z := int(math.Floor(area.MinZ)); z < int(math.Ceil(area.MaxZ)); z++ {
+				for _, b := range data.BlockCollisionBoxes(int(w.GetBlockStatus(x, y, z))) {
+					boxes = append(boxes, data.Box{
+						MinX: b.MinX + float64(x), MinY: b.MinY + float64(y), MinZ: b.MinZ + float64(z),
+						MaxX: b.MaxX + float64(x), MaxY: b.MaxY + float64(y), MaxZ: b.MaxZ + float64(z),
+					})
+				}
+			}
+		}
+	}
+	return
+}
+
+func overlap(min1, max1, min2, max2 float64) bool {
+	return min1 < max2 && max1 > min2
+}
+
+func clipY(b, p data.Box, dy float64) float64 {
+	if !overlap(b.MinX, b.MaxX, p.MinX, p.MaxX) || !overlap(b.MinZ, b.MaxZ, p.MinZ, p.MaxZ) {
+		return dy
+	}
+	if dy > 0 && b.MinY >= p.MaxY {
+		dy = math.Min(dy, b.MinY-p.MaxY)
+	} else if dy < 0 && b.MaxY <= p.MinY {
+		dy = math.Max(dy, b.MaxY-p.MinY)
+	}
+	return dy
+}
+
+func clipX(b, p data.Box, dx float64) float64 {
+	if !overlap(b.MinY, b.MaxY, p.MinY, p.MaxY) || !overlap(b.MinZ, b.MaxZ, p.MinZ, p.MaxZ) {
+		return dx
+	}
+	if dx > 0 && b.MinX >= p.MaxX {
+		dx = math.Min(dx, b.MinX-p.MaxX)
+	} else if dx < 0 && b.MaxX <= p.MinX {
+		dx = math.Max(dx, b.MaxX-p.MinX)
+	}
+	return dx
+}
+
+func clipZ(b, p data.Box, dz float64) float64 {
+	if !overlap(b.MinX, b.MaxX, p.MinX, p.MaxX) || !overlap(b.MinY, b.MaxY, p.MinY, p.MaxY) {
+		return dz
+	}
+	if dz > 0 && b.MinZ >= p.MaxZ {
+		dz = math.Min(dz, b.MinZ-p.MaxZ)
+	} else if dz < 0 && b.MaxZ <= p.MinZ {
+		dz = math.Max(dz, b.MaxZ-p.MinZ)
+	}
+	return dz
+}
@@ -0,0 +1,98 @@
+package bot
+
+import (
+	"io/ioutil"
+	"math"
+	"testing"
+
+	"github.com/Tnze/go-mc/bot/world"
+	mcnet "github.com/Tnze/go-mc/net"
+)
+
+const stone = 1 // block state ID of minecraft:stone
+
+// newPhysicsClient return a client standing in the air above a flat stone floor at y=63
+func newPhysicsClient() *Client {
+	c := NewClient()
+	c.conn = &mcnet.Conn{Writer: ioutil.Discard}
+	c.Wd.LoadChunk(0, 0, &world.Chunk{})
+	for x := 0; x < 16; x++ {
+		for z := 0; z < 16; z++ {
+			c.Wd.SetBlockStatus(x, 63, z, stone)
+		}
+	}
+	c.X, c.Y, c.Z = 2.5, 70, 8.5
+	c.resetPhysics()
+	return c
+}
+
+func tick(t *testing.T, c *Client, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := c.physicsTick(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPhysics_gravity(t *testing.T) {
+	c := newPhysicsClient()
+	tick(t, c, 1)
+	if c.Y != 70 || c.OnGround {
+		t.Errorf("the first tick should not move, get y=%v", c.Y)
+	}
+	tick(t, c, 40)
+	if c.Y != 64 || !c.OnGround {
+		t.Errorf("player should land on the floor, get y=%v, onGround=%v", c.Y, c.OnGround)
+	}
+}
+
+func TestPhysics_WalkTo(t *testing.T) {
+	c := newPhysicsClient()
+	tick(t, c, 40)
+
+	c.WalkTo(12.5, 8.5)
+	tick(t, c, 10)
+	if c.X <= 2.5 || c.X >= 12.5 || !c.Walking() {
+		t.Errorf("player should be walking, get x=%v", c.X)
+	}
+	if math.Abs(float64(c.Yaw)+90) > 1e-3 {
+		t.Errorf("player should face +X (yaw -90), get %v", c.Yaw)
+	}
+	tick(t, c, 50)
+	if c.X != 12.5 || c.Z != 8.5 || c.Y != 64 || c.Walking() {
+		t.Errorf("player should arrive (12.5, 64, 8.5), get (%v, %v, %v)", c.X, c.Y, c.Z)
+	}
+
+	c.LookAt(12.5, 64, 20)
+	tick(t, c, 1)
+	if math.Abs(float64(c.Yaw)) > 1e-3 || c.Pitch <= 0 {
+		t.Errorf("player should look down towards +Z, get yaw=%v, pitch=%v", c.Yaw, c.Pitch)
+	}
+}
+
+func TestPhysics_collision(t *testing.T) {
+	c := newPhysicsClient()
+	tick(t, c, 40)
+
+	// A step of one block can be jumped over
+	for z := 0; z < 16; z++ {
+		c.Wd.SetBlockStatus(6, 64, z, stone)
+	}
+	c.WalkTo(9.5, 8.5)
+	tick(t, c, 60)
+	if c.X != 9.5 || c.Y != 64 {
+		t.Errorf("player should jump over the step, get (%v, %v, %v)", c.X, c.Y, c.Z)
+	}
+
+	// A wall of two blocks stops the player
+	for z := 0; z < 16; z++ {
+		c.Wd.SetBlockStatus(12, 64, z, stone)
+		c.Wd.SetBlockStatus(12, 65, z, stone)
+	}
+	c.WalkTo(14.5, 8.5)
+	tick(t, c, 60)
+	if c.X != 12-playerWidth/2 || c.Y >= 65 {
+		t.Errorf("player should be stopped by the wall, get (%v, %v, %v)", c.X, c.Y, c.Z)
+	}
+}
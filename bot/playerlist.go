@@ -0,0 +1,349 @@
+package bot
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Tnze/go-mc/auth"
+	"github.com/Tnze/go-mc/chat"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
+)
+
+// PlayerListEntry is a player in the player list, which is shown by the tab key.
+// The textures property of the Profile contains the skin and cape.
+type PlayerListEntry struct {
+	auth.Profile
+	Gamemode    int
+	Ping        int           // in milliseconds
+	DisplayName *chat.Message // nil for the Name
+	Listed      bool          // whether it's shown in the list, always true before 1.19.3
+}
+
+// The actions of PlayerInfo.
+// They are the bits of the Player Info Update packet since 1.19.3,
+// while the older Player Info packet does one of them in a packet.
+const (
+	PlayerInfoAdd = 1 << iota
+	PlayerInfoInitializeChat
+	PlayerInfoUpdateGamemode
+	PlayerInfoUpdateListed
+	PlayerInfoUpdateLatency
+	PlayerInfoUpdateDisplayName
+
+	// PlayerInfoRemove is the remove player action before 1.19.3,
+	// which is the Player Info Remove packet since then.
+	// It's never set with other actions.
+	PlayerInfoRemove = 1 << 8
+)
+
+// PlayerInfo is the decoded Player Info packet, or the Player Info Update
+// packet since 1.19.3. The fields of the Entries not in the Actions are zero.
+type PlayerInfo struct {
+	Actions int
+	Entries []PlayerListEntry
+}
+
+// PlayerLeaveEvent is fired when a player is removed from the player list
+type PlayerLeaveEvent struct {
+	Player PlayerListEntry
+}
+
+// OnPlayerLeave subscribe PlayerLeaveEvent
+func (c *Client) OnPlayerLeave(f func(PlayerLeaveEvent) error) (unsubscribe func()) {
+	return c.bus.playerLeave.add(f)
+}
+
+// PlayerList return the players in the player list, by their UUIDs.
+// The returned map is a copy.
+// It should only be called in HandleGame or a Delegate.
+func (c *Client) PlayerList() map[uuid.UUID]PlayerListEntry {
+	players := make(map[uuid.UUID]PlayerListEntry, len(c.playerList))
+	for id, p := range c.playerList {
+		players[id] = *p
+	}
+	return players
+}
+
+// DecodePlayerInfo decode a Player Info packet of the protocol version.
+// The enum form from 1.8 to 1.18.2 and the bitmask form of Player Info
+// Update from 1.19.3 to 1.20.2 are supported.
+func DecodePlayerInfo(p pk.Packet, protocol int) (*PlayerInfo, error) {
+	var (
+		info *PlayerInfo
+		err  error
+	)
+	switch {
+	case protocol >= 47 && protocol <= 758: // 1.8 to 1.18.2
+		info, err = decodePlayerInfoEnum(p)
+	case protocol >= 761 && protocol <= 764: // 1.19.3 to 1.20.2
+		info, err = decodePlayerInfoBitmask(p)
+	default:
+		return nil, fmt.Errorf("bot: decode player info of unsupported protocol %d", protocol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bot: decode player info fail: %v", err)
+	}
+	return info, nil
+}
+
+// DecodePlayerInfoRemove decode a Player Info Remove packet since 1.19.3,
+// which is a PlayerInfo of PlayerInfoRemove with the UUIDs only.
+func DecodePlayerInfoRemove(p pk.Packet) (*PlayerInfo, error) {
+	var ids []pk.UUID
+	if err := p.Scan(pk.Ary{Len: new(pk.VarInt), Ary: &ids}); err != nil {
+		return nil, fmt.Errorf("bot: decode player info remove fail: %v", err)
+	}
+	info := &PlayerInfo{Actions: PlayerInfoRemove, Entries: make([]PlayerListEntry, len(ids))}
+	for i, id := range ids {
+		info.Entries[i].ID = uuid.UUID(id)
+	}
+	return info, nil
+}
+
+// the actions of the enum form, by the action IDs
+var playerInfoEnum = []int{
+	PlayerInfoAdd | PlayerInfoUpdateGamemode | PlayerInfoUpdateListed | PlayerInfoUpdateLatency | PlayerInfoUpdateDisplayName,
+	PlayerInfoUpdateGamemode,
+	PlayerInfoUpdateLatency,
+	PlayerInfoUpdateDisplayName,
+	PlayerInfoRemove,
+}
+
+func decodePlayerInfoEnum(p pk.Packet) (*PlayerInfo, error) {
+	var action, count pk.VarInt
+	r := bytes.NewReader(p.Data)
+	if err := decodeFields(r, &action, &count); err != nil {
+		return nil, err
+	}
+	if action < 0 || int(action) >= len(playerInfoEnum) {
+		return nil, fmt.Errorf("unknown action %d", action)
+	}
+	info := &PlayerInfo{Actions: playerInfoEnum[action]}
+	for i := 0; i < int(count); i++ {
+		var (
+			id       pk.UUID
+			gamemode pk.VarInt
+			ping     pk.VarInt
+			e        PlayerListEntry
+			err      error
+		)
+		if err = id.Decode(r); err != nil {
+			return nil, err
+		}
+		e.ID = uuid.UUID(id)
+		switch action {
+		case 0: // add player
+			if err = decodeProfile(r, &e.Profile); err == nil {
+				err = decodeFields(r, &gamemode, &ping)
+			}
+			if err == nil {
+				e.DisplayName, err = decodeDisplayName(r)
+			}
+			e.Gamemode, e.Ping, e.Listed = int(gamemode), int(ping), true
+		case 1: // update gamemode
+			err = gamemode.Decode(r)
+			e.Gamemode = int(gamemode)
+		case 2: // update latency
+			err = ping.Decode(r)
+			e.Ping = int(ping)
+		case 3: // update display name
+			e.DisplayName, err = decodeDisplayName(r)
+		}
+		if err != nil {
+			return nil, err
+		}
+		info.Entries = append(info.Entries, e)
+	}
+	return info, nil
+}
+
+func decodePlayerInfoBitmask(p pk.Packet) (*PlayerInfo, error) {
+	var (
+		actions pk.UnsignedByte
+		count   pk.VarInt
+	)
+	r := bytes.NewReader(p.Data)
+	if err := decodeFields(r, &actions, &count); err != nil {
+		return nil, err
+	}
+	info := &PlayerInfo{Actions: int(actions)}
+	for i := 0; i < int(count); i++ {
+		var (
+			id pk.UUID
+			e  PlayerListEntry
+		)
+		if err := id.Decode(r); err != nil {
+			return nil, err
+		}
+		e.ID = uuid.UUID(id)
+		if actions&PlayerInfoAdd != 0 {
+			if err := decodeProfile(r, &e.Profile); err != nil {
+				return nil, err
+			}
+		}
+		if actions&PlayerInfoInitializeChat != 0 {
+			if err := skipChatSession(r); err != nil {
+				return nil, err
+			}
+		}
+		if actions&PlayerInfoUpdateGamemode != 0 {
+			var gamemode pk.VarInt
+			if err := gamemode.Decode(r); err != nil {
+				return nil, err
+			}
+			e.Gamemode = int(gamemode)
+		}
+		if actions&PlayerInfoUpdateListed != 0 {
+			var listed pk.Boolean
+			if err := listed.Decode(r); err != nil {
+				return nil, err
+			}
+			e.Listed = bool(listed)
+		}
+		if actions&PlayerInfoUpdateLatency != 0 {
+			var ping pk.VarInt
+			if err := ping.Decode(r); err != nil {
+				return nil, err
+			}
+			e.Ping = int(ping)
+		}
+		if actions&PlayerInfoUpdateDisplayName != 0 {
+			var err error
+			if e.DisplayName, err = decodeDisplayName(r); err != nil {
+				return nil, err
+			}
+		}
+		info.Entries = append(info.Entries, e)
+	}
+	return info, nil
+}
+
+// decodeProfile decode the name and properties of the add player action
+func decodeProfile(r pk.DecodeReader, profile *auth.Profile) error {
+	var (
+		name       pk.String
+		propsCount pk.VarInt
+	)
+	if err := decodeFields(r, &name, &propsCount); err != nil {
+		return err
+	}
+	profile.Name = string(name)
+	for i := 0; i < int(propsCount); i++ {
+		var (
+			propName, value, signature pk.String
+			signed                     pk.Boolean
+		)
+		if err := decodeFields(r, &propName, &value, &signed); err != nil {
+			return err
+		}
+		if signed {
+			if err := signature.Decode(r); err != nil {
+				return err
+			}
+		}
+		profile.Properties = append(profile.Properties, auth.Property{
+			Name:      string(propName),
+			Value:     string(value),
+			Signature: string(signature),
+		})
+	}
+	return nil
+}
+
+// decodeDisplayName decode an optional chat component
+func decodeDisplayName(r pk.DecodeReader) (*chat.Message, error) {
+	var has pk.Boolean
+	if err := has.Decode(r); err != nil || !has {
+		return nil, err
+	}
+	msg := new(chat.Message)
+	return msg, msg.Decode(r)
+}
+
+// skipChatSession read the chat session of the initialize chat action,
+// which isn't used by the bot
+func skipChatSession(r pk.DecodeReader) error {
+	var (
+		has       pk.Boolean
+		sessionID pk.UUID
+		expiry    pk.Long
+		key, sig  pk.ByteArray
+	)
+	if err := has.Decode(r); err != nil || !has {
+		return err
+	}
+	return decodeFields(r, &sessionID, &expiry, &key, &sig)
+}
+
+func handlePlayerInfoPacket(c *Client, p pk.Packet) error {
+	info, err := DecodePlayerInfo(p, ProtocolVersion)
+	if err != nil {
+		return err
+	}
+	return c.updatePlayerList(info)
+}
+
+// updatePlayerList apply the actions of info to the player list,
+// and fire the PlayerJoinEvent and PlayerLeaveEvent
+func (c *Client) updatePlayerList(info *PlayerInfo) error {
+	for _, e := range info.Entries {
+		if info.Actions&PlayerInfoRemove != 0 {
+			old, ok := c.playerList[e.ID]
+			if !ok {
+				continue
+			}
+			delete(c.playerList, e.ID)
+			err := c.bus.playerLeave.fire(func(f interface{}) error {
+				return f.(func(PlayerLeaveEvent) error)(PlayerLeaveEvent{Player: *old})
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if info.Actions&PlayerInfoUpdateLatency != 0 && c.isMe(e.ID) {
+			c.setPing(e.Ping)
+		}
+		player, ok := c.playerList[e.ID]
+		if info.Actions&PlayerInfoAdd != 0 {
+			if c.playerList == nil {
+				c.playerList = make(map[uuid.UUID]*PlayerListEntry)
+			}
+			player = &PlayerListEntry{Profile: e.Profile}
+			c.playerList[e.ID] = player
+		} else if !ok {
+			continue // not added, ignore it
+		}
+		if info.Actions&PlayerInfoUpdateGamemode != 0 {
+			player.Gamemode = e.Gamemode
+		}
+		if info.Actions&PlayerInfoUpdateListed != 0 {
+			player.Listed = e.Listed
+		}
+		if info.Actions&PlayerInfoUpdateLatency != 0 {
+			player.Ping = e.Ping
+		}
+		if info.Actions&PlayerInfoUpdateDisplayName != 0 {
+			player.DisplayName = e.DisplayName
+		}
+
+		if info.Actions&PlayerInfoAdd != 0 {
+			je := PlayerJoinEvent{
+				UUID:        player.ID,
+				Name:        player.Name,
+				Gamemode:    player.Gamemode,
+				Ping:        player.Ping,
+				DisplayName: player.DisplayName,
+			}
+			err := c.bus.playerJoin.fire(func(f interface{}) error {
+				return f.(func(PlayerJoinEvent) error)(je)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/Tnze/go-mc/chat"
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
+)
+
+func TestDecodePlayerInfo_bitmask(t *testing.T) {
+	notch := uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5")
+	jeb := uuid.MustParse("853c80ef-3c37-49fd-aa49-938b674adae6")
+	// the Player Info Update packet of 1.19.3, the packet ID doesn't matter
+	p := pk.Marshal(0x36,
+		pk.UnsignedByte(PlayerInfoAdd|PlayerInfoInitializeChat|PlayerInfoUpdateGamemode|PlayerInfoUpdateListed|PlayerInfoUpdateLatency|PlayerInfoUpdateDisplayName),
+		pk.VarInt(2),
+		// Notch, with textures and chat session
+		pk.UUID(notch), pk.String("Notch"),
+		pk.VarInt(1), pk.String("textures"), pk.String("e30="), pk.Boolean(true), pk.String("sig"),
+		pk.Boolean(true), pk.UUID{}, pk.Long(0), pk.ByteArray{1, 2}, pk.ByteArray{3},
+		pk.VarInt(1), pk.Boolean(true), pk.VarInt(42), pk.Boolean(true), chat.Text("[Admin] Notch"),
+		// jeb_, unlisted and without chat session
+		pk.UUID(jeb), pk.String("jeb_"), pk.VarInt(0),
+		pk.Boolean(false),
+		pk.VarInt(3), pk.Boolean(false), pk.VarInt(7), pk.Boolean(false),
+	)
+	info, err := DecodePlayerInfo(p, 761)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Entries) != 2 {
+		t.Fatalf("%d entries", len(info.Entries))
+	}
+	e := info.Entries[0]
+	if e.ID != notch || e.Name != "Notch" || e.Gamemode != 1 || !e.Listed || e.Ping != 42 ||
+		e.DisplayName == nil || e.DisplayName.ClearString() != "[Admin] Notch" {
+		t.Errorf("wrong entry: %+v", e)
+	}
+	if tex, ok := e.Textures(); !ok || tex.Value != "e30=" || tex.Signature != "sig" {
+		t.Errorf("wrong textures: %+v", tex)
+	}
+	if e := info.Entries[1]; e.ID != jeb || e.Name != "jeb_" || e.Gamemode != 3 || e.Listed || e.DisplayName != nil {
+		t.Errorf("wrong entry: %+v", e)
+	}
+
+	// only the latency
+	p = pk.Marshal(0x36, pk.UnsignedByte(PlayerInfoUpdateLatency), pk.VarInt(1), pk.UUID(jeb), pk.VarInt(99))
+	if info, err = DecodePlayerInfo(p, 761); err != nil {
+		t.Fatal(err)
+	}
+	if info.Actions != PlayerInfoUpdateLatency || info.Entries[0].Ping != 99 || info.Entries[0].Name != "" {
+		t.Errorf("wrong latency update: %+v", info)
+	}
+
+	p = pk.Marshal(0x35, pk.Ary{Len: pk.VarInt(1), Ary: []pk.UUID{pk.UUID(jeb)}})
+	if info, err = DecodePlayerInfoRemove(p); err != nil {
+		t.Fatal(err)
+	}
+	if info.Actions != PlayerInfoRemove || len(info.Entries) != 1 || info.Entries[0].ID != jeb {
+		t.Errorf("wrong remove: %+v", info)
+	}
+
+	if _, err := DecodePlayerInfo(p, 760); err == nil {
+		t.Error("the unsupported protocol is decoded")
+	}
+}
+
+func TestClient_PlayerList(t *testing.T) {
+	c := NewClient()
+	var left []PlayerListEntry
+	c.OnPlayerLeave(func(e PlayerLeaveEvent) error {
+		left = append(left, e.Player)
+		return nil
+	})
+	handle := func(p pk.Packet) {
+		t.Helper()
+		if _, err := c.handlePacket(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	id := uuid.New()
+	handle(pk.Marshal(data.PlayerInfo,
+		pk.VarInt(0), pk.VarInt(1), // add 1 player
+		pk.UUID(id), pk.String("Tnze"),
+		pk.VarInt(1), pk.String("textures"), pk.String("e30="), pk.Boolean(false),
+		pk.VarInt(0), pk.VarInt(42), pk.Boolean(false),
+	))
+	handle(pk.Marshal(data.PlayerInfo, pk.VarInt(1), pk.VarInt(1), pk.UUID(id), pk.VarInt(3)))
+	handle(pk.Marshal(data.PlayerInfo, pk.VarInt(3), pk.VarInt(1), pk.UUID(id), pk.Boolean(true), chat.Text("Tnze!")))
+	e, ok := c.PlayerList()[id]
+	if !ok {
+		t.Fatal("the player isn't added")
+	}
+	if e.Name != "Tnze" || e.Gamemode != 3 || e.Ping != 42 || !e.Listed || e.DisplayName.ClearString() != "Tnze!" {
+		t.Errorf("wrong player: %+v", e)
+	}
+	if tex, ok := e.Textures(); !ok || tex.Value != "e30=" {
+		t.Errorf("wrong textures: %+v", tex)
+	}
+
+	handle(pk.Marshal(data.PlayerInfo, pk.VarInt(4), pk.VarInt(2), pk.UUID(id), pk.UUID(uuid.New())))
+	if len(c.PlayerList()) != 0 {
+		t.Errorf("players left: %v", c.PlayerList())
+	}
+	if len(left) != 1 || left[0].ID != id || left[0].Gamemode != 3 {
+		t.Errorf("leave events: %+v", left)
+	}
+}
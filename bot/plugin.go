@@ -0,0 +1,164 @@
+package bot
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/Tnze/go-mc/auth"
+	"github.com/Tnze/go-mc/data"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
+)
+
+// DefaultBrand is the default value of Client.Brand
+const DefaultBrand = "vanilla"
+
+const brandChannel = "minecraft:brand"
+
+// errStopFiring stops the firing of listeners, see loginPluginRequest
+var errStopFiring = errors.New("stop firing")
+
+// pluginChannels holds the plugin message handlers indexed by channel
+type pluginChannels struct {
+	mu       sync.Mutex
+	channels map[string]*listeners
+	unknown  listeners
+	login    listeners // see OnLoginPluginRequest
+}
+
+// OnPluginMessage register a handler for the plugin messages of the channel,
+// like "minecraft:brand" or "bungeecord:main".
+// The namespace "minecraft:" is used if the channel has none.
+// The handler is called as the typed events, see OnChatMessage.
+func (c *Client) OnPluginMessage(channel string, f func(data []byte) error) (unsubscribe func()) {
+	channel = channelName(channel)
+	pc := &c.bus.plugin
+	pc.mu.Lock()
+	if pc.channels == nil {
+		pc.channels = make(map[string]*listeners)
+	}
+	ls, ok := pc.channels[channel]
+	if !ok {
+		ls = new(listeners)
+		pc.channels[channel] = ls
+	}
+	pc.mu.Unlock()
+	return ls.add(f)
+}
+
+// OnUnknownPluginMessage register a handler for the plugin messages of the
+// channels which have no handler registered by OnPluginMessage.
+// The "minecraft:brand" channel is always known, because the bot handles it.
+func (c *Client) OnUnknownPluginMessage(f func(channel string, data []byte) error) (unsubscribe func()) {
+	return c.bus.plugin.unknown.add(f)
+}
+
+// OnLoginPluginRequest register a handler for the Login Plugin Requests,
+// which the modded servers and the proxies like Velocity send in the login.
+// It must be registered before joining the server.
+//
+// The handlers are called in the subscription order until one understands
+// the request, whose response is sent to the server. If none understands,
+// the bot responds not understood.
+func (c *Client) OnLoginPluginRequest(f func(channel string, data []byte) (response []byte, understood bool)) (unsubscribe func()) {
+	return c.bus.plugin.login.add(f)
+}
+
+// EnableVelocityForwarding answer the Velocity modern forwarding in the login,
+// for joining a server behind a Velocity proxy directly, with the forwarding
+// secret of the proxy. The address is the IP address of the player forwarded.
+// The UUID of the player is of Auth.UUID, or the offline UUID if it isn't set.
+// It must be enabled before joining the server.
+func (c *Client) EnableVelocityForwarding(secret []byte, address string) (disable func()) {
+	return c.OnLoginPluginRequest(func(channel string, data []byte) ([]byte, bool) {
+		id, err := uuid.Parse(c.Auth.UUID)
+		if err != nil {
+			id = OfflineUUID(c.Name)
+		}
+		fwd := mcnet.VelocityForwarding{
+			Secret:   secret,
+			Address:  address,
+			Profile:  auth.Profile{ID: id, Name: c.Name},
+			Protocol: ProtocolVersion,
+		}
+		return fwd.OnPluginRequest(channel, data)
+	})
+}
+
+// loginPluginRequest is the net.LoginOptions.OnPluginRequest of the bot
+func (c *Client) loginPluginRequest(channel string, data []byte) (response []byte, understood bool) {
+	_ = c.bus.plugin.login.fire(func(f interface{}) error {
+		response, understood = f.(func(string, []byte) ([]byte, bool))(channel, data)
+		if understood {
+			return errStopFiring
+		}
+		return nil
+	})
+	return
+}
+
+// SendPluginMessage send data to the server on the channel.
+// The namespace "minecraft:" is used if the channel has none.
+func (c *Client) SendPluginMessage(channel string, data []byte) error {
+	return c.PluginMessage(channelName(channel), data)
+}
+
+func channelName(channel string) string {
+	if !strings.Contains(channel, ":") {
+		return "minecraft:" + channel
+	}
+	return channel
+}
+
+func handlePluginMessagePacket(c *Client, p pk.Packet) error {
+	var (
+		channel pk.Identifier
+		msg     pluginMessageData
+	)
+	if err := p.Scan(&channel, &msg); err != nil {
+		return err
+	}
+	if c.Events.PluginMessage != nil {
+		if err := c.Events.PluginMessage(string(channel), msg); err != nil {
+			return err
+		}
+	}
+
+	pc := &c.bus.plugin
+	pc.mu.Lock()
+	ls := pc.channels[string(channel)]
+	pc.mu.Unlock()
+	known := false
+	if ls != nil {
+		ls.mu.Lock()
+		known = len(ls.l) > 0
+		ls.mu.Unlock()
+	}
+	if known {
+		if err := ls.fire(func(f interface{}) error {
+			return f.(func([]byte) error)(msg)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if channel == brandChannel {
+		// the server tells its brand, and we tell ours
+		if c.Brand == "" {
+			return nil
+		}
+		return c.SendPacket(pk.Marshal(
+			data.PluginMessageServerbound,
+			pk.Identifier(brandChannel),
+			pluginMessageData(pk.String(c.Brand).Encode()),
+		))
+	}
+	if !known {
+		return pc.unknown.fire(func(f interface{}) error {
+			return f.(func(string, []byte) error)(string(channel), msg)
+		})
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package bot
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Tnze/go-mc/data"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+func TestClient_OnPluginMessage(t *testing.T) {
+	c := NewClient()
+	var got [][]byte
+	unsubscribe := c.OnPluginMessage("bungeecord:main", func(data []byte) error {
+		got = append(got, data)
+		return nil
+	})
+	var unknown []string
+	c.OnUnknownPluginMessage(func(channel string, data []byte) error {
+		unknown = append(unknown, channel)
+		return nil
+	})
+
+	p := pk.Marshal(data.PluginMessageClientbound, pk.Identifier("bungeecord:main"), pluginMessageData("hello"))
+	if _, err := c.handlePacket(p); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || string(got[0]) != "hello" {
+		t.Errorf("wrong messages: %q", got)
+	}
+	if len(unknown) != 0 {
+		t.Errorf("known channel is routed to the default handler: %q", unknown)
+	}
+
+	unsubscribe()
+	if _, err := c.handlePacket(p); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Errorf("handler should not be called after unsubscribe")
+	}
+	if len(unknown) != 1 || unknown[0] != "bungeecord:main" {
+		t.Errorf("unknown channel is not routed to the default handler: %q", unknown)
+	}
+}
+
+func TestClient_brand(t *testing.T) {
+	var buf bytes.Buffer
+	c := NewClient()
+	c.conn = &mcnet.Conn{Writer: &buf}
+	c.Brand = "go-mc"
+
+	var serverBrand pk.String
+	c.OnPluginMessage("brand", func(data []byte) error {
+		return serverBrand.Decode(bytes.NewReader(data))
+	})
+	p := pk.Marshal(data.PluginMessageClientbound, pk.Identifier("minecraft:brand"), pk.String("vanilla"))
+	if _, err := c.handlePacket(p); err != nil {
+		t.Fatal(err)
+	}
+	if serverBrand != "vanilla" {
+		t.Errorf("wrong server brand: %q", serverBrand)
+	}
+
+	resp, err := pk.RecvPacket(&buf, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		channel pk.Identifier
+		brand   pk.String
+	)
+	if resp.ID != data.PluginMessageServerbound {
+		t.Fatalf("wrong packet ID: %#x", resp.ID)
+	}
+	if err := resp.Scan(&channel, &brand); err != nil {
+		t.Fatal(err)
+	}
+	if channel != "minecraft:brand" || brand != "go-mc" {
+		t.Errorf("wrong response: %q %q", channel, brand)
+	}
+}
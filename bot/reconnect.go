@@ -0,0 +1,207 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/Tnze/go-mc/auth"
+	"github.com/Tnze/go-mc/bot/world"
+	"github.com/Tnze/go-mc/bot/world/entity/player"
+	mcnet "github.com/Tnze/go-mc/net"
+)
+
+// Backoff decides how long RunWithReconnect waits before reconnecting.
+// The n-th reconnection in a row waits Initial * Multiplier^(n-1), up to Max,
+// and then randomized by Jitter.
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	// Jitter is the fraction of the delay to be randomized, from 0 to 1.
+	// For example, 0.2 makes the delay between 80% and 120% of it.
+	Jitter float64
+	// A connection lasts ResetAfter resets the delay to Initial.
+	// Zero means it's the same as Max.
+	ResetAfter time.Duration
+}
+
+// DefaultBackoff is a Backoff for most servers.
+// The zero Initial, Max and Multiplier of a Backoff are the ones of it.
+var DefaultBackoff = Backoff{
+	Initial:    time.Second,
+	Max:        5 * time.Minute,
+	Multiplier: 2,
+	Jitter:     0.2,
+	ResetAfter: 5 * time.Minute,
+}
+
+// delay return the delay before the n-th reconnection in a row, n >= 1
+func (b Backoff) delay(n int) time.Duration {
+	d := float64(b.Initial)
+	for i := 1; i < n && d < float64(b.Max); i++ {
+		d *= b.Multiplier
+	}
+	if d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	if b.Jitter > 0 {
+		d += d * b.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+func (b Backoff) withDefaults() Backoff {
+	if b.Initial <= 0 {
+		b.Initial = DefaultBackoff.Initial
+	}
+	if b.Max <= 0 {
+		b.Max = DefaultBackoff.Max
+	}
+	if b.Multiplier <= 0 {
+		b.Multiplier = DefaultBackoff.Multiplier
+	}
+	if b.ResetAfter <= 0 {
+		b.ResetAfter = b.Max
+	}
+	return b
+}
+
+// ReconnectEvent is fired by RunWithReconnect before waiting to reconnect.
+// A non-nil error returned by a handler stops RunWithReconnect.
+type ReconnectEvent struct {
+	Attempt int           // the number of reconnections in a row, from 1
+	Delay   time.Duration // how long it waits before reconnecting
+	Err     error         // the error ended the last connection, nil if HandleGame returned nil
+}
+
+// OnReconnect subscribe ReconnectEvent
+func (c *Client) OnReconnect(f func(ReconnectEvent) error) (unsubscribe func()) {
+	return c.bus.reconnect.add(f)
+}
+
+// RunWithReconnect connect the server by dial, join the game and run
+// HandleGame, then do them again when the connection ends. The client is
+// reset to the state of before joining each time, while the handlers,
+// events and settings are kept.
+//
+// It waits by the backoff before reconnecting, and fires ReconnectEvent.
+// It stops when ctx is done, with the error of ctx, or when the error
+// ending the connection isn't retryable, with that error. The errors not
+// retryable are the failures of authentication, and the Disconnect packets
+// for being banned, not whitelisted or the protocol version mismatched.
+func (c *Client) RunWithReconnect(ctx context.Context, dial func(ctx context.Context) (net.Conn, error), backoff Backoff) error {
+	backoff = backoff.withDefaults()
+	attempt := 0
+	for {
+		start := time.Now()
+		err := c.runOnce(ctx, dial)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !retryable(err) {
+			return err
+		}
+
+		if time.Since(start) >= backoff.ResetAfter {
+			attempt = 0
+		}
+		attempt++
+		e := ReconnectEvent{Attempt: attempt, Delay: backoff.delay(attempt), Err: err}
+		if err := c.bus.reconnect.fire(func(f interface{}) error {
+			return f.(func(ReconnectEvent) error)(e)
+		}); err != nil {
+			return err
+		}
+
+		t := time.NewTimer(e.Delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// runOnce connect and play until the connection ends
+func (c *Client) runOnce(ctx context.Context, dial func(ctx context.Context) (net.Conn, error)) error {
+	conn, err := dial(ctx)
+	if err != nil {
+		return fmt.Errorf("bot: connect server fail: %w", err)
+	}
+	defer conn.Close()
+	// the login and HandleGame are stopped by closing conn
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	c.reset()
+	if err := c.join(conn); err != nil {
+		return err
+	}
+	return c.HandleGame()
+}
+
+// reset clear the state of the last connection
+func (c *Client) reset() {
+	c.Player = player.Player{}
+	c.PlayInfo = PlayInfo{}
+	c.abilities = PlayerAbilities{}
+	c.Wd = world.World{
+		Entities: make(map[int32]*world.Entity),
+		Chunks:   make(map[world.ChunkLoc]*world.Chunk),
+	}
+	c.dead = false
+
+	c.physics.mu.Lock()
+	c.physics.walkTarget, c.physics.lookTarget = nil, nil
+	c.physics.ready, c.physics.vy, c.physics.jump = false, 0, false
+	c.physics.anySent = false
+	c.physics.mu.Unlock()
+
+	c.chatQueue.mu.Lock()
+	c.chatQueue.msgs = nil
+	c.chatQueue.mu.Unlock()
+
+	c.inventories = inventories{}
+	c.commands = nil
+	c.time = worldTime{}
+	c.joinGame = nil
+	c.bossBars = nil
+	c.scoreboard = Scoreboard{}
+	c.teams = nil
+	c.playerList = nil
+}
+
+// the translation keys of the disconnect reasons not to be retried
+var permanentDisconnects = map[string]bool{
+	"multiplayer.disconnect.banned":              true,
+	"multiplayer.disconnect.banned.reason":       true,
+	"multiplayer.disconnect.banned_ip.reason":    true,
+	"multiplayer.disconnect.not_whitelisted":     true,
+	"multiplayer.disconnect.outdated_client":     true,
+	"multiplayer.disconnect.outdated_server":     true,
+	"multiplayer.disconnect.unverified_username": true,
+}
+
+// retryable report whether the connection ended by err should be reconnected
+func retryable(err error) bool {
+	if errors.Is(err, mcnet.ErrNoAccessToken) || errors.Is(err, auth.ErrJoinForbidden) {
+		return false
+	}
+	var de *mcnet.DisconnectError
+	if errors.As(err, &de) {
+		return !permanentDisconnects[de.Reason.Translate]
+	}
+	return true
+}
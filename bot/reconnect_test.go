@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Tnze/go-mc/data"
+	"github.com/Tnze/go-mc/internal/servertest"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
+)
+
+func TestBackoff_delay(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: 10 * time.Second, Multiplier: 2}
+	for n, want := range []time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 8 * time.Second, 5: 10 * time.Second, 50: 10 * time.Second} {
+		if want == 0 {
+			continue
+		}
+		if d := b.delay(n); d != want {
+			t.Errorf("delay %d: got %v, want %v", n, d, want)
+		}
+	}
+
+	b.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if d := b.delay(2); d < time.Second || d > 3*time.Second {
+			t.Fatalf("the delay with jitter is %v", d)
+		}
+	}
+}
+
+func TestClient_RunWithReconnect(t *testing.T) {
+	s := servertest.New()
+	defer s.Close()
+
+	c := NewClient()
+	var events []ReconnectEvent
+	c.OnReconnect(func(e ReconnectEvent) error {
+		events = append(events, e)
+		return nil
+	})
+	dial := func(ctx context.Context) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", s.Addr())
+	}
+	errs := make(chan error, 1)
+	go func() { errs <- c.RunWithReconnect(context.Background(), dial, Backoff{Initial: time.Millisecond}) }()
+
+	// the first connection gets a boss bar, then kicked
+	conn := s.Accept(t)
+	conn.JoinGame(t)
+	conn.Send(t,
+		pk.Marshal(data.BossBar, pk.UUID(uuid.New()), pk.VarInt(BossBarAdd),
+			pk.Chat(`"Round 1"`), pk.Float(1), pk.VarInt(0), pk.VarInt(0), pk.UnsignedByte(0)),
+		pk.Marshal(data.DisconnectPlay, pk.Chat(`{"translate":"multiplayer.disconnect.server_shutdown"}`)),
+	)
+	conn.Close()
+
+	// the second connection starts without the state of the first
+	conn = s.Accept(t)
+	defer conn.Close()
+	conn.JoinGame(t)
+	bars := make(chan int)
+	c.Delegate <- func() error {
+		bars <- len(c.BossBars())
+		return nil
+	}
+	if n := <-bars; n != 0 {
+		t.Errorf("%d boss bars are kept after reconnecting", n)
+	}
+	conn.Send(t, pk.Marshal(data.DisconnectPlay, pk.Chat(`{"translate":"multiplayer.disconnect.banned"}`)))
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunWithReconnect doesn't stop after banned")
+	}
+	var de *mcnet.DisconnectError
+	if !errors.As(err, &de) || de.Reason.Translate != "multiplayer.disconnect.banned" {
+		t.Errorf("RunWithReconnect returns %v", err)
+	}
+	if len(events) != 1 || events[0].Attempt != 1 || !errors.As(events[0].Err, &de) {
+		t.Errorf("reconnect events: %+v", events)
+	}
+}
+
+func TestClient_RunWithReconnect_cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewClient()
+	c.OnReconnect(func(e ReconnectEvent) error {
+		if e.Attempt == 3 {
+			cancel()
+		}
+		return nil
+	})
+	dial := func(ctx context.Context) (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	}
+	err := c.RunWithReconnect(ctx, dial, Backoff{Initial: time.Millisecond})
+	if err != context.Canceled {
+		t.Errorf("RunWithReconnect returns %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package bot
+
+import (
+	"bytes"
+
+	"github.com/Tnze/go-mc/chat"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// Scoreboard is the objectives and scores sent by the server
+type Scoreboard struct {
+	Objectives map[string]*Objective // by the objective names
+	// Display is the objective names displayed at the slots,
+	// see DisplayList and others. Empty for displaying nothing.
+	Display [19]string
+}
+
+// The display slots of Scoreboard
+const (
+	DisplayList      = 0
+	DisplaySidebar   = 1
+	DisplayBelowName = 2
+	// DisplayTeamSidebar + color is the sidebar shown to the team of the color
+	DisplayTeamSidebar = 3
+)
+
+// Objective is an objective of the scoreboard
+type Objective struct {
+	Name        string
+	DisplayName chat.Message
+	Type        int32            // 0: integer, 1: hearts
+	Scores      map[string]int32 // by the entity names, which are player names or UUIDs
+}
+
+// Sidebar return the objective displayed at the sidebar, or nil for none
+func (s *Scoreboard) Sidebar() *Objective {
+	return s.Objectives[s.Display[DisplaySidebar]]
+}
+
+// Team is a team of the scoreboard
+type Team struct {
+	Name        string
+	DisplayName chat.Message
+	Flags       byte   // 0x01: allow friendly fire, 0x02: see invisible teammates
+	NameTag     string // the name tag visibility: always, hideForOtherTeams, hideForOwnTeam or never
+	Collision   string // the collision rule: always, pushOtherTeams, pushOwnTeam or never
+	Color       int32  // the text color of the names, in the order of chat colors, 21 for reset
+	Prefix      chat.Message
+	Suffix      chat.Message
+	Members     []string // the entity names, which are player names or UUIDs
+}
+
+// Scoreboard return the scoreboard of the server.
+// It should only be accessed in HandleGame or a Delegate.
+func (c *Client) Scoreboard() *Scoreboard {
+	return &c.scoreboard
+}
+
+// Teams return the teams of the server, by their names.
+// The returned map shouldn't be modified.
+// It should only be accessed in HandleGame or a Delegate.
+func (c *Client) Teams() map[string]*Team {
+	return c.teams
+}
+
+// TeamOf return the team the entity name is in, or nil if it's not in a team
+func (c *Client) TeamOf(name string) *Team {
+	for _, t := range c.teams {
+		for _, m := range t.Members {
+			if m == name {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+func (t *Team) removeMember(name string) {
+	for i, v := range t.Members {
+		if v == name {
+			t.Members = append(t.Members[:i], t.Members[i+1:]...)
+			return
+		}
+	}
+}
+
+func handleScoreboardObjectivePacket(c *Client, p pk.Packet) error {
+	var (
+		name pk.String
+		mode pk.Byte
+	)
+	r := bytes.NewReader(p.Data)
+	if err := decodeFields(r, &name, &mode); err != nil {
+		return err
+	}
+	s := &c.scoreboard
+	switch mode {
+	case 0, 2: // create, update
+		var (
+			value chat.Message
+			typ   pk.VarInt
+		)
+		if err := decodeFields(r, &value, &typ); err != nil {
+			return err
+		}
+		o, ok := s.Objectives[string(name)]
+		if !ok {
+			if s.Objectives == nil {
+				s.Objectives = make(map[string]*Objective)
+			}
+			o = &Objective{Name: string(name), Scores: make(map[string]int32)}
+			s.Objectives[o.Name] = o
+		}
+		o.DisplayName, o.Type = value, int32(typ)
+	case 1: // remove
+		delete(s.Objectives, string(name))
+		for i, v := range s.Display {
+			if v == string(name) {
+				s.Display[i] = ""
+			}
+		}
+	}
+	return nil
+}
+
+func handleUpdateScorePacket(c *Client, p pk.Packet) error {
+	var (
+		entity, objective pk.String
+		action            pk.Byte
+	)
+	r := bytes.NewReader(p.Data)
+	if err := decodeFields(r, &entity, &action, &objective); err != nil {
+		return err
+	}
+	s := &c.scoreboard
+	switch action {
+	case 0: // create or update
+		var value pk.VarInt
+		if err := value.Decode(r); err != nil {
+			return err
+		}
+		if o, ok := s.Objectives[string(objective)]; ok {
+			o.Scores[string(entity)] = int32(value)
+		}
+	case 1: // remove
+		if objective == "" { // from all objectives
+			for _, o := range s.Objectives {
+				delete(o.Scores, string(entity))
+			}
+		} else if o, ok := s.Objectives[string(objective)]; ok {
+			delete(o.Scores, string(entity))
+		}
+	}
+	return nil
+}
+
+func handleDisplayScoreboardPacket(c *Client, p pk.Packet) error {
+	var (
+		position pk.Byte
+		name     pk.String
+	)
+	if err := p.Scan(&position, &name); err != nil {
+		return err
+	}
+	if position >= 0 && int(position) < len(c.scoreboard.Display) {
+		c.scoreboard.Display[position] = string(name)
+	}
+	return nil
+}
+
+func handleTeamsPacket(c *Client, p pk.Packet) error {
+	var (
+		name pk.String
+		mode pk.Byte
+	)
+	r := bytes.NewReader(p.Data)
+	if err := decodeFields(r, &name, &mode); err != nil {
+		return err
+	}
+	if mode == 1 { // remove
+		delete(c.teams, string(name))
+		return nil
+	}
+
+	t, ok := c.teams[string(name)]
+	if !ok {
+		if mode != 0 {
+			return nil // not created, ignore it
+		}
+		t = &Team{Name: string(name)}
+	}
+	if mode == 0 || mode == 2 { // create, update info
+		var (
+			display, prefix, suffix chat.Message
+			flags                   pk.Byte
+			nameTag, collision      pk.String
+			color                   pk.VarInt
+		)
+		if err := decodeFields(r, &display, &flags, &nameTag, &collision, &color, &prefix, &suffix); err != nil {
+			return err
+		}
+		t.DisplayName, t.Prefix, t.Suffix = display, prefix, suffix
+		t.Flags = byte(flags)
+		t.NameTag, t.Collision = string(nameTag), string(collision)
+		t.Color = int32(color)
+	}
+
+	var members []pk.String
+	if mode == 0 || mode == 3 || mode == 4 { // create, add and remove entities
+		if err := (pk.Ary{Len: new(pk.VarInt), Ary: &members}).Decode(r); err != nil {
+			return err
+		}
+	}
+	switch mode {
+	case 0, 3:
+		for _, m := range members {
+			// an entity is in one team at most
+			if old := c.TeamOf(string(m)); old != nil {
+				old.removeMember(string(m))
+			}
+			t.Members = append(t.Members, string(m))
+		}
+	case 4:
+		for _, m := range members {
+			t.removeMember(string(m))
+		}
+	}
+	if !ok {
+		if c.teams == nil {
+			c.teams = make(map[string]*Team)
+		}
+		c.teams[t.Name] = t
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+func TestClient_Scoreboard(t *testing.T) {
+	c := NewClient()
+	handle := func(p pk.Packet) {
+		t.Helper()
+		if _, err := c.handlePacket(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handle(pk.Marshal(data.ScoreboardObjective, pk.String("kills"), pk.Byte(0), pk.Chat(`"Kills"`), pk.VarInt(0)))
+	handle(pk.Marshal(data.DisplayScoreboard, pk.Byte(DisplaySidebar), pk.String("kills")))
+	handle(pk.Marshal(data.UpdateScore, pk.String("Steve"), pk.Byte(0), pk.String("kills"), pk.VarInt(3)))
+	handle(pk.Marshal(data.UpdateScore, pk.String("Alex"), pk.Byte(0), pk.String("kills"), pk.VarInt(1)))
+	handle(pk.Marshal(data.UpdateScore, pk.String("Steve"), pk.Byte(0), pk.String("kills"), pk.VarInt(4)))
+	// the score of an unknown objective is ignored
+	handle(pk.Marshal(data.UpdateScore, pk.String("Steve"), pk.Byte(0), pk.String("deaths"), pk.VarInt(1)))
+
+	sidebar := c.Scoreboard().Sidebar()
+	if sidebar == nil || sidebar.DisplayName.ClearString() != "Kills" {
+		t.Fatalf("wrong sidebar: %+v", sidebar)
+	}
+	if want := map[string]int32{"Steve": 4, "Alex": 1}; !reflect.DeepEqual(sidebar.Scores, want) {
+		t.Errorf("scores: %v, want %v", sidebar.Scores, want)
+	}
+
+	handle(pk.Marshal(data.UpdateScore, pk.String("Alex"), pk.Byte(1), pk.String("kills")))
+	if _, ok := sidebar.Scores["Alex"]; ok {
+		t.Error("the removed score is kept")
+	}
+	handle(pk.Marshal(data.ScoreboardObjective, pk.String("kills"), pk.Byte(1)))
+	if c.Scoreboard().Sidebar() != nil || len(c.Scoreboard().Objectives) != 0 {
+		t.Error("the removed objective is kept")
+	}
+}
+
+func TestClient_Teams(t *testing.T) {
+	c := NewClient()
+	handle := func(p pk.Packet) {
+		t.Helper()
+		if _, err := c.handlePacket(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	team := func(name string, mode byte, fields ...pk.FieldEncoder) pk.Packet {
+		return pk.Marshal(data.Teams, append([]pk.FieldEncoder{pk.String(name), pk.Byte(mode)}, fields...)...)
+	}
+	info := []pk.FieldEncoder{
+		pk.Chat(`"Red Team"`), pk.Byte(0x01), pk.String("always"), pk.String("never"),
+		pk.VarInt(12), pk.Chat(`"[R] "`), pk.Chat(`""`),
+	}
+
+	handle(team("red", 0, append(info, pk.Ary{Len: pk.VarInt(2), Ary: []pk.String{"Steve", "Alex"}})...))
+	handle(team("blue", 0, append(info, pk.Ary{Len: pk.VarInt(0), Ary: []pk.String{}})...))
+	red := c.Teams()["red"]
+	if red == nil || red.Prefix.ClearString() != "[R] " || red.Color != 12 || red.Collision != "never" {
+		t.Fatalf("wrong team: %+v", red)
+	}
+	if !reflect.DeepEqual(red.Members, []string{"Steve", "Alex"}) {
+		t.Errorf("members: %q", red.Members)
+	}
+
+	// Alex is moved to the blue team
+	handle(team("blue", 3, pk.Ary{Len: pk.VarInt(1), Ary: []pk.String{"Alex"}}))
+	if c.TeamOf("Alex") != c.Teams()["blue"] || !reflect.DeepEqual(red.Members, []string{"Steve"}) {
+		t.Errorf("Alex isn't moved: red %q", red.Members)
+	}
+	handle(team("red", 4, pk.Ary{Len: pk.VarInt(1), Ary: []pk.String{"Steve"}}))
+	if c.TeamOf("Steve") != nil {
+		t.Error("Steve isn't removed")
+	}
+
+	handle(team("red", 2, pk.Chat(`"Reds"`), pk.Byte(0), pk.String("never"), pk.String("always"), pk.VarInt(4), pk.Chat(`""`), pk.Chat(`""`)))
+	if red.DisplayName.ClearString() != "Reds" || red.NameTag != "never" || red.Color != 4 {
+		t.Errorf("wrong updated team: %+v", red)
+	}
+	handle(team("red", 1))
+	if _, ok := c.Teams()["red"]; ok {
+		t.Error("the removed team is kept")
+	}
+}
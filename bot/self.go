@@ -0,0 +1,40 @@
+package bot
+
+import pk "github.com/Tnze/go-mc/net/packet"
+
+// Self is a view of the player's own state,
+// which is kept current by HandleGame.
+// Like Client.Player, it should be read in HandleGame or a Delegate.
+type Self struct{ c *Client }
+
+// Self return the player's own state
+func (c *Client) Self() Self { return Self{c} }
+
+// Position return the coordinate of the player's feet
+func (s Self) Position() (x, y, z float64) { return s.c.X, s.c.Y, s.c.Z }
+
+// Health return the player's health, 20 for full, 0 or less for dead
+func (s Self) Health() float32 { return s.c.Health }
+
+// Food return the player's food level, from 0 to 20
+func (s Self) Food() int32 { return s.c.Food }
+
+// Experience return the player's experience.
+// bar is the progress of the experience bar, from 0 to 1.
+func (s Self) Experience() (bar float32, level, total int32) {
+	return s.c.ExpBar, s.c.Level, s.c.TotalExp
+}
+
+func handleSetExperiencePacket(c *Client, p pk.Packet) error {
+	var (
+		bar          pk.Float
+		level, total pk.VarInt
+	)
+	if err := p.Scan(&bar, &level, &total); err != nil {
+		return err
+	}
+	c.ExpBar = float32(bar)
+	c.Level = int32(level)
+	c.TotalExp = int32(total)
+	return nil
+}
@@ -0,0 +1,49 @@
+package bot
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Tnze/go-mc/data"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+func TestClient_Self(t *testing.T) {
+	var buf bytes.Buffer
+	c := NewClient()
+	c.conn = &mcnet.Conn{Writer: &buf}
+	c.DisablePhysics = true
+
+	for _, p := range []pk.Packet{
+		pk.Marshal(data.UpdateHealth, pk.Float(15.5), pk.VarInt(18), pk.Float(2)),
+		pk.Marshal(data.SetExperience, pk.Float(0.25), pk.VarInt(3), pk.VarInt(30)),
+		pk.Marshal(data.PlayerPositionAndLookClientbound,
+			pk.Double(1.5), pk.Double(64), pk.Double(-2.5), pk.Float(90), pk.Float(0), pk.Byte(0), pk.VarInt(7)),
+	} {
+		if _, err := c.handlePacket(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	self := c.Self()
+	if x, y, z := self.Position(); x != 1.5 || y != 64 || z != -2.5 {
+		t.Errorf("wrong position: %v %v %v", x, y, z)
+	}
+	if self.Health() != 15.5 || self.Food() != 18 {
+		t.Errorf("wrong health and food: %v %v", self.Health(), self.Food())
+	}
+	if bar, level, total := self.Experience(); bar != 0.25 || level != 3 || total != 30 {
+		t.Errorf("wrong experience: %v %v %v", bar, level, total)
+	}
+
+	// the teleport must be confirmed
+	confirm, err := pk.RecvPacket(&buf, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var id pk.VarInt
+	if confirm.ID != data.TeleportConfirm || confirm.Scan(&id) != nil || id != 7 {
+		t.Errorf("want TeleportConfirm with ID 7, get %v", confirm)
+	}
+}
@@ -0,0 +1,127 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"unicode/utf16"
+
+	"github.com/Tnze/go-mc/chat"
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// Suggestion is a completion returned by TabComplete
+type Suggestion struct {
+	Match   string
+	Tooltip *chat.Message // nil if not present
+	// Start and Length are the range of the text to be replaced by Match,
+	// counted in UTF-16 code units as the server does. See Apply.
+	Start, Length int
+}
+
+// Apply replace the range of the text by the Match of the suggestion.
+func (s Suggestion) Apply(text string) string {
+	t := utf16.Encode([]rune(text))
+	start, end := s.Start, s.Start+s.Length
+	if start < 0 || start > len(t) {
+		start = len(t)
+	}
+	if end < start || end > len(t) {
+		end = len(t)
+	}
+	return string(utf16.Decode(t[:start])) + s.Match + string(utf16.Decode(t[end:]))
+}
+
+type tabCompletes struct {
+	lastID  int32 // atomic
+	mu      sync.Mutex
+	pending map[int32]chan<- []Suggestion
+}
+
+// TabComplete ask the server for completions of the text, like "/gamemode c".
+// Texts of commands should start with '/'.
+//
+// It waits for the response, so it must not be called in the goroutine
+// running HandleGame, such as in handlers or Delegate. If the server never
+// replies, TabComplete returns when ctx is done.
+func (c *Client) TabComplete(ctx context.Context, text string) ([]Suggestion, error) {
+	id := atomic.AddInt32(&c.tabCompletes.lastID, 1)
+	ch := make(chan []Suggestion, 1)
+	tc := &c.tabCompletes
+	tc.mu.Lock()
+	if tc.pending == nil {
+		tc.pending = make(map[int32]chan<- []Suggestion)
+	}
+	tc.pending[id] = ch
+	tc.mu.Unlock()
+	defer func() {
+		tc.mu.Lock()
+		delete(tc.pending, id)
+		tc.mu.Unlock()
+	}()
+
+	err := c.SendPacket(pk.Marshal(
+		data.TabCompleteServerbound,
+		pk.VarInt(id),
+		pk.String(text),
+	))
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case s := <-ch:
+		return s, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func handleTabCompletePacket(c *Client, p pk.Packet) error {
+	var (
+		id, start, length pk.VarInt
+		matches           []suggestion
+	)
+	if err := p.Scan(&id, &start, &length, pk.Ary{Len: pk.VarInt(0), Ary: &matches}); err != nil {
+		return err
+	}
+
+	tc := &c.tabCompletes
+	tc.mu.Lock()
+	ch, ok := tc.pending[int32(id)]
+	delete(tc.pending, int32(id))
+	tc.mu.Unlock()
+	if !ok { // not requested by TabComplete, or canceled
+		return nil
+	}
+
+	suggestions := make([]Suggestion, len(matches))
+	for i, m := range matches {
+		suggestions[i] = Suggestion{
+			Match:   string(m.Match),
+			Tooltip: m.Tooltip,
+			Start:   int(start),
+			Length:  int(length),
+		}
+	}
+	ch <- suggestions // buffered
+	return nil
+}
+
+type suggestion struct {
+	Match   pk.String
+	Tooltip *chat.Message
+}
+
+// Decode implement net.packet.FieldDecoder
+func (s *suggestion) Decode(r pk.DecodeReader) error {
+	var hasTooltip pk.Boolean
+	if err := decodeFields(r, &s.Match, &hasTooltip); err != nil {
+		return err
+	}
+	if hasTooltip {
+		s.Tooltip = new(chat.Message)
+		return s.Tooltip.Decode(r)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package bot
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Tnze/go-mc/chat"
+	"github.com/Tnze/go-mc/data"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+func TestClient_TabComplete(t *testing.T) {
+	c1, c2 := net.Pipe()
+	client, server := mcnet.WrapConn(c1), mcnet.WrapConn(c2)
+	defer client.Close()
+	defer server.Close()
+	c := NewClient()
+	c.conn = client
+
+	// the fake server
+	go func() {
+		p, err := server.ReadPacket()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		var (
+			id   pk.VarInt
+			text pk.String
+		)
+		if err := p.Scan(&id, &text); err != nil || text != "/gamemode c" {
+			t.Errorf("wrong request: %q %v", text, err)
+		}
+		// a response of another request should be ignored
+		resp := pk.Marshal(data.TabComplete, id+1, pk.VarInt(0), pk.VarInt(0), pk.VarInt(0))
+		if _, err := c.handlePacket(resp); err != nil {
+			t.Error(err)
+		}
+		resp = pk.Marshal(data.TabComplete, id, pk.VarInt(10), pk.VarInt(1), pk.VarInt(2),
+			pk.String("creative"), pk.Boolean(true), chat.Text("Creative mode"),
+			pk.String("custom"), pk.Boolean(false),
+		)
+		if _, err := c.handlePacket(resp); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	s, err := c.TabComplete(context.Background(), "/gamemode c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s) != 2 || s[0].Match != "creative" || s[0].Tooltip == nil || s[0].Tooltip.ClearString() != "Creative mode" ||
+		s[1].Match != "custom" || s[1].Tooltip != nil {
+		t.Fatalf("wrong suggestions: %+v", s)
+	}
+	if got := s[0].Apply("/gamemode c"); got != "/gamemode creative" {
+		t.Errorf("apply suggestion: get %q", got)
+	}
+}
+
+func TestClient_TabComplete_timeout(t *testing.T) {
+	c := NewClient()
+	c.conn = &mcnet.Conn{Writer: ioutil.Discard}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.TabComplete(ctx, "/"); err != context.DeadlineExceeded {
+		t.Errorf("want context.DeadlineExceeded, get %v", err)
+	}
+}
+
+func TestSuggestion_Apply(t *testing.T) {
+	// the range is in UTF-16 code units
+	s := Suggestion{Match: "bye", Start: 7, Length: 2}
+	if got := s.Apply("/say 😀hi"); got != "/say 😀bye" {
+		t.Errorf("get %q", got)
+	}
+}
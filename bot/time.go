@@ -0,0 +1,71 @@
+package bot
+
+import pk "github.com/Tnze/go-mc/net/packet"
+
+// TicksPerDay is the length of a Minecraft day
+const TicksPerDay = 24000
+
+// The time of day in which players can sleep in clear weather
+const (
+	nightStart = 12542
+	nightEnd   = 23460
+)
+
+// TimeUpdateEvent is fired when the server sends the world time, about every second
+type TimeUpdateEvent struct {
+	WorldAge int64 // ticks since the world was created, not affected by /time set
+	// TimeOfDay is the ticks since the first day began.
+	// It's negative when the gamerule doDaylightCycle is false,
+	// then the absolute value is the frozen time.
+	TimeOfDay int64
+}
+
+// worldTime is received from the TimeUpdate packet, see WorldTime
+type worldTime struct {
+	age       int64
+	timeOfDay int64
+}
+
+// OnTimeUpdate subscribe TimeUpdateEvent
+func (c *Client) OnTimeUpdate(f func(TimeUpdateEvent) error) (unsubscribe func()) {
+	return c.bus.timeUpdate.add(f)
+}
+
+// WorldTime return the age of the world in ticks
+func (c *Client) WorldTime() int64 { return c.time.age }
+
+// DayTime return the time of the current day, from 0 to TicksPerDay-1.
+// 0 is sunrise, 6000 is noon and 18000 is midnight.
+func (c *Client) DayTime() int64 {
+	t := c.time.timeOfDay
+	if t < 0 {
+		t = -t
+	}
+	return t % TicksPerDay
+}
+
+// DaylightCycle report whether the time of day advances,
+// which is false when the gamerule doDaylightCycle is false
+func (c *Client) DaylightCycle() bool { return c.time.timeOfDay >= 0 }
+
+// IsNight report whether it's the night that players can sleep in beds
+func (c *Client) IsNight() bool {
+	t := c.DayTime()
+	return t >= nightStart && t < nightEnd
+}
+
+// IsDay is the opposite of IsNight
+func (c *Client) IsDay() bool { return !c.IsNight() }
+
+func handleTimeUpdatePacket(c *Client, p pk.Packet) error {
+	var age, timeOfDay pk.Long
+	if err := p.Scan(&age, &timeOfDay); err != nil {
+		return err
+	}
+	c.time = worldTime{age: int64(age), timeOfDay: int64(timeOfDay)}
+
+	e := TimeUpdateEvent{WorldAge: int64(age), TimeOfDay: int64(timeOfDay)}
+	return c.bus.timeUpdate.fire(func(f interface{}) error {
+		return f.(func(TimeUpdateEvent) error)(e)
+	})
+}
@@ -0,0 +1,44 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+func TestClient_OnTimeUpdate(t *testing.T) {
+	c := NewClient()
+	var events []TimeUpdateEvent
+	c.OnTimeUpdate(func(e TimeUpdateEvent) error {
+		events = append(events, e)
+		return nil
+	})
+
+	for _, tc := range []struct {
+		age, timeOfDay int64
+		dayTime        int64
+		night, cycle   bool
+	}{
+		{100, 6000, 6000, false, true},
+		{200, 24000*3 + 18000, 18000, true, true},
+		{300, -13000, 13000, true, false}, // frozen at night
+		{400, -(24000 + 1000), 1000, false, false},
+	} {
+		if _, err := c.handlePacket(pk.Marshal(data.TimeUpdate, pk.Long(tc.age), pk.Long(tc.timeOfDay))); err != nil {
+			t.Fatal(err)
+		}
+		if c.WorldTime() != tc.age || c.DayTime() != tc.dayTime {
+			t.Errorf("time %d: WorldTime() = %d, DayTime() = %d", tc.timeOfDay, c.WorldTime(), c.DayTime())
+		}
+		if c.IsNight() != tc.night || c.IsDay() == tc.night {
+			t.Errorf("time %d: IsNight() = %v", tc.timeOfDay, c.IsNight())
+		}
+		if c.DaylightCycle() != tc.cycle {
+			t.Errorf("time %d: DaylightCycle() = %v", tc.timeOfDay, c.DaylightCycle())
+		}
+	}
+	if len(events) != 4 || events[2] != (TimeUpdateEvent{WorldAge: 300, TimeOfDay: -13000}) {
+		t.Errorf("events: %v", events)
+	}
+}
@@ -146,6 +146,15 @@ type paletteSection struct {
 	directSection
 }
 
+// newPaletteSection return a section full of air
+func newPaletteSection(bpb int) *paletteSection {
+	return &paletteSection{
+		palette:       []BlockStatus{0},
+		palettesIndex: map[BlockStatus]int{0: 0},
+		directSection: directSection{bpb: bpb, data: make([]uint64, 16*16*16*bpb/64)},
+	}
+}
+
 func (p *paletteSection) GetBlock(offset int) BlockStatus {
 	v := p.directSection.GetBlock(offset)
 	return p.palette[v]
@@ -0,0 +1,34 @@
+package world
+
+import (
+	"github.com/Tnze/go-mc/bot/world/entity"
+	"github.com/google/uuid"
+)
+
+// EntityKind tells which packet spawned the entity
+type EntityKind byte
+
+// The kinds of entities
+const (
+	ObjectEntity        EntityKind = iota // vehicles, projectiles, items and others without AI
+	LivingEntity                          // mobs and armor stands
+	PlayerEntity                          // other players
+	ExperienceOrbEntity                   // experience orbs
+)
+
+// Entity is an entity around the player, tracked from the packets of the server
+type Entity struct {
+	entity.Entity
+	Kind EntityKind
+	UUID uuid.UUID // zero for the experience orbs
+	// Type is the entity type ID of the objects and the living entities
+	Type int32
+	// Data is the data of objects, like the block state of falling blocks,
+	// or the amount of experience of the experience orbs
+	Data int32
+
+	X, Y, Z          float64
+	Yaw, Pitch       float32 // in degrees
+	VelX, VelY, VelZ float64 // in blocks per tick
+	OnGround         bool
+}
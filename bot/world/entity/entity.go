@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"bytes"
+
 	"github.com/Tnze/go-mc/data"
 	"github.com/Tnze/go-mc/nbt"
 	pk "github.com/Tnze/go-mc/net/packet"
@@ -38,6 +40,24 @@ func (s *Slot) Decode(r pk.DecodeReader) error {
 	return nil
 }
 
+//Encode implement packet.FieldEncoder interface
+func (s Slot) Encode() []byte {
+	if !s.Present {
+		return pk.Boolean(false).Encode()
+	}
+	data := pk.Boolean(true).Encode()
+	data = append(data, pk.VarInt(s.ItemID).Encode()...)
+	data = append(data, pk.Byte(s.Count).Encode()...)
+	if s.NBT == nil {
+		return append(data, nbt.TagEnd)
+	}
+	var buf bytes.Buffer
+	if err := nbt.Marshal(&buf, s.NBT); err != nil {
+		return append(data, nbt.TagEnd) // the NBT can't be marshaled, send none
+	}
+	return append(data, buf.Bytes()...)
+}
+
 func (s Slot) String() string {
 	return data.ItemNameByID[s.ItemID]
 }
@@ -0,0 +1,214 @@
+package entity
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Tnze/go-mc/chat"
+	"github.com/Tnze/go-mc/nbt"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
+)
+
+// baseMetadataKeys are the fields of all entities, indexed by the metadata index
+var baseMetadataKeys = []string{
+	"shared_flags", "air_supply", "custom_name", "custom_name_visible",
+	"silent", "no_gravity", "pose",
+}
+
+// The types of entity metadata since 1.14
+const (
+	MetaByte = iota
+	MetaVarInt
+	MetaFloat
+	MetaString
+	MetaChat
+	MetaOptChat
+	MetaSlot
+	MetaBoolean
+	MetaRotation
+	MetaPosition
+	MetaOptPosition
+	MetaDirection
+	MetaOptUUID
+	MetaOptBlockID
+	MetaNBT
+	MetaParticle
+	MetaVillagerData
+	MetaOptVarInt
+	MetaPose
+)
+
+// The particle IDs whose data is not empty
+const (
+	particleBlock       = 3
+	particleDust        = 14
+	particleFallingDust = 23
+	particleItem        = 32
+)
+
+// Particle is the value of MetaParticle
+type Particle struct {
+	ID   int32
+	Data interface{} // block state int32, [4]float32 of dust RGB and scale, or Slot
+}
+
+// ParseMetadata decode the entity metadata of the EntityMetadata packet.
+//
+// The fields of all entities are keyed by their names, like "custom_name" and "pose".
+// The names of the others depend on the entityType and are not known yet,
+// so the index itself is used as the key, like "8" for the health of living entities.
+// The values are decoded by their types: int8, int32, float32, string,
+// chat.Message, *chat.Message, Slot, bool, [3]float32, pk.Position, *pk.Position,
+// *uuid.UUID, Particle, [3]int32 or the NBT. Empty optional values are nil.
+func ParseMetadata(entityType int, data []byte) (map[string]interface{}, error) {
+	keys := baseMetadataKeys
+	r := bytes.NewReader(data)
+	m := make(map[string]interface{})
+	for {
+		var index pk.UnsignedByte
+		if err := index.Decode(r); err != nil {
+			return m, fmt.Errorf("read index fail: %w", err)
+		}
+		if index == 0xff {
+			return m, nil
+		}
+		var typ pk.VarInt
+		if err := typ.Decode(r); err != nil {
+			return m, fmt.Errorf("read type of index %d fail: %w", index, err)
+		}
+		v, err := readMetadataValue(r, int(typ))
+		if err != nil {
+			return m, fmt.Errorf("read value of index %d fail: %w", index, err)
+		}
+
+		key := strconv.Itoa(int(index))
+		if int(index) < len(keys) {
+			key = keys[index]
+		}
+		m[key] = v
+	}
+}
+
+func readMetadataValue(r pk.DecodeReader, typ int) (interface{}, error) {
+	var err error
+	switch typ {
+	case MetaByte:
+		var v pk.Byte
+		err = v.Decode(r)
+		return int8(v), err
+	case MetaVarInt, MetaDirection, MetaOptBlockID, MetaPose:
+		var v pk.VarInt
+		err = v.Decode(r)
+		return int32(v), err
+	case MetaFloat:
+		var v pk.Float
+		err = v.Decode(r)
+		return float32(v), err
+	case MetaString:
+		var v pk.String
+		err = v.Decode(r)
+		return string(v), err
+	case MetaChat:
+		var v chat.Message
+		err = v.Decode(r)
+		return v, err
+	case MetaOptChat:
+		var present pk.Boolean
+		if err := present.Decode(r); err != nil || !present {
+			return (*chat.Message)(nil), err
+		}
+		v := new(chat.Message)
+		err = v.Decode(r)
+		return v, err
+	case MetaSlot:
+		var v Slot
+		err = v.Decode(r)
+		return v, err
+	case MetaBoolean:
+		var v pk.Boolean
+		err = v.Decode(r)
+		return bool(v), err
+	case MetaRotation:
+		var x, y, z pk.Float
+		err = decodeAll(r, &x, &y, &z)
+		return [3]float32{float32(x), float32(y), float32(z)}, err
+	case MetaPosition:
+		var v pk.Position
+		err = v.Decode(r)
+		return v, err
+	case MetaOptPosition:
+		var present pk.Boolean
+		if err := present.Decode(r); err != nil || !present {
+			return (*pk.Position)(nil), err
+		}
+		v := new(pk.Position)
+		err = v.Decode(r)
+		return v, err
+	case MetaOptUUID:
+		var present pk.Boolean
+		if err := present.Decode(r); err != nil || !present {
+			return (*uuid.UUID)(nil), err
+		}
+		var v pk.UUID
+		err = v.Decode(r)
+		id := uuid.UUID(v)
+		return &id, err
+	case MetaNBT:
+		var v interface{}
+		err = nbt.NewDecoder(r).Decode(&v)
+		if errors.Is(err, nbt.ErrEND) {
+			err = nil
+		}
+		return v, err
+	case MetaParticle:
+		return readParticle(r)
+	case MetaVillagerData:
+		var typ, profession, level pk.VarInt
+		err = decodeAll(r, &typ, &profession, &level)
+		return [3]int32{int32(typ), int32(profession), int32(level)}, err
+	case MetaOptVarInt:
+		var v pk.VarInt
+		if err := v.Decode(r); err != nil || v == 0 {
+			return (*int32)(nil), err
+		}
+		i := int32(v) - 1
+		return &i, nil
+	default:
+		return nil, fmt.Errorf("unknown metadata type %d", typ)
+	}
+}
+
+func readParticle(r pk.DecodeReader) (p Particle, err error) {
+	var id pk.VarInt
+	if err = id.Decode(r); err != nil {
+		return
+	}
+	p.ID = int32(id)
+	switch p.ID {
+	case particleBlock, particleFallingDust:
+		var state pk.VarInt
+		err = state.Decode(r)
+		p.Data = int32(state)
+	case particleDust:
+		var red, green, blue, scale pk.Float
+		err = decodeAll(r, &red, &green, &blue, &scale)
+		p.Data = [4]float32{float32(red), float32(green), float32(blue), float32(scale)}
+	case particleItem:
+		var s Slot
+		err = s.Decode(r)
+		p.Data = s
+	}
+	return
+}
+
+func decodeAll(r pk.DecodeReader, fields ...pk.FieldDecoder) error {
+	for _, f := range fields {
+		if err := f.Decode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Tnze/go-mc/chat"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
+)
+
+func encodeMetadata(fields ...pk.FieldEncoder) []byte {
+	var buf bytes.Buffer
+	for _, f := range fields {
+		buf.Write(f.Encode())
+	}
+	buf.WriteByte(0xff)
+	return buf.Bytes()
+}
+
+func TestParseMetadata(t *testing.T) {
+	id := uuid.New()
+	data := encodeMetadata(
+		pk.UnsignedByte(0), pk.VarInt(MetaByte), pk.Byte(0x20),
+		pk.UnsignedByte(2), pk.VarInt(MetaOptChat), pk.Boolean(true), chat.Text("Dinnerbone"),
+		pk.UnsignedByte(6), pk.VarInt(MetaPose), pk.VarInt(5),
+		pk.UnsignedByte(8), pk.VarInt(MetaFloat), pk.Float(20),
+		pk.UnsignedByte(9), pk.VarInt(MetaOptUUID), pk.Boolean(true), pk.UUID(id),
+		pk.UnsignedByte(10), pk.VarInt(MetaOptVarInt), pk.VarInt(0),
+		pk.UnsignedByte(11), pk.VarInt(MetaParticle), pk.VarInt(particleDust),
+		pk.Float(1), pk.Float(0), pk.Float(0), pk.Float(1),
+	)
+	m, err := ParseMetadata(0, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := m["shared_flags"]; v != int8(0x20) {
+		t.Errorf("shared_flags = %v", v)
+	}
+	if v, ok := m["custom_name"].(*chat.Message); !ok || v.ClearString() != "Dinnerbone" {
+		t.Errorf("custom_name = %v", m["custom_name"])
+	}
+	if v := m["pose"]; v != int32(5) {
+		t.Errorf("pose = %v", v)
+	}
+	// the fields after the base ones are named by their index
+	if v := m["8"]; v != float32(20) {
+		t.Errorf("8 = %v", v)
+	}
+	if v, ok := m["9"].(*uuid.UUID); !ok || *v != id {
+		t.Errorf("9 = %v", m["9"])
+	}
+	if v, ok := m["10"].(*int32); !ok || v != nil {
+		t.Errorf("10 should be an empty OptVarInt, get %v", m["10"])
+	}
+	if v, ok := m["11"].(Particle); !ok || v.ID != particleDust || v.Data != [4]float32{1, 0, 0, 1} {
+		t.Errorf("11 = %v", m["11"])
+	}
+}
+
+func TestParseMetadata_endMark(t *testing.T) {
+	data := encodeMetadata(pk.UnsignedByte(8), pk.VarInt(MetaFloat), pk.Float(7.5))
+	if _, err := ParseMetadata(0, data[:len(data)-1]); err == nil {
+		t.Error("metadata without the end mark should be error")
+	}
+}
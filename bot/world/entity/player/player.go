@@ -16,4 +16,8 @@ type Player struct {
 	Health         float32 //血量
 	Food           int32   //饱食度
 	FoodSaturation float32 //食物饱和度
+
+	ExpBar   float32 //经验条, from 0 to 1
+	Level    int32   //等级
+	TotalExp int32   //总经验
 }
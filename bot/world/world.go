@@ -1,18 +1,38 @@
 package world
 
 import (
-	"github.com/Tnze/go-mc/bot/world/entity"
+	"github.com/Tnze/go-mc/data"
 )
 
 // World record all of the things in the world where player at
 type World struct {
-	Entities map[int32]entity.Entity
+	// Entities are the entities around the player indexed by their entity ID,
+	// not including the player itself.
+	Entities map[int32]*Entity
 	Chunks   map[ChunkLoc]*Chunk
 }
 
 // Chunk store a 256*16*16 column blocks
 type Chunk struct {
 	Sections [16]Section
+	// Heightmaps are the packed heights such as "MOTION_BLOCKING" and "WORLD_SURFACE",
+	// each height is stored in 9 bits.
+	Heightmaps map[string][]int64
+	// Biomes are the biome IDs of 4*4*4 cells, indexed by (y/4*4+z/4)*4+x/4.
+	Biomes []int32
+	// BlockEntities are the block entities like chests and signs, indexed by their position.
+	BlockEntities map[BlockPos]BlockEntity
+}
+
+// BlockPos is the position of a block
+type BlockPos struct {
+	X, Y, Z int
+}
+
+// BlockEntity is the extra data of a block
+type BlockEntity struct {
+	ID   string // like "minecraft:chest"
+	Data map[string]interface{}
 }
 
 // Section store a 16*16*16 cube blocks
@@ -41,18 +61,37 @@ type ChunkLoc struct {
 // 	EntityID() int32
 // }
 
-// //Face is a face of a block
-// type Face byte
+//Face is a face of a block
+type Face byte
+
+// All six faces in a block
+const (
+	Bottom Face = iota
+	Top
+	North
+	South
+	West
+	East
+)
 
-// // All six faces in a block
-// const (
-// 	Bottom Face = iota
-// 	Top
-// 	North
-// 	South
-// 	West
-// 	East
-// )
+// Offset return the direction the face towards
+func (f Face) Offset() (dx, dy, dz int) {
+	switch f {
+	case Bottom:
+		dy = -1
+	case Top:
+		dy = 1
+	case North:
+		dz = -1
+	case South:
+		dz = 1
+	case West:
+		dx = -1
+	case East:
+		dx = 1
+	}
+	return
+}
 
 // getBlock return the block in the position (x, y, z)
 func (w *World) GetBlockStatus(x, y, z int) BlockStatus {
@@ -67,6 +106,36 @@ func (w *World) GetBlockStatus(x, y, z int) BlockStatus {
 	return 0
 }
 
+// Block return the block in the position (x, y, z).
+// ok is false if the chunk is not loaded or the block state is unknown.
+func (w *World) Block(x, y, z int) (b data.Block, ok bool) {
+	if _, loaded := w.Chunks[ChunkLoc{x >> 4, z >> 4}]; !loaded || y < 0 || y >= 256 {
+		return b, false
+	}
+	return data.BlockByStateID(int(w.GetBlockStatus(x, y, z)))
+}
+
+// BlockEntity return the block entity in the position (x, y, z).
+func (w *World) BlockEntity(x, y, z int) (e BlockEntity, ok bool) {
+	if c := w.Chunks[ChunkLoc{x >> 4, z >> 4}]; c != nil {
+		e, ok = c.BlockEntities[BlockPos{x, y, z}]
+	}
+	return
+}
+
+// SetBlockStatus set the block in the position (x, y, z).
+// It does nothing if the chunk is not loaded.
+func (w *World) SetBlockStatus(x, y, z int, s BlockStatus) {
+	c := w.Chunks[ChunkLoc{x >> 4, z >> 4}]
+	if c == nil || y < 0 || y>>4 >= len(c.Sections) {
+		return
+	}
+	if c.Sections[y>>4] == nil { // the section that is full of air is not sent
+		c.Sections[y>>4] = newPaletteSection(4)
+	}
+	c.Sections[y>>4].SetBlock(SectionOffset(x&15, y&15, z&15), s)
+}
+
 // func (b Block) String() string {
 // 	return blockNameByID[b.id]
 // }
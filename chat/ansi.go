@@ -0,0 +1,57 @@
+package chat
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ansiCode return the escape sequence which reset the terminal and then set the style.
+func ansiCode(s style) string {
+	var sb strings.Builder
+	sb.WriteString("\033[0")
+	if s.bold {
+		sb.WriteString(";1")
+	}
+	if s.italic {
+		sb.WriteString(";3")
+	}
+	if s.underlined {
+		sb.WriteString(";4")
+	}
+	if s.strikethrough {
+		sb.WriteString(";9")
+	}
+	if c := ansiColor(s.color); c != "" {
+		sb.WriteString(";" + c)
+	}
+	sb.WriteByte('m')
+	return sb.String()
+}
+
+// ansiColor convert the color name or the hex color "#rrggbb" to the ANSI SGR parameter.
+// The hex colors are converted to 24-bit colors, which isn't supported by some terminals.
+func ansiColor(color string) string {
+	if c, ok := colors[color]; ok {
+		return c
+	}
+	if len(color) == 7 && color[0] == '#' {
+		rgb, err := strconv.ParseUint(color[1:], 16, 24)
+		if err == nil {
+			return fmt.Sprintf("38;2;%d;%d;%d", rgb>>16, rgb>>8&0xFF, rgb&0xFF)
+		}
+	}
+	return ""
+}
+
+// ToANSI return the message string with ANSI escape sequences for colors and formats.
+// Children components inherit the format of their parents,
+// and the terminal is reset at the end of the string.
+func (m Message) ToANSI() string {
+	r := renderer{
+		code: ansiCode,
+		text: func(s string) (string, bool) { return TransCtrlSeq(s, true) },
+	}
+	r.render(m, style{})
+	return r.finish("\033[0m")
+}
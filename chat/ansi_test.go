@@ -0,0 +1,45 @@
+package chat_test
+
+import (
+	"testing"
+
+	"github.com/Tnze/go-mc/chat"
+)
+
+func TestMessage_ToANSI(t *testing.T) {
+	for _, tt := range []struct {
+		json string
+		ansi string
+	}{
+		{`"Tnze"`, "Tnze"},
+		{`{"text":"Tnze","color":"red"}`, "\033[0;91mTnze\033[0m"},
+		{`{"text":"Tnze","color":"#FF8000"}`, "\033[0;38;2;255;128;0mTnze\033[0m"},
+		{
+			`{"text":"a","bold":true,"color":"gold","extra":[` +
+				`{"text":"b","italic":true,"extra":[{"text":"c","color":"aqua","underlined":true}]},` +
+				`{"text":"d","strikethrough":true}]}`,
+			"\033[0;1;33ma" +
+				"\033[0;1;3;33mb" +
+				"\033[0;1;3;4;96mc" +
+				"\033[0;1;9;33md\033[0m",
+		},
+		{
+			`{"text":"","extra":[{"text":"a","color":"green"},{"text":"b"}]}`,
+			"\033[0;92ma\033[0mb",
+		},
+		{
+			`{"translate":"chat.type.text","color":"gray","with":[{"text":"Tnze","color":"yellow"},"hi"]}`,
+			"\033[0;37m<\033[0;93mTnze\033[0;37m> hi\033[0m",
+		},
+		{`"§4Tnze"`, "\033[31mTnze\033[0m"},
+		{`{"text":"§4a","extra":[{"text":"b"}]}`, "\033[31ma\033[0mb"},
+	} {
+		var m chat.Message
+		if err := m.UnmarshalJSON([]byte(tt.json)); err != nil {
+			t.Fatal(err)
+		}
+		if ansi := m.ToANSI(); ansi != tt.ansi {
+			t.Errorf("%s: gets %q, wants %q", tt.json, ansi, tt.ansi)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package chat
+
+import "strconv"
+
+// Builder helps to build a Message by chained calls, for example:
+//
+//	chat.TextBuilder("Hello").Color("red").Bold().
+//		Append(chat.TextBuilder(" world").Color("gold").Build()).
+//		ClickRunCommand("/say hi").
+//		Build()
+type Builder struct {
+	msg Message
+}
+
+// TextBuilder return a Builder of a text component.
+func TextBuilder(text string) *Builder {
+	return &Builder{msg: Text(text)}
+}
+
+// TranslateBuilder return a Builder of a translate component.
+func TranslateBuilder(key string, with ...Message) *Builder {
+	return &Builder{msg: TranslateMsg(key, with...)}
+}
+
+// Build return the Message built.
+func (b *Builder) Build() Message {
+	return b.msg
+}
+
+// Color set the color name like "red", or a hex color like "#FF0000" since 1.16.
+func (b *Builder) Color(color string) *Builder {
+	b.msg.Color = color
+	return b
+}
+
+func (b *Builder) Bold() *Builder {
+	b.msg.Bold = true
+	return b
+}
+
+func (b *Builder) Italic() *Builder {
+	b.msg.Italic = true
+	return b
+}
+
+func (b *Builder) Underlined() *Builder {
+	b.msg.UnderLined = true
+	return b
+}
+
+func (b *Builder) StrikeThrough() *Builder {
+	b.msg.StrikeThrough = true
+	return b
+}
+
+func (b *Builder) Obfuscated() *Builder {
+	b.msg.Obfuscated = true
+	return b
+}
+
+// Append add the extra components, which inherit the format of this one.
+func (b *Builder) Append(extra ...Message) *Builder {
+	b.msg.Append(extra...)
+	return b
+}
+
+// Insertion set the text inserted into the chat input when the component is shift-clicked.
+func (b *Builder) Insertion(text string) *Builder {
+	b.msg.Insertion = text
+	return b
+}
+
+func (b *Builder) click(action, value string) *Builder {
+	b.msg.ClickEvent = &ClickEvent{Action: action, Value: value}
+	return b
+}
+
+func (b *Builder) ClickOpenURL(url string) *Builder { return b.click(OpenURL, url) }
+
+func (b *Builder) ClickRunCommand(cmd string) *Builder { return b.click(RunCommand, cmd) }
+
+func (b *Builder) ClickSuggestCommand(cmd string) *Builder { return b.click(SuggestCommand, cmd) }
+
+func (b *Builder) ClickChangePage(page int) *Builder {
+	return b.click(ChangePage, strconv.Itoa(page))
+}
+
+func (b *Builder) ClickCopyToClipboard(text string) *Builder { return b.click(CopyToClipboard, text) }
+
+// HoverText set the text shown when the component is hovered.
+func (b *Builder) HoverText(text Message) *Builder {
+	b.msg.HoverEvent = &HoverEvent{Action: ShowText, Text: &text}
+	return b
+}
+
+// HoverItem set the item shown when the component is hovered.
+func (b *Builder) HoverItem(item HoverItem) *Builder {
+	b.msg.HoverEvent = &HoverEvent{Action: ShowItem, Item: &item}
+	return b
+}
+
+// HoverEntity set the entity shown when the component is hovered.
+func (b *Builder) HoverEntity(entity HoverEntity) *Builder {
+	b.msg.HoverEvent = &HoverEvent{Action: ShowEntity, Entity: &entity}
+	return b
+}
@@ -0,0 +1,60 @@
+package chat_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Tnze/go-mc/chat"
+)
+
+func TestBuilder(t *testing.T) {
+	for _, tt := range []struct {
+		msg  chat.Message
+		json string
+	}{
+		{
+			chat.TextBuilder("Hello").Color("red").Bold().
+				Append(chat.TextBuilder(" world").Color("gold").Italic().Build()).
+				ClickRunCommand("/say hi").
+				Build(),
+			`{"text":"Hello","bold":true,"color":"red","clickEvent":{"action":"run_command","value":"/say hi"},` +
+				`"extra":[{"text":" world","italic":true,"color":"gold"}]}`,
+		},
+		{
+			chat.TextBuilder("Tnze").Underlined().StrikeThrough().Obfuscated().
+				Insertion("Tnze").
+				ClickSuggestCommand("/tell Tnze ").
+				HoverText(chat.TextBuilder("click to tell").Color("#FF8000").Build()).
+				Build(),
+			`{"text":"Tnze","underlined":true,"strikethrough":true,"obfuscated":true,"insertion":"Tnze",` +
+				`"clickEvent":{"action":"suggest_command","value":"/tell Tnze "},` +
+				`"hoverEvent":{"action":"show_text","contents":{"text":"click to tell","color":"#FF8000"}}}`,
+		},
+		{
+			chat.TranslateBuilder("chat.type.text", chat.Text("Tnze"), chat.Text("hi")).
+				ClickOpenURL("https://github.com/Tnze/go-mc").
+				Build(),
+			`{"clickEvent":{"action":"open_url","value":"https://github.com/Tnze/go-mc"},"translate":"chat.type.text",` +
+				`"with":["Tnze","hi"]}`,
+		},
+		{chat.TextBuilder("p").ClickChangePage(2).Build(), `{"text":"p","clickEvent":{"action":"change_page","value":"2"}}`},
+		{chat.TextBuilder("c").ClickCopyToClipboard("x").Build(), `{"text":"c","clickEvent":{"action":"copy_to_clipboard","value":"x"}}`},
+	} {
+		b, err := json.Marshal(tt.msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.json {
+			t.Errorf("gets %s\nwants %s", b, tt.json)
+		}
+
+		// decode it again
+		var m chat.Message
+		if err := json.Unmarshal(b, &m); err != nil {
+			t.Fatal(err)
+		}
+		if b2, _ := json.Marshal(m); string(b2) != tt.json {
+			t.Errorf("round trip gets %s", b2)
+		}
+	}
+}
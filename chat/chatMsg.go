@@ -25,15 +25,19 @@ type jsonChat struct {
 	Text string `json:"text,omitempty"`
 
 	Bold          bool   `json:"bold,omitempty"`          //粗体
-	Italic        bool   `json:"Italic,omitempty"`        //斜体
+	Italic        bool   `json:"italic,omitempty"`        //斜体
 	UnderLined    bool   `json:"underlined,omitempty"`    //下划线
 	StrikeThrough bool   `json:"strikethrough,omitempty"` //删除线
 	Obfuscated    bool   `json:"obfuscated,omitempty"`    //随机
 	Color         string `json:"color,omitempty"`
 
+	Insertion  string      `json:"insertion,omitempty"`
+	ClickEvent *ClickEvent `json:"clickEvent,omitempty"`
+	HoverEvent *HoverEvent `json:"hoverEvent,omitempty"`
+
 	Translate string            `json:"translate,omitempty"`
 	With      []json.RawMessage `json:"with,omitempty"` // How can go handle an JSON array with Object and String?
-	Extra     []jsonChat        `json:"extra,omitempty"`
+	Extra     []Message         `json:"extra,omitempty"`
 }
 
 //UnmarshalJSON decode json to Message
@@ -49,14 +53,37 @@ func (m *Message) UnmarshalJSON(jsonMsg []byte) (err error) {
 	return
 }
 
+//MarshalJSON encode the Message in the form vanilla accepts.
+//The formats not set are omitted, and a Message of only text is encoded
+//as a JSON string, the compact form. Otherwise the "text" is always there
+//unless it's a translation, for vanilla requires the content of a component.
+func (m Message) MarshalJSON() ([]byte, error) {
+	if m.isPlainText() {
+		return json.Marshal(m.Text)
+	}
+	if m.Translate != "" {
+		return json.Marshal(jsonChat(m))
+	}
+	return json.Marshal(struct {
+		Text string `json:"text"` // not omitted even if empty
+		jsonChat
+	}{m.Text, jsonChat(m)})
+}
+
+// isPlainText report whether the Message has nothing but text
+func (m Message) isPlainText() bool {
+	return !m.Bold && !m.Italic && !m.UnderLined && !m.StrikeThrough && !m.Obfuscated &&
+		m.Color == "" && m.Insertion == "" && m.ClickEvent == nil && m.HoverEvent == nil &&
+		m.Translate == "" && len(m.With) == 0 && len(m.Extra) == 0
+}
+
 //Decode for a ChatMsg packet
 func (m *Message) Decode(r pk.DecodeReader) error {
-	var Len pk.VarInt
-	if err := Len.Decode(r); err != nil {
+	jsonMsg, err := pk.ReadLengthPrefixed(r)
+	if err != nil {
 		return err
 	}
-
-	return json.NewDecoder(io.LimitReader(r, int64(Len))).Decode(m)
+	return json.Unmarshal(jsonMsg, m)
 }
 
 //Encode for a ChatMsg packet
@@ -73,12 +100,12 @@ func (m *Message) Append(extraMsg ...Message) {
 	finalLen := origLen + len(extraMsg)
 	if cap(m.Extra) < len(m.Extra)+len(extraMsg) {
 		// pre expansion
-		extra := make([]jsonChat, finalLen)
+		extra := make([]Message, origLen, finalLen)
 		copy(extra, m.Extra)
 		m.Extra = extra
 	}
 	for _, v := range extraMsg {
-		m.Extra = append(m.Extra, jsonChat(v))
+		m.Extra = append(m.Extra, v)
 	}
 }
 
@@ -172,7 +199,7 @@ func (m Message) ClearString() string {
 
 	if m.Extra != nil {
 		for i := range m.Extra {
-			msg.WriteString(Message(m.Extra[i]).ClearString())
+			msg.WriteString(m.Extra[i].ClearString())
 		}
 	}
 	return msg.String()
@@ -223,7 +250,7 @@ func (m Message) String() string {
 
 	if m.Extra != nil {
 		for i := range m.Extra {
-			msg.WriteString(Message(m.Extra[i]).String())
+			msg.WriteString(m.Extra[i].String())
 		}
 	}
 
@@ -2,8 +2,10 @@ package chat_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/Tnze/go-mc/chat"
+	"reflect"
 	"testing"
 
 	_ "github.com/Tnze/go-mc/data/lang/en-us"
@@ -132,3 +134,60 @@ func ExampleTranslateMsg() {
 	// Hello, world!
 	// Prefix, 11112222 again 3333 and 1111 lastly 2222 and also 1111 again!
 }
+
+func TestMessage_MarshalJSON(t *testing.T) {
+	for _, tt := range []struct {
+		vanilla string // sent by a vanilla 1.16 server
+		want    string
+	}{
+		{`{"text":"Hello"}`, `"Hello"`},
+		{`{"text":""}`, `""`},
+		{`{"bold":false,"italic":false,"text":"Tnze"}`, `"Tnze"`},
+		{
+			`{"color":"yellow","translate":"multiplayer.player.joined","with":[{"insertion":"Steve",` +
+				`"clickEvent":{"action":"suggest_command","value":"/tell Steve "},` +
+				`"hoverEvent":{"action":"show_entity","contents":{"type":"minecraft:player","id":"58f6356e-b30c-4811-8bfc-d72a9ee99e73","name":{"text":"Steve"}}},` +
+				`"text":"Steve"}]}`,
+			// the arguments of "with" are kept as they are
+			`{"color":"yellow","translate":"multiplayer.player.joined","with":[{"insertion":"Steve",` +
+				`"clickEvent":{"action":"suggest_command","value":"/tell Steve "},` +
+				`"hoverEvent":{"action":"show_entity","contents":{"type":"minecraft:player","id":"58f6356e-b30c-4811-8bfc-d72a9ee99e73","name":{"text":"Steve"}}},` +
+				`"text":"Steve"}]}`,
+		},
+		{
+			`{"extra":[{"bold":true,"color":"gold","text":"A "},{"extra":[{"italic":true,"text":"Minecraft"}],"text":""},{"text":" Server"}],"text":""}`,
+			`{"text":"","extra":[{"text":"A ","bold":true,"color":"gold"},{"text":"","extra":[{"text":"Minecraft","italic":true}]}," Server"]}`,
+		},
+		{`{"translate":"chat.type.text","with":["Steve","hi"]}`, `{"translate":"chat.type.text","with":["Steve","hi"]}`},
+		{
+			`{"hoverEvent":{"action":"show_text","contents":{"color":"gray","text":"click"}},"text":"[x]"}`,
+			`{"text":"[x]","hoverEvent":{"action":"show_text","contents":{"text":"click","color":"gray"}}}`,
+		},
+	} {
+		var m chat.Message
+		if err := json.Unmarshal([]byte(tt.vanilla), &m); err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.want {
+			t.Errorf("%s:\ngets  %s\nwants %s", tt.vanilla, b, tt.want)
+		}
+		// nothing is added or lost in the round trip
+		var m2 chat.Message
+		if err := json.Unmarshal(b, &m2); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(m, m2) {
+			t.Errorf("%s: round trip gets %+v, wants %+v", tt.vanilla, m2, m)
+		}
+	}
+
+	// the content "text" is required by vanilla
+	b, _ := json.Marshal(chat.Message{Bold: true, Extra: []chat.Message{chat.Text("a")}})
+	if want := `{"text":"","bold":true,"extra":["a"]}`; string(b) != want {
+		t.Errorf("gets %s, wants %s", b, want)
+	}
+}
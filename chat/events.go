@@ -0,0 +1,257 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Tnze/go-mc/nbt"
+)
+
+// ClickEvent is the action performed when the text is clicked.
+type ClickEvent struct {
+	Action string `json:"action"`
+	Value  string `json:"value"`
+}
+
+// Actions of ClickEvent
+const (
+	OpenURL         = "open_url"
+	RunCommand      = "run_command"
+	SuggestCommand  = "suggest_command"
+	ChangePage      = "change_page"
+	CopyToClipboard = "copy_to_clipboard"
+)
+
+// Actions of HoverEvent
+const (
+	ShowText   = "show_text"
+	ShowItem   = "show_item"
+	ShowEntity = "show_entity"
+)
+
+// HoverEvent is the tooltip shown when the text is hovered.
+// One of Text, Item and Entity is set depending on the Action.
+//
+// Since 1.16 the event is encoded in the "contents" field,
+// older versions use the "value" field which stringify items and entities as SNBT.
+// Both are decoded. It's encoded like 1.16 by default, see Message.MarshalVersion.
+type HoverEvent struct {
+	Action string
+	Text   *Message
+	Item   *HoverItem
+	Entity *HoverEntity
+
+	legacy bool // encode to the "value" field
+}
+
+// HoverItem is the item shown by a show_item HoverEvent.
+type HoverItem struct {
+	ID    string `json:"id"`
+	Count int    `json:"count,omitempty"`
+	// Tag is the NBT of the item in SNBT format, like {Damage:10}
+	Tag string `json:"tag,omitempty"`
+}
+
+// HoverEntity is the entity shown by a show_entity HoverEvent.
+type HoverEntity struct {
+	Type string   `json:"type"`
+	ID   string   `json:"id"` // uuid
+	Name *Message `json:"name,omitempty"`
+}
+
+type jsonHoverEvent struct {
+	Action   string          `json:"action"`
+	Contents json.RawMessage `json:"contents,omitempty"`
+	Value    json.RawMessage `json:"value,omitempty"`
+}
+
+// UnmarshalJSON decode the HoverEvent in both the "contents" and the legacy "value" form.
+func (h *HoverEvent) UnmarshalJSON(data []byte) error {
+	var raw jsonHoverEvent
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*h = HoverEvent{Action: raw.Action}
+	if raw.Contents == nil && raw.Value == nil {
+		return nil
+	}
+
+	if raw.Action == ShowText { // the value is a component as well
+		h.Text = new(Message)
+		if raw.Contents != nil {
+			return json.Unmarshal(raw.Contents, h.Text)
+		}
+		return json.Unmarshal(raw.Value, h.Text)
+	}
+
+	if raw.Contents != nil {
+		switch raw.Action {
+		case ShowItem:
+			h.Item = new(HoverItem)
+			if raw.Contents[0] == '"' { // only item id
+				return json.Unmarshal(raw.Contents, &h.Item.ID)
+			}
+			return json.Unmarshal(raw.Contents, h.Item)
+		case ShowEntity:
+			h.Entity = new(HoverEntity)
+			return json.Unmarshal(raw.Contents, h.Entity)
+		}
+		return nil
+	}
+
+	// legacy value is a text component which contains SNBT
+	var value Message
+	if err := json.Unmarshal(raw.Value, &value); err != nil {
+		return err
+	}
+	v, err := nbt.ParseSNBT(value.ClearString())
+	if err != nil {
+		return fmt.Errorf("chat: parse hover event value fail: %w", err)
+	}
+	tag, ok := v.(map[string]interface{})
+	if !ok {
+		return errors.New("chat: hover event value isn't a compound")
+	}
+	switch raw.Action {
+	case ShowItem:
+		h.Item = new(HoverItem)
+		h.Item.ID, _ = tag["id"].(string)
+		if count, ok := tag["Count"].(byte); ok {
+			h.Item.Count = int(int8(count))
+		}
+		if t, ok := tag["tag"]; ok {
+			if h.Item.Tag, err = nbt.MarshalSNBT(t); err != nil {
+				return err
+			}
+		}
+	case ShowEntity:
+		h.Entity = new(HoverEntity)
+		h.Entity.Type, _ = tag["type"].(string)
+		h.Entity.ID, _ = tag["id"].(string)
+		if name, ok := tag["name"].(string); ok {
+			h.Entity.Name = new(Message)
+			if err := h.Entity.Name.UnmarshalJSON([]byte(name)); err != nil {
+				return err
+			}
+		}
+	}
+	h.legacy = true
+	return nil
+}
+
+// MarshalJSON encode the HoverEvent.
+func (h HoverEvent) MarshalJSON() ([]byte, error) {
+	raw := jsonHoverEvent{Action: h.Action}
+	var contents interface{}
+	switch {
+	case h.Text != nil:
+		contents = h.Text
+	case h.Item != nil:
+		contents = h.Item
+	case h.Entity != nil:
+		contents = h.Entity
+	default:
+		return json.Marshal(raw)
+	}
+
+	var err error
+	if !h.legacy || h.Text != nil {
+		raw.Contents, err = json.Marshal(contents)
+		if h.legacy {
+			raw.Value, raw.Contents = raw.Contents, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(raw)
+	}
+
+	// stringify the item or entity to SNBT
+	var snbt string
+	if h.Item != nil {
+		snbt, err = h.Item.snbt()
+	} else {
+		snbt, err = h.Entity.snbt()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if raw.Value, err = json.Marshal(Text(snbt)); err != nil {
+		return nil, err
+	}
+	return json.Marshal(raw)
+}
+
+func (i *HoverItem) snbt() (string, error) {
+	tag := map[string]interface{}{"id": i.ID}
+	if i.Count != 0 {
+		tag["Count"] = int8(i.Count)
+	}
+	if i.Tag != "" {
+		t, err := nbt.ParseSNBT(i.Tag)
+		if err != nil {
+			return "", err
+		}
+		tag["tag"] = t
+	}
+	return nbt.MarshalSNBT(tag)
+}
+
+func (e *HoverEntity) snbt() (string, error) {
+	tag := map[string]interface{}{"type": e.Type, "id": e.ID}
+	if e.Name != nil {
+		name, err := json.Marshal(e.Name)
+		if err != nil {
+			return "", err
+		}
+		tag["name"] = string(name)
+	}
+	return nbt.MarshalSNBT(tag)
+}
+
+// ProtocolHoverContents is the first protocol version, 1.16,
+// which use the "contents" field in HoverEvent.
+const ProtocolHoverContents = 735
+
+// MarshalVersion encode the message to JSON for the protocol version.
+// The hover events are encoded to the legacy "value" form for versions before 1.16.
+func (m Message) MarshalVersion(protocol int) ([]byte, error) {
+	return json.Marshal(m.withLegacyHover(protocol < ProtocolHoverContents))
+}
+
+// withLegacyHover return a copy of the message whose hover events are set to the form
+func (m Message) withLegacyHover(legacy bool) Message {
+	if m.HoverEvent != nil {
+		h := *m.HoverEvent
+		h.legacy = legacy
+		if h.Text != nil {
+			text := h.Text.withLegacyHover(legacy)
+			h.Text = &text
+		}
+		m.HoverEvent = &h
+	}
+	if m.Extra != nil {
+		extra := make([]Message, len(m.Extra))
+		for i := range m.Extra {
+			extra[i] = m.Extra[i].withLegacyHover(legacy)
+		}
+		m.Extra = extra
+	}
+	if m.With != nil {
+		with := make([]json.RawMessage, len(m.With))
+		for i, v := range m.With {
+			with[i] = v
+			// the args may be strings or numbers, only the objects have hover events
+			var arg Message
+			if bytes.HasPrefix(bytes.TrimSpace(v), []byte("{")) && json.Unmarshal(v, &arg) == nil {
+				if b, err := json.Marshal(arg.withLegacyHover(legacy)); err == nil {
+					with[i] = b
+				}
+			}
+		}
+		m.With = with
+	}
+	return m
+}
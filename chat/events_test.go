@@ -0,0 +1,147 @@
+package chat_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Tnze/go-mc/chat"
+)
+
+func TestHoverEvent_contents(t *testing.T) {
+	for _, tt := range []struct {
+		json string
+		want chat.HoverEvent
+		enc  string // the encoded JSON, if it's not the same as json
+	}{
+		{
+			`{"action":"show_text","contents":{"text":"hi"}}`,
+			chat.HoverEvent{Action: chat.ShowText, Text: &chat.Message{Text: "hi"}},
+			`{"action":"show_text","contents":"hi"}`,
+		},
+		{
+			`{"action":"show_item","contents":{"id":"minecraft:diamond_sword","count":2,"tag":"{Damage:10}"}}`,
+			chat.HoverEvent{Action: chat.ShowItem, Item: &chat.HoverItem{ID: "minecraft:diamond_sword", Count: 2, Tag: "{Damage:10}"}},
+			"",
+		},
+		{
+			`{"action":"show_entity","contents":{"type":"minecraft:player","id":"c1445a67-7551-4d7e-813d-65ef170ae51f","name":{"text":"Xi_Xi_Mi"}}}`,
+			chat.HoverEvent{Action: chat.ShowEntity, Entity: &chat.HoverEntity{
+				Type: "minecraft:player",
+				ID:   "c1445a67-7551-4d7e-813d-65ef170ae51f",
+				Name: &chat.Message{Text: "Xi_Xi_Mi"},
+			}},
+			`{"action":"show_entity","contents":{"type":"minecraft:player","id":"c1445a67-7551-4d7e-813d-65ef170ae51f","name":"Xi_Xi_Mi"}}`,
+		},
+	} {
+		var h chat.HoverEvent
+		if err := json.Unmarshal([]byte(tt.json), &h); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(h, tt.want) {
+			t.Errorf("%s: gets %+v, wants %+v", tt.json, h, tt.want)
+		}
+		enc := tt.enc
+		if enc == "" {
+			enc = tt.json
+		}
+		if b, err := json.Marshal(h); err != nil {
+			t.Error(err)
+		} else if string(b) != enc {
+			t.Errorf("encode gets %s, wants %s", b, enc)
+		}
+	}
+
+	// contents of show_item may be only the id
+	var h chat.HoverEvent
+	if err := json.Unmarshal([]byte(`{"action":"show_item","contents":"minecraft:stone"}`), &h); err != nil {
+		t.Fatal(err)
+	}
+	if h.Item == nil || h.Item.ID != "minecraft:stone" {
+		t.Errorf("item id not decoded: %+v", h.Item)
+	}
+}
+
+func TestHoverEvent_value(t *testing.T) {
+	var m chat.Message
+	err := json.Unmarshal([]byte(`{"text":"Xi_Xi_Mi","hoverEvent":{"action":"show_entity","value":{"text":"{name:\"{\\\"text\\\":\\\"Xi_Xi_Mi\\\"}\",id:\"c1445a67-7551-4d7e-813d-65ef170ae51f\",type:\"minecraft:player\"}"}}}`), &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &chat.HoverEntity{
+		Type: "minecraft:player",
+		ID:   "c1445a67-7551-4d7e-813d-65ef170ae51f",
+		Name: &chat.Message{Text: "Xi_Xi_Mi"},
+	}
+	if m.HoverEvent == nil || !reflect.DeepEqual(m.HoverEvent.Entity, want) {
+		t.Errorf("gets %+v, wants %+v", m.HoverEvent, want)
+	}
+
+	err = json.Unmarshal([]byte(`{"text":"[Sword]","hoverEvent":{"action":"show_item","value":"{id:\"minecraft:diamond_sword\",Count:1b,tag:{Damage:10}}"}}`), &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantItem := &chat.HoverItem{ID: "minecraft:diamond_sword", Count: 1, Tag: "{Damage:10}"}
+	if m.HoverEvent == nil || !reflect.DeepEqual(m.HoverEvent.Item, wantItem) {
+		t.Errorf("gets %+v, wants %+v", m.HoverEvent.Item, wantItem)
+	}
+}
+
+func TestMessage_MarshalVersion(t *testing.T) {
+	m := chat.TextBuilder("[Sword]").
+		HoverItem(chat.HoverItem{ID: "minecraft:diamond_sword", Count: 1, Tag: "{Damage:10}"}).
+		Append(
+			chat.TextBuilder("Tnze").
+				HoverEntity(chat.HoverEntity{Type: "minecraft:player", ID: "58f6356e-b30c-4811-8bfc-d72a9ee99e73", Name: &chat.Message{Text: "Tnze"}}).
+				Build(),
+			chat.TextBuilder("!").HoverText(chat.Text("hi")).Build(),
+		).Build()
+
+	for _, tt := range []struct {
+		protocol int
+		json     string
+	}{
+		{736, `{"text":"[Sword]","hoverEvent":{"action":"show_item","contents":{"id":"minecraft:diamond_sword","count":1,"tag":"{Damage:10}"}},"extra":[` +
+			`{"text":"Tnze","hoverEvent":{"action":"show_entity","contents":{"type":"minecraft:player","id":"58f6356e-b30c-4811-8bfc-d72a9ee99e73","name":"Tnze"}}},` +
+			`{"text":"!","hoverEvent":{"action":"show_text","contents":"hi"}}]}`},
+		{578, `{"text":"[Sword]","hoverEvent":{"action":"show_item","value":"{Count:1b,id:\"minecraft:diamond_sword\",tag:{Damage:10}}"},"extra":[` +
+			`{"text":"Tnze","hoverEvent":{"action":"show_entity","value":"{id:\"58f6356e-b30c-4811-8bfc-d72a9ee99e73\",name:'\"Tnze\"',type:\"minecraft:player\"}"}},` +
+			`{"text":"!","hoverEvent":{"action":"show_text","value":"hi"}}]}`},
+	} {
+		b, err := m.MarshalVersion(tt.protocol)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.json {
+			t.Errorf("protocol %d:\ngets  %s\nwants %s", tt.protocol, b, tt.json)
+		}
+
+		// And decode back
+		var got chat.Message
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if b2, _ := got.MarshalVersion(tt.protocol); string(b2) != string(b) {
+			t.Errorf("protocol %d: round trip gets %s", tt.protocol, b2)
+		}
+	}
+}
+
+func TestMessage_MarshalVersion_translate(t *testing.T) {
+	name := chat.TextBuilder("Tnze").
+		HoverEntity(chat.HoverEntity{Type: "minecraft:player", ID: "58f6356e-b30c-4811-8bfc-d72a9ee99e73"}).
+		Build()
+	m := chat.TranslateMsg("chat.type.text", name, chat.Text("hi"))
+	m.With = append(m.With, json.RawMessage(`"plain"`))
+
+	b, err := m.MarshalVersion(578)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"translate":"chat.type.text","with":[` +
+		`{"text":"Tnze","hoverEvent":{"action":"show_entity","value":"{id:\"58f6356e-b30c-4811-8bfc-d72a9ee99e73\",type:\"minecraft:player\"}"}},` +
+		`"hi","plain"]}`
+	if string(b) != want {
+		t.Errorf("gets  %s\nwants %s", b, want)
+	}
+}
@@ -0,0 +1,126 @@
+package chat
+
+import "strings"
+
+// legacyColors are the color names of the § codes
+var legacyColors = map[byte]string{
+	'0': "black",
+	'1': "dark_blue",
+	'2': "dark_green",
+	'3': "dark_aqua",
+	'4': "dark_red",
+	'5': "dark_purple",
+	'6': "gold",
+	'7': "gray",
+	'8': "dark_gray",
+	'9': "blue",
+	'a': "green",
+	'b': "aqua",
+	'c': "red",
+	'd': "light_purple",
+	'e': "yellow",
+	'f': "white",
+}
+
+// FromLegacy parse the string formatted with § codes, like "§aHello §lworld",
+// into a Message with colors and formats set.
+//
+// As the vanilla client does, color codes reset the formats, and §r reset all.
+// Unknown codes are kept in the text.
+func FromLegacy(s string) Message {
+	var (
+		m    Message
+		cur  Message // current component
+		text strings.Builder
+	)
+	flush := func() {
+		if text.Len() > 0 {
+			cur.Text = text.String()
+			m.Extra = append(m.Extra, cur)
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		if !strings.HasPrefix(s[i:], "§") || i+len("§") >= len(s) {
+			text.WriteByte(s[i])
+			continue
+		}
+		code := s[i+len("§")] | 0x20 // to lower case
+		next := cur
+		if color, ok := legacyColors[code]; ok {
+			next = Message{Color: color}
+		} else {
+			switch code {
+			case 'k':
+				next.Obfuscated = true
+			case 'l':
+				next.Bold = true
+			case 'm':
+				next.StrikeThrough = true
+			case 'n':
+				next.UnderLined = true
+			case 'o':
+				next.Italic = true
+			case 'r':
+				next = Message{}
+			default: // not a § code
+				text.WriteByte(s[i])
+				continue
+			}
+		}
+		flush()
+		cur = next
+		i += len("§")
+	}
+	flush()
+
+	// simplify the message if there is only one component
+	if len(m.Extra) == 1 {
+		return m.Extra[0]
+	}
+	if len(m.Extra) == 0 {
+		return Text("")
+	}
+	return m
+}
+
+// legacyCode return the § codes which reset all formats and then set the style.
+func legacyCode(s style) string {
+	var sb strings.Builder
+	code := byte('r') // color codes also reset the formats
+	for c, name := range legacyColors {
+		if name == s.color {
+			code = c
+			break
+		}
+	}
+	sb.WriteString("§" + string(code))
+	if s.obfuscated {
+		sb.WriteString("§k")
+	}
+	if s.bold {
+		sb.WriteString("§l")
+	}
+	if s.strikethrough {
+		sb.WriteString("§m")
+	}
+	if s.underlined {
+		sb.WriteString("§n")
+	}
+	if s.italic {
+		sb.WriteString("§o")
+	}
+	return sb.String()
+}
+
+// ToLegacy return the message string formatted with § codes, for legacy clients.
+// Hex colors can't be represented and are ignored.
+func (m Message) ToLegacy() string {
+	r := renderer{
+		code: legacyCode,
+		text: func(s string) (string, bool) { return s, strings.Contains(s, "§") },
+	}
+	r.render(m, style{})
+	return r.finish("")
+}
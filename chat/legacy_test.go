@@ -0,0 +1,57 @@
+package chat_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Tnze/go-mc/chat"
+)
+
+func TestFromLegacy(t *testing.T) {
+	for _, tt := range []struct {
+		legacy, json string
+	}{
+		{"Tnze", `"Tnze"`},
+		{"", `""`},
+		{"§aTnze", `{"text":"Tnze","color":"green"}`},
+		{
+			"§6§lA §oMinecraft§r Server §kx§4!",
+			`{"text":"","extra":[` +
+				`{"text":"A ","bold":true,"color":"gold"},` +
+				`{"text":"Minecraft","bold":true,"italic":true,"color":"gold"},` +
+				`" Server ",` +
+				`{"text":"x","obfuscated":true},` +
+				`{"text":"!","color":"dark_red"}]}`,
+		},
+		{"§Cred §zkeep§", `{"text":"red §zkeep§","color":"red"}`},
+	} {
+		m := chat.FromLegacy(tt.legacy)
+		b, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.json {
+			t.Errorf("%q: gets %s, wants %s", tt.legacy, b, tt.json)
+		}
+	}
+}
+
+func TestMessage_ToLegacy(t *testing.T) {
+	for _, s := range []string{
+		"Tnze",
+		"§aTnze",
+		"§6§lA §6§l§oMinecraft§r Server §r§kx§4!",
+		"§cred§r plain",
+	} {
+		if got := chat.FromLegacy(s).ToLegacy(); got != s {
+			t.Errorf("round trip %q gets %q", s, got)
+		}
+	}
+
+	m := chat.Text("a")
+	m.Color = "red"
+	m.Append(chat.Message{Text: "b", Bold: true}, chat.Text("c"))
+	if got, want := m.ToLegacy(), "§ca§c§lb§cc"; got != want {
+		t.Errorf("gets %q, wants %q", got, want)
+	}
+}
@@ -0,0 +1,400 @@
+package chat
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// colorRGB are the RGB values of the color names, used by the gradients
+var colorRGB = map[string]uint32{
+	"black":        0x000000,
+	"dark_blue":    0x0000AA,
+	"dark_green":   0x00AA00,
+	"dark_aqua":    0x00AAAA,
+	"dark_red":     0xAA0000,
+	"dark_purple":  0xAA00AA,
+	"gold":         0xFFAA00,
+	"gray":         0xAAAAAA,
+	"dark_gray":    0x555555,
+	"blue":         0x5555FF,
+	"green":        0x55FF55,
+	"aqua":         0x55FFFF,
+	"red":          0xFF5555,
+	"light_purple": 0xFF55FF,
+	"yellow":       0xFFFF55,
+	"white":        0xFFFFFF,
+}
+
+// miniTagNames are the canonical names of the MiniMessage tags by their aliases
+var miniTagNames = map[string]string{
+	"color": "color", "colour": "color", "c": "color",
+	"bold": "bold", "b": "bold",
+	"italic": "italic", "i": "italic", "em": "italic",
+	"underlined": "underlined", "u": "underlined",
+	"strikethrough": "strikethrough", "st": "strikethrough",
+	"obfuscated": "obfuscated", "obf": "obfuscated",
+	"click":    "click",
+	"hover":    "hover",
+	"insert":   "insert",
+	"gradient": "gradient",
+	"reset":    "reset",
+	"newline":  "newline", "br": "newline",
+}
+
+// ParseMiniMessage parse the string in the MiniMessage format of the Adventure
+// library, like "<red>Hello <bold>world</bold>!</red>", into a Message.
+//
+// The tags supported are:
+//   - the colors like <red> and <#ff5555>, or <color:red>
+//   - the decorations <bold>, <italic>, <underlined>, <strikethrough>,
+//     <obfuscated>, and their short names <b>, <i>, <u>, <st> and <obf>
+//   - <click:action:value>, where the action is one of the ClickEvent actions
+//   - <hover:show_text:text>, where the text is in MiniMessage as well
+//   - <insert:text>, the text inserted when the component is shift-clicked
+//   - <gradient:color:color...>, which colors each character between the colors
+//   - <reset>, which closes all the tags, and <newline> or <br>
+//
+// Arguments can be quoted by ' or " to contain ':' and '>'.
+// A closing tag like </red> restores the format before the tag, and closes
+// the tags opened inside it too. Unknown tags are kept in the text, and \<
+// is an escaped '<'.
+// An error is returned for an unclosed tag, a closing tag without its
+// opening one, or bad arguments.
+func ParseMiniMessage(s string) (Message, error) {
+	var p miniParser
+	for i := 0; i < len(s); {
+		if s[i] == '\\' && i+1 < len(s) && (s[i+1] == '<' || s[i+1] == '\\') {
+			p.text.WriteByte(s[i+1])
+			i += 2
+			continue
+		}
+		if s[i] != '<' {
+			p.text.WriteByte(s[i])
+			i++
+			continue
+		}
+		end := miniTagEnd(s, i)
+		if end < 0 { // not a tag
+			p.text.WriteByte(s[i])
+			i++
+			continue
+		}
+		known, err := p.tag(s[i+1 : end])
+		if err != nil {
+			return Message{}, err
+		}
+		if !known {
+			p.text.WriteString(s[i : end+1])
+		}
+		i = end + 1
+	}
+	p.flush()
+	if len(p.tags) > 0 {
+		return Message{}, fmt.Errorf("chat: unclosed tag <%s>", p.tags[len(p.tags)-1].raw)
+	}
+	return p.message(), nil
+}
+
+// miniStyle is the format of the text set by the open tags
+type miniStyle struct {
+	msg      Message       // the formats only, without text
+	gradient *miniGradient // colors the text instead of msg.Color if it's set
+}
+
+type miniTag struct {
+	key   string    // the key of the closing tag, see miniTagName
+	raw   string    // the tag as written, for error messages
+	saved miniStyle // the style before the tag, restored when closing
+}
+
+type miniComponent struct {
+	msg      Message
+	gradient *miniGradient
+}
+
+type miniParser struct {
+	cur   miniStyle
+	tags  []miniTag // the open tags, the innermost is the last
+	comps []miniComponent
+	text  strings.Builder // the text of the current style not flushed
+}
+
+// flush add the text as a component of the current style
+func (p *miniParser) flush() {
+	if p.text.Len() == 0 {
+		return
+	}
+	m := p.cur.msg
+	m.Text = p.text.String()
+	p.comps = append(p.comps, miniComponent{msg: m, gradient: p.cur.gradient})
+	p.text.Reset()
+}
+
+// tag handle the content of a tag between '<' and '>',
+// and report whether it's a known tag
+func (p *miniParser) tag(tag string) (bool, error) {
+	if strings.HasPrefix(tag, "/") {
+		return p.close(tag[1:])
+	}
+	args := miniArgs(tag)
+	name, key, ok := miniTagName(args[0])
+	if !ok {
+		return false, nil
+	}
+
+	next := p.cur
+	switch name {
+	case "newline":
+		p.text.WriteByte('\n')
+		return true, nil
+	case "reset":
+		p.flush()
+		p.cur, p.tags = miniStyle{}, nil
+		return true, nil
+	case "color":
+		color := key // <red> or <#ff5555>
+		if key == "color" {
+			if len(args) != 2 {
+				return false, fmt.Errorf("chat: <%s> needs a color", tag)
+			}
+			if color, ok = miniColor(args[1]); !ok {
+				return false, fmt.Errorf("chat: unknown color %q", args[1])
+			}
+		}
+		next.msg.Color, next.gradient = color, nil
+	case "bold":
+		next.msg.Bold = true
+	case "italic":
+		next.msg.Italic = true
+	case "underlined":
+		next.msg.UnderLined = true
+	case "strikethrough":
+		next.msg.StrikeThrough = true
+	case "obfuscated":
+		next.msg.Obfuscated = true
+	case "click":
+		if len(args) < 3 {
+			return false, fmt.Errorf("chat: <%s> needs an action and a value", tag)
+		}
+		switch action := strings.ToLower(args[1]); action {
+		case OpenURL, RunCommand, SuggestCommand, ChangePage, CopyToClipboard:
+			next.msg.ClickEvent = &ClickEvent{Action: action, Value: strings.Join(args[2:], ":")}
+		default:
+			return false, fmt.Errorf("chat: unknown click action %q", args[1])
+		}
+	case "hover":
+		if len(args) < 3 {
+			return false, fmt.Errorf("chat: <%s> needs an action and a value", tag)
+		}
+		if action := strings.ToLower(args[1]); action != ShowText {
+			return false, fmt.Errorf("chat: unsupported hover action %q", args[1])
+		}
+		text, err := ParseMiniMessage(strings.Join(args[2:], ":"))
+		if err != nil {
+			return false, fmt.Errorf("chat: parse hover text fail: %w", err)
+		}
+		next.msg.HoverEvent = &HoverEvent{Action: ShowText, Text: &text}
+	case "insert":
+		if len(args) < 2 {
+			return false, fmt.Errorf("chat: <%s> needs a text", tag)
+		}
+		next.msg.Insertion = strings.Join(args[1:], ":")
+	case "gradient":
+		if len(args) < 3 {
+			return false, fmt.Errorf("chat: <%s> needs two colors at least", tag)
+		}
+		g := new(miniGradient)
+		for _, arg := range args[1:] {
+			rgb, ok := miniColorRGB(arg)
+			if !ok {
+				return false, fmt.Errorf("chat: unknown color %q of gradient", arg)
+			}
+			g.colors = append(g.colors, rgb)
+		}
+		next.msg.Color, next.gradient = "", g
+	}
+
+	p.flush()
+	p.tags = append(p.tags, miniTag{key: key, raw: tag, saved: p.cur})
+	p.cur = next
+	return true, nil
+}
+
+// close handle the closing tag of the name,
+// which closes the tags opened after it as well
+func (p *miniParser) close(tag string) (bool, error) {
+	_, key, ok := miniTagName(miniArgs(tag)[0])
+	if !ok {
+		return false, nil
+	}
+	for i := len(p.tags) - 1; i >= 0; i-- {
+		if p.tags[i].key == key {
+			p.flush()
+			p.cur, p.tags = p.tags[i].saved, p.tags[:i]
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("chat: closing tag </%s> without opening", tag)
+}
+
+// message return the Message of the components, coloring the gradients
+func (p *miniParser) message() Message {
+	// the gradients are spread over all of their characters
+	length := make(map[*miniGradient]int)
+	for _, c := range p.comps {
+		if c.gradient != nil {
+			length[c.gradient] += utf8.RuneCountInString(c.msg.Text)
+		}
+	}
+
+	var m Message
+	colored := make(map[*miniGradient]int)
+	for _, c := range p.comps {
+		if c.gradient == nil {
+			m.Extra = append(m.Extra, c.msg)
+			continue
+		}
+		for _, r := range c.msg.Text {
+			char := c.msg
+			char.Text = string(r)
+			char.Color = c.gradient.at(colored[c.gradient], length[c.gradient])
+			colored[c.gradient]++
+			m.Extra = append(m.Extra, char)
+		}
+	}
+
+	// simplify the message if there is only one component
+	if len(m.Extra) == 1 {
+		return m.Extra[0]
+	}
+	if len(m.Extra) == 0 {
+		return Text("")
+	}
+	return m
+}
+
+// miniTagName return the canonical name of the tag name, and the key
+// of the closing tag. The key of a color tag like <red> or <#ff5555> is
+// the color, and its name is "color".
+func miniTagName(tagName string) (name, key string, ok bool) {
+	if name, ok = miniTagNames[strings.ToLower(tagName)]; ok {
+		return name, name, true
+	}
+	if key, ok = miniColor(tagName); ok {
+		return "color", key, true
+	}
+	return "", "", false
+}
+
+// miniColor return the color of the name or the hex color "#rrggbb"
+func miniColor(color string) (string, bool) {
+	color = strings.ToLower(color)
+	switch color {
+	case "grey":
+		color = "gray"
+	case "dark_grey":
+		color = "dark_gray"
+	}
+	if _, ok := colorRGB[color]; ok {
+		return color, true
+	}
+	if len(color) == 7 && color[0] == '#' {
+		if _, err := strconv.ParseUint(color[1:], 16, 24); err == nil {
+			return color, true
+		}
+	}
+	return "", false
+}
+
+func miniColorRGB(color string) (uint32, bool) {
+	color, ok := miniColor(color)
+	if !ok {
+		return 0, false
+	}
+	if rgb, ok := colorRGB[color]; ok {
+		return rgb, true
+	}
+	rgb, _ := strconv.ParseUint(color[1:], 16, 24)
+	return uint32(rgb), true
+}
+
+// miniGradient is the colors of a <gradient> tag
+type miniGradient struct {
+	colors []uint32
+}
+
+// at return the color of the character i of the n characters in the gradient
+func (g *miniGradient) at(i, n int) string {
+	var t float64
+	if n > 1 {
+		t = float64(i) / float64(n-1)
+	}
+	seg := t * float64(len(g.colors)-1)
+	k := int(seg)
+	if k >= len(g.colors)-1 {
+		k = len(g.colors) - 2
+	}
+	from, to, f := g.colors[k], g.colors[k+1], seg-float64(k)
+
+	var rgb uint32
+	for shift := uint(0); shift <= 16; shift += 8 {
+		a, b := float64(from>>shift&0xFF), float64(to>>shift&0xFF)
+		rgb |= uint32(math.Round(a+(b-a)*f)) << shift
+	}
+	return fmt.Sprintf("#%06x", rgb)
+}
+
+// miniTagEnd return the index of the '>' ending the tag starting at s[start],
+// or -1 if it isn't a tag. The '>' in quoted arguments doesn't end the tag.
+func miniTagEnd(s string, start int) int {
+	var quote byte
+	for i := start + 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && i+1 < len(s) && s[i+1] == quote {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case (c == '\'' || c == '"') && s[i-1] == ':':
+			quote = c
+		case c == '>':
+			return i
+		case c == '<':
+			return -1
+		}
+	}
+	return -1
+}
+
+// miniArgs split the content of a tag by ':', unquoting the quoted arguments
+func miniArgs(tag string) []string {
+	var (
+		args []string
+		arg  strings.Builder
+	)
+	for i := 0; i < len(tag); i++ {
+		c := tag[i]
+		if (c == '\'' || c == '"') && arg.Len() == 0 {
+			// a quoted argument, till the closing quote
+			for i++; i < len(tag) && tag[i] != c; i++ {
+				if tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == c {
+					i++
+				}
+				arg.WriteByte(tag[i])
+			}
+			continue
+		}
+		if c == ':' {
+			args = append(args, arg.String())
+			arg.Reset()
+			continue
+		}
+		arg.WriteByte(c)
+	}
+	return append(args, arg.String())
+}
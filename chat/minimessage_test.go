@@ -0,0 +1,109 @@
+package chat_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Tnze/go-mc/chat"
+)
+
+func TestParseMiniMessage(t *testing.T) {
+	for _, tt := range []struct {
+		mini, json string
+	}{
+		{"Tnze", `"Tnze"`},
+		{"", `""`},
+		{
+			"<red>hello <bold>world</bold></red>!",
+			`{"text":"","extra":[` +
+				`{"text":"hello ","color":"red"},` +
+				`{"text":"world","bold":true,"color":"red"},` +
+				`"!"]}`,
+		},
+		{ // closing tags restore the format before them
+			"<red>a<color:#00ff00>b<u>c</u></color>d</red>",
+			`{"text":"","extra":[` +
+				`{"text":"a","color":"red"},` +
+				`{"text":"b","color":"#00ff00"},` +
+				`{"text":"c","underlined":true,"color":"#00ff00"},` +
+				`{"text":"d","color":"red"}]}`,
+		},
+		{ // closing an outer tag closes the inner ones
+			"<b><i><st>x</b>y<reset>z",
+			`{"text":"","extra":[` +
+				`{"text":"x","bold":true,"italic":true,"strikethrough":true},` +
+				`"y","z"]}`,
+		},
+		{
+			"<green><click:run_command:/say hi><hover:show_text:'<gold>Click: <b>now</b></gold>'>run</hover></click></green>",
+			`{"text":"run","color":"green",` +
+				`"clickEvent":{"action":"run_command","value":"/say hi"},` +
+				`"hoverEvent":{"action":"show_text","contents":{"text":"","extra":[` +
+				`{"text":"Click: ","color":"gold"},{"text":"now","bold":true,"color":"gold"}]}}}`,
+		},
+		{
+			"<click:open_url:https://example.com><insert:hi>link</insert></click>",
+			`{"text":"link","insertion":"hi","clickEvent":{"action":"open_url","value":"https://example.com"}}`,
+		},
+		{
+			"<gradient:#000000:white:#ff0000>abcde</gradient>!",
+			`{"text":"","extra":[` +
+				`{"text":"a","color":"#000000"},` +
+				`{"text":"b","color":"#808080"},` +
+				`{"text":"c","color":"#ffffff"},` +
+				`{"text":"d","color":"#ff8080"},` +
+				`{"text":"e","color":"#ff0000"},` +
+				`"!"]}`,
+		},
+		{ // a color inside the gradient overrides it
+			"<gradient:black:white>a<red>b</red>c</gradient>",
+			`{"text":"","extra":[` +
+				`{"text":"a","color":"#000000"},` +
+				`{"text":"b","color":"red"},` +
+				`{"text":"c","color":"#ffffff"}]}`,
+		},
+	} {
+		m, err := chat.ParseMiniMessage(tt.mini)
+		if err != nil {
+			t.Errorf("%q: %v", tt.mini, err)
+			continue
+		}
+		b, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.json {
+			t.Errorf("%q: gets %s, wants %s", tt.mini, b, tt.json)
+		}
+	}
+}
+
+func TestParseMiniMessage_text(t *testing.T) {
+	// escaped, unknown and broken tags are text
+	m, err := chat.ParseMiniMessage(`\<red> <foo>1 < 2</foo>\\<newline><br>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<red> <foo>1 < 2</foo>\\\n\n"; m.Text != want || len(m.Extra) != 0 {
+		t.Errorf("gets %q, wants %q", m.Text, want)
+	}
+}
+
+func TestParseMiniMessage_error(t *testing.T) {
+	for _, mini := range []string{
+		"<red>unclosed",
+		"<red><bold>unclosed</red><b>",
+		"not opened</red>",
+		"<color:nope>x</color>",
+		"<click:explode:x>x</click>",
+		"<hover:show_item:stone>x</hover>",
+		"<hover:show_text:'<red>unclosed'>x</hover>",
+		"<gradient:red>x</gradient>",
+	} {
+		if m, err := chat.ParseMiniMessage(mini); err == nil {
+			t.Errorf("%q: no error, gets %v", mini, m)
+		} else {
+			t.Logf("%q: %v", mini, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package chat
+
+import "strings"
+
+// style is the format of a component, inherited by its children.
+type style struct {
+	bold, italic, underlined, strikethrough, obfuscated bool
+	color                                               string
+}
+
+func (s style) inherit(m Message) style {
+	s.bold = s.bold || m.Bold
+	s.italic = s.italic || m.Italic
+	s.underlined = s.underlined || m.UnderLined
+	s.strikethrough = s.strikethrough || m.StrikeThrough
+	s.obfuscated = s.obfuscated || m.Obfuscated
+	if m.Color != "" {
+		s.color = m.Color
+	}
+	return s
+}
+
+// renderer convert the component tree to a flat string with control sequences,
+// such as ANSI escape sequences or § codes.
+type renderer struct {
+	sb strings.Builder
+	// code return the sequence which reset all formats and then set the style
+	code func(style) string
+	// text convert the text of components, and report if it contains
+	// control sequences which change the format
+	text func(string) (string, bool)
+
+	cur style // the style of output now
+	// dirty is set when the control sequences in text changed the format after cur is set
+	dirty bool
+}
+
+func (r *renderer) setStyle(s style) {
+	if r.dirty || r.cur != s {
+		r.sb.WriteString(r.code(s))
+		r.cur, r.dirty = s, false
+	}
+}
+
+func (r *renderer) render(m Message, parent style) {
+	s := parent.inherit(m)
+	if m.Text != "" {
+		r.setStyle(s)
+		text, changed := r.text(m.Text)
+		r.sb.WriteString(text)
+		r.dirty = r.dirty || changed
+	}
+
+	if m.Translate != "" {
+		args := make([]string, len(m.With))
+		for i, v := range m.With {
+			var arg Message
+			_ = arg.UnmarshalJSON(v) //ignore error
+			// each argument leave the output in the style of this component
+			sub := renderer{code: r.code, text: r.text, cur: s}
+			sub.render(arg, s)
+			sub.setStyle(s)
+			args[i] = sub.sb.String()
+		}
+
+		r.setStyle(s)
+		r.sb.WriteString(translate(translateMap, m.Translate, args))
+	}
+
+	for i := range m.Extra {
+		r.render(m.Extra[i], s)
+	}
+}
+
+// finish reset the format at the end and return the result
+func (r *renderer) finish(reset string) string {
+	if r.dirty || r.cur != (style{}) {
+		r.sb.WriteString(reset)
+	}
+	return r.sb.String()
+}
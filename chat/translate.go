@@ -0,0 +1,105 @@
+package chat
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Localize return the message string without escape sequence,
+// with the translate keys looked up in the locale map.
+//
+// The locale is a map like en_us.json, whose values use placeholders of Java's format
+// such as %s and %1$s, or the converted Go format such as %[1]s from data/lang/... .
+// Keys missing from the map are returned as it is.
+func (m Message) Localize(locale map[string]string) string {
+	var msg strings.Builder
+	text, _ := TransCtrlSeq(m.Text, false)
+	msg.WriteString(text)
+
+	if m.Translate != "" {
+		args := make([]string, len(m.With))
+		for i, v := range m.With {
+			var arg Message
+			_ = arg.UnmarshalJSON(v) //ignore error
+			args[i] = arg.Localize(locale)
+		}
+		msg.WriteString(translate(locale, m.Translate, args))
+	}
+
+	for i := range m.Extra {
+		msg.WriteString(m.Extra[i].Localize(locale))
+	}
+	return msg.String()
+}
+
+// translate look up the key in locale and fill in the args.
+func translate(locale map[string]string, key string, args []string) string {
+	format, ok := locale[key]
+	if !ok {
+		return key
+	}
+	return formatTranslation(format, args)
+}
+
+// formatTranslation substitute the placeholders like the vanilla client does.
+// It supports %s, %%, the explicit index %1$s and the Go style %[1]s.
+// Like Java, explicit indexes don't change the position of next %s.
+func formatTranslation(format string, args []string) string {
+	var sb strings.Builder
+	next := 0
+	arg := func(i int) {
+		if i >= 0 && i < len(args) {
+			sb.WriteString(args[i])
+		}
+	}
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			sb.WriteByte(format[i])
+			continue
+		}
+
+		rest := format[i+1:]
+		switch {
+		case strings.HasPrefix(rest, "%"):
+			sb.WriteByte('%')
+			i++
+		case strings.HasPrefix(rest, "s"):
+			arg(next)
+			next++
+			i++
+		default:
+			if n, l := parseIndex(rest); l > 0 {
+				arg(n - 1)
+				i += l
+			} else { // invalid placeholder, keep it
+				sb.WriteByte('%')
+			}
+		}
+	}
+	return sb.String()
+}
+
+// parseIndex parse "1$s" or "[1]s" at the start of s,
+// return the index and the length of placeholder without the '%'.
+func parseIndex(s string) (n, length int) {
+	var end string
+	if strings.HasPrefix(s, "[") {
+		s, length, end = s[1:], 1, "]s"
+	} else {
+		end = "$s"
+	}
+
+	digits := 0
+	for digits < len(s) && s[digits] >= '0' && s[digits] <= '9' {
+		digits++
+	}
+	if digits == 0 || !strings.HasPrefix(s[digits:], end) {
+		return 0, 0
+	}
+	n, err := strconv.Atoi(s[:digits])
+	if err != nil {
+		return 0, 0
+	}
+	return n, length + digits + len(end)
+}
@@ -0,0 +1,55 @@
+package chat_test
+
+import (
+	"testing"
+
+	"github.com/Tnze/go-mc/chat"
+	en_us "github.com/Tnze/go-mc/data/lang/en-us"
+)
+
+// some keys in the format of en_us.json
+var locale = map[string]string{
+	"chat.type.text":            "<%s> %s",
+	"death.attack.arrow.item":   "%1$s was shot by %2$s using %3$s",
+	"death.attack.generic":      "%1$s died",
+	"translation.test.complex":  "Prefix, %s%2$s again %s and %1$s lastly %s and also %1$s again!",
+	"translation.test.escape":   "%%s %%%s %%%%s %%%%%s",
+	"translation.test.invalid":  "hi %",
+	"translation.test.invalid2": "hi %  s",
+	"item.minecraft.bow":        "Bow",
+	"entity.minecraft.skeleton": "Skeleton",
+}
+
+func TestMessage_Localize(t *testing.T) {
+	for _, tt := range []struct {
+		json, text string
+	}{
+		{`{"translate":"chat.type.text","with":["Tnze","hi"]}`, "<Tnze> hi"},
+		{
+			`{"translate":"death.attack.arrow.item","with":["Tnze",{"translate":"entity.minecraft.skeleton"},{"text":"[","extra":[{"translate":"item.minecraft.bow"},"]"]}]}`,
+			"Tnze was shot by Skeleton using [Bow]",
+		},
+		{`{"translate":"translation.test.complex","with":["str1","str2","str3"]}`, "Prefix, str1str2 again str2 and str1 lastly str3 and also str1 again!"},
+		{`{"translate":"translation.test.escape","with":["str1","str2"]}`, "%s %str1 %%s %%str2"},
+		{`{"translate":"translation.test.invalid"}`, "hi %"},
+		{`{"translate":"translation.test.invalid2"}`, "hi %  s"},
+		{`{"translate":"death.attack.generic"}`, " died"},
+		{`{"translate":"missing.key","with":["a"]}`, "missing.key"},
+		{`{"text":"§cTnze: ","extra":[{"translate":"item.minecraft.bow"}]}`, "Tnze: Bow"},
+	} {
+		var m chat.Message
+		if err := m.UnmarshalJSON([]byte(tt.json)); err != nil {
+			t.Fatalf("%s: %v", tt.json, err)
+		}
+		if text := m.Localize(locale); text != tt.text {
+			t.Errorf("%s: gets %q, wants %q", tt.json, text, tt.text)
+		}
+	}
+}
+
+func TestMessage_Localize_goFormat(t *testing.T) {
+	m := chat.TranslateMsg("death.attack.arrow.item", chat.Text("Tnze"), chat.Text("Skeleton"), chat.Text("Bow"))
+	if text, want := m.Localize(en_us.Map), "Tnze was shot by Skeleton using Bow"; text != want {
+		t.Errorf("gets %q, wants %q", text, want)
+	}
+}
@@ -2,35 +2,16 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 
 	"github.com/Tnze/go-mc/bot"
-	"github.com/Tnze/go-mc/chat"
 	_ "github.com/Tnze/go-mc/data/lang/en-us"
-	"github.com/google/uuid"
+	"github.com/Tnze/go-mc/net"
 )
 
-type status struct {
-	Description chat.Message
-	Players     struct {
-		Max    int
-		Online int
-		Sample []struct {
-			ID   uuid.UUID
-			Name string
-		}
-	}
-	Version struct {
-		Name     string
-		Protocol int
-	}
-	//favicon ignored
-}
-
 func main() {
 	addr, port := getAddr()
 
@@ -42,14 +23,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	var s status
-	err = json.Unmarshal(resp, &s)
+	s, err := net.UnmarshalStatus(resp)
 	if err != nil {
 		fmt.Print("unmarshal resp fail:", err)
 		os.Exit(1)
 	}
 
-	fmt.Print(s)
+	fmt.Print(status(s))
 	fmt.Println("Delay:", delay)
 }
 
@@ -77,6 +57,8 @@ func getAddr() (string, int) {
 	return addr[0], port
 }
 
+type status net.Status
+
 func (s status) String() string {
 	var sb strings.Builder
 	fmt.Fprintln(&sb, "Server:", s.Version.Name)
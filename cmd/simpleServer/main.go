@@ -2,10 +2,10 @@
 package main
 
 import (
-	"github.com/Tnze/go-mc/bot"
 	"github.com/Tnze/go-mc/data"
 	"github.com/Tnze/go-mc/net"
 	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/Tnze/go-mc/offline"
 	"github.com/google/uuid"
 	"log"
 )
@@ -108,7 +108,7 @@ func acceptLogin(conn net.Conn) (info PlayerInfo, err error) {
 		log.Panic("Not Implement")
 	} else {
 		// offline-mode UUID
-		info.UUID = bot.OfflineUUID(info.Name)
+		info.UUID = offline.NameUUID(info.Name)
 	}
 
 	return
@@ -0,0 +1,73 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// DefaultNamespace is the namespace of an Identifier if omitted.
+const DefaultNamespace = "minecraft"
+
+// Identifier is a namespaced location of resources, like "minecraft:stone".
+// It's encoded as a String on the wire.
+// The zero value is not valid, use ParseIdentifier to get one.
+type Identifier struct {
+	namespace, path string
+}
+
+// ParseIdentifier parse "namespace:path" or "path" into an Identifier.
+// The namespace is DefaultNamespace if omitted.
+//
+// The namespace may only contain [a-z0-9._-],
+// and the path may also contain '/'.
+func ParseIdentifier(s string) (Identifier, error) {
+	id := Identifier{namespace: DefaultNamespace, path: s}
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		id.namespace, id.path = s[:i], s[i+1:]
+	}
+	if id.namespace == "" || id.path == "" {
+		return Identifier{}, fmt.Errorf("data: invalid identifier %q: empty namespace or path", s)
+	}
+	if i := strings.IndexFunc(id.namespace, func(r rune) bool { return !validIdentifierRune(r, false) }); i >= 0 {
+		return Identifier{}, fmt.Errorf("data: invalid identifier %q: illegal character %q in namespace", s, []rune(id.namespace[i:])[0])
+	}
+	if i := strings.IndexFunc(id.path, func(r rune) bool { return !validIdentifierRune(r, true) }); i >= 0 {
+		return Identifier{}, fmt.Errorf("data: invalid identifier %q: illegal character %q in path", s, []rune(id.path[i:])[0])
+	}
+	return id, nil
+}
+
+func validIdentifierRune(r rune, path bool) bool {
+	return r >= 'a' && r <= 'z' || r >= '0' && r <= '9' ||
+		r == '_' || r == '-' || r == '.' || path && r == '/'
+}
+
+// Namespace return the namespace of the Identifier, like "minecraft".
+func (id Identifier) Namespace() string { return id.namespace }
+
+// Path return the path of the Identifier, like "stone".
+func (id Identifier) Path() string { return id.path }
+
+// String return the Identifier in the form "namespace:path".
+func (id Identifier) String() string { return id.namespace + ":" + id.path }
+
+// Encode the Identifier as a String
+func (id Identifier) Encode() []byte {
+	return pk.String(id.String()).Encode()
+}
+
+// Decode the Identifier from a String, and validate it
+func (id *Identifier) Decode(r pk.DecodeReader) error {
+	var s pk.String
+	if err := s.Decode(r); err != nil {
+		return err
+	}
+	v, err := ParseIdentifier(string(s))
+	if err != nil {
+		return err
+	}
+	*id = v
+	return nil
+}
@@ -0,0 +1,63 @@
+package data
+
+import (
+	"bytes"
+	"testing"
+
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+func TestParseIdentifier(t *testing.T) {
+	for _, tt := range []struct {
+		s, namespace, path string
+	}{
+		{"minecraft:stone", "minecraft", "stone"},
+		{"stone", "minecraft", "stone"},
+		{"mynamespace:foo/bar", "mynamespace", "foo/bar"},
+		{"my-mod_1.0:a.b-c_d", "my-mod_1.0", "a.b-c_d"},
+	} {
+		id, err := ParseIdentifier(tt.s)
+		if err != nil {
+			t.Errorf("parse %q fail: %v", tt.s, err)
+			continue
+		}
+		if id.Namespace() != tt.namespace || id.Path() != tt.path {
+			t.Errorf("parse %q: get %q %q, want %q %q", tt.s, id.Namespace(), id.Path(), tt.namespace, tt.path)
+		}
+	}
+}
+
+func TestParseIdentifier_invalid(t *testing.T) {
+	for _, s := range []string{
+		"", ":", "minecraft:", ":stone",
+		"Minecraft:stone", "minecraft:Stone",
+		"my/namespace:foo", "minecraft:a b", "minecraft:a:b", "minecraft:石头",
+	} {
+		if id, err := ParseIdentifier(s); err == nil {
+			t.Errorf("parse %q should fail, get %v", s, id)
+		}
+	}
+}
+
+func TestIdentifier_Encode(t *testing.T) {
+	id, err := ParseIdentifier("brand")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := id.Encode()
+	if want := pk.String("minecraft:brand").Encode(); !bytes.Equal(data, want) {
+		t.Errorf("encode %v: get % x, want % x", id, data, want)
+	}
+
+	var decoded Identifier
+	if err := decoded.Decode(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != id {
+		t.Errorf("decode: get %v, want %v", decoded, id)
+	}
+
+	if err := decoded.Decode(bytes.NewReader(pk.String("Bad Name").Encode())); err == nil {
+		t.Error("decode an invalid identifier should fail")
+	}
+}
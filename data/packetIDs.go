@@ -1,5 +1,11 @@
 package data
 
+// The version of Minecraft the packet IDs belong to
+const (
+	ProtocolVersion = 736
+	GameVersion     = "1.16.1"
+)
+
 // Clientbound packet IDs
 const (
 	SpawnObject int32 = iota //0x00
@@ -0,0 +1,284 @@
+package data
+
+import "fmt"
+
+// State is the connection state which a packet ID belongs to.
+// The same ID means different packets in different states.
+type State int
+
+// Connection states
+const (
+	Handshaking State = iota
+	Status
+	Login
+	Play
+)
+
+func (s State) String() string {
+	switch s {
+	case Handshaking:
+		return "handshaking"
+	case Status:
+		return "status"
+	case Login:
+		return "login"
+	case Play:
+		return "play"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Direction is the bound of a packet.
+type Direction int
+
+// Directions of packets
+const (
+	Clientbound Direction = iota // server to client
+	Serverbound                  // client to server
+)
+
+func (d Direction) String() string {
+	switch d {
+	case Clientbound:
+		return "clientbound"
+	case Serverbound:
+		return "serverbound"
+	}
+	return fmt.Sprintf("Direction(%d)", int(d))
+}
+
+// ID is a packet ID together with the state and direction it belongs to.
+// Two IDs are equal only if they point to the same packet, even though
+// their Values are the same.
+type ID struct {
+	State     State
+	Direction Direction
+	Value     int32
+}
+
+// Lookup return the ID of the packet value in the state s and direction d.
+// ok is false if the packet is unknown.
+func Lookup(s State, d Direction, value int32) (id ID, ok bool) {
+	id = ID{State: s, Direction: d, Value: value}
+	return id, PacketName(s, d, value) != ""
+}
+
+// String return the ID in the form "play/clientbound/ChunkData(0x21)".
+func (id ID) String() string {
+	name := PacketName(id.State, id.Direction, id.Value)
+	if name == "" {
+		name = "Unknown"
+	}
+	return fmt.Sprintf("%v/%v/%s(0x%02X)", id.State, id.Direction, name, id.Value)
+}
+
+// PacketName return the name of packet id in the state s and direction d.
+// For packets in Play state, the name is the same as its constant.
+// An empty string is returned if the id is unknown.
+func PacketName(s State, d Direction, id int32) string {
+	var names map[int32]string
+	switch s {
+	case Handshaking:
+		if d == Serverbound {
+			names = ServerboundHandshakingNames
+		}
+	case Status:
+		names = pick(d, ClientboundStatusNames, ServerboundStatusNames)
+	case Login:
+		names = pick(d, ClientboundLoginNames, ServerboundLoginNames)
+	case Play:
+		names = pick(d, ClientboundPlayNames, ServerboundPlayNames)
+	}
+	return names[id]
+}
+
+func pick(d Direction, clientbound, serverbound map[int32]string) map[int32]string {
+	switch d {
+	case Clientbound:
+		return clientbound
+	case Serverbound:
+		return serverbound
+	}
+	return nil
+}
+
+// ServerboundHandshakingNames maps Handshaking packet IDs to names.
+var ServerboundHandshakingNames = map[int32]string{
+	0x00: "Handshake",
+	0xFE: "LegacyServerListPing",
+}
+
+// ClientboundStatusNames maps clientbound Status packet IDs to names.
+var ClientboundStatusNames = map[int32]string{
+	0x00: "Response",
+	0x01: "Pong",
+}
+
+// ServerboundStatusNames maps serverbound Status packet IDs to names.
+var ServerboundStatusNames = map[int32]string{
+	0x00: "Request",
+	0x01: "Ping",
+}
+
+// ClientboundLoginNames maps clientbound Login packet IDs to names.
+var ClientboundLoginNames = map[int32]string{
+	0x00: "Disconnect",
+	0x01: "EncryptionRequest",
+	0x02: "LoginSuccess",
+	0x03: "SetCompression",
+	0x04: "LoginPluginRequest",
+}
+
+// ServerboundLoginNames maps serverbound Login packet IDs to names.
+var ServerboundLoginNames = map[int32]string{
+	0x00: "LoginStart",
+	0x01: "EncryptionResponse",
+	0x02: "LoginPluginResponse",
+}
+
+// ClientboundPlayNames maps clientbound Play packet IDs to their constant names.
+var ClientboundPlayNames = map[int32]string{
+	SpawnObject:                      "SpawnObject",
+	SpawnExperienceOrb:               "SpawnExperienceOrb",
+	SpawnLivingEntity:                "SpawnLivingEntity",
+	SpawnPainting:                    "SpawnPainting",
+	SpawnPlayer:                      "SpawnPlayer",
+	EntityAnimationClientbound:       "EntityAnimationClientbound",
+	Statistics:                       "Statistics",
+	AcknowledgePlayerDigging:         "AcknowledgePlayerDigging",
+	BlockBreakAnimation:              "BlockBreakAnimation",
+	BlockEntityData:                  "BlockEntityData",
+	BlockAction:                      "BlockAction",
+	BlockChange:                      "BlockChange",
+	BossBar:                          "BossBar",
+	ServerDifficulty:                 "ServerDifficulty",
+	ChatMessageClientbound:           "ChatMessageClientbound",
+	MultiBlockChange:                 "MultiBlockChange",
+	TabComplete:                      "TabComplete",
+	DeclareCommands:                  "DeclareCommands",
+	WindowConfirmationClientbound:    "WindowConfirmationClientbound",
+	CloseWindowClientbound:           "CloseWindowClientbound",
+	WindowItems:                      "WindowItems",
+	WindowProperty:                   "WindowProperty",
+	SetSlot:                          "SetSlot",
+	SetCooldown:                      "SetCooldown",
+	PluginMessageClientbound:         "PluginMessageClientbound",
+	NamedSoundEffect:                 "NamedSoundEffect",
+	DisconnectPlay:                   "DisconnectPlay",
+	EntityStatus:                     "EntityStatus",
+	Explosion:                        "Explosion",
+	UnloadChunk:                      "UnloadChunk",
+	ChangeGameState:                  "ChangeGameState",
+	OpenHorseWindow:                  "OpenHorseWindow",
+	KeepAliveClientbound:             "KeepAliveClientbound",
+	ChunkData:                        "ChunkData",
+	Effect:                           "Effect",
+	Particle:                         "Particle",
+	UpdateLight:                      "UpdateLight",
+	JoinGame:                         "JoinGame",
+	MapData:                          "MapData",
+	TradeList:                        "TradeList",
+	EntityRelativeMove:               "EntityRelativeMove",
+	EntityLookAndRelativeMove:        "EntityLookAndRelativeMove",
+	EntityLook:                       "EntityLook",
+	Entity:                           "Entity",
+	VehicleMoveClientbound:           "VehicleMoveClientbound",
+	OpenBook:                         "OpenBook",
+	OpenWindow:                       "OpenWindow",
+	OpenSignEditor:                   "OpenSignEditor",
+	CraftRecipeResponse:              "CraftRecipeResponse",
+	PlayerAbilitiesClientbound:       "PlayerAbilitiesClientbound",
+	CombatEvent:                      "CombatEvent",
+	PlayerInfo:                       "PlayerInfo",
+	FacePlayer:                       "FacePlayer",
+	PlayerPositionAndLookClientbound: "PlayerPositionAndLookClientbound",
+	UnlockRecipes:                    "UnlockRecipes",
+	DestroyEntities:                  "DestroyEntities",
+	RemoveEntityEffect:               "RemoveEntityEffect",
+	ResourcePackSend:                 "ResourcePackSend",
+	Respawn:                          "Respawn",
+	EntityHeadLook:                   "EntityHeadLook",
+	SelectAdvancementTab:             "SelectAdvancementTab",
+	WorldBorder:                      "WorldBorder",
+	Camera:                           "Camera",
+	HeldItemChangeClientbound:        "HeldItemChangeClientbound",
+	UpdateViewPosition:               "UpdateViewPosition",
+	UpdateViewDistance:               "UpdateViewDistance",
+	SpawnPosition:                    "SpawnPosition",
+	DisplayScoreboard:                "DisplayScoreboard",
+	EntityMetadata:                   "EntityMetadata",
+	AttachEntity:                     "AttachEntity",
+	EntityVelocity:                   "EntityVelocity",
+	EntityEquipment:                  "EntityEquipment",
+	SetExperience:                    "SetExperience",
+	UpdateHealth:                     "UpdateHealth",
+	ScoreboardObjective:              "ScoreboardObjective",
+	SetPassengers:                    "SetPassengers",
+	Teams:                            "Teams",
+	UpdateScore:                      "UpdateScore",
+	TimeUpdate:                       "TimeUpdate",
+	Title:                            "Title",
+	EntitySoundEffect:                "EntitySoundEffect",
+	SoundEffect:                      "SoundEffect",
+	StopSound:                        "StopSound",
+	PlayerListHeaderAndFooter:        "PlayerListHeaderAndFooter",
+	NBTQueryResponse:                 "NBTQueryResponse",
+	CollectItem:                      "CollectItem",
+	EntityTeleport:                   "EntityTeleport",
+	Advancements:                     "Advancements",
+	EntityProperties:                 "EntityProperties",
+	EntityEffect:                     "EntityEffect",
+	DeclareRecipes:                   "DeclareRecipes",
+	Tags:                             "Tags",
+}
+
+// ServerboundPlayNames maps serverbound Play packet IDs to their constant names.
+var ServerboundPlayNames = map[int32]string{
+	TeleportConfirm:                  "TeleportConfirm",
+	QueryBlockNBT:                    "QueryBlockNBT",
+	SetDifficulty:                    "SetDifficulty",
+	ChatMessageServerbound:           "ChatMessageServerbound",
+	ClientStatus:                     "ClientStatus",
+	ClientSettings:                   "ClientSettings",
+	TabCompleteServerbound:           "TabCompleteServerbound",
+	ConfirmTransactionServerbound:    "ConfirmTransactionServerbound",
+	ClickWindowButton:                "ClickWindowButton",
+	ClickWindow:                      "ClickWindow",
+	CloseWindowServerbound:           "CloseWindowServerbound",
+	PluginMessageServerbound:         "PluginMessageServerbound",
+	EditBook:                         "EditBook",
+	QueryEntityNBT:                   "QueryEntityNBT",
+	UseEntity:                        "UseEntity",
+	GenerateStructure:                "GenerateStructure",
+	KeepAliveServerbound:             "KeepAliveServerbound",
+	LockDifficulty:                   "LockDifficulty",
+	PlayerPosition:                   "PlayerPosition",
+	PlayerPositionAndLookServerbound: "PlayerPositionAndLookServerbound",
+	PlayerLook:                       "PlayerLook",
+	Player:                           "Player",
+	VehicleMoveServerbound:           "VehicleMoveServerbound",
+	SteerBoat:                        "SteerBoat",
+	PickItem:                         "PickItem",
+	CraftRecipeRequest:               "CraftRecipeRequest",
+	PlayerAbilitiesServerbound:       "PlayerAbilitiesServerbound",
+	PlayerDigging:                    "PlayerDigging",
+	EntityAction:                     "EntityAction",
+	SteerVehicle:                     "SteerVehicle",
+	RecipeBookData:                   "RecipeBookData",
+	NameItem:                         "NameItem",
+	ResourcePackStatus:               "ResourcePackStatus",
+	AdvancementTab:                   "AdvancementTab",
+	SelectTrade:                      "SelectTrade",
+	SetBeaconEffect:                  "SetBeaconEffect",
+	HeldItemChangeServerbound:        "HeldItemChangeServerbound",
+	UpdateCommandBlock:               "UpdateCommandBlock",
+	UpdateCommandBlockMinecart:       "UpdateCommandBlockMinecart",
+	CreativeInventoryAction:          "CreativeInventoryAction",
+	UpdateJigsawBlock:                "UpdateJigsawBlock",
+	UpdateStructureBlock:             "UpdateStructureBlock",
+	UpdateSign:                       "UpdateSign",
+	AnimationServerbound:             "AnimationServerbound",
+	Spectate:                         "Spectate",
+	PlayerBlockPlacement:             "PlayerBlockPlacement",
+	UseItem:                          "UseItem",
+}
@@ -0,0 +1,40 @@
+package data
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	for _, tt := range []struct {
+		s     State
+		d     Direction
+		value int32
+		ok    bool
+		str   string
+	}{
+		{Play, Clientbound, ChunkData, true, "play/clientbound/ChunkData(0x21)"},
+		{Login, Serverbound, 0x00, true, "login/serverbound/LoginStart(0x00)"},
+		// the handshaking state has only serverbound packets
+		{Handshaking, Serverbound, 0xFE, true, "handshaking/serverbound/LegacyServerListPing(0xFE)"},
+		{Handshaking, Clientbound, 0xFE, false, "handshaking/clientbound/Unknown(0xFE)"},
+		// unknown IDs
+		{Status, Clientbound, 0x7F, false, "status/clientbound/Unknown(0x7F)"},
+		{State(7), Direction(2), 0x00, false, "State(7)/Direction(2)/Unknown(0x00)"},
+	} {
+		id, ok := Lookup(tt.s, tt.d, tt.value)
+		if ok != tt.ok {
+			t.Errorf("Lookup(%v, %v, 0x%02X) ok = %v, want %v", tt.s, tt.d, tt.value, ok, tt.ok)
+		}
+		if id != (ID{State: tt.s, Direction: tt.d, Value: tt.value}) {
+			t.Errorf("Lookup(%v, %v, 0x%02X) = %#v", tt.s, tt.d, tt.value, id)
+		}
+		if got := id.String(); got != tt.str {
+			t.Errorf("ID.String() = %q, want %q", got, tt.str)
+		}
+	}
+
+	// the same value in different states are different IDs
+	handshake, _ := Lookup(Handshaking, Serverbound, 0x00)
+	request, _ := Lookup(Status, Serverbound, 0x00)
+	if handshake == request {
+		t.Error("IDs of different states should not be equal")
+	}
+}
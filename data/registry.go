@@ -0,0 +1,74 @@
+package data
+
+import (
+	"sort"
+	"sync"
+)
+
+// Block is a block type in the block registry
+type Block struct {
+	ID           int // the block ID, not the block state ID
+	Name         string
+	DefaultState int // the state ID of the default state
+	MinState     int // the range of the state IDs, inclusive
+	MaxState     int
+}
+
+var (
+	registryOnce sync.Once
+	blocks       []Block
+	blocksByName map[string]*Block
+)
+
+// initRegistry is called lazily, after all the init of this package
+func initRegistry() {
+	// The block IDs are in the same order as their states
+	for name, v := range blockStates {
+		b := Block{Name: name, MinState: v.States[0].ID, MaxState: v.States[0].ID}
+		for _, s := range v.States {
+			if s.Default {
+				b.DefaultState = s.ID
+			}
+			if s.ID < b.MinState {
+				b.MinState = s.ID
+			}
+			if s.ID > b.MaxState {
+				b.MaxState = s.ID
+			}
+		}
+		blocks = append(blocks, b)
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].MinState < blocks[j].MinState })
+	blocksByName = make(map[string]*Block, len(blocks))
+	for i := range blocks {
+		blocks[i].ID = i
+		blocksByName[blocks[i].Name] = &blocks[i]
+	}
+}
+
+// BlockByName return the block of the name, like "minecraft:stone"
+func BlockByName(name string) (Block, bool) {
+	registryOnce.Do(initRegistry)
+	b, ok := blocksByName[name]
+	if !ok {
+		return Block{}, false
+	}
+	return *b, true
+}
+
+// BlockByID return the block of the block ID
+func BlockByID(id int) (Block, bool) {
+	registryOnce.Do(initRegistry)
+	if id < 0 || id >= len(blocks) {
+		return Block{}, false
+	}
+	return blocks[id], true
+}
+
+// BlockByStateID return the block which the state ID belongs to
+func BlockByStateID(stateID int) (Block, bool) {
+	if stateID < 0 || stateID >= len(BlockNameByID) {
+		return Block{}, false
+	}
+	return BlockByName(BlockNameByID[stateID])
+}
@@ -0,0 +1,39 @@
+package data
+
+import "testing"
+
+func TestBlockByName(t *testing.T) {
+	stone, ok := BlockByName("minecraft:stone")
+	if !ok {
+		t.Fatal("stone not found")
+	}
+	if stone.ID != 1 || stone.DefaultState != 1 || stone.MinState != 1 || stone.MaxState != 1 {
+		t.Errorf("wrong stone: %+v", stone)
+	}
+	if b, ok := BlockByID(stone.ID); !ok || b != stone {
+		t.Errorf("BlockByID(%d) = %+v, %v", stone.ID, b, ok)
+	}
+
+	stairs, ok := BlockByName("minecraft:oak_stairs")
+	if !ok {
+		t.Fatal("oak_stairs not found")
+	}
+	if stairs.MinState > stairs.DefaultState || stairs.DefaultState > stairs.MaxState {
+		t.Errorf("default state out of range: %+v", stairs)
+	}
+	for _, s := range []int{stairs.MinState, stairs.DefaultState, stairs.MaxState} {
+		if b, ok := BlockByStateID(s); !ok || b.Name != stairs.Name {
+			t.Errorf("BlockByStateID(%d) = %+v, %v", s, b, ok)
+		}
+	}
+
+	if _, ok := BlockByName("minecraft:nothing"); ok {
+		t.Error("unknown block found")
+	}
+	if _, ok := BlockByID(-1); ok {
+		t.Error("block of ID -1 found")
+	}
+	if _, ok := BlockByStateID(len(BlockNameByID)); ok {
+		t.Error("block of unknown state found")
+	}
+}
@@ -0,0 +1,131 @@
+package data
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Box is an axis-aligned bounding box relative to the block's origin.
+// A full block is Box{0, 0, 0, 1, 1, 1}.
+type Box struct {
+	MinX, MinY, MinZ float64
+	MaxX, MaxY, MaxZ float64
+}
+
+var (
+	fullBlock   = []Box{{0, 0, 0, 1, 1, 1}}
+	bottomSlab  = []Box{{0, 0, 0, 1, 0.5, 1}}
+	topSlab     = []Box{{0, 0.5, 0, 1, 1, 1}}
+	fenceLike   = []Box{{0, 0, 0, 1, 1.5, 1}}
+	carpetLike  = []Box{{0, 0, 0, 1, 1.0 / 16, 1}}
+	farmland    = []Box{{0, 0, 0, 1, 15.0 / 16, 1}}
+	soulSand    = []Box{{0, 0, 0, 1, 14.0 / 16, 1}}
+	snowLayers  [8][]Box
+	shapesOnce  sync.Once
+	stateShapes [][]Box
+)
+
+func init() {
+	for i := range snowLayers {
+		if i > 0 {
+			snowLayers[i] = []Box{{0, 0, 0, 1, float64(i) / 8, 1}}
+		}
+	}
+}
+
+// passable are the blocks without collision
+var passable = map[string]bool{
+	"air": true, "cave_air": true, "void_air": true, "water": true, "lava": true,
+	"grass": true, "tall_grass": true, "fern": true, "large_fern": true, "dead_bush": true,
+	"seagrass": true, "tall_seagrass": true, "kelp": true, "kelp_plant": true,
+	"dandelion": true, "poppy": true, "blue_orchid": true, "allium": true, "azure_bluet": true,
+	"oxeye_daisy": true, "cornflower": true, "lily_of_the_valley": true, "wither_rose": true,
+	"sunflower": true, "lilac": true, "rose_bush": true, "peony": true,
+	"brown_mushroom": true, "red_mushroom": true, "crimson_fungus": true, "warped_fungus": true,
+	"crimson_roots": true, "warped_roots": true, "nether_sprouts": true,
+	"weeping_vines": true, "weeping_vines_plant": true, "twisting_vines": true, "twisting_vines_plant": true,
+	"torch": true, "wall_torch": true, "redstone_torch": true, "redstone_wall_torch": true,
+	"soul_torch": true, "soul_wall_torch": true,
+	"rail": true, "powered_rail": true, "detector_rail": true, "activator_rail": true,
+	"redstone_wire": true, "lever": true, "tripwire": true, "tripwire_hook": true,
+	"vine": true, "sugar_cane": true, "wheat": true, "carrots": true, "potatoes": true, "beetroots": true,
+	"nether_wart": true, "melon_stem": true, "pumpkin_stem": true,
+	"attached_melon_stem": true, "attached_pumpkin_stem": true, "sweet_berry_bush": true,
+	"cobweb": true, "fire": true, "soul_fire": true, "nether_portal": true, "end_portal": true,
+	"end_gateway": true, "structure_void": true, "bubble_column": true,
+}
+
+// passableSuffixes are the suffixes of the block families without collision
+var passableSuffixes = []string{
+	"_sapling", "_tulip", "_sign", "_button", "_pressure_plate", "_banner",
+	"_coral", "_coral_fan", "_coral_wall_fan",
+}
+
+// BlockCollisionBoxes return the collision boxes of the block state.
+//
+// The shapes are approximated from the block names and properties:
+// blocks like air, fluids, plants and torches have no collision,
+// slabs, snow layers, carpets and a few others have their real height,
+// fences, walls and closed fence gates are 1.5 blocks high,
+// and all the other blocks are full cubes.
+func BlockCollisionBoxes(stateID int) []Box {
+	shapesOnce.Do(initShapes)
+	if stateID < 0 || stateID >= len(stateShapes) {
+		return nil
+	}
+	return stateShapes[stateID]
+}
+
+func initShapes() {
+	stateShapes = make([][]Box, blockStatesLen)
+	for name, block := range blockStates {
+		name = strings.TrimPrefix(name, "minecraft:")
+		for _, s := range block.States {
+			stateShapes[s.ID] = blockShape(name, s.Properties)
+		}
+	}
+}
+
+func blockShape(name string, properties map[string]interface{}) []Box {
+	if passable[name] {
+		return nil
+	}
+	for _, v := range passableSuffixes {
+		if strings.HasSuffix(name, v) {
+			return nil
+		}
+	}
+	prop := func(key string) string {
+		s, _ := properties[key].(string)
+		return s
+	}
+	switch {
+	case strings.HasSuffix(name, "_slab"):
+		switch prop("type") {
+		case "bottom":
+			return bottomSlab
+		case "top":
+			return topSlab
+		}
+	case name == "snow":
+		layers, _ := strconv.Atoi(prop("layers"))
+		if layers >= 1 && layers <= 8 {
+			return snowLayers[layers-1]
+		}
+	case strings.HasSuffix(name, "_carpet"):
+		return carpetLike
+	case name == "farmland" || name == "grass_path":
+		return farmland
+	case name == "soul_sand":
+		return soulSand
+	case strings.HasSuffix(name, "_fence_gate"):
+		if prop("open") == "true" {
+			return nil
+		}
+		return fenceLike
+	case strings.HasSuffix(name, "_fence"), strings.HasSuffix(name, "_wall"):
+		return fenceLike
+	}
+	return fullBlock
+}
@@ -0,0 +1,81 @@
+package data
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	statesOnce sync.Once
+	stateProps []map[string]string // the property values of each state ID
+)
+
+// initStates is called lazily, for most programs needn't the properties
+func initStates() {
+	stateProps = make([]map[string]string, blockStatesLen)
+	for _, v := range blockStates {
+		for _, s := range v.States {
+			if len(s.Properties) == 0 {
+				continue
+			}
+			props := make(map[string]string, len(s.Properties))
+			for k, v := range s.Properties {
+				props[k] = fmt.Sprint(v)
+			}
+			stateProps[s.ID] = props
+		}
+	}
+}
+
+// StateProperties return the block name and the property values of the
+// block state ID, like "minecraft:oak_stairs" with facing=north, half=bottom.
+// The props is nil for the blocks without properties, and it's a copy,
+// so it's safe to be modified.
+func StateProperties(stateID int) (name string, props map[string]string, ok bool) {
+	if stateID < 0 || stateID >= len(BlockNameByID) || BlockNameByID[stateID] == "" {
+		return "", nil, false
+	}
+	statesOnce.Do(initStates)
+	if p := stateProps[stateID]; p != nil {
+		props = make(map[string]string, len(p))
+		for k, v := range p {
+			props[k] = v
+		}
+	}
+	return BlockNameByID[stateID], props, true
+}
+
+// StateID return the state ID of the block with the property values,
+// the reverse of StateProperties. The properties not given are of the
+// default state.
+func StateID(name string, props map[string]string) (int, bool) {
+	b, ok := BlockByName(name)
+	if !ok {
+		return 0, false
+	}
+	statesOnce.Do(initStates)
+	def := stateProps[b.DefaultState]
+	for id := b.MinState; id <= b.MaxState; id++ {
+		match := true
+		for k, v := range stateProps[id] {
+			want, ok := props[k]
+			if !ok {
+				want = def[k]
+			}
+			if v != want {
+				match = false
+				break
+			}
+		}
+		if match {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// DefaultState return the state ID of the default state of the block
+func DefaultState(name string) (int, bool) {
+	b, ok := BlockByName(name)
+	return b.DefaultState, ok
+}
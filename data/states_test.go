@@ -0,0 +1,55 @@
+package data
+
+import "testing"
+
+func TestStateProperties(t *testing.T) {
+	def, ok := DefaultState("minecraft:oak_stairs")
+	if !ok {
+		t.Fatal("oak_stairs not found")
+	}
+	name, props, ok := StateProperties(def)
+	if !ok || name != "minecraft:oak_stairs" {
+		t.Fatalf("StateProperties(%d) = %q, %v", def, name, ok)
+	}
+	want := map[string]string{"facing": "north", "half": "bottom", "shape": "straight", "waterlogged": "false"}
+	if len(props) != len(want) {
+		t.Fatalf("wrong properties: %v", props)
+	}
+	for k, v := range want {
+		if props[k] != v {
+			t.Errorf("property %s = %q, want %q", k, props[k], v)
+		}
+	}
+	props["facing"] = "east" // the returned map is a copy
+	if _, p, _ := StateProperties(def); p["facing"] != "north" {
+		t.Error("the properties table is modified")
+	}
+
+	// the reverse, with the missing properties of the default state
+	id, ok := StateID("minecraft:oak_stairs", map[string]string{"facing": "east", "half": "top"})
+	if !ok {
+		t.Fatal("StateID of east top oak_stairs not found")
+	}
+	if _, p, _ := StateProperties(id); p["facing"] != "east" || p["half"] != "top" ||
+		p["shape"] != "straight" || p["waterlogged"] != "false" {
+		t.Errorf("StateID return %d of %v", id, p)
+	}
+	if id, ok := StateID("minecraft:stone", nil); !ok || id != 1 {
+		t.Errorf("StateID of stone = %d, %v", id, ok)
+	}
+	if _, ok := StateID("minecraft:oak_stairs", map[string]string{"facing": "up"}); ok {
+		t.Error("StateID of an invalid value should not be ok")
+	}
+
+	if _, props, ok := StateProperties(1); !ok || props != nil {
+		t.Errorf("stone should have no properties, get %v, %v", props, ok)
+	}
+	for _, id := range []int{-1, blockStatesLen} {
+		if _, _, ok := StateProperties(id); ok {
+			t.Errorf("StateProperties(%d) should not be ok", id)
+		}
+	}
+	if _, ok := DefaultState("minecraft:not_exist"); ok {
+		t.Error("DefaultState of an unknown block should not be ok")
+	}
+}
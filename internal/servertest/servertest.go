@@ -0,0 +1,262 @@
+// Package servertest provides a fake Minecraft server for testing the clients,
+// without a real server running.
+//
+// The Server completes the handshake and an offline-mode login, then the
+// test sends the packets of the play state and checks what the client sends:
+//
+//	s := servertest.New()
+//	defer s.Close()
+//	go client.JoinServer(s.Host(), s.Port())
+//	conn := s.Accept(t)
+//	conn.JoinGame(t)
+//	conn.Chat(t, "hello")
+//	p := conn.Expect(t, data.ChatMessageServerbound)
+package servertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Tnze/go-mc/data"
+	"github.com/Tnze/go-mc/nbt"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/Tnze/go-mc/offline"
+)
+
+// DefaultTimeout is the default of Server.Timeout
+const DefaultTimeout = 5 * time.Second
+
+// Server is a fake Minecraft server listening on a random local port
+type Server struct {
+	// Threshold is the compression threshold sent to the clients in the login.
+	// The compression isn't enabled if it's negative, which is the default of New.
+	Threshold int
+	// Timeout limits the reading of each packet from the clients.
+	Timeout time.Duration
+	// Login, if not nil, is called after the Login Start is received,
+	// for sending more packets of the login, like LoginPluginRequest.
+	Login func(c *Conn) error
+
+	l net.Listener
+}
+
+// New start a Server listening on 127.0.0.1 at a random port.
+// It panics if it can't listen, like httptest.NewServer.
+// Close the Server after the test.
+func New() *Server {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("servertest: failed to listen: %v", err))
+	}
+	return &Server{Threshold: -1, Timeout: DefaultTimeout, l: l}
+}
+
+// Addr return the address of the Server, "host:port"
+func (s *Server) Addr() string { return s.l.Addr().String() }
+
+// Host return the host of the Server
+func (s *Server) Host() string { return s.l.Addr().(*net.TCPAddr).IP.String() }
+
+// Port return the port of the Server
+func (s *Server) Port() int { return s.l.Addr().(*net.TCPAddr).Port }
+
+// Close stop listening. The accepted Conns are not closed.
+func (s *Server) Close() error { return s.l.Close() }
+
+// Conn is a client accepted by the Server, which is in the play state
+type Conn struct {
+	*mcnet.Conn
+	Protocol int    // the protocol version of the handshake
+	Name     string // the player name of the login
+	UUID     pk.UUID
+
+	timeout time.Duration
+}
+
+// Accept wait for a client and log it in. The test fails if the login fails.
+func (s *Server) Accept(t testing.TB) *Conn {
+	t.Helper()
+	c, err := s.accept()
+	if err != nil {
+		t.Fatalf("servertest: accept: %v", err)
+	}
+	return c
+}
+
+func (s *Server) accept() (*Conn, error) {
+	socket, err := s.l.Accept()
+	if err != nil {
+		return nil, err
+	}
+	c := &Conn{Conn: mcnet.WrapConn(socket), timeout: s.Timeout}
+
+	// Handshake
+	p, err := c.recv()
+	if err != nil {
+		return nil, fmt.Errorf("read handshake: %v", err)
+	}
+	var (
+		protocol pk.VarInt
+		addr     pk.String
+		port     pk.UnsignedShort
+		next     pk.VarInt
+	)
+	if err := p.Scan(&protocol, &addr, &port, &next); err != nil {
+		return nil, fmt.Errorf("scan handshake: %v", err)
+	}
+	if next != 2 {
+		return nil, fmt.Errorf("the next state of handshake is %d, not login", next)
+	}
+	c.Protocol = int(protocol)
+
+	// Login Start
+	if p, err = c.recv(); err != nil {
+		return nil, fmt.Errorf("read login start: %v", err)
+	}
+	var name pk.String
+	if err := p.Scan(&name); err != nil {
+		return nil, fmt.Errorf("scan login start: %v", err)
+	}
+	c.Name, c.UUID = string(name), pk.UUID(offline.NameUUID(string(name)))
+	if s.Login != nil {
+		if err := s.Login(c); err != nil {
+			return nil, fmt.Errorf("login: %v", err)
+		}
+	}
+
+	if s.Threshold >= 0 {
+		if err := c.EnableCompression(s.Threshold); err != nil {
+			return nil, fmt.Errorf("enable compression: %v", err)
+		}
+	}
+	if err := c.WritePacket(pk.Marshal(0x02, c.UUID, name)); err != nil {
+		return nil, fmt.Errorf("send login success: %v", err)
+	}
+	return c, nil
+}
+
+// LoginPluginRequest send a Login Plugin Request to the client in the login,
+// and return its response. It's used in Server.Login.
+func (c *Conn) LoginPluginRequest(msgID int, channel string, data []byte) (response []byte, understood bool, err error) {
+	if err := c.WritePacket(pk.Marshal(0x04, pk.VarInt(msgID), pk.Identifier(channel), rawField(data))); err != nil {
+		return nil, false, err
+	}
+	p, err := c.recv()
+	if err != nil {
+		return nil, false, err
+	}
+	var (
+		id pk.VarInt
+		ok pk.Boolean
+	)
+	r := bytes.NewReader(p.Data)
+	if err := id.Decode(r); err != nil {
+		return nil, false, err
+	}
+	if err := ok.Decode(r); err != nil {
+		return nil, false, err
+	}
+	if p.ID != 0x02 || int(id) != msgID {
+		return nil, false, fmt.Errorf("response packet 0x%02X of message %d, want 0x02 of %d", p.ID, id, msgID)
+	}
+	return p.Data[len(p.Data)-r.Len():], bool(ok), nil
+}
+
+func (c *Conn) recv() (pk.Packet, error) {
+	if err := c.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return pk.Packet{}, err
+	}
+	return c.ReadPacket()
+}
+
+// Send write the packets to the client in order
+func (c *Conn) Send(t testing.TB, packets ...pk.Packet) {
+	t.Helper()
+	for _, p := range packets {
+		if err := c.WritePacket(p); err != nil {
+			t.Fatalf("servertest: send packet 0x%02X: %v", p.ID, err)
+		}
+	}
+}
+
+// Recv read the next packet sent by the client
+func (c *Conn) Recv(t testing.TB) pk.Packet {
+	t.Helper()
+	p, err := c.recv()
+	if err != nil {
+		t.Fatalf("servertest: recv packet: %v", err)
+	}
+	return p
+}
+
+// Expect read the packets sent by the client until one of the ID,
+// skipping the others. The test fails if it's not received in the Timeout.
+func (c *Conn) Expect(t testing.TB, id int32) pk.Packet {
+	t.Helper()
+	deadline := time.Now().Add(c.timeout)
+	for {
+		if err := c.SetReadDeadline(deadline); err != nil {
+			t.Fatal(err)
+		}
+		p, err := c.ReadPacket()
+		if err != nil {
+			t.Fatalf("servertest: expect packet 0x%02X: %v", id, err)
+		}
+		if p.ID == id {
+			return p
+		}
+	}
+}
+
+// JoinGame send a Join Game of the overworld, which is usually the first
+// packet of the play state, with the entity ID 1 and creative mode.
+func (c *Conn) JoinGame(t testing.TB) {
+	t.Helper()
+	codec := nbt.NewCompound().Put("dimension", nbt.NewList(nbt.NewCompound().
+		Put("name", nbt.String("minecraft:overworld")).
+		Put("has_skylight", nbt.Byte(1)).
+		Put("natural", nbt.Byte(1)).
+		Put("logical_height", nbt.Int(256)).
+		Put("infiniburn", nbt.String("minecraft:infiniburn_overworld"))))
+	c.Send(t, pk.Marshal(data.JoinGame,
+		pk.Int(1), pk.UnsignedByte(1), pk.Byte(-1),
+		pk.Ary{Len: pk.VarInt(0), Ary: []pk.Identifier{"minecraft:overworld"}},
+		rawField(codec.Encode()), pk.Identifier("minecraft:overworld"), pk.Identifier("minecraft:overworld"),
+		pk.Long(0), pk.UnsignedByte(20), pk.VarInt(10),
+		pk.Boolean(false), pk.Boolean(true), pk.Boolean(false), pk.Boolean(false),
+	))
+}
+
+// rawField is the encoded bytes of a field
+type rawField []byte
+
+func (r rawField) Encode() []byte { return r }
+
+// Chat send a chat message of the text to the client, as if a player said it
+func (c *Conn) Chat(t testing.TB, text string) {
+	t.Helper()
+	msg, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Send(t, pk.Marshal(data.ChatMessageClientbound, pk.String(msg), pk.Byte(0), pk.UUID{}))
+}
+
+// KeepAlive send a keep alive to the client and check its response
+func (c *Conn) KeepAlive(t testing.TB, id int64) {
+	t.Helper()
+	c.Send(t, pk.Marshal(data.KeepAliveClientbound, pk.Long(id)))
+	p := c.Expect(t, data.KeepAliveServerbound)
+	var got pk.Long
+	if err := p.Scan(&got); err != nil {
+		t.Fatalf("servertest: scan keep alive: %v", err)
+	}
+	if int64(got) != id {
+		t.Fatalf("servertest: keep alive response %d, want %d", got, id)
+	}
+}
@@ -0,0 +1,39 @@
+package nbt
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+)
+
+// ReadCompressed decode a NBT from r, which may be compressed with gzip (like level.dat),
+// zlib (like chunks in region files) or not compressed.
+// The format is detected by the magic bytes.
+func ReadCompressed(r io.Reader, v interface{}) error {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(2)
+	if err != nil {
+		return fmt.Errorf("nbt: %w", err)
+	}
+
+	var src io.Reader = br
+	switch {
+	case head[0] == 0x1f && head[1] == 0x8b: // gzip
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("nbt: open gzip fail: %w", err)
+		}
+		defer gr.Close()
+		src = gr
+	case head[0] == 0x78: // zlib
+		zr, err := zlib.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("nbt: open zlib fail: %w", err)
+		}
+		defer zr.Close()
+		src = zr
+	}
+	return NewDecoder(src).Decode(v)
+}
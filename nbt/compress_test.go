@@ -0,0 +1,50 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"testing"
+)
+
+func TestReadCompressed(t *testing.T) {
+	type level struct {
+		Data struct {
+			LevelName string
+			Time      int64
+		}
+	}
+	var want level
+	want.Data.LevelName = "world"
+	want.Data.Time = 24000
+
+	var raw bytes.Buffer
+	if err := Marshal(&raw, want); err != nil {
+		t.Fatal(err)
+	}
+	compress := func(newWriter func(io.Writer) io.WriteCloser) []byte {
+		var buf bytes.Buffer
+		w := newWriter(&buf)
+		if _, err := w.Write(raw.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	for name, data := range map[string][]byte{
+		"raw":  raw.Bytes(),
+		"gzip": compress(func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }),
+		"zlib": compress(func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }),
+	} {
+		var got level
+		if err := ReadCompressed(bytes.NewReader(data), &got); err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if got != want {
+			t.Errorf("%s: get %+v, want %+v", name, got, want)
+		}
+	}
+}
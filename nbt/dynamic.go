@@ -0,0 +1,411 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Tag is a value of a dynamic tag tree, built without defining Go structs.
+//
+// Tags can be marshaled directly or as a part of other values, and a
+// Compound or List can be unmarshaled from any NBT of its type, keeping
+// every tag and their order, which makes round-trip of arbitrary NBT possible.
+type Tag interface {
+	TagType() byte
+}
+
+// The basic types of Tag
+type (
+	Byte      int8
+	Short     int16
+	Int       int32
+	Long      int64
+	Float     float32
+	Double    float64
+	String    string
+	ByteArray []byte
+	IntArray  []int32
+	LongArray []int64
+)
+
+func (Byte) TagType() byte      { return TagByte }
+func (Short) TagType() byte     { return TagShort }
+func (Int) TagType() byte       { return TagInt }
+func (Long) TagType() byte      { return TagLong }
+func (Float) TagType() byte     { return TagFloat }
+func (Double) TagType() byte    { return TagDouble }
+func (String) TagType() byte    { return TagString }
+func (ByteArray) TagType() byte { return TagByteArray }
+func (IntArray) TagType() byte  { return TagIntArray }
+func (LongArray) TagType() byte { return TagLongArray }
+
+// Compound is a TagCompound which keeps the order of its tags.
+// The zero value is an empty Compound ready to use.
+type Compound struct {
+	names []string
+	tags  map[string]Tag
+}
+
+// NewCompound return an empty Compound
+func NewCompound() *Compound { return new(Compound) }
+
+func (*Compound) TagType() byte { return TagCompound }
+
+// Put set the tag of the name and return the Compound itself for chaining.
+// A new name is appended at the end, while an existing one keeps its place.
+// It panics if v is nil.
+func (c *Compound) Put(name string, v Tag) *Compound {
+	if v == nil {
+		panic("nbt: put nil Tag into Compound")
+	}
+	if c.tags == nil {
+		c.tags = make(map[string]Tag)
+	}
+	if _, ok := c.tags[name]; !ok {
+		c.names = append(c.names, name)
+	}
+	c.tags[name] = v
+	return c
+}
+
+// Remove delete the tag of the name
+func (c *Compound) Remove(name string) {
+	if _, ok := c.tags[name]; !ok {
+		return
+	}
+	delete(c.tags, name)
+	for i, n := range c.names {
+		if n == name {
+			c.names = append(c.names[:i], c.names[i+1:]...)
+			break
+		}
+	}
+}
+
+// Names return the names of the tags in order
+func (c *Compound) Names() []string { return append([]string(nil), c.names...) }
+
+// Len return the number of tags
+func (c *Compound) Len() int { return len(c.names) }
+
+// Get return the tag at the path, such as Get("Item", "tag", "Damage").
+// Elements of a List are indexed by numbers, like Get("Inventory", "0", "id").
+func (c *Compound) Get(path ...string) (Tag, bool) { return getPath(c, path) }
+
+// Encode the Compound as a root tag with an empty name, which is the NBT
+// format used in packets before 1.20.2. See Encoder.NetworkFormat for the later.
+func (c *Compound) Encode() []byte {
+	var buf bytes.Buffer
+	_ = NewEncoder(&buf).writeNamedTag(c, "") // the only error is from the writer
+	return buf.Bytes()
+}
+
+// Unmarshal implement Unmarshaler
+func (c *Compound) Unmarshal(tagType byte, tagName string, r DecoderReader) error {
+	if tagType != TagCompound {
+		return fmt.Errorf("cannot parse tag 0x%02x as Compound", tagType)
+	}
+	*c = Compound{}
+	return (&Decoder{r: r}).readCompound(c)
+}
+
+// List is a TagList, whose elements are all of the same type.
+// The zero value is an empty List ready to use.
+type List struct {
+	elemType byte
+	elems    []Tag
+}
+
+// NewList return a List with the elements
+func NewList(elems ...Tag) *List {
+	l := new(List)
+	for _, v := range elems {
+		l.Add(v)
+	}
+	return l
+}
+
+func (*List) TagType() byte { return TagList }
+
+// Add append the tag and return the List itself for chaining.
+// It panics if v is nil or its type is different from the other elements.
+func (l *List) Add(v Tag) *List {
+	if v == nil {
+		panic("nbt: add nil Tag into List")
+	}
+	if len(l.elems) == 0 {
+		l.elemType = v.TagType()
+	} else if v.TagType() != l.elemType {
+		panic(fmt.Sprintf("nbt: add tag 0x%02x into List of 0x%02x", v.TagType(), l.elemType))
+	}
+	l.elems = append(l.elems, v)
+	return l
+}
+
+// ElemType return the type of the elements, TagEnd if the List is empty
+func (l *List) ElemType() byte { return l.elemType }
+
+// Len return the number of elements
+func (l *List) Len() int { return len(l.elems) }
+
+// Index return the element i
+func (l *List) Index(i int) Tag { return l.elems[i] }
+
+// Get return the tag at the path, see Compound.Get
+func (l *List) Get(path ...string) (Tag, bool) { return getPath(l, path) }
+
+// Unmarshal implement Unmarshaler
+func (l *List) Unmarshal(tagType byte, tagName string, r DecoderReader) error {
+	if tagType != TagList {
+		return fmt.Errorf("cannot parse tag 0x%02x as List", tagType)
+	}
+	*l = List{}
+	return (&Decoder{r: r}).readList(l)
+}
+
+func getPath(t Tag, path []string) (Tag, bool) {
+	for _, p := range path {
+		switch v := t.(type) {
+		case *Compound:
+			var ok bool
+			if t, ok = v.tags[p]; !ok {
+				return nil, false
+			}
+		case *List:
+			i, err := strconv.Atoi(p)
+			if err != nil || i < 0 || i >= len(v.elems) {
+				return nil, false
+			}
+			t = v.elems[i]
+		default:
+			return nil, false
+		}
+	}
+	return t, true
+}
+
+func (e *Encoder) writeNamedTag(t Tag, tagName string) error {
+	if err := e.writeTag(t.TagType(), tagName); err != nil {
+		return err
+	}
+	return e.writePayload(t)
+}
+
+func (e *Encoder) writePayload(t Tag) error {
+	switch v := t.(type) {
+	case Byte:
+		_, err := e.w.Write([]byte{byte(v)})
+		return err
+	case Short:
+		return e.writeInt16(int16(v))
+	case Int:
+		return e.writeInt32(int32(v))
+	case Long:
+		return e.writeInt64(int64(v))
+	case Float:
+		return e.writeInt32(int32(math.Float32bits(float32(v))))
+	case Double:
+		return e.writeInt64(int64(math.Float64bits(float64(v))))
+	case String:
+		if err := e.writeInt16(int16(len(v))); err != nil {
+			return err
+		}
+		_, err := io.WriteString(e.w, string(v))
+		return err
+	case ByteArray:
+		if err := e.writeInt32(int32(len(v))); err != nil {
+			return err
+		}
+		_, err := e.w.Write(v)
+		return err
+	case IntArray:
+		if err := e.writeInt32(int32(len(v))); err != nil {
+			return err
+		}
+		for _, n := range v {
+			if err := e.writeInt32(n); err != nil {
+				return err
+			}
+		}
+	case LongArray:
+		if err := e.writeInt32(int32(len(v))); err != nil {
+			return err
+		}
+		for _, n := range v {
+			if err := e.writeInt64(n); err != nil {
+				return err
+			}
+		}
+	case *List:
+		if _, err := e.w.Write([]byte{v.elemType}); err != nil {
+			return err
+		}
+		if err := e.writeInt32(int32(len(v.elems))); err != nil {
+			return err
+		}
+		for _, elem := range v.elems {
+			if err := e.writePayload(elem); err != nil {
+				return err
+			}
+		}
+	case *Compound:
+		for _, name := range v.names {
+			if err := e.writeNamedTag(v.tags[name], name); err != nil {
+				return err
+			}
+		}
+		_, err := e.w.Write([]byte{TagEnd})
+		return err
+	default:
+		return fmt.Errorf("unknown Tag type %T", t)
+	}
+	return nil
+}
+
+// maxPrealloc limits the memory allocated before the elements are actually read
+const maxPrealloc = 1024
+
+func (d *Decoder) readPayload(tagType byte) (Tag, error) {
+	switch tagType {
+	case TagByte:
+		b, err := d.r.ReadByte()
+		return Byte(b), err
+	case TagShort:
+		n, err := d.readInt16()
+		return Short(n), err
+	case TagInt:
+		n, err := d.readInt32()
+		return Int(n), err
+	case TagLong:
+		n, err := d.readInt64()
+		return Long(n), err
+	case TagFloat:
+		n, err := d.readInt32()
+		return Float(math.Float32frombits(uint32(n))), err
+	case TagDouble:
+		n, err := d.readInt64()
+		return Double(math.Float64frombits(uint64(n))), err
+	case TagString:
+		s, err := d.readString()
+		return String(s), err
+	case TagByteArray:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		_, err = io.CopyN(&buf, d.r, int64(n))
+		return ByteArray(buf.Bytes()), err
+	case TagIntArray:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		ary := make(IntArray, 0, min(n, maxPrealloc))
+		for i := 0; i < n; i++ {
+			v, err := d.readInt32()
+			if err != nil {
+				return nil, err
+			}
+			ary = append(ary, v)
+		}
+		return ary, nil
+	case TagLongArray:
+		n, err := d.readLength()
+		if err != nil {
+			return nil, err
+		}
+		ary := make(LongArray, 0, min(n, maxPrealloc))
+		for i := 0; i < n; i++ {
+			v, err := d.readInt64()
+			if err != nil {
+				return nil, err
+			}
+			ary = append(ary, v)
+		}
+		return ary, nil
+	case TagList:
+		l := new(List)
+		return l, d.readList(l)
+	case TagCompound:
+		c := new(Compound)
+		return c, d.readCompound(c)
+	case TagEnd:
+		return nil, ErrEND
+	}
+	return nil, fmt.Errorf("unknown Tag 0x%02x", tagType)
+}
+
+func (d *Decoder) readLength() (int, error) {
+	n, err := d.readInt32()
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, errors.New("array len less than 0")
+	}
+	return int(n), nil
+}
+
+func (d *Decoder) readList(l *List) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+	elemType, err := d.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	n, err := d.readInt32()
+	if err != nil {
+		return err
+	}
+	l.elemType = elemType // kept for empty lists to be written back the same
+	if n <= 0 {
+		return nil
+	}
+	if elemType == TagEnd {
+		return errors.New("list of TagEnd")
+	}
+	l.elems = make([]Tag, 0, min(int(n), maxPrealloc))
+	for i := 0; i < int(n); i++ {
+		v, err := d.readPayload(elemType)
+		if err != nil {
+			return fmt.Errorf("list[%d]: %w", i, err)
+		}
+		l.elems = append(l.elems, v)
+	}
+	return nil
+}
+
+func (d *Decoder) readCompound(c *Compound) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+	for {
+		tagType, tagName, err := d.readTag()
+		if err != nil {
+			return err
+		}
+		if tagType == TagEnd {
+			return nil
+		}
+		v, err := d.readPayload(tagType)
+		if err != nil {
+			return fmt.Errorf("%q: %w", tagName, err)
+		}
+		c.Put(tagName, v)
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,160 @@
+package nbt
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestCompound_Marshal(t *testing.T) {
+	c := NewCompound().
+		Put("Count", Byte(1)).
+		Put("id", String("minecraft:stone")).
+		Put("tag", NewCompound().
+			Put("Damage", Int(3)).
+			Put("Lore", NewList(String("a"), String("b"))))
+
+	var dyn, ref bytes.Buffer
+	if err := Marshal(&dyn, c); err != nil {
+		t.Fatal(err)
+	}
+	// write the same NBT by reflection, note that the fields are in order
+	if err := Marshal(&ref, struct {
+		Count byte
+		ID    string `nbt:"id"`
+		Tag   struct {
+			Damage int32
+			Lore   []string
+		} `nbt:"tag"`
+	}{Count: 1, ID: "minecraft:stone", Tag: struct {
+		Damage int32
+		Lore   []string
+	}{Damage: 3, Lore: []string{"a", "b"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dyn.Bytes(), ref.Bytes()) {
+		t.Errorf("dynamic marshal:\n% 02x\nwant:\n% 02x", dyn.Bytes(), ref.Bytes())
+	}
+	if !bytes.Equal(c.Encode(), dyn.Bytes()) {
+		t.Errorf("Encode:\n% 02x\nwant:\n% 02x", c.Encode(), dyn.Bytes())
+	}
+}
+
+func TestCompound_Put_replace(t *testing.T) {
+	c := NewCompound().Put("a", Int(1)).Put("b", Int(2)).Put("a", Int(3))
+	if names := c.Names(); !reflect.DeepEqual(names, []string{"a", "b"}) {
+		t.Errorf("names: %v", names)
+	}
+	if v, _ := c.Get("a"); v != Int(3) {
+		t.Errorf("a = %v, want 3", v)
+	}
+	c.Remove("a")
+	if names := c.Names(); !reflect.DeepEqual(names, []string{"b"}) {
+		t.Errorf("names after remove: %v", names)
+	}
+}
+
+func TestCompound_Get(t *testing.T) {
+	c := NewCompound().Put("Inventory", NewList(
+		NewCompound().Put("id", String("minecraft:dirt")),
+		NewCompound().Put("id", String("minecraft:stone")),
+	))
+	for _, tc := range []struct {
+		path []string
+		want Tag
+		ok   bool
+	}{
+		{[]string{"Inventory", "1", "id"}, String("minecraft:stone"), true},
+		{[]string{"Inventory", "2", "id"}, nil, false},
+		{[]string{"Inventory", "x"}, nil, false},
+		{[]string{"Inventory", "0", "id", "more"}, nil, false},
+		{[]string{"Missing"}, nil, false},
+		{nil, c, true},
+	} {
+		got, ok := c.Get(tc.path...)
+		if ok != tc.ok || got != tc.want {
+			t.Errorf("Get(%q) = %v, %v; want %v, %v", tc.path, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestList_Add_mismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("adding a tag of another type should panic")
+		}
+	}()
+	NewList(Int(1)).Add(Long(2))
+}
+
+func TestCompound_Unmarshal(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Marshal(&buf, struct {
+		Name      string
+		Health    float32
+		Data      []byte
+		Heights   []int64
+		Names     []string
+		Empty     []int32
+		Timestamp int64
+		Level     int16
+	}{
+		Name:      "Steve",
+		Health:    20,
+		Data:      []byte{1, 2, 3},
+		Heights:   []int64{4, 5},
+		Names:     []string{"a", "b"},
+		Empty:     []int32{7},
+		Timestamp: 1 << 40,
+		Level:     30,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var c Compound
+	if err := Unmarshal(buf.Bytes(), &c); err != nil {
+		t.Fatal(err)
+	}
+	want := NewCompound().
+		Put("Name", String("Steve")).
+		Put("Health", Float(20)).
+		Put("Data", ByteArray{1, 2, 3}).
+		Put("Heights", LongArray{4, 5}).
+		Put("Names", NewList(String("a"), String("b"))).
+		Put("Empty", IntArray{7}).
+		Put("Timestamp", Long(1<<40)).
+		Put("Level", Short(30))
+	if !reflect.DeepEqual(&c, want) {
+		t.Errorf("unmarshal: %#v\nwant: %#v", c, want)
+	}
+
+	// write it back
+	var out bytes.Buffer
+	if err := Marshal(&out, &c); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), buf.Bytes()) {
+		t.Errorf("round-trip:\n% 02x\nwant:\n% 02x", out.Bytes(), buf.Bytes())
+	}
+}
+
+func TestCompound_Unmarshal_field(t *testing.T) {
+	data := NewCompound().
+		Put("Name", String("Alex")).
+		Put("Extra", NewCompound().Put("Flag", Byte(1))).
+		Encode()
+
+	var v struct {
+		Name  string
+		Extra *Compound
+	}
+	if err := Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "Alex" {
+		t.Errorf("Name = %q", v.Name)
+	}
+	if f, ok := v.Extra.Get("Flag"); !ok || f != Byte(1) {
+		t.Errorf("Extra.Flag = %v, %v", f, ok)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"math"
 	"reflect"
+	"sort"
 )
 
 func Marshal(w io.Writer, v interface{}) error {
@@ -13,6 +14,9 @@ func Marshal(w io.Writer, v interface{}) error {
 
 type Encoder struct {
 	w io.Writer
+
+	networkFormat bool // the root tag has no name, see NetworkFormat
+	nameless      bool // the next tag written is the root in network format
 }
 
 func NewEncoder(w io.Writer) *Encoder {
@@ -21,10 +25,16 @@ func NewEncoder(w io.Writer) *Encoder {
 
 func (e *Encoder) Encode(v interface{}) error {
 	val := reflect.ValueOf(v)
+	e.nameless = e.networkFormat
 	return e.marshal(val, "")
 }
 
 func (e *Encoder) marshal(val reflect.Value, tagName string) error {
+	if val.CanInterface() {
+		if t, ok := val.Interface().(Tag); ok && !(val.Kind() == reflect.Ptr && val.IsNil()) {
+			return e.writeNamedTag(t, tagName)
+		}
+	}
 	switch vk := val.Kind(); vk {
 	default:
 		return errors.New("unknown type " + vk.String())
@@ -92,7 +102,7 @@ func (e *Encoder) marshal(val reflect.Value, tagName string) error {
 				}
 			}
 
-		case reflect.Int64:
+		case reflect.Int64, reflect.Uint64:
 			if err := e.writeTag(TagLongArray, tagName); err != nil {
 				return err
 			}
@@ -100,8 +110,16 @@ func (e *Encoder) marshal(val reflect.Value, tagName string) error {
 			if err := e.writeInt32(int32(n)); err != nil {
 				return err
 			}
+			isUint := val.Type().Elem().Kind() == reflect.Uint64
 			for i := 0; i < n; i++ {
-				if err := e.writeInt64(val.Index(i).Int()); err != nil {
+				v := val.Index(i)
+				var err error
+				if isUint {
+					err = e.writeInt64(int64(v.Uint()))
+				} else {
+					err = e.writeInt64(v.Int())
+				}
+				if err != nil {
 					return err
 				}
 			}
@@ -195,22 +213,42 @@ func (e *Encoder) marshal(val reflect.Value, tagName string) error {
 		_, err := e.w.Write([]byte(val.String()))
 		return err
 
+	case reflect.Interface, reflect.Ptr:
+		if val.IsNil() {
+			return errors.New("cannot marshal nil " + val.Type().String())
+		}
+		return e.marshal(val.Elem(), tagName)
+
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			return errors.New("cannot marshal " + val.Type().String() + " as TagCompound")
+		}
+		if err := e.writeTag(TagCompound, tagName); err != nil {
+			return err
+		}
+
+		// sort the keys, make the output deterministic
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			if err := e.marshal(val.MapIndex(k), k.String()); err != nil {
+				return err
+			}
+		}
+		_, err := e.w.Write([]byte{TagEnd})
+		return err
+
 	case reflect.Struct:
-		if err := e.writeTag(TagCompound, ""); err != nil {
+		// fields are written in the order they declared
+		if err := e.writeTag(TagCompound, tagName); err != nil {
 			return err
 		}
 
 		n := val.NumField()
 		for i := 0; i < n; i++ {
-			f := val.Type().Field(i)
-			tag := f.Tag.Get("nbt")
-			if (f.PkgPath != "" && !f.Anonymous) || tag == "-" {
-				continue // Private field
-			}
-
-			tagName := f.Name
-			if tag != "" {
-				tagName = tag
+			tagName, omitEmpty, ok := parseTag(val.Type().Field(i))
+			if !ok || omitEmpty && isEmptyValue(val.Field(i)) {
+				continue // Private or empty field
 			}
 
 			err := e.marshal(val.Field(i), tagName)
@@ -228,6 +266,10 @@ func (e *Encoder) writeTag(tagType byte, tagName string) error {
 	if _, err := e.w.Write([]byte{tagType}); err != nil {
 		return err
 	}
+	if e.nameless {
+		e.nameless = false
+		return nil
+	}
 	bName := []byte(tagName)
 	if err := e.writeInt16(int16(len(bName))); err != nil {
 		return err
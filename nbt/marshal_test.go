@@ -3,6 +3,7 @@ package nbt
 import (
 	"bytes"
 	"math"
+	"reflect"
 	"testing"
 )
 
@@ -55,3 +56,237 @@ func TestMarshal_FloatArray(t *testing.T) {
 		t.Errorf("output binary not right: get % 02x, want % 02x ", buf.Bytes(), out)
 	}
 }
+
+func TestMarshal_LongArray(t *testing.T) {
+	type heightmaps struct {
+		MotionBlocking []int64 `nbt:"MOTION_BLOCKING"`
+	}
+	large := make([]int64, 4096)
+	for i := range large {
+		large[i] = int64(i) * -0x0102030405060708
+	}
+
+	for _, ary := range [][]int64{{}, {1, -1, math.MaxInt64, math.MinInt64}, large} {
+		var buf bytes.Buffer
+		if err := Marshal(&buf, heightmaps{ary}); err != nil {
+			t.Fatal(err)
+		}
+		// TagCompound "" { TagLongArray "MOTION_BLOCKING" }
+		if b := buf.Bytes(); b[3] != TagLongArray {
+			t.Fatalf("tag type should be TagLongArray, get 0x%02x", b[3])
+		}
+
+		var got heightmaps
+		if err := Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got.MotionBlocking, ary) {
+			t.Errorf("round trip fail: get %v, want %v", got.MotionBlocking, ary)
+		}
+	}
+}
+
+func TestMarshal_UnsignedLongArray(t *testing.T) {
+	v := []uint64{0, math.MaxUint64}
+	out := []byte{TagLongArray, 0x00, 0x00, 0, 0, 0, 2,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	}
+	var buf bytes.Buffer
+	if err := Marshal(&buf, v); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(buf.Bytes(), out) {
+		t.Errorf("output binary not right: get % 02x, want % 02x ", buf.Bytes(), out)
+	}
+
+	var got []uint64
+	if err := Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("parse fail, expect %v, get %v", v, got)
+	}
+}
+
+func TestMarshal_deterministic(t *testing.T) {
+	type display struct {
+		Name string
+		Lore []string
+	}
+	v := struct {
+		Count  byte                   `nbt:"Count"`
+		ID     string                 `nbt:"id"`
+		Tag    map[string]interface{} `nbt:"tag"`
+		Damage int32
+	}{
+		Count: 1,
+		ID:    "minecraft:diamond_sword",
+		Tag: map[string]interface{}{
+			"display":     display{Name: "sword", Lore: []string{"a"}},
+			"RepairCost":  int32(1),
+			"Unbreakable": byte(1),
+			"HideFlags":   int32(0),
+			"Enchantments": map[string]int16{
+				"minecraft:unbreaking": 3,
+				"minecraft:sharpness":  5,
+			},
+		},
+		Damage: 12,
+	}
+
+	var first []byte
+	for i := 0; i < 20; i++ {
+		var buf bytes.Buffer
+		if err := Marshal(&buf, v); err != nil {
+			t.Fatal(err)
+		}
+		if first == nil {
+			first = buf.Bytes()
+		} else if !bytes.Equal(first, buf.Bytes()) {
+			t.Fatalf("output changed between runs:\n% 02x\n% 02x", first, buf.Bytes())
+		}
+	}
+
+	// check order of tags
+	d := NewDecoder(bytes.NewReader(first))
+	var names []string
+	for depth := 0; ; {
+		tok, err := d.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, tok.Name)
+		switch tok.Type {
+		case TagCompound:
+			depth++
+		case TagEnd:
+			depth--
+		default:
+			err = d.Skip()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	want := []string{
+		"", "Count", "id", "tag",
+		"Enchantments", "minecraft:sharpness", "minecraft:unbreaking", "",
+		"HideFlags", "RepairCost", "Unbreakable",
+		"display", "Name", "Lore", "",
+		"", "Damage", "",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("tag order should be\n%q\nget\n%q", want, names)
+	}
+}
+
+func TestMarshal_nestedStruct(t *testing.T) {
+	type inner struct {
+		Name string
+	}
+	type outer struct {
+		Inner inner `nbt:"inner"`
+		Ptr   *inner
+	}
+	v := outer{Inner: inner{"a"}, Ptr: &inner{"b"}}
+
+	var buf bytes.Buffer
+	if err := Marshal(&buf, v); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Inner inner `nbt:"inner"`
+		Ptr   inner
+	}
+	if err := Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Inner.Name != "a" || got.Ptr.Name != "b" {
+		t.Errorf("round trip fail: %+v", got)
+	}
+}
+
+func TestMarshal_omitEmpty(t *testing.T) {
+	type display struct {
+		Name string
+	}
+	type item struct {
+		ID         string                 `nbt:"id"`
+		CustomName string                 `nbt:"CustomName,omitempty"`
+		Count      byte                   `nbt:"Count,omitempty"`
+		Damage     int32                  `nbt:",omitempty"`
+		Speed      float32                `nbt:"speed,omitempty"`
+		Lore       []string               `nbt:"Lore,omitempty"`
+		Colors     []int32                `nbt:"Colors,omitempty"`
+		Tag        map[string]interface{} `nbt:"tag,omitempty"`
+		Display    *display               `nbt:"display,omitempty"`
+		Ignored    string                 `nbt:"-"`
+	}
+	emptyItem := []byte{TagCompound, 0x00, 0x00,
+		TagString, 0x00, 0x02, 'i', 'd', 0x00, 0x01, 'a',
+		TagEnd,
+	}
+
+	for _, v := range []item{
+		{ID: "a"},
+		{ID: "a", Lore: []string{}, Colors: []int32{}, Tag: map[string]interface{}{}},
+		{ID: "a", Ignored: "ignored"},
+	} {
+		var buf bytes.Buffer
+		if err := Marshal(&buf, v); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(buf.Bytes(), emptyItem) {
+			t.Errorf("output binary not right: get % 02x, want % 02x ", buf.Bytes(), emptyItem)
+		}
+	}
+
+	// non-empty fields are written
+	v := item{
+		ID: "a", CustomName: "b", Count: 1, Damage: 2, Speed: 0.5,
+		Lore: []string{"c"}, Colors: []int32{3},
+		Tag:     map[string]interface{}{"d": int32(4)},
+		Display: &display{},
+	}
+	var buf bytes.Buffer
+	if err := Marshal(&buf, v); err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(&buf)
+	var names []string
+	if _, err := d.Token(); err != nil { // root
+		t.Fatal(err)
+	}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.Type == TagEnd {
+			break
+		}
+		names = append(names, tok.Name)
+		if err := d.Skip(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"id", "CustomName", "Count", "Damage", "speed", "Lore", "Colors", "tag", "display"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("tags should be %q, get %q", want, names)
+	}
+
+	// the option shouldn't be a part of tag name when unmarshal
+	buf.Reset()
+	if err := Marshal(&buf, v); err != nil {
+		t.Fatal(err)
+	}
+	var got item
+	if err := Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.CustomName != "b" || got.Count != 1 || got.Damage != 2 {
+		t.Errorf("unmarshal fail: %+v", got)
+	}
+}
@@ -29,7 +29,20 @@ type DecoderReader = interface {
 	io.Reader
 }
 type Decoder struct {
-	r DecoderReader
+	r   DecoderReader
+	buf [8]byte // reused when reading numbers, avoid allocating
+
+	// the tag whose header is read by Token but payload isn't
+	pending     bool
+	pendingType byte
+	pendingName string
+
+	// names of the tags being decoded, for the path in error messages
+	path []string
+
+	depth int // the nesting of Lists and Compounds being decoded, see maxDepth
+
+	networkFormat bool // the root tag has no name, see NetworkFormat
 }
 
 func NewDecoder(r io.Reader) *Decoder {
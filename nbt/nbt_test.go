@@ -3,6 +3,7 @@ package nbt
 import (
 	"bytes"
 	"compress/gzip"
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -323,3 +324,105 @@ func TestUnmarshal_ErrorString(t *testing.T) {
 	t.Log(err)
 
 }
+
+func TestUnmarshal_typeErrorPath(t *testing.T) {
+	sections := NewList()
+	for i := 0; i < 4; i++ {
+		sections.Add(NewCompound().Put("Y", Byte(i)))
+	}
+	sections.Index(3).(*Compound).Put("BlockStates", LongArray{1, 2, 3})
+	data := NewCompound().
+		Put("Level", NewCompound().
+			Put("xPos", Int(1)).
+			Put("Sections", sections)).
+		Encode()
+
+	var value struct {
+		Level struct {
+			XPos     int32 `nbt:"xPos"`
+			Sections []struct {
+				Y           byte
+				BlockStates []int32
+			}
+		}
+	}
+	err := Unmarshal(data, &value)
+	want := "nbt: Level.Sections[3].BlockStates: cannot decode TAG_Long_Array into []int32"
+	if err == nil || err.Error() != want {
+		t.Fatalf("error: %v, want %q", err, want)
+	}
+	var typeErr *UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatal("error should be an UnmarshalTypeError")
+	}
+	if typeErr.TagType != TagLongArray || typeErr.Type != reflect.TypeOf([]int32(nil)) {
+		t.Errorf("UnmarshalTypeError: %#v", typeErr)
+	}
+
+	// the path must not leak into the next decoding
+	var wrong struct{ Level int32 }
+	err = Unmarshal(data, &wrong)
+	want = "nbt: Level: cannot decode TAG_Compound into int32"
+	if err == nil || err.Error() != want {
+		t.Errorf("error: %v, want %q", err, want)
+	}
+}
+
+// TestUnmarshal_badLength decode the arrays and lists declaring a huge length
+// but having few elements, which fail without allocating the length
+func TestUnmarshal_badLength(t *testing.T) {
+	for _, data := range [][]byte{
+		{TagByteArray, 0, 0, 0x7f, 0xff, 0xff, 0xff, 0x01},
+		{TagIntArray, 0, 0, 0x7f, 0xff, 0xff, 0xff, 0, 0, 0, 1},
+		{TagLongArray, 0, 0, 0x7f, 0xff, 0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 1},
+		{TagList, 0, 0, TagInt, 0x7f, 0xff, 0xff, 0xff, 0, 0, 0, 1},
+	} {
+		var v interface{}
+		if err := Unmarshal(data, &v); err == nil {
+			t.Errorf("decode % x into interface: no error", data[:3])
+		}
+	}
+	var ints []int32
+	if err := Unmarshal([]byte{TagList, 0, 0, TagInt, 0x7f, 0xff, 0xff, 0xff, 0, 0, 0, 1}, &ints); err == nil {
+		t.Error("decode list into slice: no error")
+	}
+}
+
+func TestUnmarshal_tooDeep(t *testing.T) {
+	nested := func(depth int) []byte {
+		data := []byte{TagList, 0, 0}
+		for i := 1; i < depth; i++ {
+			data = append(data, TagList, 0, 0, 0, 1) // a list of one list
+		}
+		return append(data, TagEnd, 0, 0, 0, 0)
+	}
+
+	var v interface{}
+	if err := Unmarshal(nested(maxDepth), &v); err != nil {
+		t.Errorf("decode %d lists: %v", maxDepth, err)
+	}
+	if err := Unmarshal(nested(maxDepth+1), &v); err == nil {
+		t.Errorf("decode %d lists: no error", maxDepth+1)
+	}
+
+	// the dynamic tags and the skipped ones are limited too
+	inCompound := func(depth int) []byte {
+		return append(append([]byte{TagCompound, 0, 0}, nested(depth-1)...), TagEnd)
+	}
+	var (
+		c Compound
+		s struct{}
+	)
+	if err := Unmarshal(inCompound(maxDepth), &c); err != nil {
+		t.Errorf("decode the Compound of %d lists: %v", maxDepth-1, err)
+	}
+	if err := Unmarshal(inCompound(maxDepth+1), &c); err == nil {
+		t.Errorf("decode the Compound of %d lists: no error", maxDepth)
+	}
+	if err := Unmarshal(inCompound(maxDepth), &s); err != nil {
+		t.Errorf("skip %d lists: %v", maxDepth-1, err)
+	}
+	if err := Unmarshal(inCompound(maxDepth+1), &s); err == nil {
+		t.Errorf("skip %d lists: no error", maxDepth)
+	}
+}
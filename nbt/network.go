@@ -0,0 +1,34 @@
+package nbt
+
+import (
+	"bytes"
+	"io"
+)
+
+// Since 1.20.2 (protocol 764), the NBT sent in packets has a nameless root
+// tag: the type byte of the root is followed by its payload directly,
+// without the length and bytes of the name. The NBT files keep the named root.
+
+// NetworkUnmarshal decode the NBT of network format in data into v
+func NetworkUnmarshal(data []byte, v interface{}) error {
+	return NewDecoder(bytes.NewReader(data)).NetworkFormat(true).Decode(v)
+}
+
+// NetworkMarshal encode v into w as NBT of network format
+func NetworkMarshal(w io.Writer, v interface{}) error {
+	return NewEncoder(w).NetworkFormat(true).Encode(v)
+}
+
+// NetworkFormat set whether the root tags decoded by Decode are nameless,
+// the network format since 1.20.2. Token isn't affected. It returns d.
+func (d *Decoder) NetworkFormat(nameless bool) *Decoder {
+	d.networkFormat = nameless
+	return d
+}
+
+// NetworkFormat set whether the root tags encoded by Encode are nameless,
+// the network format since 1.20.2. It returns e.
+func (e *Encoder) NetworkFormat(nameless bool) *Encoder {
+	e.networkFormat = nameless
+	return e
+}
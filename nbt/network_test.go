@@ -0,0 +1,85 @@
+package nbt
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type networkTestItem struct {
+	ID    string `nbt:"id"`
+	Count byte
+}
+
+var (
+	networkTestValue = networkTestItem{ID: "minecraft:stone", Count: 64}
+	// the root compound without name
+	networkTestData = []byte{
+		TagCompound,
+		TagString, 0x00, 0x02, 'i', 'd', 0x00, 0x0f,
+		'm', 'i', 'n', 'e', 'c', 'r', 'a', 'f', 't', ':', 's', 't', 'o', 'n', 'e',
+		TagByte, 0x00, 0x05, 'C', 'o', 'u', 'n', 't', 64,
+		TagEnd,
+	}
+)
+
+func TestNetworkMarshal(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NetworkMarshal(&buf, networkTestValue); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), networkTestData) {
+		t.Errorf("got  [% x]\nwant [% x]", buf.Bytes(), networkTestData)
+	}
+
+	// the dynamic tags
+	c := NewCompound().Put("id", String("minecraft:stone")).Put("Count", Byte(64))
+	buf.Reset()
+	if err := NetworkMarshal(&buf, c); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), networkTestData) {
+		t.Errorf("Compound: got [% x]", buf.Bytes())
+	}
+
+	// the file format keeps the name of the root
+	buf.Reset()
+	if err := Marshal(&buf, networkTestValue); err != nil {
+		t.Fatal(err)
+	}
+	if want := append([]byte{TagCompound, 0x00, 0x00}, networkTestData[1:]...); !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("file format: got [% x]", buf.Bytes())
+	}
+}
+
+func TestNetworkUnmarshal(t *testing.T) {
+	var v networkTestItem
+	if err := NetworkUnmarshal(networkTestData, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v != networkTestValue {
+		t.Errorf("got %+v, want %+v", v, networkTestValue)
+	}
+
+	var c Compound
+	if err := NetworkUnmarshal(networkTestData, &c); err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := c.Get("id"); id != String("minecraft:stone") {
+		t.Errorf("Compound: id is %v", id)
+	}
+
+	// the roots of both formats in a stream
+	var buf bytes.Buffer
+	_ = Marshal(&buf, networkTestValue)
+	d := NewDecoder(bytes.NewReader(append(buf.Bytes(), networkTestData...)))
+	for _, network := range []bool{false, true} {
+		var v networkTestItem
+		if err := d.NetworkFormat(network).Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(v, networkTestValue) {
+			t.Errorf("network %v: got %+v", network, v)
+		}
+	}
+}
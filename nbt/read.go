@@ -8,6 +8,8 @@ import (
 	"io/ioutil"
 	"math"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 func Unmarshal(data []byte, v interface{}) error {
@@ -20,19 +22,39 @@ func (d *Decoder) Decode(v interface{}) error {
 		return errors.New("nbt: non-pointer passed to Unmarshal")
 	}
 
-	//start read NBT
-	tagType, tagName, err := d.readTag()
-	if err != nil {
-		return fmt.Errorf("nbt: %w", err)
-	}
+	var tagType byte
+	var tagName string
+	if d.pending { // decode the tag returned by Token
+		tagType, tagName = d.pendingType, d.pendingName
+		d.pending = false
+	} else { //start read NBT
+		var err error
+		if d.networkFormat {
+			tagType, err = d.r.ReadByte()
+		} else {
+			tagType, tagName, err = d.readTag()
+		}
+		if err != nil {
+			return fmt.Errorf("nbt: %w", err)
+		}
 
-	if c := d.checkCompressed(tagType); c != "" {
-		return fmt.Errorf("nbt: unknown Tag, maybe need %s", c)
+		if c := d.checkCompressed(tagType); c != "" {
+			return fmt.Errorf("nbt: unknown Tag, maybe need %s", c)
+		}
 	}
 
-	err = d.unmarshal(val.Elem(), tagType, tagName)
+	err := d.unmarshal(val.Elem(), tagType, tagName)
 	if err != nil {
-		return fmt.Errorf("nbt: fail to decode tag %q: %w", tagName, err)
+		path := d.pathString()
+		d.path = d.path[:0]
+		var typeErr *UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return fmt.Errorf("nbt: %w", err)
+		}
+		if path == "" {
+			path = tagName
+		}
+		return fmt.Errorf("nbt: fail to decode tag %q: %w", path, err)
 	}
 	return nil
 }
@@ -50,12 +72,94 @@ func (d *Decoder) checkCompressed(head byte) (compress string) {
 // ErrEND error will be returned when reading a NBT with only Tag_End
 var ErrEND = errors.New("unexpected TAG_End")
 
+// An UnmarshalTypeError describes a tag that cannot be decoded into the Go value
+type UnmarshalTypeError struct {
+	Path    string // the path of the tag, such as Level.Sections[3].BlockStates
+	TagType byte
+	Type    reflect.Type
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	msg := "cannot decode " + tagTypeName(e.TagType) + " into " + e.Type.String()
+	if e.Path == "" {
+		return msg
+	}
+	return e.Path + ": " + msg
+}
+
+var tagTypeNames = [...]string{
+	TagEnd:       "TAG_End",
+	TagByte:      "TAG_Byte",
+	TagShort:     "TAG_Short",
+	TagInt:       "TAG_Int",
+	TagLong:      "TAG_Long",
+	TagFloat:     "TAG_Float",
+	TagDouble:    "TAG_Double",
+	TagByteArray: "TAG_Byte_Array",
+	TagString:    "TAG_String",
+	TagList:      "TAG_List",
+	TagCompound:  "TAG_Compound",
+	TagIntArray:  "TAG_Int_Array",
+	TagLongArray: "TAG_Long_Array",
+}
+
+func tagTypeName(tagType byte) string {
+	if int(tagType) < len(tagTypeNames) {
+		return tagTypeNames[tagType]
+	}
+	return fmt.Sprintf("Tag 0x%02x", tagType)
+}
+
+func (d *Decoder) typeError(tagType byte, t reflect.Type) error {
+	return &UnmarshalTypeError{Path: d.pathString(), TagType: tagType, Type: t}
+}
+
+// pathString join the names in d.path, list indexes are appended without dots
+func (d *Decoder) pathString() string {
+	var sb strings.Builder
+	for i, name := range d.path {
+		if i > 0 && !strings.HasPrefix(name, "[") {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(name)
+	}
+	return sb.String()
+}
+
+// unmarshalChild decode a tag inside a TagCompound or TagList.
+// The path is left unpopped on error so the outermost caller can report it.
+func (d *Decoder) unmarshalChild(val reflect.Value, tagType byte, tagName, path string) error {
+	d.path = append(d.path, path)
+	if err := d.unmarshal(val, tagType, tagName); err != nil {
+		return err
+	}
+	d.path = d.path[:len(d.path)-1]
+	return nil
+}
+
 func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) error {
-	if val.CanInterface() {
+	if val.CanInterface() && !(val.Kind() == reflect.Ptr && val.IsNil()) {
 		if i, ok := val.Interface().(Unmarshaler); ok {
 			return i.Unmarshal(tagType, tagName, d.r)
 		}
 	}
+	if val.Kind() == reflect.Ptr && tagType != TagEnd {
+		if val.IsNil() {
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+		return d.unmarshal(val.Elem(), tagType, tagName)
+	}
+	if val.CanAddr() {
+		if i, ok := val.Addr().Interface().(Unmarshaler); ok {
+			return i.Unmarshal(tagType, tagName, d.r)
+		}
+	}
+	if tagType == TagList || tagType == TagCompound {
+		if err := d.enter(); err != nil {
+			return err
+		}
+		defer d.leave()
+	}
 
 	switch tagType {
 	default:
@@ -70,7 +174,7 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 		}
 		switch vk := val.Kind(); vk {
 		default:
-			return errors.New("cannot parse TagByte as " + vk.String())
+			return d.typeError(tagType, val.Type())
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 			val.SetInt(int64(value))
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
@@ -86,7 +190,7 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 		}
 		switch vk := val.Kind(); vk {
 		default:
-			return errors.New("cannot parse TagShort as " + vk.String())
+			return d.typeError(tagType, val.Type())
 		case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64:
 			val.SetInt(int64(value))
 		case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
@@ -102,7 +206,7 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 		}
 		switch vk := val.Kind(); vk {
 		default:
-			return errors.New("cannot parse TagInt as " + vk.String())
+			return d.typeError(tagType, val.Type())
 		case reflect.Int, reflect.Int32, reflect.Int64:
 			val.SetInt(int64(value))
 		case reflect.Uint, reflect.Uint32, reflect.Uint64:
@@ -119,7 +223,7 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 		value := math.Float32frombits(uint32(vInt))
 		switch vk := val.Kind(); vk {
 		default:
-			return errors.New("cannot parse TagFloat as " + vk.String())
+			return d.typeError(tagType, val.Type())
 		case reflect.Float32:
 			val.Set(reflect.ValueOf(value))
 		case reflect.Float64:
@@ -135,7 +239,7 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 		}
 		switch vk := val.Kind(); vk {
 		default:
-			return errors.New("cannot parse TagLong as " + vk.String())
+			return d.typeError(tagType, val.Type())
 		case reflect.Int, reflect.Int64:
 			val.SetInt(int64(value))
 		case reflect.Uint, reflect.Uint64:
@@ -153,7 +257,7 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 
 		switch vk := val.Kind(); vk {
 		default:
-			return errors.New("cannot parse TagDouble as " + vk.String())
+			return d.typeError(tagType, val.Type())
 		case reflect.Float64:
 			val.Set(reflect.ValueOf(value))
 		case reflect.Interface:
@@ -167,7 +271,7 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 		}
 		switch vk := val.Kind(); vk {
 		default:
-			return errors.New("cannot parse TagString as " + vk.String())
+			return d.typeError(tagType, val.Type())
 		case reflect.String:
 			val.SetString(s)
 		case reflect.Interface:
@@ -182,14 +286,19 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 		if aryLen < 0 {
 			return errors.New("byte array len less than 0")
 		}
-		ba := make([]byte, aryLen)
-		if _, err = io.ReadFull(d.r, ba); err != nil {
+		// the buffer grows as the bytes are read, not by the length
+		var buf bytes.Buffer
+		if _, err = io.CopyN(&buf, d.r, int64(aryLen)); err != nil {
 			return err
 		}
+		ba := buf.Bytes()
+		if ba == nil {
+			ba = []byte{}
+		}
 
 		switch vt := val.Type(); {
 		default:
-			return errors.New("cannot parse TagByteArray to " + vt.String() + ", use []byte in this instance")
+			return d.typeError(tagType, vt)
 		case vt == reflect.TypeOf(ba):
 			val.SetBytes(ba)
 		case vt.Kind() == reflect.Interface:
@@ -201,21 +310,25 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 		if err != nil {
 			return err
 		}
+		if aryLen < 0 {
+			return errors.New("int array len less than 0")
+		}
 		vt := val.Type() //receiver must be []int or []int32
 		if vt.Kind() == reflect.Interface {
 			vt = reflect.TypeOf([]int32{}) // pass
 		} else if vt.Kind() != reflect.Slice {
-			return errors.New("cannot parse TagIntArray to " + vt.String() + ", it must be a slice")
+			return d.typeError(tagType, vt)
 		} else if tk := val.Type().Elem().Kind(); tk != reflect.Int && tk != reflect.Int32 {
-			return errors.New("cannot parse TagIntArray to " + vt.String())
+			return d.typeError(tagType, vt)
 		}
 
-		buf := reflect.MakeSlice(vt, int(aryLen), int(aryLen))
+		buf := reflect.MakeSlice(vt, 0, min(int(aryLen), maxPrealloc))
 		for i := 0; i < int(aryLen); i++ {
 			value, err := d.readInt32()
 			if err != nil {
 				return err
 			}
+			buf = reflect.Append(buf, reflect.Zero(vt.Elem()))
 			buf.Index(i).SetInt(int64(value))
 		}
 		val.Set(buf)
@@ -225,22 +338,31 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 		if err != nil {
 			return err
 		}
-		vt := val.Type() //receiver must be []int or []int64
+		if aryLen < 0 {
+			return errors.New("long array len less than 0")
+		}
+		vt := val.Type() //receiver must be []int64 or []uint64
 		if vt.Kind() == reflect.Interface {
 			vt = reflect.TypeOf([]int64{}) // pass
 		} else if vt.Kind() != reflect.Slice {
-			return errors.New("cannot parse TagLongArray to " + vt.String() + ", it must be a slice")
-		} else if val.Type().Elem().Kind() != reflect.Int64 {
-			return errors.New("cannot parse TagLongArray to " + vt.String())
+			return d.typeError(tagType, vt)
+		} else if tk := val.Type().Elem().Kind(); tk != reflect.Int64 && tk != reflect.Uint64 {
+			return d.typeError(tagType, vt)
 		}
 
-		buf := reflect.MakeSlice(vt, int(aryLen), int(aryLen))
+		buf := reflect.MakeSlice(vt, 0, min(int(aryLen), maxPrealloc))
+		isUint := vt.Elem().Kind() == reflect.Uint64
 		for i := 0; i < int(aryLen); i++ {
 			value, err := d.readInt64()
 			if err != nil {
 				return err
 			}
-			buf.Index(i).SetInt(value)
+			buf = reflect.Append(buf, reflect.Zero(vt.Elem()))
+			if isUint { // the packed block states in chunks are usually treated as uint64
+				buf.Index(i).SetUint(uint64(value))
+			} else {
+				buf.Index(i).SetInt(value)
+			}
 		}
 		val.Set(buf)
 
@@ -257,17 +379,18 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 			return errors.New("list length less than 0")
 		}
 
-		// If we need parse TAG_List into slice, make a new with right length.
+		// If we need parse TAG_List into slice, make a new one growing as the
+		// elements are read, so a bad length doesn't allocate much memory.
 		// Otherwise if we need parse into array, we check if len(array) are enough.
 		var buf reflect.Value
 		vk := val.Kind()
 		switch vk {
 		default:
-			return errors.New("cannot parse TagList as " + vk.String())
+			return d.typeError(tagType, val.Type())
 		case reflect.Interface:
-			buf = reflect.ValueOf(make([]interface{}, listLen))
+			buf = reflect.ValueOf(make([]interface{}, 0, min(int(listLen), maxPrealloc)))
 		case reflect.Slice:
-			buf = reflect.MakeSlice(val.Type(), int(listLen), int(listLen))
+			buf = reflect.MakeSlice(val.Type(), 0, min(int(listLen), maxPrealloc))
 		case reflect.Array:
 			if vl := val.Len(); vl < int(listLen) {
 				return fmt.Errorf(
@@ -277,7 +400,10 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 			buf = val
 		}
 		for i := 0; i < int(listLen); i++ {
-			if err := d.unmarshal(buf.Index(i), listType, ""); err != nil {
+			if vk != reflect.Array {
+				buf = reflect.Append(buf, reflect.Zero(buf.Type().Elem()))
+			}
+			if err := d.unmarshalChild(buf.Index(i), listType, "", "["+strconv.Itoa(i)+"]"); err != nil {
 				return err
 			}
 		}
@@ -289,7 +415,7 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 	case TagCompound:
 		switch vk := val.Kind(); vk {
 		default:
-			return errors.New("cannot parse TagCompound as " + vk.String())
+			return d.typeError(tagType, val.Type())
 		case reflect.Struct:
 			tinfo := getTypeInfo(val.Type())
 			for {
@@ -302,9 +428,8 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 				}
 				field := tinfo.findIndexByName(tn)
 				if field != -1 {
-					err = d.unmarshal(val.Field(field), tt, tn)
-					if err != nil {
-						return fmt.Errorf("fail to decode tag %q: %w", tn, err)
+					if err := d.unmarshalChild(val.Field(field), tt, tn, tn); err != nil {
+						return err
 					}
 				} else {
 					if err := d.rawRead(tt); err != nil {
@@ -314,7 +439,7 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 			}
 		case reflect.Map:
 			if val.Type().Key().Kind() != reflect.String {
-				return errors.New("cannot parse TagCompound as " + val.Type().String())
+				return d.typeError(tagType, val.Type())
 			}
 			if val.IsNil() {
 				val.Set(reflect.MakeMap(val.Type()))
@@ -328,8 +453,8 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 					break
 				}
 				v := reflect.New(val.Type().Elem())
-				if err = d.unmarshal(v.Elem(), tt, tn); err != nil {
-					return fmt.Errorf("fail to decode tag %q: %w", tn, err)
+				if err = d.unmarshalChild(v.Elem(), tt, tn, tn); err != nil {
+					return err
 				}
 				val.SetMapIndex(reflect.ValueOf(tn), v.Elem())
 			}
@@ -344,8 +469,8 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 					break
 				}
 				var value interface{}
-				if err = d.unmarshal(reflect.ValueOf(&value).Elem(), tt, tn); err != nil {
-					return fmt.Errorf("fail to decode tag %q: %w", tn, err)
+				if err = d.unmarshalChild(reflect.ValueOf(&value).Elem(), tt, tn, tn); err != nil {
+					return err
 				}
 				buf[tn] = value
 			}
@@ -356,8 +481,29 @@ func (d *Decoder) unmarshal(val reflect.Value, tagType byte, tagName string) err
 	return nil
 }
 
+// maxDepth is the max nesting of Lists and Compounds, the same as vanilla.
+// Deeper tags are rejected, or decoding them could overflow the stack.
+const maxDepth = 512
+
+// enter a List or Compound, it should be paired with leave
+func (d *Decoder) enter() error {
+	if d.depth >= maxDepth {
+		return fmt.Errorf("tags nested deeper than %d", maxDepth)
+	}
+	d.depth++
+	return nil
+}
+
+func (d *Decoder) leave() { d.depth-- }
+
+// rawRead skip the payload of a tag without allocating
 func (d *Decoder) rawRead(tagType byte) error {
-	var buf [8]byte
+	if tagType == TagList || tagType == TagCompound {
+		if err := d.enter(); err != nil {
+			return err
+		}
+		defer d.leave()
+	}
 	switch tagType {
 	default:
 		return fmt.Errorf("unknown to read 0x%02x", tagType)
@@ -365,47 +511,28 @@ func (d *Decoder) rawRead(tagType byte) error {
 		_, err := d.r.ReadByte()
 		return err
 	case TagString:
-		_, err := d.readString()
-		return err
+		return d.skipString()
 	case TagShort:
-		_, err := io.ReadFull(d.r, buf[:2])
-		return err
+		return d.skip(2)
 	case TagInt, TagFloat:
-		_, err := io.ReadFull(d.r, buf[:4])
-		return err
+		return d.skip(4)
 	case TagLong, TagDouble:
-		_, err := io.ReadFull(d.r, buf[:8])
-		return err
-	case TagByteArray:
-		aryLen, err := d.readInt32()
-		if err != nil {
-			return err
-		}
-
-		if _, err = io.CopyN(ioutil.Discard, d.r, int64(aryLen)); err != nil {
-			return err
-		}
-	case TagIntArray:
+		return d.skip(8)
+	case TagByteArray, TagIntArray, TagLongArray:
 		aryLen, err := d.readInt32()
 		if err != nil {
 			return err
 		}
-		for i := 0; i < int(aryLen); i++ {
-			if _, err := d.readInt32(); err != nil {
-				return err
-			}
-		}
-
-	case TagLongArray:
-		aryLen, err := d.readInt32()
-		if err != nil {
-			return err
+		if aryLen < 0 {
+			return errors.New("array len less than 0")
 		}
-		for i := 0; i < int(aryLen); i++ {
-			if _, err := d.readInt64(); err != nil {
-				return err
-			}
+		size := int64(1)
+		if tagType == TagIntArray {
+			size = 4
+		} else if tagType == TagLongArray {
+			size = 8
 		}
+		return d.skip(int64(aryLen) * size)
 
 	case TagList:
 		listType, err := d.r.ReadByte()
@@ -423,15 +550,17 @@ func (d *Decoder) rawRead(tagType byte) error {
 		}
 	case TagCompound:
 		for {
-			tt, _, err := d.readTag()
+			tt, err := d.r.ReadByte()
 			if err != nil {
 				return err
 			}
 			if tt == TagEnd {
 				break
 			}
-			err = d.rawRead(tt)
-			if err != nil {
+			if err := d.skipString(); err != nil { // tag name
+				return err
+			}
+			if err := d.rawRead(tt); err != nil {
 				return err
 			}
 		}
@@ -439,6 +568,26 @@ func (d *Decoder) rawRead(tagType byte) error {
 	return nil
 }
 
+// skip discard n bytes from the underlying reader
+func (d *Decoder) skip(n int64) error {
+	if r, ok := d.r.(interface{ Discard(int) (int, error) }); ok { // *bufio.Reader
+		_, err := r.Discard(int(n))
+		return err
+	}
+	_, err := io.CopyN(ioutil.Discard, d.r, n)
+	return err
+}
+
+func (d *Decoder) skipString() error {
+	length, err := d.readInt16()
+	if err != nil {
+		return err
+	} else if length < 0 {
+		return errors.New("string length less than 0")
+	}
+	return d.skip(int64(length))
+}
+
 func (d *Decoder) readTag() (tagType byte, tagName string, err error) {
 	tagType, err = d.r.ReadByte()
 	if err != nil {
@@ -452,21 +601,21 @@ func (d *Decoder) readTag() (tagType byte, tagName string, err error) {
 }
 
 func (d *Decoder) readInt16() (int16, error) {
-	var data [2]byte
-	_, err := io.ReadFull(d.r, data[:])
+	data := d.buf[:2]
+	_, err := io.ReadFull(d.r, data)
 	return int16(data[0])<<8 | int16(data[1]), err
 }
 
 func (d *Decoder) readInt32() (int32, error) {
-	var data [4]byte
-	_, err := io.ReadFull(d.r, data[:])
+	data := d.buf[:4]
+	_, err := io.ReadFull(d.r, data)
 	return int32(data[0])<<24 | int32(data[1])<<16 |
 		int32(data[2])<<8 | int32(data[3]), err
 }
 
 func (d *Decoder) readInt64() (int64, error) {
-	var data [8]byte
-	_, err := io.ReadFull(d.r, data[:])
+	data := d.buf[:8]
+	_, err := io.ReadFull(d.r, data)
 	return int64(data[0])<<56 | int64(data[1])<<48 |
 		int64(data[2])<<40 | int64(data[3])<<32 |
 		int64(data[4])<<24 | int64(data[5])<<16 |
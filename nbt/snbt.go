@@ -0,0 +1,518 @@
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MarshalSNBT encode v to the stringified NBT, which is used in commands and data packs.
+// e.g. {Count:1b,id:"minecraft:stone"}
+//
+// Types are mapped as Marshal does, also map[string]T are encoded to compounds with sorted keys,
+// []interface{} to lists and bool to bytes 1b and 0b.
+func MarshalSNBT(v interface{}) (string, error) {
+	var sb strings.Builder
+	if err := writeSNBT(&sb, reflect.ValueOf(v)); err != nil {
+		return "", fmt.Errorf("nbt: %w", err)
+	}
+	return sb.String(), nil
+}
+
+func writeSNBT(sb *strings.Builder, val reflect.Value) error {
+	// the dynamic tags keep their fields unexported, walk them directly
+	if val.IsValid() && val.CanInterface() {
+		switch t := val.Interface().(type) {
+		case *Compound:
+			if t != nil {
+				return writeSNBTCompound(sb, t)
+			}
+		case Compound:
+			return writeSNBTCompound(sb, &t)
+		case *List:
+			if t != nil {
+				return writeSNBTList(sb, t)
+			}
+		case List:
+			return writeSNBTList(sb, &t)
+		}
+	}
+
+	switch vk := val.Kind(); vk {
+	default:
+		return errors.New("unknown type " + vk.String())
+	case reflect.Interface, reflect.Ptr:
+		if val.IsNil() {
+			return errors.New("cannot encode nil " + val.Type().String())
+		}
+		return writeSNBT(sb, val.Elem())
+
+	case reflect.Bool:
+		if val.Bool() {
+			sb.WriteString("1b")
+		} else {
+			sb.WriteString("0b")
+		}
+	case reflect.Int8:
+		sb.WriteString(strconv.FormatInt(val.Int(), 10) + "b")
+	case reflect.Uint8:
+		sb.WriteString(strconv.FormatInt(int64(int8(val.Uint())), 10) + "b")
+	case reflect.Int16:
+		sb.WriteString(strconv.FormatInt(val.Int(), 10) + "s")
+	case reflect.Uint16:
+		sb.WriteString(strconv.FormatInt(int64(int16(val.Uint())), 10) + "s")
+	case reflect.Int32, reflect.Int:
+		sb.WriteString(strconv.FormatInt(int64(int32(val.Int())), 10))
+	case reflect.Uint32:
+		sb.WriteString(strconv.FormatInt(int64(int32(val.Uint())), 10))
+	case reflect.Int64:
+		sb.WriteString(strconv.FormatInt(val.Int(), 10) + "L")
+	case reflect.Uint64:
+		sb.WriteString(strconv.FormatInt(int64(val.Uint()), 10) + "L")
+
+	case reflect.Float32, reflect.Float64:
+		f := val.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("cannot encode %v in SNBT", f)
+		}
+		if vk == reflect.Float32 {
+			sb.WriteString(strconv.FormatFloat(f, 'g', -1, 32) + "f")
+		} else {
+			sb.WriteString(strconv.FormatFloat(f, 'g', -1, 64) + "d")
+		}
+
+	case reflect.String:
+		writeSNBTString(sb, val.String())
+
+	case reflect.Array, reflect.Slice:
+		prefix := ""
+		switch val.Type().Elem().Kind() {
+		case reflect.Uint8, reflect.Int8:
+			prefix = "B;"
+		case reflect.Int32:
+			prefix = "I;"
+		case reflect.Int64, reflect.Uint64:
+			prefix = "L;"
+		}
+		sb.WriteString("[" + prefix)
+		for i := 0; i < val.Len(); i++ {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			v := val.Index(i)
+			if prefix == "I;" { // the elements of int arrays have no suffix
+				sb.WriteString(strconv.FormatInt(v.Int(), 10))
+			} else if err := writeSNBT(sb, v); err != nil {
+				return err
+			}
+		}
+		sb.WriteByte(']')
+
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			return errors.New("cannot encode " + val.Type().String() + " as compound")
+		}
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+		sb.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			writeSNBTKey(sb, k.String())
+			sb.WriteByte(':')
+			if err := writeSNBT(sb, val.MapIndex(k)); err != nil {
+				return fmt.Errorf("fail to encode tag %q: %w", k.String(), err)
+			}
+		}
+		sb.WriteByte('}')
+
+	case reflect.Struct:
+		sb.WriteByte('{')
+		first := true
+		for i := 0; i < val.NumField(); i++ {
+			tagName, omitEmpty, ok := parseTag(val.Type().Field(i))
+			if !ok || omitEmpty && isEmptyValue(val.Field(i)) {
+				continue // Private or empty field
+			}
+
+			if !first {
+				sb.WriteByte(',')
+			}
+			first = false
+			writeSNBTKey(sb, tagName)
+			sb.WriteByte(':')
+			if err := writeSNBT(sb, val.Field(i)); err != nil {
+				return fmt.Errorf("fail to encode tag %q: %w", tagName, err)
+			}
+		}
+		sb.WriteByte('}')
+	}
+	return nil
+}
+
+func writeSNBTCompound(sb *strings.Builder, c *Compound) error {
+	sb.WriteByte('{')
+	for i, name := range c.names {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		writeSNBTKey(sb, name)
+		sb.WriteByte(':')
+		if err := writeSNBT(sb, reflect.ValueOf(c.tags[name])); err != nil {
+			return fmt.Errorf("fail to encode tag %q: %w", name, err)
+		}
+	}
+	sb.WriteByte('}')
+	return nil
+}
+
+func writeSNBTList(sb *strings.Builder, l *List) error {
+	sb.WriteByte('[')
+	for i, elem := range l.elems {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		if err := writeSNBT(sb, reflect.ValueOf(elem)); err != nil {
+			return err
+		}
+	}
+	sb.WriteByte(']')
+	return nil
+}
+
+func isUnquotedChar(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' ||
+		c == '_' || c == '-' || c == '.' || c == '+'
+}
+
+func writeSNBTKey(sb *strings.Builder, key string) {
+	for i := 0; i < len(key); i++ {
+		if !isUnquotedChar(key[i]) {
+			writeSNBTString(sb, key)
+			return
+		}
+	}
+	if key == "" {
+		sb.WriteString(`""`)
+		return
+	}
+	sb.WriteString(key)
+}
+
+func writeSNBTString(sb *strings.Builder, s string) {
+	// use single quotes if there are double quotes inside, like the vanilla does
+	quote := byte('"')
+	if strings.IndexByte(s, '"') != -1 && strings.IndexByte(s, '\'') == -1 {
+		quote = '\''
+	}
+	sb.WriteByte(quote)
+	for i := 0; i < len(s); i++ {
+		if s[i] == quote || s[i] == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(s[i])
+	}
+	sb.WriteByte(quote)
+}
+
+// ParseSNBT parse the stringified NBT.
+//
+// The result has the same types as Unmarshal into an interface{}:
+// bytes are byte, shorts int16, ints int32, longs int64, floats float32, doubles float64,
+// arrays []byte, []int32 or []int64, lists []interface{} and compounds map[string]interface{}.
+func ParseSNBT(s string) (interface{}, error) {
+	p := snbtParser{s: s}
+	v, err := p.value()
+	if err == nil {
+		p.skipSpace()
+		if p.i < len(p.s) {
+			err = p.errorf("unexpected trailing data")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("nbt: %w", err)
+	}
+	return v, nil
+}
+
+type snbtParser struct {
+	s     string
+	i     int
+	depth int // the nesting of lists and compounds being parsed, see maxDepth
+}
+
+// enter a list or compound, it should be paired with leave
+func (p *snbtParser) enter() error {
+	if p.depth >= maxDepth {
+		return p.errorf("tags nested deeper than %d", maxDepth)
+	}
+	p.depth++
+	return nil
+}
+
+func (p *snbtParser) leave() { p.depth-- }
+
+func (p *snbtParser) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("snbt at %d: %s", p.i, fmt.Sprintf(format, a...))
+}
+
+func (p *snbtParser) skipSpace() {
+	for p.i < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.i]) != -1 {
+		p.i++
+	}
+}
+
+// peek return the next non-space byte, or 0 at the end of input
+func (p *snbtParser) peek() byte {
+	p.skipSpace()
+	if p.i >= len(p.s) {
+		return 0
+	}
+	return p.s[p.i]
+}
+
+func (p *snbtParser) expect(c byte) error {
+	if p.peek() != c {
+		return p.errorf("expect %q", c)
+	}
+	p.i++
+	return nil
+}
+
+func (p *snbtParser) value() (interface{}, error) {
+	switch p.peek() {
+	case 0:
+		return nil, p.errorf("unexpected end of input")
+	case '{':
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		return p.compound()
+	case '[':
+		if p.i+2 < len(p.s) && p.s[p.i+2] == ';' {
+			return p.array()
+		}
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		return p.list()
+	case '"', '\'':
+		return p.quoted()
+	}
+
+	start := p.i
+	for p.i < len(p.s) && isUnquotedChar(p.s[p.i]) {
+		p.i++
+	}
+	if start == p.i {
+		return nil, p.errorf("unexpected character %q", p.s[p.i])
+	}
+	return parseSNBTPrimitive(p.s[start:p.i]), nil
+}
+
+// parseSNBTPrimitive check the type of an unquoted value,
+// if it isn't a valid number the value is a string.
+func parseSNBTPrimitive(s string) interface{} {
+	switch strings.ToLower(s) {
+	case "true":
+		return byte(1)
+	case "false":
+		return byte(0)
+	}
+
+	if s == "" {
+		return s
+	}
+	num, suffix := s, byte(0)
+	if last := s[len(s)-1]; last > '9' && last != '.' {
+		num, suffix = s[:len(s)-1], last|0x20 // to lower case
+	}
+	if num == "" || strings.Trim(num, "0123456789.eE+-") != "" || num[0] > '9' && num[0] != '.' {
+		return s
+	}
+	isInteger := strings.IndexAny(num, ".eE") == -1
+
+	switch suffix {
+	case 'b':
+		if v, err := strconv.ParseInt(num, 10, 8); err == nil && isInteger {
+			return byte(v)
+		}
+	case 's':
+		if v, err := strconv.ParseInt(num, 10, 16); err == nil && isInteger {
+			return int16(v)
+		}
+	case 'l':
+		if v, err := strconv.ParseInt(num, 10, 64); err == nil && isInteger {
+			return v
+		}
+	case 'f':
+		if v, err := strconv.ParseFloat(num, 32); err == nil {
+			return float32(v)
+		}
+	case 'd':
+		if v, err := strconv.ParseFloat(num, 64); err == nil {
+			return v
+		}
+	case 0:
+		if isInteger {
+			if v, err := strconv.ParseInt(num, 10, 32); err == nil {
+				return int32(v)
+			}
+		} else if v, err := strconv.ParseFloat(num, 64); err == nil {
+			return v
+		}
+	}
+	return s
+}
+
+func (p *snbtParser) quoted() (string, error) {
+	quote := p.s[p.i]
+	p.i++
+	var sb strings.Builder
+	for ; p.i < len(p.s); p.i++ {
+		switch c := p.s[p.i]; c {
+		case quote:
+			p.i++
+			return sb.String(), nil
+		case '\\':
+			if p.i++; p.i >= len(p.s) {
+				break
+			}
+			if c := p.s[p.i]; c != quote && c != '\\' {
+				return "", p.errorf("invalid escape sequence \\%c", c)
+			}
+			sb.WriteByte(p.s[p.i])
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *snbtParser) key() (string, error) {
+	if c := p.peek(); c == '"' || c == '\'' {
+		return p.quoted()
+	}
+	start := p.i
+	for p.i < len(p.s) && isUnquotedChar(p.s[p.i]) {
+		p.i++
+	}
+	if start == p.i {
+		return "", p.errorf("expect a key")
+	}
+	return p.s[start:p.i], nil
+}
+
+func (p *snbtParser) compound() (map[string]interface{}, error) {
+	p.i++ // '{'
+	m := make(map[string]interface{})
+	if p.peek() == '}' {
+		p.i++
+		return m, nil
+	}
+	for {
+		k, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		if m[k], err = p.value(); err != nil {
+			return nil, err
+		}
+
+		switch p.peek() {
+		case ',':
+			p.i++
+		case '}':
+			p.i++
+			return m, nil
+		default:
+			return nil, p.errorf("expect ',' or '}'")
+		}
+	}
+}
+
+func (p *snbtParser) list() ([]interface{}, error) {
+	p.i++ // '['
+	list := []interface{}{}
+	if p.peek() == ']' {
+		p.i++
+		return list, nil
+	}
+	for {
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if len(list) > 0 && reflect.TypeOf(v) != reflect.TypeOf(list[0]) {
+			return nil, p.errorf("cannot insert %T into list of %T", v, list[0])
+		}
+		list = append(list, v)
+
+		switch p.peek() {
+		case ',':
+			p.i++
+		case ']':
+			p.i++
+			return list, nil
+		default:
+			return nil, p.errorf("expect ',' or ']'")
+		}
+	}
+}
+
+func (p *snbtParser) array() (interface{}, error) {
+	typ := p.s[p.i+1]
+	p.i += 3 // "[X;"
+
+	var ary reflect.Value
+	switch typ {
+	case 'B':
+		ary = reflect.ValueOf([]byte{})
+	case 'I':
+		ary = reflect.ValueOf([]int32{})
+	case 'L':
+		ary = reflect.ValueOf([]int64{})
+	default:
+		return nil, p.errorf("invalid array type %q", typ)
+	}
+	elemType := ary.Type().Elem()
+
+	if p.peek() == ']' {
+		p.i++
+		return ary.Interface(), nil
+	}
+	for {
+		start := p.i
+		for p.i < len(p.s) && isUnquotedChar(p.s[p.i]) {
+			p.i++
+		}
+		v := parseSNBTPrimitive(p.s[start:p.i])
+		if reflect.TypeOf(v) != elemType {
+			p.i = start
+			return nil, p.errorf("cannot insert %v into %c array", v, typ)
+		}
+		ary = reflect.Append(ary, reflect.ValueOf(v))
+
+		switch p.peek() {
+		case ',':
+			p.i++
+			p.skipSpace()
+		case ']':
+			p.i++
+			return ary.Interface(), nil
+		default:
+			return nil, p.errorf("expect ',' or ']'")
+		}
+	}
+}
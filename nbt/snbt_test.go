@@ -0,0 +1,192 @@
+package nbt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// an item stack tag
+var snbtItem = map[string]interface{}{
+	"Count": byte(1),
+	"Slot":  byte(0xFF),
+	"id":    "minecraft:diamond_sword",
+	"tag": map[string]interface{}{
+		"Damage": int32(12),
+		"display": map[string]interface{}{
+			"Name": `{"text":"Tnze's sword"}`,
+			"Lore": []interface{}{`"line 1"`, "line 2"},
+		},
+		"Enchantments": []interface{}{
+			map[string]interface{}{"id": "minecraft:sharpness", "lvl": int16(5)},
+			map[string]interface{}{"id": "minecraft:unbreaking", "lvl": int16(3)},
+		},
+		"RepairCost":       int32(1),
+		"HideFlags":        int32(0),
+		"Unbreakable":      byte(0),
+		"CustomModelData":  int64(-1234567890123),
+		"generic.speed":    float32(0.25),
+		"Attack Damage":    float64(7.5),
+		"empty list":       []interface{}{},
+		"Colors":           []int32{1, -2, 3},
+		"Bytes":            []byte{1, 2, 255},
+		"Longs":            []int64{},
+		"with\"both'quote": "\\",
+	},
+}
+
+func TestSNBT_roundTrip(t *testing.T) {
+	s, err := MarshalSNBT(snbtItem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(s)
+
+	v, err := ParseSNBT(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, snbtItem) {
+		t.Errorf("round trip fail:\nget  %#v\nwant %#v", v, snbtItem)
+	}
+}
+
+func TestMarshalSNBT_dynamic(t *testing.T) {
+	c := NewCompound().
+		Put("id", String("minecraft:stone")).
+		Put("Count", Byte(1)).
+		Put("tag", NewCompound().
+			Put("Damage", Int(10)).
+			Put("Lore", NewList(String("a"), String("b"))).
+			Put("Colors", IntArray{1, -2})).
+		Put("Empty", NewList())
+
+	s, err := MarshalSNBT(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{id:"minecraft:stone",Count:1b,tag:{Damage:10,Lore:["a","b"],Colors:[I;1,-2]},Empty:[]}`
+	if s != want {
+		t.Errorf("marshal Compound:\nget  %s\nwant %s", s, want)
+	}
+
+	v, err := ParseSNBT(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantV := map[string]interface{}{
+		"id":    "minecraft:stone",
+		"Count": byte(1),
+		"tag": map[string]interface{}{
+			"Damage": int32(10),
+			"Lore":   []interface{}{"a", "b"},
+			"Colors": []int32{1, -2},
+		},
+		"Empty": []interface{}{},
+	}
+	if !reflect.DeepEqual(v, wantV) {
+		t.Errorf("round trip fail:\nget  %#v\nwant %#v", v, wantV)
+	}
+
+	if s, err := MarshalSNBT(NewList(Long(1), Long(2))); err != nil || s != "[1L,2L]" {
+		t.Errorf("marshal List: get %s, %v", s, err)
+	}
+}
+
+func TestMarshalSNBT(t *testing.T) {
+	for _, tt := range []struct {
+		v    interface{}
+		snbt string
+	}{
+		{byte(1), "1b"},
+		{int16(-2), "-2s"},
+		{int32(3), "3"},
+		{int64(4), "4L"},
+		{float32(0.5), "0.5f"},
+		{5.0, "5d"},
+		{true, "1b"},
+		{"minecraft:stone", `"minecraft:stone"`},
+		{`say "hi"`, `'say "hi"'`},
+		{[]string{"a", "b"}, `["a","b"]`},
+		{[]int32{1, 2, 3}, "[I;1,2,3]"},
+		{[]int64{1}, "[L;1L]"},
+		{[]byte{1, 255}, "[B;1b,-1b]"},
+		{map[string]int32{"b": 2, "a": 1, "a b": 3}, `{a:1,"a b":3,b:2}`},
+		{struct {
+			Count byte   `nbt:"Count"`
+			ID    string `nbt:"id"`
+			skip  int32
+		}{1, "minecraft:stone", 0}, `{Count:1b,id:"minecraft:stone"}`},
+	} {
+		s, err := MarshalSNBT(tt.v)
+		if err != nil {
+			t.Errorf("marshal %#v: %v", tt.v, err)
+		} else if s != tt.snbt {
+			t.Errorf("marshal %#v: get %s, want %s", tt.v, s, tt.snbt)
+		}
+	}
+}
+
+func TestParseSNBT(t *testing.T) {
+	for _, tt := range []struct {
+		snbt string
+		v    interface{}
+	}{
+		{"1b", byte(1)},
+		{"-1B", byte(0xFF)},
+		{"true", byte(1)},
+		{"2s", int16(2)},
+		{"3", int32(3)},
+		{"4l", int64(4)},
+		{"0.5F", float32(.5)},
+		{"6d", 6.0},
+		{"7.5", 7.5},
+		{"stone", "stone"},
+		{"3000000000", "3000000000"}, // out of int range
+		{"128b", "128b"},
+		{"1.2.3", "1.2.3"},
+		{`'it\'s'`, "it's"},
+		{" [I; 1 , 2 ] ", []int32{1, 2}},
+		{"[B;]", []byte{}},
+		{"[1,2]", []interface{}{int32(1), int32(2)}},
+		{`{a: 1b, "b c" : {}}`, map[string]interface{}{"a": byte(1), "b c": map[string]interface{}{}}},
+	} {
+		v, err := ParseSNBT(tt.snbt)
+		if err != nil {
+			t.Errorf("parse %s: %v", tt.snbt, err)
+		} else if !reflect.DeepEqual(v, tt.v) {
+			t.Errorf("parse %s: get %#v, want %#v", tt.snbt, v, tt.v)
+		}
+	}
+
+	for _, s := range []string{
+		"", "{", "{a}", "{a:1", "[1,2b]", "[I;1b]", "[X;1]", `"abc`, `"\n"`, "1 2", "{a:1,}", "minecraft:stone",
+	} {
+		if v, err := ParseSNBT(s); err == nil {
+			t.Errorf("parse %q should be error, get %#v", s, v)
+		}
+	}
+}
+
+func TestParseSNBT_depth(t *testing.T) {
+	nested := func(n int) string {
+		return strings.Repeat("[", n) + strings.Repeat("]", n)
+	}
+	if _, err := ParseSNBT(nested(maxDepth)); err != nil {
+		t.Errorf("parse %d lists: %v", maxDepth, err)
+	}
+	if _, err := ParseSNBT(nested(maxDepth + 1)); err == nil {
+		t.Errorf("parse %d lists: no error", maxDepth+1)
+	}
+	compounds := strings.Repeat("{a:", maxDepth) + "1b" + strings.Repeat("}", maxDepth)
+	if _, err := ParseSNBT(compounds); err != nil {
+		t.Errorf("parse %d compounds: %v", maxDepth, err)
+	}
+	if _, err := ParseSNBT("[" + compounds + "]"); err == nil {
+		t.Errorf("parse %d tags: no error", maxDepth+1)
+	}
+	// a deep input shouldn't overflow the stack
+	if _, err := ParseSNBT(nested(1 << 20)); err == nil {
+		t.Error("parse deeply nested lists: no error")
+	}
+}
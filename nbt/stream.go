@@ -0,0 +1,52 @@
+package nbt
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Token is the header of a tag read by Decoder.Token.
+type Token struct {
+	Type byte
+	Name string
+}
+
+// Token read the header of next tag.
+//
+// After a Token returned, call Decode to decode its payload, or Skip to skip it.
+// If the tag is a TagCompound, successive calls to Token return tags inside it,
+// and a Token with Type TagEnd is returned at the end of that compound.
+// Calling Token after other tags skip their payload automatically.
+func (d *Decoder) Token() (Token, error) {
+	if d.pending {
+		d.pending = false
+		if d.pendingType != TagCompound { // enter the compound, otherwise skip the payload
+			if err := d.rawRead(d.pendingType); err != nil {
+				return Token{}, fmt.Errorf("nbt: fail to skip tag %q: %w", d.pendingName, err)
+			}
+		}
+	}
+
+	tagType, tagName, err := d.readTag()
+	if err != nil {
+		return Token{}, fmt.Errorf("nbt: %w", err)
+	}
+	if tagType != TagEnd {
+		d.pending = true
+		d.pendingType, d.pendingName = tagType, tagName
+	}
+	return Token{Type: tagType, Name: tagName}, nil
+}
+
+// Skip the payload of the tag returned by the last call to Token, including all its subtrees.
+// Nothing is allocated when the Decoder reads from a *bufio.Reader.
+func (d *Decoder) Skip() error {
+	if !d.pending {
+		return errors.New("nbt: no tag to skip, call Token first")
+	}
+	d.pending = false
+	if err := d.rawRead(d.pendingType); err != nil {
+		return fmt.Errorf("nbt: fail to skip tag %q: %w", d.pendingName, err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package nbt
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// chunkLikeNBT build a NBT similar to the chunks saved in region files
+func chunkLikeNBT() []byte {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	_ = e.writeTag(TagCompound, "")
+	_ = e.writeTag(TagInt, "DataVersion")
+	_ = e.writeInt32(2566)
+	_ = e.writeTag(TagCompound, "Level")
+	{
+		_ = e.writeTag(TagList, "Sections")
+		buf.WriteByte(TagCompound)
+		_ = e.writeInt32(16)
+		for y := 0; y < 16; y++ {
+			_ = e.writeTag(TagByte, "Y")
+			buf.WriteByte(byte(y))
+			_ = e.writeTag(TagString, "Name")
+			_ = e.writeInt16(int16(len("minecraft:stone")))
+			buf.WriteString("minecraft:stone")
+			_ = e.writeTag(TagLongArray, "BlockStates")
+			_ = e.writeInt32(256)
+			for i := 0; i < 256; i++ {
+				_ = e.writeInt64(int64(i))
+			}
+			buf.WriteByte(TagEnd)
+		}
+
+		_ = e.writeTag(TagCompound, "Heightmaps")
+		_ = e.writeTag(TagLongArray, "MOTION_BLOCKING")
+		_ = e.writeInt32(3)
+		for i := 1; i <= 3; i++ {
+			_ = e.writeInt64(int64(i))
+		}
+		buf.WriteByte(TagEnd)
+
+		_ = e.writeTag(TagIntArray, "Biomes")
+		_ = e.writeInt32(2)
+		_ = e.writeInt32(1)
+		_ = e.writeInt32(2)
+	}
+	buf.WriteByte(TagEnd)
+	buf.WriteByte(TagEnd)
+	return buf.Bytes()
+}
+
+func TestDecoder_Token(t *testing.T) {
+	d := NewDecoder(bytes.NewReader(chunkLikeNBT()))
+
+	var (
+		heightmaps map[string][]int64
+		biomes     []int32
+		names      []string
+	)
+	for depth := 0; ; {
+		tok, err := d.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, tok.Name)
+		switch tok.Name {
+		case "Heightmaps":
+			err = d.Decode(&heightmaps)
+		case "Biomes":
+			err = d.Decode(&biomes)
+		case "DataVersion", "Sections":
+			err = d.Skip()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tok.Type == TagCompound && tok.Name != "Heightmaps" {
+			depth++
+		} else if tok.Type == TagEnd {
+			if depth--; depth == 0 {
+				break
+			}
+		}
+	}
+
+	wantNames := []string{"", "DataVersion", "Level", "Sections", "Heightmaps", "Biomes", "", ""}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("tokens should be %q, get %q", wantNames, names)
+	}
+	if want := map[string][]int64{"MOTION_BLOCKING": {1, 2, 3}}; !reflect.DeepEqual(heightmaps, want) {
+		t.Errorf("heightmaps should be %v, get %v", want, heightmaps)
+	}
+	if want := []int32{1, 2}; !reflect.DeepEqual(biomes, want) {
+		t.Errorf("biomes should be %v, get %v", want, biomes)
+	}
+}
+
+func TestDecoder_Skip(t *testing.T) {
+	data := chunkLikeNBT()
+	r := bytes.NewReader(data)
+	br := bufio.NewReader(r)
+	d := NewDecoder(br)
+
+	allocs := testing.AllocsPerRun(10, func() {
+		r.Reset(data)
+		br.Reset(r)
+		if _, err := d.Token(); err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Skip(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("skip the whole chunk should not allocate, but %v allocs", allocs)
+	}
+	if r.Len() != 0 || br.Buffered() != 0 {
+		t.Error("skip didn't reach the end of NBT")
+	}
+
+	if err := d.Skip(); err == nil {
+		t.Error("skip without Token should be error")
+	}
+}
+
+func TestDecoder_Skip_truncated(t *testing.T) {
+	data := chunkLikeNBT()
+	d := NewDecoder(bytes.NewReader(data[:len(data)/2]))
+	if _, err := d.Token(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Skip(); err == nil {
+		t.Error("skip truncated data should be error")
+	}
+}
@@ -2,6 +2,7 @@ package nbt
 
 import (
 	"reflect"
+	"strings"
 	"sync"
 )
 
@@ -23,12 +24,12 @@ func getTypeInfo(typ reflect.Type) *typeInfo {
 		n := typ.NumField()
 		for i := 0; i < n; i++ {
 			f := typ.Field(i)
-			tag := f.Tag.Get("nbt")
-			if (f.PkgPath != "" && !f.Anonymous) || tag == "-" {
+			tagName, _, ok := parseTag(f)
+			if !ok {
 				continue // Private field
 			}
 
-			tInfo.nameToIndex[tag] = i
+			tInfo.nameToIndex[tagName] = i
 			if _, ok := tInfo.nameToIndex[f.Name]; !ok {
 				tInfo.nameToIndex[f.Name] = i
 			}
@@ -46,3 +47,45 @@ func (t *typeInfo) findIndexByName(name string) int {
 	}
 	return i
 }
+
+// parseTag parse the struct tag like `nbt:"name,omitempty"`.
+// The tagName is the field name if it isn't set in the tag.
+// For private fields and fields tagged `nbt:"-"`, ok is false.
+func parseTag(f reflect.StructField) (tagName string, omitEmpty, ok bool) {
+	tag := f.Tag.Get("nbt")
+	if (f.PkgPath != "" && !f.Anonymous) || tag == "-" {
+		return "", false, false
+	}
+
+	// Tag names may contain commas, so only cut the known option at the end.
+	const optOmitEmpty = ",omitempty"
+	if strings.HasSuffix(tag, optOmitEmpty) {
+		tag = tag[:len(tag)-len(optOmitEmpty)]
+		omitEmpty = true
+	}
+	tagName = tag
+	if tagName == "" {
+		tagName = f.Name
+	}
+	return tagName, omitEmpty, true
+}
+
+// isEmptyValue report whether v should be omitted by the omitempty option,
+// as the encoding/json does.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -3,11 +3,20 @@ package net
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
 	"crypto/cipher"
+	"errors"
+	"fmt"
 	"io"
 	"net"
+	"strconv"
+	"sync/atomic"
 	"time"
 
+	"github.com/Tnze/go-mc/data"
+	"github.com/Tnze/go-mc/net/CFB8"
 	pk "github.com/Tnze/go-mc/net/packet"
 )
 
@@ -27,12 +36,25 @@ func ListenMC(addr string) (*Listener, error) {
 func (l Listener) Accept() (Conn, error) {
 	conn, err := l.Listener.Accept()
 	return Conn{
-		Socket: conn,
-		Reader: bufio.NewReader(conn),
-		Writer: conn,
+		Socket:        conn,
+		Reader:        bufio.NewReader(conn),
+		Writer:        conn,
+		MaxPacketSize: MaxPacketSizeServerbound,
 	}, err
 }
 
+// The limits of packet size in vanilla
+const (
+	// MaxPacketSizeServerbound is the max length of the packets a server accepts,
+	// which is the largest length prefix of 3 bytes.
+	MaxPacketSizeServerbound = 1<<21 - 1
+	// MaxPacketSizeClientbound is the max uncompressed length of the packets a client accepts.
+	MaxPacketSizeClientbound = 8 << 20
+
+	// DefaultMaxPacketSize is used when the MaxPacketSize of Conn is zero
+	DefaultMaxPacketSize = MaxPacketSizeClientbound
+)
+
 //Conn is a minecraft Connection
 type Conn struct {
 	Socket net.Conn
@@ -42,7 +64,26 @@ type Conn struct {
 	}
 	io.Writer
 
-	threshold int
+	// MaxPacketSize limits the length of packets read by ReadPacket, both the
+	// length on the wire and the uncompressed length, to avoid allocating
+	// for a huge length sent by a malicious peer. Zero means DefaultMaxPacketSize.
+	MaxPacketSize int
+
+	// Logger receives the logs of the packets and state transitions.
+	// Nil means no logs.
+	Logger Logger
+
+	threshold  int  // the compression threshold, used if compressed
+	compressed bool // whether the compression is enabled by SetThreshold
+	encrypted  bool
+
+	state  int32 // the data.State, see State
+	client bool  // whether the Handshake is written, or read
+
+	// the unfinished packet of ReadPacket, see readFrame
+	head []byte // the length prefix
+	body []byte // the content, allocated once the length is known
+	read int    // bytes of body have been read
 }
 
 // DialMC create a Minecraft connection
@@ -65,6 +106,37 @@ func DialMCTimeout(addr string, timeout time.Duration) (*Conn, error) {
 	}, err
 }
 
+// DialContext create a Minecraft connection with a context.
+// The dialing is canceled when ctx is done.
+//
+// The addr is "host[:port]". If the port is omitted, the _minecraft._tcp SRV
+// records are looked up, and their targets are tried in the order of priority
+// and weight until one is connected. Without SRV records, the host is dialed
+// at DefaultPort.
+func DialContext(ctx context.Context, addr string) (*Conn, error) {
+	conn, _, err := dialContext(ctx, addr)
+	return conn, err
+}
+
+// dialContext is DialContext, and also returns the target connected.
+func dialContext(ctx context.Context, addr string) (*Conn, target, error) {
+	var (
+		d   net.Dialer
+		err error
+	)
+	for _, t := range resolveTargets(ctx, addr) {
+		var conn net.Conn
+		conn, err = d.DialContext(ctx, "tcp", net.JoinHostPort(t.host, strconv.Itoa(t.port)))
+		if err == nil {
+			return WrapConn(conn), t, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, target{}, err
+}
+
 // WrapConn warp an net.Conn to MC-Conn
 // Helps you modify the connection process (eg. using DialContext).
 func WrapConn(conn net.Conn) *Conn {
@@ -79,23 +151,184 @@ func WrapConn(conn net.Conn) *Conn {
 func (c *Conn) Close() error { return c.Socket.Close() }
 
 // ReadPacket read a Packet from Conn.
+// The Data of the packet can be put back to the pool by Release.
+//
+// If the read deadline is exceeded, the error is a net.Error whose
+// Timeout() is true. The bytes of the packet
+// have been read are kept, so the next ReadPacket continues the packet
+// without breaking the compressed or encrypted stream.
 func (c *Conn) ReadPacket() (pk.Packet, error) {
-	p, err := pk.RecvPacket(c.Reader, c.threshold > 0)
+	if err := c.readFrame(); err != nil {
+		return pk.Packet{}, err
+	}
+	body := c.body
+	c.head, c.body, c.read = c.head[:0], nil, 0
+
+	if c.compressed {
+		var size pk.VarInt
+		if err := size.Decode(bytes.NewReader(body)); err != nil {
+			(&pk.Packet{Data: body}).Release()
+			return pk.Packet{}, err
+		}
+		if size < 0 || int(size) > c.maxPacketSize() {
+			(&pk.Packet{Data: body}).Release()
+			return pk.Packet{}, fmt.Errorf("net: uncompressed packet length %d exceeds the limit %d", size, c.maxPacketSize())
+		}
+	}
+	p, err := pk.Unpack(body, c.compressed) // body is released by Unpack on error
 	if err != nil {
+		if logging(c.Logger) {
+			c.Logger.Warnf("net: unpack packet of %d bytes fail: %v", len(body), err)
+		}
 		return pk.Packet{}, err
 	}
+	if logging(c.Logger) {
+		c.Logger.Debugf("net: read %v packet 0x%02X of %d bytes", c.State(), p.ID, len(p.Data))
+	}
+	c.trackState(*p, false)
 	return *p, err
 }
 
+// readFrame read a whole packet into c.head and c.body.
+// It can be called again after an error to continue reading.
+func (c *Conn) readFrame() error {
+	for c.body == nil {
+		b, err := c.Reader.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(c.head) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		c.head = append(c.head, b)
+		if b&0x80 != 0 {
+			if len(c.head) >= 5 {
+				return errors.New("net: packet length too big")
+			}
+			continue
+		}
+
+		var length pk.VarInt
+		if err := length.Decode(bytes.NewReader(c.head)); err != nil {
+			return err
+		}
+		if length < 1 {
+			return errors.New("net: packet length too short")
+		}
+		if int(length) > c.maxPacketSize() {
+			return fmt.Errorf("net: packet length %d exceeds the limit %d", length, c.maxPacketSize())
+		}
+		c.body = pk.GetBuffer(int(length))
+	}
+	for c.read < len(c.body) {
+		n, err := c.Reader.Read(c.body[c.read:])
+		c.read += n
+		if err != nil && c.read < len(c.body) {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Conn) maxPacketSize() int {
+	if c.MaxPacketSize > 0 {
+		return c.MaxPacketSize
+	}
+	return DefaultMaxPacketSize
+}
+
 //WritePacket write a Packet to Conn.
 func (c *Conn) WritePacket(p pk.Packet) error {
-	_, err := c.Write(p.Pack(c.threshold))
+	_, err := c.Write(p.Pack(c.compression()))
+	if err == nil {
+		if logging(c.Logger) {
+			c.Logger.Debugf("net: write %v packet 0x%02X of %d bytes", c.State(), p.ID, len(p.Data))
+		}
+		c.trackState(p, true)
+	}
 	return err
 }
 
+// State return the connection state, by which the packet IDs are meant.
+//
+// A new Conn is in the Handshaking state. ReadPacket and WritePacket
+// follow the Handshake and Login Success packets to change the state,
+// so it's usually not needed to call SetState.
+func (c *Conn) State() data.State {
+	return data.State(atomic.LoadInt32(&c.state))
+}
+
+// SetState change the connection state.
+// Only the transitions of the protocol are valid, which are Handshaking to
+// Status or Login, and Login to Play. Setting the current state is a no-op.
+//
+// The compression and the encryption are enabled by EnableCompression and
+// SetEncryption during the Login state, before the transition to Play.
+func (c *Conn) SetState(s data.State) error {
+	old := c.State()
+	switch {
+	case s == old:
+	case old == data.Handshaking && (s == data.Status || s == data.Login):
+	case old == data.Login && s == data.Play:
+	default:
+		return fmt.Errorf("net: invalid state transition from %v to %v", old, s)
+	}
+	atomic.StoreInt32(&c.state, int32(s))
+	if s != old && logging(c.Logger) {
+		c.Logger.Infof("net: state changed from %v to %v", old, s)
+	}
+	return nil
+}
+
+// trackState change the state after p is read or written
+func (c *Conn) trackState(p pk.Packet, written bool) {
+	switch c.State() {
+	case data.Handshaking:
+		var (
+			protocol pk.VarInt
+			addr     pk.String
+			port     pk.UnsignedShort
+			next     pk.VarInt
+		)
+		if p.ID != 0x00 || p.Scan(&protocol, &addr, &port, &next) != nil {
+			return
+		}
+		switch next {
+		case 1:
+			_ = c.SetState(data.Status)
+		case 2:
+			_ = c.SetState(data.Login)
+		default:
+			return
+		}
+		c.client = written
+	case data.Login:
+		// Login Success is clientbound,
+		// while the serverbound 0x02 is Login Plugin Response
+		if p.ID == 0x02 && written != c.client {
+			_ = c.SetState(data.Play)
+		}
+	}
+}
+
+// SetReadDeadline set the deadline of the following ReadPacket.
+// A zero value of t means ReadPacket will not time out.
+func (c *Conn) SetReadDeadline(t time.Time) error { return c.Socket.SetReadDeadline(t) }
+
+// SetWriteDeadline set the deadline of the following WritePacket.
+// A zero value of t means WritePacket will not time out.
+//
+// A packet may be partially written when the deadline is exceeded,
+// and the connection should be closed then.
+func (c *Conn) SetWriteDeadline(t time.Time) error { return c.Socket.SetWriteDeadline(t) }
+
 // SetCipher load the decode/encode stream to this Conn
 func (c *Conn) SetCipher(ecoStream, decoStream cipher.Stream) {
 	//加密连接
+	c.encrypted = true
 	c.Reader = bufio.NewReader(cipher.StreamReader{ //Set receiver for AES
 		S: decoStream,
 		R: c.Socket,
@@ -106,9 +339,66 @@ func (c *Conn) SetCipher(ecoStream, decoStream cipher.Stream) {
 	}
 }
 
+// SetEncryption enable the AES/CFB8 encryption used after login,
+// which is after the Encryption Response of the Login state.
+// The sharedSecret is used as both key and IV, as Minecraft requires.
+// All packets read or write after this call are encrypted.
+func (c *Conn) SetEncryption(sharedSecret []byte) error {
+	b, err := aes.NewCipher(sharedSecret)
+	if err != nil {
+		return err
+	}
+	c.SetCipher(
+		CFB8.NewCFB8Encrypt(b, sharedSecret),
+		CFB8.NewCFB8Decrypt(b, sharedSecret),
+	)
+	if logging(c.Logger) {
+		c.Logger.Infof("net: encryption enabled")
+	}
+	return nil
+}
+
 // SetThreshold set threshold to Conn.
-// The data packet with length longer then threshold
-// will be compress when sending.
+// The data packet with length equal or longer then threshold
+// will be compress when sending, and
+// all packets received are read in the compressed format.
+// It's called when the Set Compression of the Login state is sent or received.
+//
+// As the vanilla does, a threshold of 0 compresses all the packets,
+// and a negative threshold disables the compression.
 func (c *Conn) SetThreshold(t int) {
-	c.threshold = t
+	c.threshold, c.compressed = t, t >= 0
+	if logging(c.Logger) {
+		c.Logger.Infof("net: compression threshold set to %d", t)
+	}
+}
+
+// compression return the threshold for Pack, which is -1 if the compression is disabled
+func (c *Conn) compression() int {
+	if !c.compressed {
+		return -1
+	}
+	return c.threshold
+}
+
+// EnableCompression enable the compression of the Login state with the threshold.
+//
+// On the server side, which has read the handshake, it sends the Set
+// Compression packet uncompressed, then switches to the compressed format,
+// so the packets written after it are all compressed. The threshold isn't
+// changed if the packet fails to be sent.
+// On the client side, it's called after the Set Compression is received.
+//
+// Like WritePacket, it shouldn't be called concurrently with writing.
+func (c *Conn) EnableCompression(threshold int) error {
+	if c.State() != data.Login {
+		return fmt.Errorf("net: enable compression in the %v state", c.State())
+	}
+	if !c.client {
+		if err := c.WritePacket(pk.Marshal(0x03, pk.VarInt(threshold))); err != nil {
+			return fmt.Errorf("net: send set compression fail: %w", err)
+		}
+	}
+	c.SetThreshold(threshold)
+	return nil
 }
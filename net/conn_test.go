@@ -0,0 +1,457 @@
+package net
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tnze/go-mc/data"
+	"github.com/Tnze/go-mc/net/CFB8"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// pipe return two connected Conn
+func pipe() (*Conn, *Conn) {
+	c1, c2 := net.Pipe()
+	return WrapConn(c1), WrapConn(c2)
+}
+
+// roundTrip send p from src and receive it from dst
+func roundTrip(t *testing.T, src, dst *Conn, p pk.Packet) pk.Packet {
+	errs := make(chan error, 1)
+	go func() { errs <- src.WritePacket(p) }()
+
+	recv, err := dst.ReadPacket()
+	if err != nil {
+		t.Fatalf("read packet fail: %v", err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("write packet fail: %v", err)
+	}
+	return recv
+}
+
+func TestConn_compression(t *testing.T) {
+	const threshold = 256
+	client, server := pipe()
+	defer client.Close()
+	defer server.Close()
+	client.SetThreshold(threshold)
+	server.SetThreshold(threshold)
+
+	for _, size := range []int{0, 10, threshold - 1, threshold, 100000} {
+		want := pk.Packet{ID: 0x22, Data: bytes.Repeat([]byte{'x'}, size)}
+		get := roundTrip(t, client, server, want)
+		if get.ID != want.ID || !bytes.Equal(get.Data, want.Data) {
+			t.Errorf("packet with %d bytes changed after compression", size)
+		}
+	}
+}
+
+func TestConn_compression_zero(t *testing.T) {
+	// the vanilla compresses all the packets at threshold 0
+	client, server := pipe()
+	defer client.Close()
+	defer server.Close()
+	client.SetThreshold(0)
+	server.SetThreshold(0)
+
+	for _, size := range []int{0, 10, 1000} {
+		want := pk.Packet{ID: 0x22, Data: bytes.Repeat([]byte{'x'}, size)}
+		get := roundTrip(t, client, server, want)
+		if get.ID != want.ID || !bytes.Equal(get.Data, want.Data) {
+			t.Errorf("packet with %d bytes changed after compression", size)
+		}
+	}
+
+	small := pk.Marshal(0x01)
+	r := bytes.NewReader(small.Pack(0))
+	var length, dataLength pk.VarInt
+	if err := length.Decode(r); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataLength.Decode(r); err != nil {
+		t.Fatal(err)
+	}
+	if dataLength != 1 {
+		t.Errorf("packet should be compressed at threshold 0, get data length %d", dataLength)
+	}
+}
+
+func TestPack_threshold(t *testing.T) {
+	const threshold = 256
+	small := pk.Packet{ID: 0x01, Data: []byte{0x02, 0x03}}
+	want := []byte{0x04, 0x00, 0x01, 0x02, 0x03}
+	if get := small.Pack(threshold); !bytes.Equal(get, want) {
+		t.Errorf("small packet should be sent uncompressed as \"% x\", get \"% x\"", want, get)
+	}
+
+	large := pk.Packet{ID: 0x01, Data: bytes.Repeat([]byte{0}, 1000)}
+	r := bytes.NewReader(large.Pack(threshold))
+	var length, dataLength pk.VarInt
+	if err := length.Decode(r); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataLength.Decode(r); err != nil {
+		t.Fatal(err)
+	}
+	if dataLength != 1001 {
+		t.Errorf("large packet should be compressed with data length 1001, get %d", dataLength)
+	}
+	if int(length) != len(dataLength.Encode())+r.Len() || r.Len() >= 1000 {
+		t.Errorf("compressed packet has wrong length %d, remain %d bytes", length, r.Len())
+	}
+}
+
+func TestConn_encryption(t *testing.T) {
+	secret := []byte("0123456789abcdef")
+	c1, c2 := net.Pipe()
+	client, server := WrapConn(c1), WrapConn(c2)
+	defer client.Close()
+	defer server.Close()
+
+	if err := client.SetEncryption(secret); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetEncryption(secret); err != nil {
+		t.Fatal(err)
+	}
+
+	// The stream cipher keeps its state between packets,
+	// so sending several packets checks the streams stay in sync.
+	for i, want := range []pk.Packet{
+		pk.Marshal(0x00, pk.String("Tnze")),
+		pk.Marshal(0x0E, pk.VarInt(-1), pk.Long(1234567890)),
+		{ID: 0x22, Data: bytes.Repeat([]byte("go-mc"), 1000)},
+	} {
+		src, dst := client, server
+		if i%2 == 1 {
+			src, dst = server, client
+		}
+		get := roundTrip(t, src, dst, want)
+		if get.ID != want.ID || !bytes.Equal(get.Data, want.Data) {
+			t.Errorf("packet %d changed after encryption: get %v, want %v", i, get, want)
+		}
+	}
+}
+
+func TestConn_encryptionOnWire(t *testing.T) {
+	secret := []byte("0123456789abcdef")
+	c1, c2 := net.Pipe()
+	client := WrapConn(c1)
+	defer client.Close()
+	defer c2.Close()
+	if err := client.SetEncryption(secret); err != nil {
+		t.Fatal(err)
+	}
+
+	p := pk.Marshal(0x00, pk.String("Tnze"))
+	plain := p.Pack(-1)
+	go client.WritePacket(p)
+
+	cipherText := make([]byte, len(plain))
+	if _, err := io.ReadFull(c2, cipherText); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(cipherText, plain) {
+		t.Error("packet is sent without encryption")
+	}
+
+	// decrypt what was on wire
+	b, _ := aes.NewCipher(secret)
+	CFB8.NewCFB8Decrypt(b, secret).XORKeyStream(cipherText, cipherText)
+	if !bytes.Equal(cipherText, plain) {
+		t.Errorf("decrypted packet should be \"% x\", get \"% x\"", plain, cipherText)
+	}
+}
+
+func TestDialContext_srv(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		if conn, err := l.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+	port := l.Addr().(*net.TCPAddr).Port
+
+	// a port nobody listens
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	defer func(f func(context.Context, string, string, string) (string, []*net.SRV, error)) { lookupSRV = f }(lookupSRV)
+	lookupSRV = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		if service != "minecraft" || proto != "tcp" || name != "mc.example.com" {
+			t.Errorf("wrong lookup: %s %s %s", service, proto, name)
+		}
+		return "", []*net.SRV{
+			{Target: "127.0.0.1.", Port: uint16(closedPort), Priority: 0},
+			{Target: "127.0.0.1.", Port: uint16(port), Priority: 1},
+		}, nil
+	}
+
+	conn, err := DialContext(context.Background(), "mc.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if got := conn.Socket.RemoteAddr().(*net.TCPAddr).Port; got != port {
+		t.Errorf("connected to port %d, want the next target %d", got, port)
+	}
+}
+
+func TestResolveTargets_fallback(t *testing.T) {
+	defer func(f func(context.Context, string, string, string) (string, []*net.SRV, error)) { lookupSRV = f }(lookupSRV)
+	lookupSRV = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	targets := resolveTargets(context.Background(), "mc.example.com")
+	if len(targets) != 1 || targets[0] != (target{"mc.example.com", DefaultPort}) {
+		t.Errorf("want fallback to the default port, get %v", targets)
+	}
+}
+
+func TestConn_ReadPacket_deadline(t *testing.T) {
+	const threshold = 16
+	client, server := pipe()
+	defer client.Close()
+	defer server.Close()
+	secret := []byte("0123456789abcdef")
+	for _, c := range []*Conn{client, server} {
+		c.SetThreshold(threshold)
+		if err := c.SetEncryption(secret); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := pk.Marshal(0x22, pk.String(strings.Repeat("go-mc", 20)))
+	var frame bytes.Buffer
+	frameConn := &Conn{Writer: &frame}
+	frameConn.SetThreshold(threshold)
+	if err := frameConn.WritePacket(p); err != nil {
+		t.Fatal(err)
+	}
+	raw := frame.Bytes()
+
+	// the server stalls in the middle of the packet
+	go func() { server.Write(raw[:len(raw)/2]) }()
+	if err := client.SetReadDeadline(time.Now().Add(50 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	var ne net.Error
+	if _, err := client.ReadPacket(); !errors.As(err, &ne) || !ne.Timeout() {
+		t.Fatalf("want a deadline exceeded error, get %v", err)
+	}
+
+	go func() { server.Write(raw[len(raw)/2:]) }()
+	if err := client.SetReadDeadline(time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	recv, err := client.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recv.ID != p.ID || !bytes.Equal(recv.Data, p.Data) {
+		t.Errorf("packet broken after the timeout: get %v", recv)
+	}
+}
+
+func TestConn_ReadPacket_maxPacketSize(t *testing.T) {
+	// a length prefix claiming 1GB, but without the content
+	huge := pk.VarInt(1 << 30).Encode()
+	c := &Conn{Reader: bytes.NewReader(huge)}
+	if _, err := c.ReadPacket(); err == nil || !strings.Contains(err.Error(), "exceeds the limit") {
+		t.Errorf("oversized length prefix should be rejected, got %v", err)
+	}
+
+	p := pk.Marshal(0x01, pk.String(strings.Repeat("x", 100)))
+	c = &Conn{Reader: bytes.NewReader(p.Pack(-1)), MaxPacketSize: 64}
+	if _, err := c.ReadPacket(); err == nil {
+		t.Error("packet longer than MaxPacketSize should be rejected")
+	}
+	c = &Conn{Reader: bytes.NewReader(p.Pack(-1)), MaxPacketSize: 128}
+	if _, err := c.ReadPacket(); err != nil {
+		t.Errorf("packet within MaxPacketSize: %v", err)
+	}
+
+	// a small compressed packet claiming a huge uncompressed length
+	var bomb bytes.Buffer
+	bomb.Write(pk.VarInt(1 << 30).Encode())
+	bomb.Write([]byte{0x78, 0x9c})
+	frame := append(pk.VarInt(bomb.Len()).Encode(), bomb.Bytes()...)
+	c = &Conn{Reader: bytes.NewReader(frame)}
+	c.SetThreshold(256)
+	if _, err := c.ReadPacket(); err == nil || !strings.Contains(err.Error(), "exceeds the limit") {
+		t.Errorf("oversized uncompressed length should be rejected, got %v", err)
+	}
+}
+
+func TestConn_State(t *testing.T) {
+	client, server := pipe()
+	check := func(step string, want data.State) {
+		t.Helper()
+		if client.State() != want || server.State() != want {
+			t.Errorf("%s: client is %v and server is %v, want %v", step, client.State(), server.State(), want)
+		}
+	}
+	check("new", data.Handshaking)
+
+	// not a handshake
+	roundTrip(t, client, server, pk.Marshal(0x00, pk.VarInt(1)))
+	check("non-handshake", data.Handshaking)
+
+	roundTrip(t, client, server, pk.Marshal(0x00, pk.VarInt(736), pk.String("localhost"), pk.UnsignedShort(25565), pk.VarInt(2)))
+	check("handshake", data.Login)
+
+	// the serverbound 0x02 is Login Plugin Response
+	roundTrip(t, client, server, pk.Marshal(0x02, pk.VarInt(0), pk.Boolean(false)))
+	check("login plugin response", data.Login)
+
+	roundTrip(t, server, client, pk.Marshal(0x02, pk.UUID{}, pk.String("Steve")))
+	check("login success", data.Play)
+
+	if err := client.SetState(data.Status); err == nil {
+		t.Error("the transition from Play to Status should be invalid")
+	}
+	if err := client.SetState(data.Play); err != nil {
+		t.Errorf("setting the current state: %v", err)
+	}
+
+	client, server = pipe()
+	roundTrip(t, client, server, pk.Marshal(0x00, pk.VarInt(-1), pk.String("localhost"), pk.UnsignedShort(25565), pk.Byte(1)))
+	check("status handshake", data.Status)
+	if err := server.SetState(data.Login); err == nil {
+		t.Error("the transition from Status to Login should be invalid")
+	}
+}
+
+// testLogger records the logs with their levels
+type testLogger []string
+
+func (l *testLogger) logf(level, format string, args ...interface{}) {
+	*l = append(*l, level+" "+fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Debugf(format string, args ...interface{}) { l.logf("debug", format, args...) }
+func (l *testLogger) Infof(format string, args ...interface{})  { l.logf("info", format, args...) }
+func (l *testLogger) Warnf(format string, args ...interface{})  { l.logf("warn", format, args...) }
+func (l *testLogger) Errorf(format string, args ...interface{}) { l.logf("error", format, args...) }
+
+func TestConn_Logger(t *testing.T) {
+	var (
+		logs testLogger
+		buf  bytes.Buffer
+	)
+	client := &Conn{Writer: &buf, Logger: &logs}
+	handshake := pk.Marshal(0x00, pk.VarInt(736), pk.String("localhost"), pk.UnsignedShort(25565), pk.VarInt(2))
+	if err := client.WritePacket(handshake); err != nil {
+		t.Fatal(err)
+	}
+	client.SetThreshold(256)
+
+	server := &Conn{Reader: bytes.NewReader(buf.Bytes()), Logger: &logs}
+	if _, err := server.ReadPacket(); err != nil {
+		t.Fatal(err)
+	}
+	// a compressed packet with broken zlib data
+	server.Reader = bytes.NewReader([]byte{0x04, 0x7f, 0x01, 0x02, 0x03})
+	server.SetThreshold(1)
+	if _, err := server.ReadPacket(); err == nil {
+		t.Fatal("broken packet is read")
+	}
+
+	want := []string{
+		"debug net: write handshaking packet 0x00 of 15 bytes",
+		"info net: state changed from handshaking to login",
+		"info net: compression threshold set to 256",
+		"debug net: read handshaking packet 0x00 of 15 bytes",
+		"info net: state changed from handshaking to login",
+		"info net: compression threshold set to 1",
+	}
+	if len(logs) != len(want)+1 || !strings.HasPrefix(logs[len(want)], "warn net: unpack packet of 4 bytes fail") {
+		t.Fatalf("logs: %q", logs)
+	}
+	for i := range want {
+		if logs[i] != want[i] {
+			t.Errorf("log %d: got %q, want %q", i, logs[i], want[i])
+		}
+	}
+}
+
+func TestConn_Logger_nop(t *testing.T) {
+	p := pk.Marshal(0x00, pk.String("Steve"))
+	write := func(l Logger) float64 {
+		c := &Conn{Writer: ioutil.Discard, Logger: l}
+		return testing.AllocsPerRun(100, func() { _ = c.WritePacket(p) })
+	}
+	if nop, none := write(NopLogger), write(nil); nop != none {
+		t.Errorf("writing with NopLogger allocates %v times, while %v times without logger", nop, none)
+	}
+}
+
+func TestConn_EnableCompression(t *testing.T) {
+	const threshold = 64
+	client, server := pipe()
+	defer client.Close()
+	defer server.Close()
+	roundTrip(t, client, server, pk.Marshal(0x00, pk.VarInt(736), pk.String("localhost"), pk.UnsignedShort(25565), pk.VarInt(2)))
+
+	errs := make(chan error, 1)
+	go func() { errs <- server.EnableCompression(threshold) }()
+	p, err := client.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var t2 pk.VarInt
+	if p.ID != 0x03 || p.Scan(&t2) != nil || t2 != threshold {
+		t.Fatalf("the set compression packet: %+v", p)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if err := client.EnableCompression(int(t2)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []int{0, threshold - 1, threshold, 1000} {
+		want := pk.Packet{ID: 0x22, Data: bytes.Repeat([]byte{'x'}, size)}
+		if get := roundTrip(t, client, server, want); !bytes.Equal(get.Data, want.Data) {
+			t.Errorf("the packet of %d bytes from client changed", size)
+		}
+		if get := roundTrip(t, server, client, want); !bytes.Equal(get.Data, want.Data) {
+			t.Errorf("the packet of %d bytes from server changed", size)
+		}
+
+		// both sides write the same frame
+		var fromClient, fromServer bytes.Buffer
+		c, s := *client, *server
+		c.Writer, s.Writer = &fromClient, &fromServer
+		if c.WritePacket(want) != nil || s.WritePacket(want) != nil {
+			t.Fatal("write packet fail")
+		}
+		if !bytes.Equal(fromClient.Bytes(), fromServer.Bytes()) {
+			t.Errorf("the frames of %d bytes differ:\nclient [% x]\nserver [% x]", size, fromClient.Bytes(), fromServer.Bytes())
+		}
+	}
+
+	// not in the login state
+	roundTrip(t, server, client, pk.Marshal(0x02, pk.UUID{}, pk.String("Steve")))
+	if err := client.EnableCompression(threshold); err == nil {
+		t.Error("compression is enabled in the play state")
+	}
+}
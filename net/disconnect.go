@@ -0,0 +1,43 @@
+package net
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Tnze/go-mc/chat"
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// DisconnectError is returned when the server sends a Disconnect packet.
+// Use errors.As to get the reason.
+//
+// The servers disconnect in the Login state for the reasons like server full,
+// outdated client or not whitelisted, while they kick the players in the
+// Play state.
+type DisconnectError struct {
+	State     data.State // data.Login or data.Play
+	Reason    chat.Message
+	RawReason string // the JSON chat component sent by the server
+}
+
+func (e *DisconnectError) Error() string {
+	if e.State == data.Login {
+		return fmt.Sprintf("net: disconnected by server: %s", e.Reason.ClearString())
+	}
+	return fmt.Sprintf("net: kicked by server: %s", e.Reason.ClearString())
+}
+
+// DecodeDisconnect decode the Disconnect packet p received in the state.
+// If the reason isn't a valid chat component, it's used as the plain text.
+func DecodeDisconnect(p pk.Packet, state data.State) (*DisconnectError, error) {
+	var reason pk.Chat
+	if err := p.Scan(&reason); err != nil {
+		return nil, fmt.Errorf("net: read disconnect message fail: %v", err)
+	}
+	e := &DisconnectError{State: state, RawReason: string(reason)}
+	if err := json.Unmarshal([]byte(reason), &e.Reason); err != nil {
+		e.Reason = chat.Text(string(reason))
+	}
+	return e, nil
+}
@@ -0,0 +1,82 @@
+package net
+
+import "strings"
+
+// The versions of Forge Mod Loader handshake
+const (
+	NoFML = 0
+	FML1  = 1 // Forge before 1.13, the mods negotiate by plugin messages in the play state
+	FML2  = 2 // Forge of 1.13 to 1.17, the mods negotiate by login plugin messages
+	FML3  = 3 // Forge since 1.18
+)
+
+// ParseFMLAddress split the FML marker from the server address of a handshake.
+// Forge clients append "\x00FML\x00", "\x00FML2\x00" or "\x00FML3\x00" to it.
+// The version is NoFML if the address has no marker.
+//
+// Pipe forwards the handshake and the negotiation of mods unmodified,
+// so proxying a Forge connection needs nothing else.
+func ParseFMLAddress(addr string) (host string, version int) {
+	i := strings.IndexByte(addr, 0)
+	if i < 0 {
+		return addr, NoFML
+	}
+	host, marker := addr[:i], strings.TrimRight(addr[i:], "\x00")
+	switch {
+	case strings.HasPrefix(marker, "\x00FML3"):
+		return host, FML3
+	case strings.HasPrefix(marker, "\x00FML2"):
+		return host, FML2
+	case strings.HasPrefix(marker, "\x00FML"):
+		return host, FML1
+	}
+	return host, NoFML
+}
+
+// ModInfo is the "modinfo" of the status of FML1 servers
+type ModInfo struct {
+	Type    string `json:"type"` // "FML"
+	ModList []struct {
+		ModID   string `json:"modid"`
+		Version string `json:"version"`
+	} `json:"modList"`
+}
+
+// ForgeData is the "forgeData" of the status of FML2 and later servers
+type ForgeData struct {
+	Channels []struct {
+		Res      string `json:"res"`
+		Version  string `json:"version"`
+		Required bool   `json:"required"`
+	} `json:"channels"`
+	Mods []struct {
+		ModID     string `json:"modId"`
+		ModMarker string `json:"modmarker"` // the version of the mod
+	} `json:"mods"`
+	FMLNetworkVersion int `json:"fmlNetworkVersion"`
+	// Truncated is true if the lists are cut off for the length of the status
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Mod is a mod installed on a Forge server
+type Mod struct {
+	ID      string
+	Version string
+}
+
+// Mods return the mods from either ModInfo or ForgeData.
+// It's empty for vanilla servers.
+func (s Status) Mods() []Mod {
+	var mods []Mod
+	if s.ModInfo != nil {
+		for _, m := range s.ModInfo.ModList {
+			mods = append(mods, Mod{ID: m.ModID, Version: m.Version})
+		}
+	}
+	if s.ForgeData != nil {
+		for _, m := range s.ForgeData.Mods {
+			mods = append(mods, Mod{ID: m.ModID, Version: m.ModMarker})
+		}
+	}
+	return mods
+}
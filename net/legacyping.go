@@ -0,0 +1,151 @@
+package net
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// legacyPingProtocol is the protocol version sent in the legacy ping,
+// which is the version of 1.6.4.
+const legacyPingProtocol = 78
+
+// LegacyPing send the 0xFE server list ping used by pre-1.7 clients.
+// It's for compatibility with old servers which don't support the ServerListPing.
+//
+// The addr is "host[:port]", the SRV record is looked up if the port is omitted.
+// Servers older than 1.4 don't report protocol version, which will be -1.
+//
+// See https://wiki.vg/Server_List_Ping#1.6
+func LegacyPing(addr string) (protocol int, motd string, online, max int, err error) {
+	return LegacyPingContext(context.Background(), addr)
+}
+
+// LegacyPingContext is the LegacyPing with a context.
+// The dialing and the whole request are canceled when ctx is done.
+func LegacyPingContext(ctx context.Context, addr string) (protocol int, motd string, online, max int, err error) {
+	host, port := resolveAddr(ctx, addr)
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		err = fmt.Errorf("legacy ping: dial fail: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// interrupt blocking reads and writes when ctx is done,
+	// so the error always wraps ctx.Err()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+	defer func() {
+		if err != nil && ctx.Err() != nil {
+			err = fmt.Errorf("%v: %w", err, ctx.Err())
+		}
+	}()
+
+	if _, err = conn.Write(legacyPingRequest(host, port)); err != nil {
+		err = fmt.Errorf("legacy ping: send request fail: %v", err)
+		return
+	}
+	return parseLegacyPing(bufio.NewReader(conn))
+}
+
+// legacyPingRequest build the 1.6 ping request,
+// a 0xFE 0x01 followed by a MC|PingHost plugin message.
+func legacyPingRequest(host string, port int) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0xFE, 0x01, 0xFA})
+	writeUTF16String(&buf, "MC|PingHost")
+
+	hostRunes := utf16.Encode([]rune(host))
+	_ = binary.Write(&buf, binary.BigEndian, uint16(7+2*len(hostRunes)))
+	buf.WriteByte(legacyPingProtocol)
+	writeUTF16String(&buf, host)
+	_ = binary.Write(&buf, binary.BigEndian, int32(port))
+	return buf.Bytes()
+}
+
+func writeUTF16String(w io.Writer, s string) {
+	u := utf16.Encode([]rune(s))
+	_ = binary.Write(w, binary.BigEndian, uint16(len(u)))
+	_ = binary.Write(w, binary.BigEndian, u)
+}
+
+// parseLegacyPing read the kick packet servers response to the legacy ping.
+func parseLegacyPing(r io.Reader) (protocol int, motd string, online, max int, err error) {
+	var header struct {
+		ID  byte
+		Len uint16
+	}
+	if err = binary.Read(r, binary.BigEndian, &header); err != nil {
+		err = fmt.Errorf("legacy ping: read response fail: %v", err)
+		return
+	}
+	if header.ID != 0xFF {
+		err = fmt.Errorf("legacy ping: unexpected packet id 0x%02X", header.ID)
+		return
+	}
+	u := make([]uint16, header.Len)
+	if err = binary.Read(r, binary.BigEndian, u); err != nil {
+		err = fmt.Errorf("legacy ping: read response fail: %v", err)
+		return
+	}
+	resp := string(utf16.Decode(u))
+
+	var fields []string
+	if strings.HasPrefix(resp, "§1\x00") {
+		// 1.4 - 1.6: §1\0protocol\0version\0motd\0online\0max
+		fields = strings.Split(resp, "\x00")
+		if len(fields) != 6 {
+			err = errors.New("legacy ping: malformed response")
+			return
+		}
+		if protocol, err = strconv.Atoi(fields[1]); err != nil {
+			err = fmt.Errorf("legacy ping: parse protocol fail: %v", err)
+			return
+		}
+		fields = fields[3:]
+	} else {
+		// beta 1.8 - 1.3: motd§online§max
+		protocol = -1
+		fields = strings.Split(resp, "§")
+		if len(fields) < 3 {
+			err = errors.New("legacy ping: malformed response")
+			return
+		}
+		// the motd may contain section signs
+		fields = []string{
+			strings.Join(fields[:len(fields)-2], "§"),
+			fields[len(fields)-2],
+			fields[len(fields)-1],
+		}
+	}
+
+	motd = fields[0]
+	if online, err = strconv.Atoi(fields[1]); err != nil {
+		err = fmt.Errorf("legacy ping: parse online players fail: %v", err)
+		return
+	}
+	if max, err = strconv.Atoi(fields[2]); err != nil {
+		err = fmt.Errorf("legacy ping: parse max players fail: %v", err)
+		return
+	}
+	return
+}
@@ -0,0 +1,119 @@
+package net
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// response of a vanilla 1.6.4 server
+var legacyPingResponse164 = []byte{
+	0xff, 0x00, 0x23, 0x00, 0xa7, 0x00, 0x31, 0x00, 0x00, 0x00, 0x37, 0x00, 0x38, 0x00, 0x00, 0x00,
+	0x31, 0x00, 0x2e, 0x00, 0x36, 0x00, 0x2e, 0x00, 0x34, 0x00, 0x00, 0x00, 0x41, 0x00, 0x20, 0x00,
+	0x4d, 0x00, 0x69, 0x00, 0x6e, 0x00, 0x65, 0x00, 0x63, 0x00, 0x72, 0x00, 0x61, 0x00, 0x66, 0x00,
+	0x74, 0x00, 0x20, 0x00, 0x53, 0x00, 0x65, 0x00, 0x72, 0x00, 0x76, 0x00, 0x65, 0x00, 0x72, 0x00,
+	0x00, 0x00, 0x30, 0x00, 0x00, 0x00, 0x32, 0x00, 0x30,
+}
+
+func TestParseLegacyPing(t *testing.T) {
+	protocol, motd, online, max, err := parseLegacyPing(bytes.NewReader(legacyPingResponse164))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if protocol != 78 || motd != "A Minecraft Server" || online != 0 || max != 20 {
+		t.Errorf("wrong result: %d %q %d/%d", protocol, motd, online, max)
+	}
+}
+
+func TestParseLegacyPing_beta(t *testing.T) {
+	resp := utf16.Encode([]rune("A §aMinecraft§r Server§3§10"))
+	var buf bytes.Buffer
+	buf.WriteByte(0xFF)
+	_ = binary.Write(&buf, binary.BigEndian, uint16(len(resp)))
+	_ = binary.Write(&buf, binary.BigEndian, resp)
+
+	protocol, motd, online, max, err := parseLegacyPing(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if protocol != -1 || motd != "A §aMinecraft§r Server" || online != 3 || max != 10 {
+		t.Errorf("wrong result: %d %q %d/%d", protocol, motd, online, max)
+	}
+}
+
+func TestLegacyPingRequest(t *testing.T) {
+	want := []byte{
+		0xfe, 0x01, 0xfa,
+		0x00, 0x0b, // len("MC|PingHost")
+		0x00, 0x4d, 0x00, 0x43, 0x00, 0x7c, 0x00, 0x50, 0x00, 0x69, 0x00, 0x6e,
+		0x00, 0x67, 0x00, 0x48, 0x00, 0x6f, 0x00, 0x73, 0x00, 0x74,
+		0x00, 0x11, // 7 + 2*len("local")
+		0x4e,       // protocol 78
+		0x00, 0x05, // len("local")
+		0x00, 0x6c, 0x00, 0x6f, 0x00, 0x63, 0x00, 0x61, 0x00, 0x6c,
+		0x00, 0x00, 0x63, 0xdd, // port 25565
+	}
+	if got := legacyPingRequest("local", 25565); !bytes.Equal(got, want) {
+		t.Errorf("request should be\n% x\nget\n% x", want, got)
+	}
+}
+
+func TestLegacyPingContext_timeout(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() { // accept but never response
+		conn, err := l.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _, _, _, err = LegacyPingContext(ctx, l.Addr().String())
+	if err == nil {
+		t.Fatal("ping a silent server should be error")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error should wrap the context error, get %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("ping didn't stop at the context deadline")
+	}
+}
+
+func TestLegacyPingContext_cancel(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() { // accept but never response
+		conn, err := l.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	_, _, _, _, err = LegacyPingContext(ctx, l.Addr().String())
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error should wrap the context error, get %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("ping didn't stop when the context is canceled")
+	}
+}
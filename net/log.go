@@ -0,0 +1,32 @@
+package net
+
+// Logger receives the logs of a Conn, and the bot Client.
+// The sugared loggers of the popular logging libraries satisfy it.
+//
+// The packets read and written are logged at the debug level, with their
+// ID and length. The state transitions of the connection are logged at the
+// info level, and the packets failed to be decoded at the warn level.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// NopLogger is a Logger discards all logs.
+// A nil Logger is the same, and both of them cost nothing at the hot path.
+var NopLogger Logger = nopLogger{}
+
+type nopLogger struct{}
+
+func (nopLogger) Debugf(string, ...interface{}) {}
+func (nopLogger) Infof(string, ...interface{})  {}
+func (nopLogger) Warnf(string, ...interface{})  {}
+func (nopLogger) Errorf(string, ...interface{}) {}
+
+// logging report whether l should be called.
+// The args of a call to the Logger escape to the heap, so it's checked
+// before building them.
+func logging(l Logger) bool {
+	return l != nil && l != NopLogger
+}
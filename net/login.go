@@ -0,0 +1,183 @@
+package net
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/Tnze/go-mc/auth"
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
+)
+
+// LoginOptions are the options of Login
+type LoginOptions struct {
+	// Host and Port are the server address sent in the handshake
+	Host string
+	Port int
+	// Protocol is the protocol version of the handshake.
+	// If it's 0, data.ProtocolVersion is used.
+	Protocol int
+	// AccessToken is the access token of the player's account, which is
+	// needed to join the servers in online mode. Without it, only the
+	// servers in offline mode can be joined.
+	AccessToken string
+	// OnPluginRequest answers the Login Plugin Requests of the server, which
+	// are used by the modded servers and the proxies like Velocity.
+	// If it's nil or understood is false, the request is answered with not
+	// understood, and the response is ignored.
+	OnPluginRequest func(channel string, data []byte) (response []byte, understood bool)
+}
+
+// ErrNoAccessToken is returned by Login when the server is in online mode
+// while no access token is given.
+var ErrNoAccessToken = errors.New("net: the server requests encryption, but no access token is given")
+
+// Login log the player of profile in the server through conn, which should
+// be just connected. It sends the handshake and Login Start, then handles the
+// packets of the server until Login Success:
+//
+// The encryption is enabled on conn after telling the session server the
+// player is joining, if the server is in online mode. Only the Name of
+// profile is needed for offline mode, while the ID is needed for online mode.
+//
+// The compression is enabled on conn when the server sets it, and the
+// threshold is returned, which is -1 if the compression isn't enabled.
+// The Login Plugin Requests are answered by opts.OnPluginRequest.
+//
+// If the server disconnects, the error is a *DisconnectError.
+// The serverProfile is the UUID and name of Login Success. After Login
+// returns without error, conn is in the play state.
+func Login(conn *Conn, profile auth.Profile, opts LoginOptions) (threshold int, serverProfile auth.Profile, err error) {
+	threshold = -1
+	protocol := opts.Protocol
+	if protocol == 0 {
+		protocol = data.ProtocolVersion
+	}
+	err = conn.WritePacket(pk.Marshal(
+		0x00, // Handshake
+		pk.VarInt(protocol),
+		pk.String(opts.Host),
+		pk.UnsignedShort(opts.Port),
+		pk.VarInt(2), // next state: login
+	))
+	if err != nil {
+		return threshold, serverProfile, fmt.Errorf("net: send handshake packet fail: %v", err)
+	}
+	if err = conn.WritePacket(pk.Marshal(0x00, pk.String(profile.Name))); err != nil {
+		return threshold, serverProfile, fmt.Errorf("net: send login start packet fail: %v", err)
+	}
+
+	for {
+		var p pk.Packet
+		if p, err = conn.ReadPacket(); err != nil {
+			return threshold, serverProfile, fmt.Errorf("net: recv packet for login fail: %v", err)
+		}
+		switch p.ID {
+		case 0x00: // Disconnect
+			var de *DisconnectError
+			if de, err = DecodeDisconnect(p, data.Login); err != nil {
+				return threshold, serverProfile, err
+			}
+			return threshold, serverProfile, de
+		case 0x01: // Encryption Request
+			if opts.AccessToken == "" {
+				return threshold, serverProfile, ErrNoAccessToken
+			}
+			if err = loginEncrypt(conn, p, profile, opts.AccessToken); err != nil {
+				return threshold, serverProfile, fmt.Errorf("net: encryption fail: %w", err)
+			}
+		case 0x02: // Login Success
+			var (
+				id   pk.UUID
+				name pk.String
+			)
+			if err = p.Scan(&id, &name); err != nil {
+				return threshold, serverProfile, fmt.Errorf("net: read login success fail: %v", err)
+			}
+			serverProfile = auth.Profile{ID: uuid.UUID(id), Name: string(name)}
+			return threshold, serverProfile, nil
+		case 0x03: // Set Compression
+			var t pk.VarInt
+			if err = p.Scan(&t); err != nil {
+				return threshold, serverProfile, fmt.Errorf("net: read set compression fail: %v", err)
+			}
+			threshold = int(t)
+			if err = conn.EnableCompression(threshold); err != nil {
+				return threshold, serverProfile, err
+			}
+		case 0x04: // Login Plugin Request
+			if err = loginPluginResponse(conn, p, opts.OnPluginRequest); err != nil {
+				return threshold, serverProfile, fmt.Errorf("net: login plugin request fail: %v", err)
+			}
+		}
+	}
+}
+
+// loginPluginResponse answer the Login Plugin Request p with the same message ID
+func loginPluginResponse(conn *Conn, p pk.Packet, onRequest func(string, []byte) ([]byte, bool)) error {
+	var (
+		msgID   pk.VarInt
+		channel pk.Identifier
+	)
+	r := bytes.NewReader(p.Data)
+	if err := msgID.Decode(r); err != nil {
+		return err
+	}
+	if err := channel.Decode(r); err != nil {
+		return err
+	}
+	data := p.Data[len(p.Data)-r.Len():] // the rest of the packet
+
+	var (
+		response   []byte
+		understood bool
+	)
+	if onRequest != nil {
+		response, understood = onRequest(string(channel), data)
+	}
+	if !understood {
+		return conn.WritePacket(pk.Marshal(0x02, msgID, pk.Boolean(false)))
+	}
+	return conn.WritePacket(pk.Marshal(0x02, msgID, pk.Boolean(true), rawBytes(response)))
+}
+
+// rawBytes is a field of the bytes without length prefix,
+// which is the rest of a packet
+type rawBytes []byte
+
+func (b rawBytes) Encode() []byte { return b }
+
+// loginEncrypt handle the Encryption Request p: generate the shared secret,
+// join the session server, send the Encryption Response and enable the encryption.
+func loginEncrypt(conn *Conn, p pk.Packet, profile auth.Profile, accessToken string) error {
+	var (
+		serverID    pk.String
+		publicKey   pk.ByteArray
+		verifyToken pk.ByteArray
+	)
+	if err := p.Scan(&serverID, &publicKey, &verifyToken); err != nil {
+		return fmt.Errorf("read encryption request fail: %v", err)
+	}
+
+	sharedSecret := make([]byte, 16)
+	if _, err := rand.Read(sharedSecret); err != nil {
+		return fmt.Errorf("gen shared secret fail: %v", err)
+	}
+	hash := auth.ServerHash(string(serverID), sharedSecret, publicKey)
+	if err := auth.Join(accessToken, profile, hash); err != nil {
+		return err
+	}
+
+	cryptSecret, cryptToken, err := auth.EncryptResponse(publicKey, sharedSecret, verifyToken)
+	if err != nil {
+		return err
+	}
+	err = conn.WritePacket(pk.Marshal(0x01, pk.ByteArray(cryptSecret), pk.ByteArray(cryptToken)))
+	if err != nil {
+		return fmt.Errorf("send encryption response fail: %v", err)
+	}
+	return conn.SetEncryption(sharedSecret)
+}
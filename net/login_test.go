@@ -0,0 +1,246 @@
+package net
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Tnze/go-mc/auth"
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/Tnze/go-mc/offline"
+	"github.com/google/uuid"
+)
+
+// loginServer read the handshake and Login Start from conn,
+// then run fn as the rest of the server side of the login.
+func loginServer(conn *Conn, fn func(name pk.String) error) <-chan error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- func() error {
+			p, err := conn.ReadPacket()
+			if err != nil {
+				return err
+			}
+			var (
+				protocol, next pk.VarInt
+				host           pk.String
+				port           pk.UnsignedShort
+			)
+			if err := p.Scan(&protocol, &host, &port, &next); err != nil {
+				return err
+			}
+			if protocol != data.ProtocolVersion || host != "localhost" || port != 25565 || next != 2 {
+				return fmt.Errorf("handshake: %d %s %d %d", protocol, host, port, next)
+			}
+			if p, err = conn.ReadPacket(); err != nil {
+				return err
+			}
+			var name pk.String
+			if err := p.Scan(&name); err != nil {
+				return err
+			}
+			return fn(name)
+		}()
+	}()
+	return errs
+}
+
+func TestLogin_offline(t *testing.T) {
+	client, server := pipe()
+	errs := loginServer(server, func(name pk.String) error {
+		if err := server.WritePacket(pk.Marshal(0x03, pk.VarInt(256))); err != nil {
+			return err
+		}
+		server.SetThreshold(256)
+		if err := server.WritePacket(pk.Marshal(0x04, pk.VarInt(7), pk.Identifier("velocity:player_info"))); err != nil {
+			return err
+		}
+		p, err := server.ReadPacket()
+		if err != nil {
+			return err
+		}
+		var (
+			msgID      pk.VarInt
+			understood pk.Boolean
+		)
+		if err := p.Scan(&msgID, &understood); err != nil {
+			return err
+		}
+		if p.ID != 0x02 || msgID != 7 || understood {
+			return fmt.Errorf("login plugin response: 0x%02X %d %v", p.ID, msgID, understood)
+		}
+		return server.WritePacket(pk.Marshal(0x02, pk.UUID(offline.NameUUID(string(name))), name))
+	})
+
+	threshold, profile, err := Login(client, auth.Profile{Name: "Tnze"}, LoginOptions{Host: "localhost", Port: 25565})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if threshold != 256 {
+		t.Errorf("threshold: got %d, want 256", threshold)
+	}
+	if profile.Name != "Tnze" || profile.ID != offline.NameUUID("Tnze") {
+		t.Errorf("profile: got %v", profile)
+	}
+	if client.State() != data.Play {
+		t.Errorf("state: got %v, want play", client.State())
+	}
+}
+
+func TestLogin_disconnect(t *testing.T) {
+	client, server := pipe()
+	errs := loginServer(server, func(pk.String) error {
+		return server.WritePacket(pk.Marshal(0x00, pk.Chat(`{"text":"banned"}`)))
+	})
+	_, _, err := Login(client, auth.Profile{Name: "Tnze"}, LoginOptions{Host: "localhost", Port: 25565})
+	var de *DisconnectError
+	if !errors.As(err, &de) {
+		t.Fatalf("login should fail with DisconnectError, got %v", err)
+	}
+	if de.State != data.Login || de.Reason.ClearString() != "banned" || de.RawReason != `{"text":"banned"}` {
+		t.Errorf("wrong disconnect error: %+v", de)
+	}
+	if err.Error() != "net: disconnected by server: banned" {
+		t.Errorf("error message: %s", err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLogin_online(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notch := auth.Profile{ID: uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5"), Name: "Notch"}
+
+	joined := make(chan string, 1)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			AccessToken string `json:"accessToken"`
+			ServerID    string `json:"serverId"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.AccessToken != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		joined <- req.ServerID
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer s.Close()
+	defer func(u string) { auth.SessionURL = u }(auth.SessionURL)
+	auth.SessionURL = s.URL
+
+	client, server := pipe()
+	errs := loginServer(server, func(name pk.String) error {
+		err := server.WritePacket(pk.Marshal(0x01, pk.String(""), pk.ByteArray(publicKey), pk.ByteArray("abcd")))
+		if err != nil {
+			return err
+		}
+		p, err := server.ReadPacket()
+		if err != nil {
+			return err
+		}
+		var cryptSecret, cryptToken pk.ByteArray
+		if err := p.Scan(&cryptSecret, &cryptToken); err != nil {
+			return err
+		}
+		secret, err := rsa.DecryptPKCS1v15(rand.Reader, key, cryptSecret)
+		if err != nil {
+			return err
+		}
+		if token, err := rsa.DecryptPKCS1v15(rand.Reader, key, cryptToken); err != nil || string(token) != "abcd" {
+			return fmt.Errorf("verify token: %q %v", token, err)
+		}
+		if hash := auth.ServerHash("", secret, publicKey); hash != <-joined {
+			return fmt.Errorf("the player joined with a different hash")
+		}
+		if err := server.SetEncryption(secret); err != nil {
+			return err
+		}
+		return server.WritePacket(pk.Marshal(0x02, pk.UUID(notch.ID), name))
+	})
+
+	threshold, profile, err := Login(client, notch, LoginOptions{Host: "localhost", Port: 25565, AccessToken: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if threshold != -1 {
+		t.Errorf("threshold: got %d, want -1", threshold)
+	}
+	if profile.ID != notch.ID || profile.Name != notch.Name {
+		t.Errorf("profile: got %v, want %v", profile, notch)
+	}
+}
+
+func TestLogin_noAccessToken(t *testing.T) {
+	client, server := pipe()
+	errs := loginServer(server, func(pk.String) error {
+		return server.WritePacket(pk.Marshal(0x01, pk.String(""), pk.ByteArray{}, pk.ByteArray{}))
+	})
+	_, _, err := Login(client, auth.Profile{Name: "Tnze"}, LoginOptions{Host: "localhost", Port: 25565})
+	if err != ErrNoAccessToken {
+		t.Errorf("got %v, want ErrNoAccessToken", err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLogin_pluginRequest(t *testing.T) {
+	client, server := pipe()
+	errs := loginServer(server, func(name pk.String) error {
+		for i, channel := range []pk.Identifier{"velocity:player_info", "unknown:channel"} {
+			if err := server.WritePacket(pk.Marshal(0x04, pk.VarInt(i), channel, rawBytes{1, 2, 3})); err != nil {
+				return err
+			}
+			p, err := server.ReadPacket()
+			if err != nil {
+				return err
+			}
+			want := pk.Marshal(0x02, pk.VarInt(i), pk.Boolean(false))
+			if i == 0 {
+				want = pk.Marshal(0x02, pk.VarInt(i), pk.Boolean(true), rawBytes{3, 2, 1})
+			}
+			if p.ID != want.ID || !bytes.Equal(p.Data, want.Data) {
+				return fmt.Errorf("login plugin response of %s: [% x]", channel, p.Data)
+			}
+		}
+		return server.WritePacket(pk.Marshal(0x02, pk.UUID(offline.NameUUID(string(name))), name))
+	})
+
+	_, _, err := Login(client, auth.Profile{Name: "Tnze"}, LoginOptions{
+		Host: "localhost", Port: 25565,
+		OnPluginRequest: func(channel string, data []byte) ([]byte, bool) {
+			if channel != "velocity:player_info" || !bytes.Equal(data, []byte{1, 2, 3}) {
+				return nil, false
+			}
+			return []byte{3, 2, 1}, true
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
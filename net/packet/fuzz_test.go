@@ -0,0 +1,60 @@
+//go:build go1.18
+// +build go1.18
+
+package packet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Tnze/go-mc/nbt"
+)
+
+// FuzzPacket_Decode decode the fields like a 1.16 Chunk Data.
+// Any data is either decoded or rejected by an error, and never panics.
+func FuzzPacket_Decode(f *testing.F) {
+	var seed bytes.Buffer
+	seed.Write(VarInt(0x21).Encode())
+	seed.Write(Marshal(0, Int(1), Int(-2), Boolean(true), Boolean(false), VarInt(0x03)).Data)
+	_ = nbt.Marshal(&seed, map[string][]int64{"MOTION_BLOCKING": {1, 2, 3}})
+	seed.Write(Marshal(0, Ary{Len: VarInt(0), Ary: []VarInt{1, 2, 3}}, ByteArray{0x00, 0x01, 0x02}, VarInt(1)).Data)
+	_ = nbt.Marshal(&seed, map[string]interface{}{"id": "minecraft:chest"})
+	f.Add(seed.Bytes())
+	f.Add([]byte{0x21})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var (
+			p                   Packet
+			x, z                Int
+			full, ignoreOldData Boolean
+			mask                VarInt
+			heightmaps          map[string][]int64
+			biomes              []VarInt
+			sections            ByteArray
+			blockEntities       []nbtEntity
+		)
+		_ = p.Decode(data, &x, &z, &full, &ignoreOldData, &mask, NBT{V: &heightmaps},
+			Ary{Len: new(VarInt), Ary: &biomes, Max: 1024},
+			&sections,
+			Ary{Len: new(VarInt), Ary: &blockEntities})
+	})
+}
+
+// nbtEntity is a Field of any NBT compound
+type nbtEntity struct{ V interface{} }
+
+func (e *nbtEntity) Decode(r DecodeReader) error { return NBT{V: &e.V}.Decode(r) }
+
+// FuzzUnpack parse the content of packets, compressed or not
+func FuzzUnpack(f *testing.F) {
+	big := Marshal(0x22, ByteArray(bytes.Repeat([]byte{'x'}, 300)))
+	f.Add(big.Pack(256)[2:], true)
+	f.Add(big.Pack(-1)[2:], false)
+	f.Add([]byte{0x00, 0x01, 0x02}, true)
+	f.Fuzz(func(t *testing.T, data []byte, useZlib bool) {
+		p, err := Unpack(append([]byte(nil), data...), useZlib)
+		if err == nil && len(p.Data) > MaxLengthPrefixed {
+			t.Errorf("unpacked %d bytes", len(p.Data))
+		}
+		_, _ = UnCompress(data)
+	})
+}
@@ -36,11 +36,28 @@ func (p Packet) Scan(fields ...FieldDecoder) error {
 	return nil
 }
 
+// Decode parse data, the uncompressed content of a packet which is the
+// packet ID followed by the fields, into p, then decode the fields like Scan.
+// The Data of p refers to data without copying.
+//
+// The decoders of this package return errors rather than panicking with
+// any malformed data, so Decode is the entry of fuzzing the packets.
+func (p *Packet) Decode(data []byte, fields ...FieldDecoder) error {
+	r := bytes.NewReader(data)
+	id, _, err := ReadVarInt(r)
+	if err != nil {
+		return fmt.Errorf("read packet id fail: %v", err)
+	}
+	p.ID, p.Data = id, data[len(data)-r.Len():]
+	return p.Scan(fields...)
+}
+
 // Pack 打包一个数据包
+// A negative threshold means the compression is disabled.
 func (p *Packet) Pack(threshold int) (pack []byte) {
 	data := append(VarInt(p.ID).Encode(), p.Data...)
-	if threshold > 0 { //是否启用了压缩
-		if len(data) > threshold { //是否需要压缩
+	if threshold >= 0 { //是否启用了压缩
+		if len(data) >= threshold { //是否需要压缩
 			Len := len(data)
 			VarLen := VarInt(Len).Encode()
 			data = Compress(data)
@@ -71,25 +88,12 @@ func RecvPacket(r DecodeReader, useZlib bool) (*Packet, error) {
 		return nil, fmt.Errorf("packet length too short")
 	}
 
-	data := make([]byte, length) // read packet content
+	data := GetBuffer(int(length)) // read packet content
 	if _, err := io.ReadFull(r, data); err != nil {
+		(&Packet{Data: data}).Release()
 		return nil, fmt.Errorf("read content of packet fail: %v", err)
 	}
-
-	//解压数据
-	if useZlib {
-		return UnCompress(data)
-	}
-
-	buf := bytes.NewBuffer(data)
-	var packetID VarInt
-	if err := packetID.Decode(buf); err != nil {
-		return nil, fmt.Errorf("read packet id fail: %v", err)
-	}
-	return &Packet{
-		ID:   int32(packetID),
-		Data: buf.Bytes(),
-	}, nil
+	return Unpack(data, useZlib)
 }
 
 // UnCompress 读取一个压缩的包
@@ -101,6 +105,9 @@ func UnCompress(data []byte) (*Packet, error) {
 		return nil, err
 	}
 
+	if err := checkUncompressedSize(sizeUncompressed); err != nil {
+		return nil, err
+	}
 	uncompressData := make([]byte, sizeUncompressed)
 	if sizeUncompressed != 0 { // != 0 means compressed, let's decompress
 		r, err := zlib.NewReader(reader)
@@ -113,7 +120,7 @@ func UnCompress(data []byte) (*Packet, error) {
 			return nil, fmt.Errorf("decompress fail: %v", err)
 		}
 	} else {
-		uncompressData = data[1:]
+		uncompressData = data[len(data)-reader.Len():]
 	}
 	buf := bytes.NewBuffer(uncompressData)
 	var packetID VarInt
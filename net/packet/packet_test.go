@@ -2,6 +2,9 @@ package packet
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
 	"testing"
 )
 
@@ -84,3 +87,287 @@ func TestUnpackVarLong(t *testing.T) {
 		}
 	}
 }
+
+func TestUnpackVarLong_TooLongData(t *testing.T) {
+	var vl VarLong
+	var data = []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}
+	if err := vl.Decode(bytes.NewReader(data)); err != nil {
+		t.Logf("unpack \"% x\" error: %v", data, err)
+	} else {
+		t.Errorf("unpack \"% x\" should be error, get %d", data, vl)
+	}
+}
+
+func TestUnpackVarLong_Truncated(t *testing.T) {
+	for _, v := range PackedVarLongs {
+		if len(v) < 2 {
+			continue
+		}
+		var vl VarLong
+		data := v[:len(v)-1]
+		if err := vl.Decode(bytes.NewReader(data)); err == nil {
+			t.Errorf("unpack truncated \"% x\" should be error, get %d", data, vl)
+		}
+	}
+}
+
+var positions = []Position{
+	{X: 18357644, Y: 831, Z: -20882616},
+	{X: -1, Y: -1, Z: -1},
+	{X: 1, Y: -2, Z: 3},
+	{X: -33554432, Y: -2048, Z: 33554431},
+}
+
+var packedPositions = [][]byte{
+	{0x46, 0x07, 0x63, 0x2c, 0x15, 0xb4, 0x83, 0x3f},
+	{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	{0x00, 0x00, 0x00, 0x40, 0x00, 0x00, 0x3f, 0xfe},
+	{0x80, 0x00, 0x00, 0x1f, 0xff, 0xff, 0xf8, 0x00},
+}
+
+var packedPositionsPre1_14 = [][]byte{
+	{0x46, 0x07, 0x63, 0x0c, 0xfe, 0xc1, 0x5b, 0x48},
+	{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	{0x00, 0x00, 0x00, 0x7f, 0xf8, 0x00, 0x00, 0x03},
+	{0x80, 0x00, 0x00, 0x20, 0x01, 0xff, 0xff, 0xff},
+}
+
+func TestPosition(t *testing.T) {
+	for i, v := range positions {
+		if p := v.Encode(); !bytes.Equal(p, packedPositions[i]) {
+			t.Errorf("pack position %v should be \"% x\", get \"% x\"", v, packedPositions[i], p)
+		}
+		var pos Position
+		if err := pos.Decode(bytes.NewReader(packedPositions[i])); err != nil {
+			t.Errorf("unpack \"% x\" error: %v", packedPositions[i], err)
+		}
+		if pos != v {
+			t.Errorf("unpack \"% x\" should be %v, get %v", packedPositions[i], v, pos)
+		}
+	}
+}
+
+func TestPositionPre1_14(t *testing.T) {
+	for i, v := range positions {
+		if p := PositionPre1_14(v).Encode(); !bytes.Equal(p, packedPositionsPre1_14[i]) {
+			t.Errorf("pack position %v should be \"% x\", get \"% x\"", v, packedPositionsPre1_14[i], p)
+		}
+		var pos Position
+		if err := PositionWithVersion(&pos, 404).Decode(bytes.NewReader(packedPositionsPre1_14[i])); err != nil {
+			t.Errorf("unpack \"% x\" error: %v", packedPositionsPre1_14[i], err)
+		}
+		if pos != v {
+			t.Errorf("unpack \"% x\" should be %v, get %v", packedPositionsPre1_14[i], v, pos)
+		}
+	}
+}
+
+func TestUUID(t *testing.T) {
+	const s = "069a79f4-44e9-4726-a5be-fca90e38aaf5" // Notch
+	u, err := ParseUUID(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.String() != s {
+		t.Errorf("String() = %q, want %q", u.String(), s)
+	}
+	if v, err := ParseUUID("069a79f444e94726a5befca90e38aaf5"); err != nil || v != u {
+		t.Errorf("parse without dashes: %v, %v", v, err)
+	}
+
+	want := []byte{
+		0x06, 0x9a, 0x79, 0xf4, 0x44, 0xe9, 0x47, 0x26,
+		0xa5, 0xbe, 0xfc, 0xa9, 0x0e, 0x38, 0xaa, 0xf5,
+	}
+	if !bytes.Equal(u.Encode(), want) {
+		t.Errorf("Encode() = % x, want % x", u.Encode(), want)
+	}
+	var d UUID
+	if err := d.Decode(bytes.NewReader(want)); err != nil || d != u {
+		t.Errorf("Decode: %v, %v", d, err)
+	}
+
+	most, least := u.Longs()
+	if most != 0x069a79f444e94726 || uint64(least) != 0xa5befca90e38aaf5 {
+		t.Errorf("Longs() = %x, %x", most, least)
+	}
+	if UUIDFromLongs(most, least) != u {
+		t.Error("UUIDFromLongs is not the inverse of Longs")
+	}
+
+	j, err := json.Marshal(struct{ ID UUID }{u})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(j) != `{"ID":"`+s+`"}` {
+		t.Errorf("json: %s", j)
+	}
+	var v struct{ ID UUID }
+	if err := json.Unmarshal(j, &v); err != nil || v.ID != u {
+		t.Errorf("json unmarshal: %v, %v", v.ID, err)
+	}
+}
+
+func TestAngle(t *testing.T) {
+	for _, tc := range []struct {
+		deg  float64
+		want Angle
+	}{
+		{0, 0},
+		{90, 64},
+		{180, 128},
+		{270, 192},
+		{360, 0},
+		{-90, 192},
+		{-180, 128},
+		{720 + 90, 64},
+	} {
+		if a := AngleFromDegrees(tc.deg); a != tc.want {
+			t.Errorf("AngleFromDegrees(%v) = %d, want %d", tc.deg, a, tc.want)
+		}
+	}
+	if d := Angle(64).Degrees(); d != 90 {
+		t.Errorf("Angle(64).Degrees() = %v, want 90", d)
+	}
+	if d := AngleFromDegrees(-90).Degrees(); d != 270 {
+		t.Errorf("-90 degrees wrapped to %v, want 270", d)
+	}
+
+	var a Angle
+	if err := a.Decode(bytes.NewReader(Angle(128).Encode())); err != nil || a != 128 {
+		t.Errorf("decode angle: %d, %v", a, err)
+	}
+}
+
+func TestFixedPointInt(t *testing.T) {
+	for _, tc := range []struct {
+		blocks float64
+		want   FixedPointInt
+	}{
+		{0, 0},
+		{1, 32},
+		{1.5, 48},
+		{1.01, 32}, // rounded down
+		{1.99, 63}, // not to the nearest
+		{-1, -32},
+		{-1.01, -33}, // rounded down to the more negative
+		{-0.01, -1},
+		{-100.5, -3216},
+	} {
+		if f := FixedPointIntFromBlocks(tc.blocks); f != tc.want {
+			t.Errorf("FixedPointIntFromBlocks(%v) = %d, want %d", tc.blocks, f, tc.want)
+		}
+	}
+	if b := FixedPointInt(-48).Blocks(); b != -1.5 {
+		t.Errorf("FixedPointInt(-48).Blocks() = %v, want -1.5", b)
+	}
+
+	data := FixedPointInt(-33).Encode()
+	if !bytes.Equal(data, []byte{0xFF, 0xFF, 0xFF, 0xDF}) {
+		t.Errorf("encode FixedPointInt(-33): % X", data)
+	}
+	var f FixedPointInt
+	if err := f.Decode(bytes.NewReader(data)); err != nil || f != -33 {
+		t.Errorf("decode FixedPointInt: %d, %v", f, err)
+	}
+}
+
+func TestReadNBytes(t *testing.T) {
+	data := make([]byte, 3*allocChunk+5)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	for _, n := range []int{0, 5, allocChunk, len(data)} {
+		bs, err := ReadNBytes(bytes.NewReader(data), n)
+		if err != nil || !bytes.Equal(bs, data[:n]) {
+			t.Errorf("read %d bytes: %d bytes, %v", n, len(bs), err)
+		}
+	}
+
+	// truncated, both within and after the first chunk
+	for _, n := range []int{10, len(data) + 1} {
+		if _, err := ReadNBytes(bytes.NewReader(data[:n-1]), n); err != io.ErrUnexpectedEOF {
+			t.Errorf("read %d bytes from %d: %v, want io.ErrUnexpectedEOF", n, n-1, err)
+		}
+	}
+	if _, err := ReadNBytes(bytes.NewReader(nil), 1); err != io.EOF {
+		t.Errorf("read from empty: %v, want io.EOF", err)
+	}
+	if _, err := ReadNBytes(bytes.NewReader(data), -1); err == nil {
+		t.Error("read negative length should be error")
+	}
+}
+
+func TestReadLengthPrefixed(t *testing.T) {
+	// the reader without ReadByte is supported
+	r := io.MultiReader(bytes.NewReader(ByteArray("hello").Encode()), bytes.NewReader([]byte("!")))
+	bs, err := ReadLengthPrefixed(r)
+	if err != nil || string(bs) != "hello" {
+		t.Errorf("read %q, %v", bs, err)
+	}
+
+	for _, v := range []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{name: "empty", data: nil, err: io.EOF},
+		{name: "truncated length", data: []byte{0x80}, err: io.EOF},
+		{name: "truncated bytes", data: []byte{0x03, 'a', 'b'}, err: io.ErrUnexpectedEOF},
+		{name: "no bytes", data: []byte{0x03}, err: io.ErrUnexpectedEOF},
+		{name: "max length", data: VarInt(MaxLengthPrefixed).Encode(), err: io.ErrUnexpectedEOF},
+		{name: "too long", data: VarInt(MaxLengthPrefixed + 1).Encode(), err: ErrTooLong},
+		{name: "negative", data: VarInt(-1).Encode(), err: nil},
+	} {
+		_, err := ReadLengthPrefixed(bytes.NewReader(v.data))
+		if err == nil || v.err != nil && !errors.Is(err, v.err) {
+			t.Errorf("%s: got %v, want %v", v.name, err, v.err)
+		}
+	}
+
+	var s String
+	if err := s.Decode(bytes.NewReader(VarInt(MaxLengthPrefixed + 1).Encode())); !errors.Is(err, ErrTooLong) {
+		t.Errorf("decode too long String: %v", err)
+	}
+	var b ByteArray
+	if err := b.Decode(bytes.NewReader([]byte{0x03, 1, 2})); err != io.ErrUnexpectedEOF {
+		t.Errorf("decode truncated ByteArray: %v", err)
+	}
+}
+
+func TestPacket_Decode(t *testing.T) {
+	var (
+		p    Packet
+		name String
+		n    VarInt
+	)
+	data := append(VarInt(0x21).Encode(), Marshal(0, String("Steve"), VarInt(300)).Data...)
+	if err := p.Decode(data, &name, &n); err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != 0x21 || name != "Steve" || n != 300 {
+		t.Errorf("got packet 0x%02X with %q %d", p.ID, name, n)
+	}
+
+	for _, data := range [][]byte{
+		nil,                            // no packet ID
+		{0x80, 0x80, 0x80, 0x80, 0x80}, // bad packet ID
+		{0x21, 0x05, 'S'},              // truncated String
+	} {
+		if err := p.Decode(data, &name, &n); err == nil {
+			t.Errorf("decode \"% x\": no error", data)
+		}
+	}
+}
+
+func TestUnpack_badSize(t *testing.T) {
+	for _, size := range []VarInt{-1, MaxLengthPrefixed + 1} {
+		data := append(size.Encode(), 0x78, 0x9c, 0x00)
+		if _, err := Unpack(append([]byte(nil), data...), true); err == nil {
+			t.Errorf("unpack with uncompressed size %d: no error", size)
+		}
+		if _, err := UnCompress(data); err == nil {
+			t.Errorf("uncompress with uncompressed size %d: no error", size)
+		}
+	}
+}
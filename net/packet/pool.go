@@ -0,0 +1,130 @@
+package packet
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// maxPooledSize is the max capacity of the buffers kept by the pool.
+// Bigger buffers are left to the GC, or the pool may hold too much memory.
+const maxPooledSize = 1 << 20
+
+// The pool of packet buffers.
+//
+// The Data of packets returned by RecvPacket, Unpack and MarshalPooled
+// are from the pool. Calling Release of them when they are no longer used
+// lets later packets reuse the buffers, but it's never required.
+// After Release, neither the Data nor any slice of it can be used, because
+// the buffer will be overwritten by another packet. The values decoded by
+// Scan don't refer to the Data and are safe to keep.
+var bufPool sync.Pool
+
+// GetBuffer return a buffer of length n, from the pool if possible.
+// The buffer can be put back by Release of a Packet having it as Data.
+func GetBuffer(n int) []byte {
+	if b, ok := bufPool.Get().([]byte); ok && cap(b) >= n {
+		return b[:n]
+	}
+	return make([]byte, n)
+}
+
+// Release put the Data of the packet back to the pool, and set it to nil.
+// See GetBuffer for the ownership of the buffer.
+func (p *Packet) Release() {
+	if p.Data != nil && cap(p.Data) <= maxPooledSize {
+		bufPool.Put(p.Data[:0])
+	}
+	p.Data = nil
+}
+
+// MarshalPooled is like Marshal, but the Data is a buffer from the pool.
+func MarshalPooled(ID int32, fields ...FieldEncoder) (pk Packet) {
+	pk.ID = ID
+	pk.Data = GetBuffer(0)
+	for _, v := range fields {
+		pk.Data = append(pk.Data, v.Encode()...)
+	}
+	return
+}
+
+// zlibPool reuses the zlib readers, which are expensive to create.
+var zlibPool sync.Pool
+
+// Unpack parse the content of a packet, which follows the length prefix.
+// If useZlib, the content is in the compressed format.
+//
+// Unpack takes the ownership of data. The Data of returned Packet is either
+// the buffer of data, or a buffer from the pool, when data is put back to the pool.
+// If an error is returned, data is put back to the pool.
+func Unpack(data []byte, useZlib bool) (_ *Packet, err error) {
+	defer func() {
+		if err != nil {
+			(&Packet{Data: data}).Release()
+		}
+	}()
+	if useZlib {
+		r := bytes.NewReader(data)
+		var sizeUncompressed VarInt
+		if err := sizeUncompressed.Decode(r); err != nil {
+			return nil, err
+		}
+		if err := checkUncompressedSize(sizeUncompressed); err != nil {
+			return nil, err
+		}
+		if sizeUncompressed != 0 { // != 0 means compressed, let's decompress
+			uncompressData := GetBuffer(int(sizeUncompressed))
+			if err := decompress(r, uncompressData); err != nil {
+				(&Packet{Data: uncompressData}).Release()
+				return nil, fmt.Errorf("decompress fail: %v", err)
+			}
+			(&Packet{Data: data}).Release()
+			data = uncompressData
+		} else {
+			data = data[len(data)-r.Len():]
+		}
+	}
+
+	r := bytes.NewReader(data)
+	var packetID VarInt
+	if err := packetID.Decode(r); err != nil {
+		return nil, fmt.Errorf("read packet id fail: %v", err)
+	}
+	// move the payload to the start of the buffer,
+	// so its full capacity is kept for reusing
+	n := copy(data, data[len(data)-r.Len():])
+	return &Packet{
+		ID:   int32(packetID),
+		Data: data[:n],
+	}, nil
+}
+
+// checkUncompressedSize check the declared size of a compressed packet,
+// before the buffer of that size is allocated
+func checkUncompressedSize(n VarInt) error {
+	if n < 0 {
+		return fmt.Errorf("negative uncompressed size %d", n)
+	}
+	if n > MaxLengthPrefixed {
+		return fmt.Errorf("%w: uncompressed size %d > %d", ErrTooLong, n, MaxLengthPrefixed)
+	}
+	return nil
+}
+
+// decompress read the zlib stream from r and fill up dst
+func decompress(r io.Reader, dst []byte) (err error) {
+	zr, ok := zlibPool.Get().(io.ReadCloser)
+	if ok {
+		err = zr.(zlib.Resetter).Reset(r, nil)
+	} else {
+		zr, err = zlib.NewReader(r)
+	}
+	if err != nil {
+		return err
+	}
+	defer zlibPool.Put(zr)
+	_, err = io.ReadFull(zr, dst)
+	return err
+}
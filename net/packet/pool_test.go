@@ -0,0 +1,102 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRecvPacket_release(t *testing.T) {
+	for _, threshold := range []int{-1, 16} {
+		first := Marshal(0x20, String("a packet long enough for compressing"))
+		p, err := RecvPacket(bytes.NewReader(first.Pack(threshold)), threshold > 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var s String
+		if err := p.Scan(&s); err != nil {
+			t.Fatal(err)
+		}
+		p.Release()
+		if p.Data != nil {
+			t.Error("Data should be nil after Release")
+		}
+
+		second := Marshal(0x21, Long(42))
+		p, err = RecvPacket(bytes.NewReader(second.Pack(threshold)), threshold > 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.ID != second.ID || !bytes.Equal(p.Data, second.Data) {
+			t.Errorf("threshold %d: want %v, get %v", threshold, second, p)
+		}
+		if s != "a packet long enough for compressing" {
+			t.Errorf("the scanned value is changed by the reused buffer: %q", s)
+		}
+	}
+}
+
+func TestUnpack_releaseOnError(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data []byte
+	}{
+		{"negative size", append(VarInt(-1).Encode(), 0x00)},
+		{"bad zlib stream", append(VarInt(100).Encode(), "not a zlib stream"...)},
+	} {
+		// the pool may drop buffers, so try a few times
+		var reused bool
+		for i := 0; i < 10 && !reused; i++ {
+			data := GetBuffer(len(tt.data))
+			copy(data, tt.data)
+			if _, err := Unpack(data, true); err == nil {
+				t.Fatalf("%s: should be error", tt.name)
+			}
+			for j := 0; j < 2; j++ {
+				if b := GetBuffer(0); cap(b) > 0 && &b[:1][0] == &data[0] {
+					reused = true
+				}
+			}
+		}
+		if !reused {
+			t.Errorf("%s: the buffer isn't put back to the pool", tt.name)
+		}
+	}
+}
+
+func TestMarshalPooled(t *testing.T) {
+	fields := []FieldEncoder{VarInt(736), String("localhost"), UnsignedShort(25565), Byte(2)}
+	want := Marshal(0x00, fields...)
+	for i := 0; i < 2; i++ {
+		p := MarshalPooled(0x00, fields...)
+		if p.ID != want.ID || !bytes.Equal(p.Data, want.Data) {
+			t.Errorf("want %v, get %v", want, p)
+		}
+		p.Release()
+	}
+}
+
+func benchmarkRecvPacket(b *testing.B, threshold int, release bool) {
+	p := Marshal(0x22, ByteArray(bytes.Repeat([]byte("go-mc"), 200)))
+	frame := p.Pack(threshold)
+	r := bytes.NewReader(frame)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Reset(frame)
+		p, err := RecvPacket(r, threshold > 0)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if release {
+			p.Release()
+		}
+	}
+}
+
+func BenchmarkRecvPacket(b *testing.B) { benchmarkRecvPacket(b, -1, false) }
+
+func BenchmarkRecvPacket_release(b *testing.B) { benchmarkRecvPacket(b, -1, true) }
+
+func BenchmarkRecvPacket_compressed(b *testing.B) { benchmarkRecvPacket(b, 256, false) }
+
+func BenchmarkRecvPacket_compressedRelease(b *testing.B) { benchmarkRecvPacket(b, 256, true) }
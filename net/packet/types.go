@@ -1,7 +1,9 @@
 package packet
 
 import (
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"io"
 	"math"
@@ -67,9 +69,15 @@ type (
 	Position struct {
 		X, Y, Z int
 	}
+	//PositionPre1_14 is the Position layout used before 1.14 (protocol 477):
+	//x as a 26-bit integer, followed by y as a 12-bit integer, followed by z as a 26-bit integer
+	PositionPre1_14 Position
 
 	//Angle is rotation angle in steps of 1/256 of a full turn
-	Angle int8
+	Angle uint8
+
+	//FixedPointInt is a coordinate in 1/32 blocks, used by the protocols before 1.9
+	FixedPointInt int32
 
 	//UUID encoded as an unsigned 128-bit integer
 	UUID uuid.UUID
@@ -83,18 +91,78 @@ type (
 	ByteArray []byte
 )
 
-//ReadNBytes read N bytes from bytes.Reader
-func ReadNBytes(r DecodeReader, n int) (bs []byte, err error) {
-	bs = make([]byte, n)
-	for i := 0; i < n; i++ {
-		bs[i], err = r.ReadByte()
+// MaxLengthPrefixed is the max length accepted by ReadLengthPrefixed,
+// which is the max uncompressed length of a packet.
+const MaxLengthPrefixed = 8 << 20
+
+// ErrTooLong is returned when the declared length is longer than the limit
+var ErrTooLong = errors.New("length prefix is too long")
+
+// allocChunk is the max bytes allocated before they are read by ReadNBytes
+const allocChunk = 64 << 10
+
+//ReadNBytes read N bytes from r.
+//A bad length doesn't allocate much more memory than the actual data,
+//for the buffer grows as the bytes are read.
+func ReadNBytes(r io.Reader, n int) (bs []byte, err error) {
+	if n < 0 {
+		return nil, fmt.Errorf("read bytes of negative length %d", n)
+	}
+	if n <= allocChunk {
+		bs = make([]byte, n)
+		_, err = io.ReadFull(r, bs)
+		return
+	}
+	bs = make([]byte, 0, allocChunk)
+	for len(bs) < n {
+		chunk := n - len(bs)
+		if chunk > allocChunk {
+			chunk = allocChunk
+		}
+		bs = append(bs, make([]byte, chunk)...)
+		var read int
+		read, err = io.ReadFull(r, bs[len(bs)-chunk:])
 		if err != nil {
-			return
+			if err == io.EOF && read == 0 && len(bs) > chunk {
+				err = io.ErrUnexpectedEOF
+			}
+			return bs[:len(bs)-chunk+read], err
 		}
 	}
 	return
 }
 
+//ReadLengthPrefixed read a VarInt length then that many bytes from r,
+//which is the layout of String and ByteArray.
+//ErrTooLong is returned if the length is above MaxLengthPrefixed.
+func ReadLengthPrefixed(r io.Reader) ([]byte, error) {
+	br, ok := r.(DecodeReader)
+	if !ok {
+		br = byteReader{r}
+	}
+	var l VarInt
+	if err := l.Decode(br); err != nil {
+		return nil, err
+	}
+	if l > MaxLengthPrefixed {
+		return nil, fmt.Errorf("%w: %d > %d", ErrTooLong, l, MaxLengthPrefixed)
+	}
+	bs, err := ReadNBytes(br, int(l))
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF // the length is read, so the bytes are truncated
+	}
+	return bs, err
+}
+
+// byteReader add ReadByte to an io.Reader, reading a byte each time
+type byteReader struct{ io.Reader }
+
+func (r byteReader) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(r.Reader, b[:])
+	return b[0], err
+}
+
 //Encode a Boolean
 func (b Boolean) Encode() []byte {
 	if b {
@@ -124,12 +192,7 @@ func (s String) Encode() (p []byte) {
 
 //Decode a String
 func (s *String) Decode(r DecodeReader) error {
-	var l VarInt //String length
-	if err := l.Decode(r); err != nil {
-		return err
-	}
-
-	bs, err := ReadNBytes(r, int(l))
+	bs, err := ReadLengthPrefixed(r)
 	if err != nil {
 		return err
 	}
@@ -268,22 +331,10 @@ func (v VarInt) Encode() (vi []byte) {
 
 //Decode a VarInt
 func (v *VarInt) Decode(r DecodeReader) error {
-	var n uint32
-	for i := 0; ; i++ { //读数据前的长度标记
-		sec, err := r.ReadByte()
-		if err != nil {
-			return err
-		}
-
-		n |= uint32(sec&0x7F) << uint32(7*i)
-
-		if i >= 5 {
-			return errors.New("VarInt is too big")
-		} else if sec&0x80 == 0 {
-			break
-		}
+	n, _, err := ReadVarInt(r)
+	if err != nil {
+		return err
 	}
-
 	*v = VarInt(n)
 	return nil
 }
@@ -364,6 +415,45 @@ func (p *Position) Decode(r DecodeReader) error {
 	return nil
 }
 
+//Encode a PositionPre1_14
+func (p PositionPre1_14) Encode() []byte {
+	b := make([]byte, 8)
+	position := uint64(p.X&0x3FFFFFF)<<38 | uint64((p.Y&0xFFF)<<26) | uint64(p.Z&0x3FFFFFF)
+	for i := 7; i >= 0; i-- {
+		b[i] = byte(position)
+		position >>= 8
+	}
+	return b
+}
+
+// Decode a PositionPre1_14
+func (p *PositionPre1_14) Decode(r DecodeReader) error {
+	var v Long
+	if err := v.Decode(r); err != nil {
+		return err
+	}
+
+	// Long is signed, so the arithmetic shifts extend the sign of x and z
+	x := int(v >> 38)
+	y := int(v >> 26 & 0xFFF)
+	z := int(v << 38 >> 38)
+
+	if y >= 1<<11 {
+		y -= 1 << 12
+	}
+
+	p.X, p.Y, p.Z = x, y, z
+	return nil
+}
+
+// PositionWithVersion return the Position field layout used by the protocol version.
+func PositionWithVersion(pos *Position, protocol int) Field {
+	if protocol < 477 {
+		return (*PositionPre1_14)(pos)
+	}
+	return pos
+}
+
 //Encode a Float
 func (f Float) Encode() []byte {
 	return Int(math.Float32bits(float32(f))).Encode()
@@ -408,13 +498,59 @@ func (b ByteArray) Encode() []byte {
 
 // Decode a ByteArray
 func (b *ByteArray) Decode(r DecodeReader) error {
-	var Len VarInt
-	if err := Len.Decode(r); err != nil {
+	bs, err := ReadLengthPrefixed(r)
+	if err != nil {
 		return err
 	}
-	*b = make([]byte, Len)
-	_, err := r.Read(*b)
-	return err
+	*b = bs
+	return nil
+}
+
+// AngleFromDegrees return the Angle nearest to d degrees.
+// It wraps around, so 360 and -90 are the same as 0 and 270.
+func AngleFromDegrees(d float64) Angle {
+	return Angle(int64(math.Round(d * 256 / 360)))
+}
+
+// Degrees return the angle in degrees, in the range [0, 360)
+func (a Angle) Degrees() float64 {
+	return float64(a) * 360 / 256
+}
+
+// Encode a Angle
+func (a Angle) Encode() []byte {
+	return []byte{byte(a)}
+}
+
+// Decode a Angle
+func (a *Angle) Decode(r DecodeReader) error {
+	v, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+	*a = Angle(v)
+	return nil
+}
+
+// FixedPointIntFromBlocks return the FixedPointInt of v blocks,
+// which is rounded down to 1/32 blocks as the vanilla does.
+func FixedPointIntFromBlocks(v float64) FixedPointInt {
+	return FixedPointInt(math.Floor(v * 32))
+}
+
+// Blocks return the coordinate in blocks
+func (f FixedPointInt) Blocks() float64 {
+	return float64(f) / 32
+}
+
+// Encode a FixedPointInt
+func (f FixedPointInt) Encode() []byte {
+	return Int(f).Encode()
+}
+
+// Decode a FixedPointInt
+func (f *FixedPointInt) Decode(r DecodeReader) error {
+	return (*Int)(f).Decode(r)
 }
 
 // Encode a UUID
@@ -427,3 +563,43 @@ func (u *UUID) Decode(r DecodeReader) error {
 	_, err := io.ReadFull(r, (*u)[:])
 	return err
 }
+
+// ParseUUID parse the UUID in the dashed form "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx",
+// or without dashes as Mojang API returns.
+func ParseUUID(s string) (UUID, error) {
+	u, err := uuid.Parse(s)
+	return UUID(u), err
+}
+
+// UUIDFromLongs return the UUID of the most and least significant 64 bits
+func UUIDFromLongs(most, least int64) (u UUID) {
+	binary.BigEndian.PutUint64(u[:8], uint64(most))
+	binary.BigEndian.PutUint64(u[8:], uint64(least))
+	return
+}
+
+// Longs return the most and least significant 64 bits of the UUID,
+// which is how it's encoded in packets and stored in NBT
+func (u UUID) Longs() (most, least int64) {
+	return int64(binary.BigEndian.Uint64(u[:8])), int64(binary.BigEndian.Uint64(u[8:]))
+}
+
+// String return the UUID in the dashed form
+func (u UUID) String() string {
+	return uuid.UUID(u).String()
+}
+
+// MarshalText implement encoding.TextMarshaler, so the UUID is a string in JSON
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implement encoding.TextUnmarshaler, accepting the forms of ParseUUID
+func (u *UUID) UnmarshalText(text []byte) error {
+	v, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
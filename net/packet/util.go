@@ -0,0 +1,361 @@
+package packet
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Opt is an optional field prefixed by a Boolean which tells if the field is present.
+//
+// When encoding, Field is written only if Has is true, and it must be a FieldEncoder.
+// When decoding, Has is read first and Field is decoded only if Has is true,
+// so Field must be a FieldDecoder (usually a pointer).
+// Field can be another Opt to build nested optional fields.
+type Opt struct {
+	Has   Boolean
+	Field interface{}
+}
+
+// Encode an Opt
+func (o Opt) Encode() []byte {
+	if !o.Has {
+		return o.Has.Encode()
+	}
+	return append(o.Has.Encode(), o.Field.(FieldEncoder).Encode()...)
+}
+
+// Decode an Opt
+func (o *Opt) Decode(r DecodeReader) error {
+	if err := o.Has.Decode(r); err != nil {
+		return err
+	}
+	if !o.Has {
+		return nil
+	}
+	d, ok := o.Field.(FieldDecoder)
+	if !ok {
+		return fmt.Errorf("decode optional field: %T is not a FieldDecoder", o.Field)
+	}
+	return d.Decode(r)
+}
+
+// Ary is an array field prefixed by its length.
+//
+// Len decides how the length is encoded, such as VarInt(0) or new(Int).
+// When decoding, if Len is a pointer it receives the length.
+// Ary is a slice of FieldEncoder when encoding,
+// and a pointer to a slice whose elements' pointers are FieldDecoders when decoding.
+// Elements are appended one by one while decoding, so the memory used grows with
+// the data actually read rather than the declared length.
+// Max limits the declared length accepted while decoding. Zero means unlimited.
+type Ary struct {
+	Len interface{}
+	Ary interface{}
+	Max int
+}
+
+// Encode an Ary
+func (a Ary) Encode() []byte {
+	ary := reflect.ValueOf(a.Ary)
+	if ary.Kind() == reflect.Ptr {
+		ary = ary.Elem()
+	}
+
+	length := newLength(a.Len)
+	switch length.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		length.SetUint(uint64(ary.Len()))
+	default:
+		length.SetInt(int64(ary.Len()))
+	}
+
+	data := length.Interface().(FieldEncoder).Encode()
+	for i := 0; i < ary.Len(); i++ {
+		elem := ary.Index(i)
+		if enc, ok := elem.Interface().(FieldEncoder); ok {
+			data = append(data, enc.Encode()...)
+		} else {
+			data = append(data, elem.Addr().Interface().(FieldEncoder).Encode()...)
+		}
+	}
+	return data
+}
+
+// Decode an Ary
+func (a Ary) Decode(r DecodeReader) error {
+	var length reflect.Value
+	if v := reflect.ValueOf(a.Len); v.Kind() == reflect.Ptr {
+		length = v.Elem()
+	} else {
+		length = newLength(a.Len)
+	}
+	if err := length.Addr().Interface().(FieldDecoder).Decode(r); err != nil {
+		return err
+	}
+
+	var n int64
+	switch length.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = int64(length.Uint())
+	default:
+		n = length.Int()
+	}
+	if n < 0 {
+		return fmt.Errorf("decode array: negative length %d", n)
+	}
+	if a.Max > 0 && n > int64(a.Max) {
+		return fmt.Errorf("decode array: length %d exceeds the limit %d", n, a.Max)
+	}
+
+	ary := reflect.ValueOf(a.Ary)
+	if ary.Kind() != reflect.Ptr || ary.Elem().Kind() != reflect.Slice {
+		return errors.New("decode array: Ary must be a pointer to slice")
+	}
+	ary = ary.Elem()
+	elemType := ary.Type().Elem()
+
+	const maxPrealloc = 1024
+	buf := reflect.MakeSlice(ary.Type(), 0, int(min64(n, maxPrealloc)))
+	for i := int64(0); i < n; i++ {
+		var elem reflect.Value
+		if elemType.Kind() == reflect.Ptr {
+			elem = reflect.New(elemType.Elem())
+		} else {
+			elem = reflect.New(elemType)
+		}
+		dec, ok := elem.Interface().(FieldDecoder)
+		if !ok {
+			return fmt.Errorf("decode array: %v is not a FieldDecoder", elem.Type())
+		}
+		if err := dec.Decode(r); err != nil {
+			return fmt.Errorf("decode array[%d]: %w", i, err)
+		}
+		if elemType.Kind() != reflect.Ptr {
+			elem = elem.Elem()
+		}
+		buf = reflect.Append(buf, elem)
+	}
+	ary.Set(buf)
+	return nil
+}
+
+// newLength make a new settable value with the same type as Len
+func newLength(Len interface{}) reflect.Value {
+	t := reflect.TypeOf(Len)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return reflect.New(t).Elem()
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// ErrInvalidEnum is returned when decoding an Enum whose value isn't registered
+var ErrInvalidEnum = errors.New("invalid enum value")
+
+// EnumValues is the set of valid values of an enumeration, such as game modes.
+// Values should be registered before the EnumValues is used for decoding.
+type EnumValues struct {
+	Name  string // used in error messages
+	valid map[int32]bool
+}
+
+// NewEnumValues return the EnumValues contains the values
+func NewEnumValues(name string, values ...int32) *EnumValues {
+	return (&EnumValues{Name: name}).Register(values...)
+}
+
+// EnumRange return the EnumValues contains the values from min to max, both inclusive
+func EnumRange(name string, min, max int32) *EnumValues {
+	e := &EnumValues{Name: name}
+	for v := min; v <= max; v++ {
+		e.Register(v)
+		if v == max { // avoid overflow when max is math.MaxInt32
+			break
+		}
+	}
+	return e
+}
+
+// Register add valid values and return the EnumValues itself
+func (e *EnumValues) Register(values ...int32) *EnumValues {
+	if e.valid == nil {
+		e.valid = make(map[int32]bool, len(values))
+	}
+	for _, v := range values {
+		e.valid[v] = true
+	}
+	return e
+}
+
+// Valid report whether v is one of the values
+func (e *EnumValues) Valid(v int32) bool { return e.valid[v] }
+
+// Enum is a VarInt field whose value must be one of the Values.
+//
+// Value is an integer when encoding, and a pointer to an integer when decoding,
+// such as a *GameMode whose underlying type is byte.
+// If the decoded value isn't valid, the error wraps ErrInvalidEnum
+// and Value is left unchanged.
+type Enum struct {
+	Values *EnumValues
+	Value  interface{}
+}
+
+// Encode an Enum
+func (e Enum) Encode() []byte {
+	v := reflect.ValueOf(e.Value)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return VarInt(v.Uint()).Encode()
+	default:
+		return VarInt(v.Int()).Encode()
+	}
+}
+
+// Decode an Enum
+func (e Enum) Decode(r DecodeReader) error {
+	var n VarInt
+	if err := n.Decode(r); err != nil {
+		return err
+	}
+	if !e.Values.Valid(int32(n)) {
+		return fmt.Errorf("decode %s: %w %d", e.Values.Name, ErrInvalidEnum, n)
+	}
+
+	v := reflect.ValueOf(e.Value)
+	if v.Kind() != reflect.Ptr {
+		return errors.New("decode enum: Value must be a pointer")
+	}
+	v = v.Elem()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.OverflowInt(int64(n)) {
+			return fmt.Errorf("decode %s: %d overflows %v", e.Values.Name, n, v.Type())
+		}
+		v.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n < 0 || v.OverflowUint(uint64(n)) {
+			return fmt.Errorf("decode %s: %d overflows %v", e.Values.Name, n, v.Type())
+		}
+		v.SetUint(uint64(n))
+	default:
+		return fmt.Errorf("decode enum: %v is not an integer", v.Type())
+	}
+	return nil
+}
+
+// BitSet is a set of bits backed by longs.
+// It's encoded as a VarInt length of the longs, followed by the longs.
+// Bit i is the bit i%64 of the long i/64.
+type BitSet []int64
+
+// Get return whether the bit i is set
+func (b BitSet) Get(i int) bool {
+	if i < 0 || i/64 >= len(b) {
+		return false
+	}
+	return b[i/64]&(1<<uint(i%64)) != 0
+}
+
+// Set the bit i, the BitSet grows if needed.
+// A negative i is ignored, as it's never set for Get.
+func (b *BitSet) Set(i int) {
+	if i < 0 {
+		return
+	}
+	for i/64 >= len(*b) {
+		*b = append(*b, 0)
+	}
+	(*b)[i/64] |= 1 << uint(i%64)
+}
+
+// Clear the bit i
+func (b BitSet) Clear(i int) {
+	if i >= 0 && i/64 < len(b) {
+		b[i/64] &^= 1 << uint(i%64)
+	}
+}
+
+// Len return the number of bits the BitSet can hold
+func (b BitSet) Len() int { return len(b) * 64 }
+
+// Encode a BitSet
+func (b BitSet) Encode() []byte {
+	data := VarInt(len(b)).Encode()
+	for _, v := range b {
+		data = append(data, Long(v).Encode()...)
+	}
+	return data
+}
+
+// Decode a BitSet
+func (b *BitSet) Decode(r DecodeReader) error {
+	var n VarInt
+	if err := n.Decode(r); err != nil {
+		return err
+	}
+	if n < 0 {
+		return fmt.Errorf("decode bitset: negative length %d", n)
+	}
+	const maxPrealloc = 1024
+	set := make(BitSet, 0, min64(int64(n), maxPrealloc))
+	for i := 0; i < int(n); i++ {
+		var v Long
+		if err := v.Decode(r); err != nil {
+			return fmt.Errorf("decode bitset: %w", err)
+		}
+		set = append(set, int64(v))
+	}
+	*b = set
+	return nil
+}
+
+// FixedBitSet is a set of a fixed number of bits.
+// It's encoded as ceil(n/8) bytes without a length prefix,
+// where bit i is the bit i%8 of the byte i/8.
+// Make it by NewFixedBitSet, then the length is known for decoding.
+type FixedBitSet []byte
+
+// NewFixedBitSet make a FixedBitSet of n bits
+func NewFixedBitSet(n int) FixedBitSet {
+	return make(FixedBitSet, (n+7)/8)
+}
+
+// Get return whether the bit i is set
+func (f FixedBitSet) Get(i int) bool {
+	if i < 0 || i/8 >= len(f) {
+		return false
+	}
+	return f[i/8]&(1<<uint(i%8)) != 0
+}
+
+// Set the bit i. It panics if i is out of range.
+func (f FixedBitSet) Set(i int) { f[i/8] |= 1 << uint(i%8) }
+
+// Clear the bit i. It panics if i is out of range.
+func (f FixedBitSet) Clear(i int) { f[i/8] &^= 1 << uint(i%8) }
+
+// Len return the number of bits the FixedBitSet can hold
+func (f FixedBitSet) Len() int { return len(f) * 8 }
+
+// Encode a FixedBitSet
+func (f FixedBitSet) Encode() []byte {
+	return append([]byte(nil), f...)
+}
+
+// Decode a FixedBitSet, filling the bytes it already has
+func (f FixedBitSet) Decode(r DecodeReader) error {
+	_, err := io.ReadFull(r, f)
+	return err
+}
@@ -0,0 +1,302 @@
+package packet
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestOpt_absent(t *testing.T) {
+	p := Marshal(0, Opt{Has: false, Field: String("unused")})
+	if !bytes.Equal(p.Data, []byte{0x00}) {
+		t.Fatalf("absent Opt should encode as a single false, get \"% x\"", p.Data)
+	}
+
+	s := String("untouched")
+	opt := Opt{Field: &s}
+	if err := p.Scan(&opt); err != nil {
+		t.Fatal(err)
+	}
+	if opt.Has || s != "untouched" {
+		t.Errorf("absent Opt decode error: Has=%v, Field=%q", opt.Has, s)
+	}
+}
+
+func TestOpt_present(t *testing.T) {
+	p := Marshal(0, Opt{Has: true, Field: String("Tnze")}, VarInt(1))
+	want := []byte{0x01, 0x04, 'T', 'n', 'z', 'e', 0x01}
+	if !bytes.Equal(p.Data, want) {
+		t.Fatalf("present Opt should encode as \"% x\", get \"% x\"", want, p.Data)
+	}
+
+	var (
+		s    String
+		next VarInt
+	)
+	opt := Opt{Field: &s}
+	if err := p.Scan(&opt, &next); err != nil {
+		t.Fatal(err)
+	}
+	if !opt.Has || s != "Tnze" || next != 1 {
+		t.Errorf("present Opt decode error: Has=%v, Field=%q, next=%d", opt.Has, s, next)
+	}
+}
+
+func TestOpt_nested(t *testing.T) {
+	for _, tt := range []struct {
+		outer, inner Boolean
+		want         []byte
+	}{
+		{false, false, []byte{0x00}},
+		{true, false, []byte{0x01, 0x00}},
+		{true, true, []byte{0x01, 0x01, 0x2a}},
+	} {
+		p := Marshal(0, Opt{Has: tt.outer, Field: Opt{Has: tt.inner, Field: VarInt(42)}})
+		if !bytes.Equal(p.Data, tt.want) {
+			t.Errorf("nested Opt should encode as \"% x\", get \"% x\"", tt.want, p.Data)
+			continue
+		}
+
+		var v VarInt
+		inner := Opt{Field: &v}
+		outer := Opt{Field: &inner}
+		if err := p.Scan(&outer); err != nil {
+			t.Error(err)
+			continue
+		}
+		if outer.Has != tt.outer || inner.Has != tt.inner || (tt.inner && v != 42) {
+			t.Errorf("nested Opt decode error: outer=%v inner=%v value=%d", outer.Has, inner.Has, v)
+		}
+	}
+}
+
+func TestOpt_notDecoder(t *testing.T) {
+	opt := Opt{Field: String("not a pointer")}
+	if err := (Packet{Data: []byte{0x01, 0x00}}).Scan(&opt); err == nil {
+		t.Error("decode Opt into a non-FieldDecoder should be error")
+	}
+}
+
+type testEntry struct {
+	Name  String
+	Value VarInt
+}
+
+func (e testEntry) Encode() []byte {
+	return append(e.Name.Encode(), e.Value.Encode()...)
+}
+
+func (e *testEntry) Decode(r DecodeReader) error {
+	if err := e.Name.Decode(r); err != nil {
+		return err
+	}
+	return e.Value.Decode(r)
+}
+
+func TestAry(t *testing.T) {
+	strs := []String{"a", "bc"}
+	p := Marshal(0, Ary{Len: VarInt(0), Ary: strs})
+	want := []byte{0x02, 0x01, 'a', 0x02, 'b', 'c'}
+	if !bytes.Equal(p.Data, want) {
+		t.Fatalf("Ary should encode as \"% x\", get \"% x\"", want, p.Data)
+	}
+
+	var (
+		n   VarInt
+		got []String
+	)
+	if err := p.Scan(Ary{Len: &n, Ary: &got}); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || len(got) != 2 || got[0] != "a" || got[1] != "bc" {
+		t.Errorf("Ary decode error: len=%d, %q", n, got)
+	}
+}
+
+func TestAry_composite(t *testing.T) {
+	entries := []testEntry{{"x", 1}, {"y", 300}}
+	p := Marshal(0, Ary{Len: Int(0), Ary: entries})
+	want := []byte{0, 0, 0, 2, 0x01, 'x', 0x01, 0x01, 'y', 0xac, 0x02}
+	if !bytes.Equal(p.Data, want) {
+		t.Fatalf("Ary should encode as \"% x\", get \"% x\"", want, p.Data)
+	}
+
+	var got []testEntry
+	if err := p.Scan(Ary{Len: Int(0), Ary: &got}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != entries[0] || got[1] != entries[1] {
+		t.Errorf("Ary decode error: get %v, want %v", got, entries)
+	}
+
+	var ptrs []*testEntry
+	if err := p.Scan(Ary{Len: Int(0), Ary: &ptrs}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ptrs) != 2 || *ptrs[0] != entries[0] || *ptrs[1] != entries[1] {
+		t.Errorf("Ary decode into pointers error: %v", ptrs)
+	}
+}
+
+func TestAry_limit(t *testing.T) {
+	var got []VarInt
+	// claims 2147483647 elements but has only one
+	p := Packet{Data: []byte{0xff, 0xff, 0xff, 0xff, 0x07, 0x01}}
+	if err := p.Scan(Ary{Len: VarInt(0), Ary: &got, Max: 1024}); err == nil {
+		t.Error("decode Ary longer than Max should be error")
+	}
+	if err := p.Scan(Ary{Len: VarInt(0), Ary: &got}); err == nil {
+		t.Error("decode truncated Ary should be error")
+	}
+	p = Packet{Data: []byte{0xff, 0xff, 0xff, 0xff, 0x0f}} // -1
+	if err := p.Scan(Ary{Len: VarInt(0), Ary: &got}); err == nil {
+		t.Error("decode Ary with negative length should be error")
+	}
+}
+
+func TestOpt_ary(t *testing.T) {
+	p := Marshal(0, Opt{Has: true, Field: Ary{Len: VarInt(0), Ary: []VarInt{1, 2, 3}}})
+	want := []byte{0x01, 0x03, 0x01, 0x02, 0x03}
+	if !bytes.Equal(p.Data, want) {
+		t.Fatalf("Opt of Ary should encode as \"% x\", get \"% x\"", want, p.Data)
+	}
+
+	var got []VarInt
+	opt := Opt{Field: Ary{Len: VarInt(0), Ary: &got}}
+	if err := p.Scan(&opt); err != nil {
+		t.Fatal(err)
+	}
+	if !opt.Has || len(got) != 3 || got[2] != 3 {
+		t.Errorf("Opt of Ary decode error: Has=%v %v", opt.Has, got)
+	}
+}
+
+func TestBitSet(t *testing.T) {
+	var b BitSet
+	for _, i := range []int{0, 63, 64, 130} {
+		b.Set(i)
+	}
+	if len(b) != 3 || b.Len() != 192 {
+		t.Fatalf("BitSet should grow to 3 longs, get %d", len(b))
+	}
+	if b[0] != -1<<63|1 || b[1] != 1 || b[2] != 1<<2 {
+		t.Errorf("wrong bits across the word boundary: %x", []int64(b))
+	}
+	for i := 0; i < 200; i++ {
+		want := i == 0 || i == 63 || i == 64 || i == 130
+		if b.Get(i) != want {
+			t.Errorf("bit %d should be %v", i, want)
+		}
+	}
+	b.Clear(63)
+	if b.Get(63) || !b.Get(64) {
+		t.Error("Clear should only clear the bit")
+	}
+
+	p := Marshal(0, b)
+	want := []byte{0x03,
+		0, 0, 0, 0, 0, 0, 0, 0x01,
+		0, 0, 0, 0, 0, 0, 0, 0x01,
+		0, 0, 0, 0, 0, 0, 0, 0x04,
+	}
+	if !bytes.Equal(p.Data, want) {
+		t.Fatalf("BitSet should encode as \"% x\", get \"% x\"", want, p.Data)
+	}
+	var got BitSet
+	if err := p.Scan(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0] != 1 || got[1] != 1 || got[2] != 4 {
+		t.Errorf("BitSet decode error: %x", []int64(got))
+	}
+
+	// claims 2147483647 longs but has only one
+	p = Packet{Data: []byte{0xff, 0xff, 0xff, 0xff, 0x07, 0, 0, 0, 0, 0, 0, 0, 0}}
+	if err := p.Scan(&got); err == nil {
+		t.Error("decode truncated BitSet should be error")
+	}
+}
+
+func TestBitSet_negative(t *testing.T) {
+	b := BitSet{0}
+	for _, i := range []int{-1, -63, -64, -129} {
+		b.Set(i)
+		if b.Get(i) {
+			t.Errorf("bit %d should never be set", i)
+		}
+		b.Clear(i)
+	}
+	if len(b) != 1 || b[0] != 0 {
+		t.Errorf("negative bits shouldn't change the BitSet: %x", []int64(b))
+	}
+}
+
+func TestFixedBitSet(t *testing.T) {
+	f := NewFixedBitSet(20)
+	if len(f) != 3 || f.Len() != 24 {
+		t.Fatalf("FixedBitSet of 20 bits should have 3 bytes, get %d", len(f))
+	}
+	f.Set(0)
+	f.Set(7)
+	f.Set(8)
+	f.Set(19)
+	if want := []byte{0x81, 0x01, 0x08}; !bytes.Equal(f.Encode(), want) {
+		t.Errorf("FixedBitSet should encode as \"% x\", get \"% x\"", want, f.Encode())
+	}
+	if !f.Get(7) || !f.Get(8) || f.Get(9) || f.Get(100) {
+		t.Error("wrong bits across the byte boundary")
+	}
+
+	got := NewFixedBitSet(20)
+	if err := (Packet{Data: f.Encode()}).Scan(got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, f) {
+		t.Errorf("FixedBitSet decode error: % x", []byte(got))
+	}
+	if err := (Packet{Data: []byte{0x01}}).Scan(NewFixedBitSet(20)); err == nil {
+		t.Error("decode truncated FixedBitSet should be error")
+	}
+}
+
+func TestEnum(t *testing.T) {
+	type GameMode byte
+	const (
+		Survival GameMode = iota
+		Creative
+		Adventure
+		Spectator
+	)
+	gameModes := EnumRange("game mode", int32(Survival), int32(Spectator))
+
+	var mode GameMode
+	if err := (Enum{gameModes, &mode}).Decode(bytes.NewReader(VarInt(2).Encode())); err != nil {
+		t.Fatal(err)
+	}
+	if mode != Adventure {
+		t.Errorf("decoded %d, want %d", mode, Adventure)
+	}
+	if data := (Enum{gameModes, Creative}).Encode(); !bytes.Equal(data, []byte{1}) {
+		t.Errorf("encode: % x", data)
+	}
+
+	for _, v := range []VarInt{4, -1, 300} {
+		err := (Enum{gameModes, &mode}).Decode(bytes.NewReader(v.Encode()))
+		if !errors.Is(err, ErrInvalidEnum) {
+			t.Errorf("decode %d: %v, want ErrInvalidEnum", v, err)
+		}
+		if mode != Adventure {
+			t.Errorf("value changed to %d after decoding %d", mode, v)
+		}
+	}
+
+	// registered values can be sparse
+	hands := NewEnumValues("hand", 0).Register(1)
+	var hand int32
+	if err := (Enum{hands, &hand}).Decode(bytes.NewReader(VarInt(1).Encode())); err != nil || hand != 1 {
+		t.Errorf("decode hand: %d, %v", hand, err)
+	}
+	if hands.Valid(2) {
+		t.Error("2 isn't a registered hand")
+	}
+}
@@ -0,0 +1,56 @@
+package packet
+
+import (
+	"errors"
+	"io"
+)
+
+// MaxVarIntLen is the max number of bytes of an encoded VarInt
+const MaxVarIntLen = 5
+
+// ErrVarIntTooBig is returned when a VarInt is encoded in more than
+// MaxVarIntLen bytes, which is an overlong or corrupted encoding.
+var ErrVarIntTooBig = errors.New("VarInt is too big")
+
+// ReadVarInt read a VarInt from r, returning the value and the number of
+// bytes consumed. It reads no more than MaxVarIntLen bytes, so the stream
+// stays at the byte after the VarInt, or after the 5th byte for ErrVarIntTooBig.
+//
+// It is the VarInt.Decode on any io.ByteReader, like a bufio.Reader,
+// for reading outside a Packet.
+func ReadVarInt(r io.ByteReader) (int32, int, error) {
+	var v uint32
+	for i := 0; i < MaxVarIntLen; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, i, err
+		}
+		v |= uint32(b&0x7F) << uint(7*i)
+		if b&0x80 == 0 {
+			return int32(v), i + 1, nil
+		}
+	}
+	return 0, MaxVarIntLen, ErrVarIntTooBig
+}
+
+// WriteVarInt write v to w as a VarInt, returning the number of bytes written.
+// Unlike VarInt.Encode, it doesn't allocate.
+func WriteVarInt(w io.Writer, v int32) (int, error) {
+	var buf [MaxVarIntLen]byte
+	n := putVarInt(buf[:], v)
+	return w.Write(buf[:n])
+}
+
+// putVarInt encode v into buf as a VarInt and returns the number of bytes
+// written. It panics if buf is too small, which MaxVarIntLen never is.
+func putVarInt(buf []byte, v int32) int {
+	num := uint32(v)
+	i := 0
+	for num >= 0x80 {
+		buf[i] = byte(num) | 0x80
+		num >>= 7
+		i++
+	}
+	buf[i] = byte(num)
+	return i + 1
+}
@@ -0,0 +1,119 @@
+package packet
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestReadVarInt(t *testing.T) {
+	for i, data := range PackedVarInts {
+		v, n, err := ReadVarInt(bytes.NewReader(data))
+		if err != nil || v != int32(VarInts[i]) || n != len(data) {
+			t.Errorf("read \"% x\": got %d of %d bytes, %v, want %d", data, v, n, err, VarInts[i])
+		}
+	}
+}
+
+func TestReadVarInt_tooBig(t *testing.T) {
+	r := bytes.NewReader([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x01})
+	if _, n, err := ReadVarInt(r); !errors.Is(err, ErrVarIntTooBig) || n != MaxVarIntLen {
+		t.Errorf("got %d bytes, %v, want ErrVarIntTooBig", n, err)
+	}
+	if r.Len() != 1 {
+		t.Errorf("read over %d bytes", MaxVarIntLen)
+	}
+}
+
+func TestReadVarInt_truncated(t *testing.T) {
+	if _, n, err := ReadVarInt(bytes.NewReader([]byte{0xff, 0xff})); err != io.EOF || n != 2 {
+		t.Errorf("got %d bytes, %v, want EOF", n, err)
+	}
+}
+
+func TestWriteVarInt(t *testing.T) {
+	var buf bytes.Buffer
+	for i, v := range VarInts {
+		buf.Reset()
+		n, err := WriteVarInt(&buf, int32(v))
+		if err != nil || n != buf.Len() || !bytes.Equal(buf.Bytes(), PackedVarInts[i]) {
+			t.Errorf("write %d: got \"% x\", %v, want \"% x\"", v, buf.Bytes(), err, PackedVarInts[i])
+		}
+	}
+}
+
+// TestVarInt_random write and read random values through a bufio.Reader,
+// comparing with VarInt.Encode and VarInt.Decode
+func TestVarInt_random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	values := []int32{math.MinInt32, math.MaxInt32}
+	for i := 0; i < 10000; i++ {
+		// values of every length, not only the 5-byte ones
+		values = append(values, int32(rnd.Uint32()>>uint(rnd.Intn(32))))
+	}
+
+	var buf bytes.Buffer
+	for _, v := range values {
+		n, err := WriteVarInt(&buf, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes()[buf.Len()-n:], VarInt(v).Encode()) {
+			t.Fatalf("write %d: got \"% x\", want \"% x\"", v, buf.Bytes()[buf.Len()-n:], VarInt(v).Encode())
+		}
+	}
+	r := bufio.NewReader(&buf)
+	for _, v := range values {
+		got, n, err := ReadVarInt(r)
+		if err != nil || got != v || n != len(VarInt(v).Encode()) {
+			t.Fatalf("read %d: got %d of %d bytes, %v", v, got, n, err)
+		}
+	}
+	if _, _, err := ReadVarInt(r); err != io.EOF {
+		t.Errorf("read at the end: got %v, want EOF", err)
+	}
+}
+
+// TestReadVarInt_randomBytes read random bytes, which never fails but with
+// ErrVarIntTooBig or EOF, nor reads over MaxVarIntLen bytes
+func TestReadVarInt_randomBytes(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	data := make([]byte, 8)
+	for i := 0; i < 10000; i++ {
+		rnd.Read(data[:rnd.Intn(len(data))+1])
+		r := bytes.NewReader(data[:rnd.Intn(len(data))+1])
+		size := r.Len()
+
+		v, n, err := ReadVarInt(r)
+		if n != size-r.Len() || n > MaxVarIntLen {
+			t.Fatalf("read \"% x\": consumed %d bytes, reported %d", data[:size], size-r.Len(), n)
+		}
+		switch {
+		case err == nil:
+			// the padded encodings like "80 00" are accepted, as vanilla does
+			if again, _, _ := ReadVarInt(bytes.NewReader(VarInt(v).Encode())); again != v {
+				t.Errorf("read \"% x\": got %d, but %d after encoding again", data[:n], v, again)
+			}
+		case err != io.EOF && !errors.Is(err, ErrVarIntTooBig):
+			t.Errorf("read \"% x\": %v", data[:size], err)
+		}
+	}
+}
+
+func BenchmarkReadVarInt(b *testing.B) {
+	data := bytes.Repeat(VarInt(math.MaxInt32).Encode(), 1024)
+	r := bytes.NewReader(data)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if r.Len() == 0 {
+			r.Reset(data)
+		}
+		if _, _, err := ReadVarInt(r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
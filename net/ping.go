@@ -0,0 +1,160 @@
+package net
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// DefaultPort is the port which Minecraft servers listen by default.
+const DefaultPort = 25565
+
+// PingAndList check server status and list online player.
+// Returns a JSON data with server status, and the delay.
+//
+// The addr is "host[:port]". If the port is omitted, the _minecraft._tcp SRV record
+// is looked up like the vanilla client does, and fallback to DefaultPort.
+//
+// For more information for JSON format, see https://wiki.vg/Server_List_Ping#Response
+func PingAndList(addr string) ([]byte, time.Duration, error) {
+	return PingAndListContext(context.Background(), addr)
+}
+
+// PingAndListTimeout is the version of PingAndList with max request time.
+func PingAndListTimeout(addr string, timeout time.Duration) ([]byte, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return PingAndListContext(ctx, addr)
+}
+
+// PingAndListContext is the version of PingAndList with a context.
+// The dialing and the whole request are canceled when ctx is done.
+func PingAndListContext(ctx context.Context, addr string) ([]byte, time.Duration, error) {
+	return PingAndListProtocol(ctx, addr, -1)
+}
+
+// PingAndListProtocol is the version of PingAndListContext which sends protocol
+// in the handshake, rather than the -1 used when the version is unknown.
+// Some servers response the status according to the protocol version of the client.
+func PingAndListProtocol(ctx context.Context, addr string, protocol int) ([]byte, time.Duration, error) {
+	conn, t, err := dialContext(ctx, addr)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ping: dial fail: %v", err)
+	}
+	defer conn.Close()
+
+	// interrupt blocking reads and writes when ctx is done, which is also
+	// its deadline, so the error always wraps ctx.Err()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Socket.SetDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+
+	status, delay, err := pingAndList(conn, t.host, t.port, protocol)
+	if err != nil && ctx.Err() != nil {
+		err = fmt.Errorf("%v: %w", err, ctx.Err())
+	}
+	return status, delay, err
+}
+
+// lookupSRV is net.DefaultResolver.LookupSRV, replaced in tests.
+var lookupSRV = net.DefaultResolver.LookupSRV
+
+// resolveAddr split addr into host and port.
+// If addr doesn't contain a port, look up the SRV record as the vanilla client.
+func resolveAddr(ctx context.Context, addr string) (host string, port int) {
+	t := resolveTargets(ctx, addr)[0]
+	return t.host, t.port
+}
+
+type target struct {
+	host string
+	port int
+}
+
+// resolveTargets return the servers addr points to, in the order to try.
+// If addr doesn't contain a port, they are the targets of the SRV records
+// sorted by priority and weight, or the host with DefaultPort if there are none.
+// At least one target is returned.
+func resolveTargets(ctx context.Context, addr string) []target {
+	if h, p, err := net.SplitHostPort(addr); err == nil {
+		if port, err := strconv.Atoi(p); err == nil {
+			return []target{{h, port}}
+		}
+	}
+
+	host := strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+	if net.ParseIP(host) == nil {
+		// LookupSRV sorts the records by priority and randomizes them by weight
+		_, srvs, err := lookupSRV(ctx, "minecraft", "tcp", host)
+		if err == nil && len(srvs) > 0 {
+			targets := make([]target, len(srvs))
+			for i, srv := range srvs {
+				targets[i] = target{strings.TrimSuffix(srv.Target, "."), int(srv.Port)}
+			}
+			return targets
+		}
+	}
+	return []target{{host, DefaultPort}}
+}
+
+func pingAndList(conn *Conn, host string, port, protocol int) ([]byte, time.Duration, error) {
+	// Handshake
+	err := conn.WritePacket(pk.Marshal(
+		0x00,                // Handshake packet ID
+		pk.VarInt(protocol), // Protocol version
+		pk.String(host),     // Server's address
+		pk.UnsignedShort(port),
+		pk.Byte(1), // next state: status
+	))
+	if err != nil {
+		return nil, 0, fmt.Errorf("ping: send handshake packet fail: %v", err)
+	}
+
+	// Request
+	if err := conn.WritePacket(pk.Marshal(0x00)); err != nil {
+		return nil, 0, fmt.Errorf("ping: send list packet fail: %v", err)
+	}
+
+	// Response
+	recv, err := conn.ReadPacket()
+	if err != nil {
+		return nil, 0, fmt.Errorf("ping: recv list packet fail: %v", err)
+	}
+	var s pk.String
+	if err := recv.Scan(&s); err != nil {
+		return nil, 0, fmt.Errorf("ping: scan list packet fail: %v", err)
+	}
+
+	// Ping
+	startTime := time.Now()
+	payload := pk.Long(startTime.Unix())
+	if err := conn.WritePacket(pk.Marshal(0x01, payload)); err != nil {
+		return nil, 0, fmt.Errorf("ping: send ping packet fail: %v", err)
+	}
+
+	// Pong
+	recv, err = conn.ReadPacket()
+	if err != nil {
+		return nil, 0, fmt.Errorf("ping: recv pong packet fail: %v", err)
+	}
+	var t pk.Long
+	if err := recv.Scan(&t); err != nil {
+		return nil, 0, fmt.Errorf("ping: scan pong packet fail: %v", err)
+	}
+	if t != payload {
+		return nil, 0, fmt.Errorf("ping: pong packet no match: get %d, want %d", t, payload)
+	}
+
+	return []byte(s), time.Since(startTime), nil
+}
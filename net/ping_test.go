@@ -0,0 +1,161 @@
+package net
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+const testStatus = `{"version":{"name":"1.16.1","protocol":736},"players":{"max":20,"online":0},"description":{"text":"A Minecraft Server"}}`
+
+// statusServer accept one connection and answer the list ping
+// with the protocol version in the handshake checked.
+func statusServer(t *testing.T, l *Listener, wantProtocol int) {
+	conn, err := l.Accept()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer conn.Close()
+
+	var (
+		protocol, intention pk.VarInt
+		addr                pk.String
+		port                pk.UnsignedShort
+	)
+	p, err := conn.ReadPacket()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if err := p.Scan(&protocol, &addr, &port, &intention); err != nil {
+		t.Error(err)
+		return
+	}
+	if p.ID != 0 || intention != 1 {
+		t.Errorf("wrong handshake packet: id=%d intention=%d", p.ID, intention)
+	}
+	if int(protocol) != wantProtocol {
+		t.Errorf("protocol version should be %d, get %d", wantProtocol, protocol)
+	}
+
+	for i := 0; i < 2; i++ {
+		p, err := conn.ReadPacket()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		switch p.ID {
+		case 0x00:
+			err = conn.WritePacket(pk.Marshal(0x00, pk.String(testStatus)))
+		case 0x01:
+			err = conn.WritePacket(p)
+		}
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+}
+
+func TestPingAndList(t *testing.T) {
+	l, err := ListenMC("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go statusServer(t, l, -1)
+
+	status, delay, err := PingAndList(l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(status) != testStatus {
+		t.Errorf("status should be %s, get %s", testStatus, status)
+	}
+	t.Log("delay:", delay)
+}
+
+func TestPingAndListProtocol_srvFallback(t *testing.T) {
+	l, err := ListenMC("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go statusServer(t, l, 736)
+	port := l.Addr().(*net.TCPAddr).Port
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	defer func(f func(context.Context, string, string, string) (string, []*net.SRV, error)) { lookupSRV = f }(lookupSRV)
+	lookupSRV = func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+		return "", []*net.SRV{
+			{Target: "127.0.0.1.", Port: uint16(closedPort), Priority: 0},
+			{Target: "127.0.0.1.", Port: uint16(port), Priority: 1},
+		}, nil
+	}
+
+	status, _, err := PingAndListProtocol(context.Background(), "mc.example.com", 736)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(status) != testStatus {
+		t.Errorf("status should be %s, get %s", testStatus, status)
+	}
+}
+
+func TestPingAndListContext_cancel(t *testing.T) {
+	l, err := ListenMC("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() { // accept but never response
+		conn, err := l.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _, err = PingAndListContext(ctx, l.Addr().String())
+	if err == nil {
+		t.Fatal("ping a silent server should be error")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error should wrap the context error, get %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("ping didn't stop when context done")
+	}
+}
+
+func TestResolveAddr(t *testing.T) {
+	for _, tt := range []struct {
+		addr string
+		host string
+		port int
+	}{
+		{"127.0.0.1:25566", "127.0.0.1", 25566},
+		{"127.0.0.1", "127.0.0.1", DefaultPort},
+		{"[::1]:25566", "::1", 25566},
+		{"[::1]", "::1", DefaultPort},
+	} {
+		host, port := resolveAddr(context.Background(), tt.addr)
+		if host != tt.host || port != tt.port {
+			t.Errorf("resolve %q: get %s %d, want %s %d", tt.addr, host, port, tt.host, tt.port)
+		}
+	}
+}
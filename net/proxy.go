@@ -0,0 +1,202 @@
+package net
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// Pipe forwards the packets between a client and a server, as a proxy.
+//
+// client is the Conn accepted from a Minecraft client, and server is the Conn
+// dialed to the server. Before each packet is forwarded, onPacket is called
+// with it and the state it belongs to. onPacket can modify the packet, or drop
+// it by returning false. It's never called concurrently.
+//
+// Pipe follows the handshake and the login to know the state, and enables
+// the compression of both Conns when the server asks. The state transitions
+// follow the packets as received, not as modified by onPacket.
+//
+// Online-mode servers encrypt the connection with a secret the proxy can't
+// know. So once the client answers the encryption request, the bytes are
+// copied as they are, and onPacket isn't called anymore.
+//
+// Pipe returns when either side is closed or fails, and closes both Conns.
+// It returns nil if the connection is closed normally.
+func Pipe(client, server *Conn, onPacket func(dir data.Direction, state data.State, p *pk.Packet) bool) error {
+	defer client.Close()
+	defer server.Close()
+	pr := &proxy{client: client, server: server, onPacket: onPacket, state: data.Handshaking}
+	err := pr.run()
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+type proxy struct {
+	client, server *Conn
+	onPacket       func(dir data.Direction, state data.State, p *pk.Packet) bool
+
+	mu    sync.Mutex // serializes onPacket
+	state data.State
+}
+
+func (pr *proxy) run() error {
+	p, err := pr.recv(data.Serverbound)
+	if err != nil {
+		return err
+	}
+	var (
+		protocol pk.VarInt
+		addr     pk.String
+		port     pk.UnsignedShort
+		next     pk.VarInt
+	)
+	if err := p.Scan(&protocol, &addr, &port, &next); err != nil {
+		return fmt.Errorf("net: proxy read handshake fail: %v", err)
+	}
+	if err := pr.send(data.Serverbound, p); err != nil {
+		return err
+	}
+
+	switch next {
+	case 1:
+		pr.state = data.Status
+		return pr.both()
+	case 2:
+		pr.state = data.Login
+		return pr.login()
+	default:
+		return fmt.Errorf("net: proxy handshake with unknown next state %d", next)
+	}
+}
+
+// login forward the login sequentially, for the compression is enabled in it
+func (pr *proxy) login() error {
+	if err := pr.pass(data.Serverbound); err != nil { // Login Start
+		return err
+	}
+	for {
+		p, err := pr.recv(data.Clientbound)
+		if err != nil {
+			return err
+		}
+		switch p.ID {
+		case 0x00: // Disconnect
+			if err := pr.send(data.Clientbound, p); err != nil {
+				return err
+			}
+			return nil
+		case 0x01: // Encryption Request
+			if err := pr.send(data.Clientbound, p); err != nil {
+				return err
+			}
+			if err := pr.pass(data.Serverbound); err != nil { // Encryption Response
+				return err
+			}
+			return pr.raw()
+		case 0x02: // Login Success
+			if err := pr.send(data.Clientbound, p); err != nil {
+				return err
+			}
+			pr.state = data.Play
+			return pr.both()
+		case 0x03: // Set Compression
+			var threshold pk.VarInt
+			if err := p.Scan(&threshold); err != nil {
+				return fmt.Errorf("net: proxy read set compression fail: %v", err)
+			}
+			// the server compresses the packets after this one,
+			// and the client after receiving it
+			pr.server.SetThreshold(int(threshold))
+			if err := pr.send(data.Clientbound, p); err != nil {
+				return err
+			}
+			pr.client.SetThreshold(int(threshold))
+		case 0x04: // Login Plugin Request
+			if err := pr.send(data.Clientbound, p); err != nil {
+				return err
+			}
+			if err := pr.pass(data.Serverbound); err != nil { // Login Plugin Response
+				return err
+			}
+		default:
+			if err := pr.send(data.Clientbound, p); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// both forward the packets of both directions until either fails
+func (pr *proxy) both() error {
+	errs := make(chan error, 2)
+	for _, dir := range []data.Direction{data.Serverbound, data.Clientbound} {
+		go func(dir data.Direction) {
+			for {
+				if err := pr.pass(dir); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(dir)
+	}
+	return <-errs // the other goroutine returns when the Conns are closed
+}
+
+// raw copy the bytes of both directions until either fails
+func (pr *proxy) raw() error {
+	errs := make(chan error, 2)
+	go func() {
+		_, err := io.Copy(pr.server, pr.client.Reader)
+		errs <- err
+	}()
+	go func() {
+		_, err := io.Copy(pr.client, pr.server.Reader)
+		errs <- err
+	}()
+	return <-errs
+}
+
+// pass forward a packet of the direction
+func (pr *proxy) pass(dir data.Direction) error {
+	p, err := pr.recv(dir)
+	if err != nil {
+		return err
+	}
+	return pr.send(dir, p)
+}
+
+func (pr *proxy) recv(dir data.Direction) (pk.Packet, error) {
+	src := pr.client
+	if dir == data.Clientbound {
+		src = pr.server
+	}
+	p, err := src.ReadPacket()
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = fmt.Errorf("net: proxy read %v packet fail: %w", dir, err)
+	}
+	return p, err
+}
+
+func (pr *proxy) send(dir data.Direction, p pk.Packet) error {
+	dst := pr.server
+	if dir == data.Clientbound {
+		dst = pr.client
+	}
+	pr.mu.Lock()
+	forward := pr.onPacket == nil || pr.onPacket(dir, pr.state, &p)
+	pr.mu.Unlock()
+	if !forward {
+		return nil
+	}
+	if err := dst.WritePacket(p); err != nil {
+		return fmt.Errorf("net: proxy write %v packet fail: %w", dir, err)
+	}
+	return nil
+}
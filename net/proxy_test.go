@@ -0,0 +1,114 @@
+package net
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// startPipe connect a client and a server through Pipe
+func startPipe(onPacket func(data.Direction, data.State, *pk.Packet) bool) (client, server *Conn, errs chan error) {
+	c1, c2 := net.Pipe()
+	s1, s2 := net.Pipe()
+	errs = make(chan error, 1)
+	go func() { errs <- Pipe(WrapConn(c2), WrapConn(s1), onPacket) }()
+	return WrapConn(c1), WrapConn(s2), errs
+}
+
+func TestPipe(t *testing.T) {
+	type seen struct {
+		dir   data.Direction
+		state data.State
+		id    int32
+	}
+	var (
+		mu      sync.Mutex
+		packets []seen
+	)
+	client, server, errs := startPipe(func(dir data.Direction, state data.State, p *pk.Packet) bool {
+		mu.Lock()
+		packets = append(packets, seen{dir, state, p.ID})
+		mu.Unlock()
+		if state == data.Play && p.ID == data.ChatMessageServerbound {
+			*p = pk.Marshal(p.ID, pk.String("modified"))
+		}
+		return !(state == data.Play && p.ID == 0x7F) // dropped
+	})
+
+	roundTrip(t, client, server, pk.Marshal(0x00, pk.VarInt(736), pk.String("localhost"), pk.UnsignedShort(25565), pk.VarInt(2)))
+	roundTrip(t, client, server, pk.Marshal(0x00, pk.String("Steve")))
+	roundTrip(t, server, client, pk.Marshal(0x03, pk.VarInt(16)))
+	server.SetThreshold(16)
+	client.SetThreshold(16)
+	roundTrip(t, server, client, pk.Marshal(0x02, pk.UUID{}, pk.String("Steve")))
+
+	chat := roundTrip(t, client, server, pk.Marshal(data.ChatMessageServerbound, pk.String("hi")))
+	var msg pk.String
+	if err := chat.Scan(&msg); err != nil || msg != "modified" {
+		t.Errorf("the packet should be modified, get %q, %v", msg, err)
+	}
+
+	// the dropped packet doesn't reach the client, but the next one
+	go func() {
+		server.WritePacket(pk.Marshal(0x7F))
+		server.WritePacket(pk.Marshal(data.ChunkData, pk.ByteArray(bytes.Repeat([]byte{1}, 1000))))
+	}()
+	p, err := client.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != data.ChunkData || len(p.Data) != 1002 {
+		t.Errorf("wrong packet after the dropped one: 0x%02X, %d bytes", p.ID, len(p.Data))
+	}
+
+	client.Close()
+	if err := <-errs; err != nil {
+		t.Errorf("Pipe should return nil when closed, get %v", err)
+	}
+
+	want := []seen{
+		{data.Serverbound, data.Handshaking, 0x00},
+		{data.Serverbound, data.Login, 0x00},
+		{data.Clientbound, data.Login, 0x03},
+		{data.Clientbound, data.Login, 0x02},
+		{data.Serverbound, data.Play, data.ChatMessageServerbound},
+		{data.Clientbound, data.Play, 0x7F},
+		{data.Clientbound, data.Play, data.ChunkData},
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(packets) != len(want) {
+		t.Fatalf("onPacket called with %v, want %v", packets, want)
+	}
+	for i := range want {
+		if packets[i] != want[i] {
+			t.Errorf("packet %d: %v, want %v", i, packets[i], want[i])
+		}
+	}
+}
+
+func TestPipe_encrypted(t *testing.T) {
+	client, server, errs := startPipe(nil)
+
+	roundTrip(t, client, server, pk.Marshal(0x00, pk.VarInt(736), pk.String("localhost"), pk.UnsignedShort(25565), pk.VarInt(2)))
+	roundTrip(t, client, server, pk.Marshal(0x00, pk.String("Steve")))
+	roundTrip(t, server, client, pk.Marshal(0x01, pk.String(""), pk.ByteArray{1}, pk.ByteArray{2}))
+	roundTrip(t, client, server, pk.Marshal(0x01, pk.ByteArray{3}, pk.ByteArray{4}))
+
+	// the bytes after are copied as they are
+	go client.Write([]byte("not a packet"))
+	buf := make([]byte, 12)
+	if _, err := io.ReadFull(server.Reader, buf); err != nil || string(buf) != "not a packet" {
+		t.Errorf("raw bytes: %q, %v", buf, err)
+	}
+
+	client.Close()
+	if err := <-errs; err != nil {
+		t.Errorf("Pipe should return nil when closed, get %v", err)
+	}
+}
@@ -6,42 +6,40 @@ import (
 )
 
 func Test(t *testing.T) {
-	p := make(chan int, 1)
-	go server(t, p)
-	<-p
-	client(t)
-}
-
-func server(t *testing.T, prepare chan<- int) {
 	l, err := ListenRCON("localhost:25575")
 	if err != nil {
 		t.Fatal(err)
 	}
 	defer l.Close()
+	go server(t, l)
+	client(t)
+}
 
-	prepare <- 1
-
+func server(t *testing.T, l *RCONListener) {
 	for {
 		conn, err := l.Accept()
 		if err != nil {
-			t.Fatal(err)
+			// the listener is closed when the test completed
+			return
 		}
 		go func(conn RCONServerConn) {
 			err := conn.AcceptLogin("RightPassword")
 			if err != nil {
-				t.Fatal("password wrong")
+				t.Error("password wrong")
+				return
 			}
 
 			for {
 				cmd, err := conn.AcceptCmd()
 				if err != nil {
-					t.Log(err)
+					// the client closed the connection, maybe after the test completed
 					return
 				}
 				resp := handleCommand(cmd)
 				err = conn.RespCmd(resp)
 				if err != nil {
-					t.Fatal(err)
+					t.Error(err)
+					return
 				}
 			}
 		}(conn)
@@ -0,0 +1,178 @@
+package net
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// The packet log starts with recordMagic, followed by the records.
+// Each record is a VarInt length and then the fields:
+//
+//	Byte     direction
+//	VarInt   state
+//	Boolean  encrypted
+//	VarInt   compression threshold
+//	Long     timestamp in Unix nanoseconds
+//	VarInt   packet ID
+//	...      packet data, uncompressed and decrypted
+const recordMagic = "GOMCREC\x01"
+
+// maxRecordSize is the max length of a record, which is the fields
+// before the packet data, of 25 bytes at most, and the largest packet.
+const maxRecordSize = 25 + DefaultMaxPacketSize
+
+// Direction of a recorded packet, relative to the recording side
+type Direction byte
+
+const (
+	Inbound  Direction = iota // read from the Conn
+	Outbound                  // written to the Conn
+)
+
+// Record is a packet captured by Recorder
+type Record struct {
+	Direction Direction
+	State     data.State
+	Encrypted bool // whether the Conn was encrypted
+	Threshold int  // the compression threshold of the Conn, negative for disabled
+	Time      time.Time
+	Packet    pk.Packet
+}
+
+// Recorder wraps a Conn and writes every packet read or written to a log.
+// The log can be replayed by ReplayConn.
+type Recorder struct {
+	*Conn
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewRecorder return a Recorder of conn writing the log to w.
+// Each packet is recorded with the State of conn it belongs to.
+func NewRecorder(conn *Conn, w io.Writer) (*Recorder, error) {
+	if _, err := io.WriteString(w, recordMagic); err != nil {
+		return nil, err
+	}
+	return &Recorder{Conn: conn, w: w}, nil
+}
+
+// ReadPacket read a Packet from Conn and record it.
+func (r *Recorder) ReadPacket() (pk.Packet, error) {
+	s := r.Conn.State() // before the packet changes it
+	p, err := r.Conn.ReadPacket()
+	if err != nil {
+		return p, err
+	}
+	return p, r.record(Inbound, s, p)
+}
+
+// WritePacket record the Packet and write it to Conn.
+func (r *Recorder) WritePacket(p pk.Packet) error {
+	if err := r.record(Outbound, r.Conn.State(), p); err != nil {
+		return err
+	}
+	return r.Conn.WritePacket(p)
+}
+
+func (r *Recorder) record(d Direction, s data.State, p pk.Packet) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var buf bytes.Buffer
+	buf.Write(pk.Byte(d).Encode())
+	buf.Write(pk.VarInt(s).Encode())
+	buf.Write(pk.Boolean(r.Conn.encrypted).Encode())
+	buf.Write(pk.VarInt(r.Conn.compression()).Encode())
+	buf.Write(pk.Long(time.Now().UnixNano()).Encode())
+	buf.Write(pk.VarInt(p.ID).Encode())
+	buf.Write(p.Data)
+
+	if _, err := r.w.Write(append(pk.VarInt(buf.Len()).Encode(), buf.Bytes()...)); err != nil {
+		return fmt.Errorf("net: record packet fail: %v", err)
+	}
+	return nil
+}
+
+// ReplayConn reads a log written by Recorder,
+// and serves the inbound packets as if from a live connection.
+type ReplayConn struct {
+	r *bufio.Reader
+}
+
+// NewReplayConn return a ReplayConn reading the log from r.
+func NewReplayConn(r io.Reader) (*ReplayConn, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(recordMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("net: read packet log fail: %v", err)
+	}
+	if string(magic) != recordMagic {
+		return nil, errors.New("net: not a packet log")
+	}
+	return &ReplayConn{r: br}, nil
+}
+
+// Next return the next Record of both directions.
+// io.EOF is returned at the end of the log.
+func (c *ReplayConn) Next() (rec Record, err error) {
+	var length pk.VarInt
+	if err := length.Decode(c.r); err != nil {
+		return rec, err // io.EOF at the end
+	}
+	if length < 0 || length > maxRecordSize {
+		return rec, fmt.Errorf("net: record length %d out of range", length)
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(c.r, buf); err != nil {
+		return rec, fmt.Errorf("net: read record fail: %v", err)
+	}
+
+	var (
+		d         pk.Byte
+		state     pk.VarInt
+		encrypted pk.Boolean
+		threshold pk.VarInt
+		timestamp pk.Long
+		id        pk.VarInt
+	)
+	r := bytes.NewReader(buf)
+	for _, f := range []pk.FieldDecoder{&d, &state, &encrypted, &threshold, &timestamp, &id} {
+		if err := f.Decode(r); err != nil {
+			return rec, fmt.Errorf("net: decode record fail: %v", err)
+		}
+	}
+	return Record{
+		Direction: Direction(d),
+		State:     data.State(state),
+		Encrypted: bool(encrypted),
+		Threshold: int(threshold),
+		Time:      time.Unix(0, int64(timestamp)),
+		Packet:    pk.Packet{ID: int32(id), Data: buf[len(buf)-r.Len():]},
+	}, nil
+}
+
+// ReadPacket return the packet of the next inbound Record.
+// io.EOF is returned at the end of the log.
+func (c *ReplayConn) ReadPacket() (pk.Packet, error) {
+	for {
+		rec, err := c.Next()
+		if err != nil {
+			return pk.Packet{}, err
+		}
+		if rec.Direction == Inbound {
+			return rec.Packet, nil
+		}
+	}
+}
+
+// WritePacket discard the packet.
+// The outbound packets in the log are skipped by ReadPacket.
+func (c *ReplayConn) WritePacket(p pk.Packet) error { return nil }
@@ -0,0 +1,113 @@
+package net
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+func TestRecorder(t *testing.T) {
+	client, server := pipe()
+	defer client.Close()
+	defer server.Close()
+
+	var log bytes.Buffer
+	rec, err := NewRecorder(client, &log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handshake := pk.Marshal(0x00, pk.VarInt(736), pk.String("localhost"), pk.UnsignedShort(25565), pk.Byte(2))
+	errs := make(chan error, 1)
+	go func() {
+		_, err := server.ReadPacket()
+		errs <- err
+	}()
+	if err := rec.WritePacket(handshake); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("read packet fail: %v", err)
+	}
+
+	rec.SetThreshold(256)
+	server.SetThreshold(256)
+	loginSuccess := pk.Marshal(0x02, pk.String("Tnze"))
+	go func() { errs <- server.WritePacket(loginSuccess) }()
+	if _, err := rec.ReadPacket(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("write packet fail: %v", err)
+	}
+
+	replay, err := NewReplayConn(&log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Record{
+		{Direction: Outbound, State: data.Handshaking, Threshold: -1, Packet: handshake},
+		{Direction: Inbound, State: data.Login, Threshold: 256, Packet: loginSuccess},
+	}
+	for i, w := range want {
+		got, err := replay.Next()
+		if err != nil {
+			t.Fatalf("read record %d fail: %v", i, err)
+		}
+		if got.Direction != w.Direction || got.State != w.State || got.Threshold != w.Threshold || got.Encrypted ||
+			got.Packet.ID != w.Packet.ID || !bytes.Equal(got.Packet.Data, w.Packet.Data) {
+			t.Errorf("record %d: want %+v, get %+v", i, w, got)
+		}
+		if got.Time.IsZero() {
+			t.Errorf("record %d has no timestamp", i)
+		}
+	}
+	if _, err := replay.Next(); err != io.EOF {
+		t.Errorf("want io.EOF at the end, get %v", err)
+	}
+}
+
+func TestReplayConn_ReadPacket(t *testing.T) {
+	var log bytes.Buffer
+	rec, err := NewRecorder(&Conn{Writer: ioutil.Discard}, &log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the outbound packets should be skipped
+	rec.WritePacket(pk.Marshal(0x00))
+	rec.record(Inbound, data.Play, pk.Marshal(0x01, pk.Long(42)))
+
+	replay, err := NewReplayConn(&log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := replay.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v pk.Long
+	if p.ID != 0x01 || p.Scan(&v) != nil || v != 42 {
+		t.Errorf("wrong packet: %+v", p)
+	}
+	if _, err := replay.ReadPacket(); err != io.EOF {
+		t.Errorf("want io.EOF at the end, get %v", err)
+	}
+
+	for _, length := range []pk.VarInt{-1, maxRecordSize + 1} {
+		replay, err := NewReplayConn(bytes.NewReader(append([]byte(recordMagic), length.Encode()...)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := replay.Next(); err == nil || err == io.EOF {
+			t.Errorf("record of length %d should be error, get %v", length, err)
+		}
+	}
+
+	if _, err := NewReplayConn(bytes.NewReader([]byte("not a log"))); err == nil {
+		t.Error("NewReplayConn should fail on a bad log")
+	}
+}
@@ -0,0 +1,84 @@
+package net
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Tnze/go-mc/chat"
+)
+
+// Status is the server status returned by the ServerListPing.
+//
+// For more information for JSON format, see https://wiki.vg/Server_List_Ping#Response
+type Status struct {
+	Version struct {
+		Name     string `json:"name"`
+		Protocol int    `json:"protocol"`
+	} `json:"version"`
+	Players struct {
+		Max    int `json:"max"`
+		Online int `json:"online"`
+		// Sample is some of the online players. Servers often put lines of
+		// text here with made-up IDs, so the IDs aren't validated as UUIDs.
+		Sample []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"sample,omitempty"`
+	} `json:"players"`
+	// Description is the MOTD of server,
+	// which may be a plain string or a chat component.
+	Description chat.Message `json:"description"`
+	// FaviconPNG is the PNG image decoded from the favicon field,
+	// empty if the server doesn't have one.
+	FaviconPNG []byte `json:"-"`
+
+	// The mods of Forge servers, nil for vanilla servers. See Mods.
+	ModInfo   *ModInfo   `json:"modinfo,omitempty"`   // FML1
+	ForgeData *ForgeData `json:"forgeData,omitempty"` // FML2 and later
+}
+
+const faviconPrefix = "data:image/png;base64,"
+
+// UnmarshalStatus parse the JSON data returned by PingAndList.
+func UnmarshalStatus(data []byte) (Status, error) {
+	var s struct {
+		Status
+		Favicon string
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Status{}, fmt.Errorf("status: unmarshal fail: %w", err)
+	}
+
+	if s.Favicon != "" {
+		if !strings.HasPrefix(s.Favicon, faviconPrefix) {
+			return s.Status, fmt.Errorf("status: unknown favicon format")
+		}
+		// some servers break the base64 string into lines
+		b64 := strings.NewReplacer("\n", "", "\r", "").Replace(s.Favicon[len(faviconPrefix):])
+		png, err := base64.StdEncoding.DecodeString(b64)
+		if err != nil {
+			return s.Status, fmt.Errorf("status: decode favicon fail: %w", err)
+		}
+		s.FaviconPNG = png
+	}
+	return s.Status, nil
+}
+
+// MarshalStatus encode s to the JSON of the status response,
+// which is sent by the servers to the ServerListPing.
+func MarshalStatus(s Status) ([]byte, error) {
+	v := struct {
+		Status
+		Favicon string `json:"favicon,omitempty"`
+	}{Status: s}
+	if s.FaviconPNG != nil {
+		v.Favicon = faviconPrefix + base64.StdEncoding.EncodeToString(s.FaviconPNG)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("status: marshal fail: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,151 @@
+package net
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Tnze/go-mc/chat"
+)
+
+func TestUnmarshalStatus(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data string
+		desc string
+	}{
+		{
+			name: "plain string",
+			data: `{"version":{"name":"1.16.1","protocol":736},"players":{"max":20,"online":1,"sample":[{"name":"Tnze","id":"58f6356e-b30c-4811-8bfc-d72a9ee99e73"}]},"description":"A Minecraft Server"}`,
+			desc: "A Minecraft Server",
+		},
+		{
+			name: "chat component",
+			data: `{"version":{"name":"1.16.1","protocol":736},"players":{"max":20,"online":1,"sample":[{"name":"Tnze","id":"58f6356e-b30c-4811-8bfc-d72a9ee99e73"}]},"description":{"text":"A ","extra":[{"text":"Minecraft Server","bold":true}]}}`,
+			desc: "A Minecraft Server",
+		},
+	} {
+		s, err := UnmarshalStatus([]byte(tt.data))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if s.Version.Name != "1.16.1" || s.Version.Protocol != 736 {
+			t.Errorf("%s: wrong version: %+v", tt.name, s.Version)
+		}
+		if s.Players.Max != 20 || s.Players.Online != 1 ||
+			len(s.Players.Sample) != 1 || s.Players.Sample[0].Name != "Tnze" ||
+			s.Players.Sample[0].ID != "58f6356e-b30c-4811-8bfc-d72a9ee99e73" {
+			t.Errorf("%s: wrong players: %+v", tt.name, s.Players)
+		}
+		if desc := s.Description.ClearString(); desc != tt.desc {
+			t.Errorf("%s: description should be %q, get %q", tt.name, tt.desc, desc)
+		}
+		if s.FaviconPNG != nil {
+			t.Errorf("%s: favicon should be empty", tt.name)
+		}
+	}
+}
+
+func TestUnmarshalStatus_sampleID(t *testing.T) {
+	s, err := UnmarshalStatus([]byte(`{"players":{"max":20,"online":0,"sample":[{"name":"Welcome!","id":""},{"name":"Tnze","id":"58f6356e"}]},"description":""}`))
+	if err != nil {
+		t.Fatalf("malformed sample ids shouldn't fail the status: %v", err)
+	}
+	if len(s.Players.Sample) != 2 || s.Players.Sample[0].Name != "Welcome!" || s.Players.Sample[1].ID != "58f6356e" {
+		t.Errorf("wrong sample: %+v", s.Players.Sample)
+	}
+}
+
+func TestUnmarshalStatus_favicon(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+	s, err := UnmarshalStatus([]byte(`{"description":"","favicon":"data:image/png;base64,iVBO\nRw0KGgo="}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s.FaviconPNG, png) {
+		t.Errorf("favicon should be %q, get %q", png, s.FaviconPNG)
+	}
+
+	if _, err := UnmarshalStatus([]byte(`{"favicon":"data:image/jpeg;base64,"}`)); err == nil {
+		t.Error("unknown favicon format should be error")
+	}
+}
+
+func TestUnmarshalStatus_forge(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data string
+	}{
+		{
+			name: "FML1",
+			data: `{"version":{"name":"1.12.2","protocol":340},"description":"A Forge Server","modinfo":{"type":"FML","modList":[{"modid":"minecraft","version":"1.12.2"},{"modid":"forge","version":"14.23.5.2854"}]}}`,
+		},
+		{
+			name: "FML2",
+			data: `{"version":{"name":"1.16.5","protocol":754},"description":"A Forge Server","forgeData":{"channels":[{"res":"forge:tier_sorting","version":"1.0","required":false}],"mods":[{"modId":"minecraft","modmarker":"1.12.2"},{"modId":"forge","modmarker":"14.23.5.2854"}],"fmlNetworkVersion":2}}`,
+		},
+	} {
+		s, err := UnmarshalStatus([]byte(tt.data))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		mods := s.Mods()
+		if len(mods) != 2 || mods[1] != (Mod{ID: "forge", Version: "14.23.5.2854"}) {
+			t.Errorf("%s: wrong mods: %v", tt.name, mods)
+		}
+	}
+
+	s, err := UnmarshalStatus([]byte(`{"version":{"name":"1.16.1","protocol":736},"description":""}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ModInfo != nil || s.ForgeData != nil || len(s.Mods()) != 0 {
+		t.Errorf("vanilla server shouldn't have mods: %+v", s)
+	}
+}
+
+func TestParseFMLAddress(t *testing.T) {
+	for _, tt := range []struct {
+		addr    string
+		host    string
+		version int
+	}{
+		{"example.com", "example.com", NoFML},
+		{"example.com\x00FML\x00", "example.com", FML1},
+		{"example.com\x00FML2\x00", "example.com", FML2},
+		{"example.com\x00FML3\x00", "example.com", FML3},
+		{"example.com\x00something", "example.com", NoFML},
+	} {
+		host, version := ParseFMLAddress(tt.addr)
+		if host != tt.host || version != tt.version {
+			t.Errorf("ParseFMLAddress(%q) = %q, %d; want %q, %d", tt.addr, host, version, tt.host, tt.version)
+		}
+	}
+}
+
+func TestMarshalStatus(t *testing.T) {
+	var s Status
+	s.Version.Name = "1.16.1"
+	s.Version.Protocol = 736
+	s.Players.Max, s.Players.Online = 20, 0
+	s.Description = chat.Text("A Minecraft Server")
+	data, err := MarshalStatus(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `{"version":{"name":"1.16.1","protocol":736},"players":{"max":20,"online":0},"description":"A Minecraft Server"}`
+	if string(data) != want {
+		t.Errorf("got  %s\nwant %s", data, want)
+	}
+
+	s.FaviconPNG = []byte("\x89PNG\r\n\x1a\n")
+	if data, err = MarshalStatus(s); err != nil {
+		t.Fatal(err)
+	}
+	got, err := UnmarshalStatus(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.FaviconPNG, s.FaviconPNG) || got.Version != s.Version {
+		t.Errorf("round trip: %s", data)
+	}
+}
@@ -0,0 +1,134 @@
+package net
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"time"
+
+	"github.com/Tnze/go-mc/auth"
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/google/uuid"
+)
+
+// VelocityChannel is the channel of the Login Plugin Request
+// of Velocity modern forwarding
+const VelocityChannel = "velocity:player_info"
+
+// The versions of Velocity modern forwarding
+const (
+	VelocityForwardingDefault   = 1 // the address, UUID, name and properties
+	VelocityForwardingWithKey   = 2 // with the player's public key of 1.19
+	VelocityForwardingWithKeyV2 = 3 // with the public key of 1.19.1 and its holder UUID
+	VelocityForwardingLazy      = 4 // without key, for 1.19.3 and later
+	VelocityForwardingMax       = VelocityForwardingLazy
+)
+
+// protocol1193 is the protocol version of 1.19.3, since which the key isn't forwarded
+const protocol1193 = 761
+
+// VelocityForwarding answer the Login Plugin Request of Velocity modern
+// forwarding, as if the client connects to the server through a Velocity
+// proxy. The server must be behind Velocity with the same forwarding secret.
+//
+// Use its OnPluginRequest as the LoginOptions.OnPluginRequest:
+//
+//	fwd := VelocityForwarding{Secret: secret, Address: "127.0.0.1", Profile: profile}
+//	_, _, err := Login(conn, profile, LoginOptions{OnPluginRequest: fwd.OnPluginRequest})
+type VelocityForwarding struct {
+	Secret  []byte       // the forwarding secret
+	Address string       // the IP address of the player
+	Profile auth.Profile // the UUID, name and properties of the player
+	// Protocol is the protocol version of the player's game, which decides
+	// the forwarding version. If it's 0, data.ProtocolVersion is used.
+	Protocol int
+	// PlayerKey is the public key of the player since 1.19, nil for none
+	PlayerKey *VelocityPlayerKey
+}
+
+// VelocityPlayerKey is the public key of the player, signed by Mojang
+type VelocityPlayerKey struct {
+	Expiry    time.Time
+	PublicKey []byte // encoded in X.509
+	Signature []byte
+	// Holder is the UUID of the player signed with the key of 1.19.1,
+	// nil for the key of 1.19
+	Holder *uuid.UUID
+}
+
+// OnPluginRequest answer the request on VelocityChannel with the forwarding
+// data. The requests on other channels are not understood.
+func (v VelocityForwarding) OnPluginRequest(channel string, data []byte) (response []byte, understood bool) {
+	if channel != VelocityChannel {
+		return nil, false
+	}
+	// The request of version 1 has no data,
+	// while the later ones contain the max version of the server.
+	requested := VelocityForwardingDefault
+	if len(data) > 0 {
+		requested = int(data[0])
+	}
+	return v.Data(v.version(requested)), true
+}
+
+// version choose the forwarding version for the requested one,
+// like Velocity does
+func (v VelocityForwarding) version(requested int) int {
+	if requested > VelocityForwardingMax {
+		requested = VelocityForwardingMax
+	}
+	if requested <= VelocityForwardingDefault {
+		return VelocityForwardingDefault
+	}
+	protocol := v.Protocol
+	if protocol == 0 {
+		protocol = data.ProtocolVersion
+	}
+	switch {
+	case protocol >= protocol1193:
+		if requested >= VelocityForwardingLazy {
+			return VelocityForwardingLazy
+		}
+	case v.PlayerKey == nil:
+	case v.PlayerKey.Holder == nil:
+		return VelocityForwardingWithKey
+	case requested >= VelocityForwardingWithKeyV2:
+		// the key of 1.19.1 isn't compatible with version 2
+		return VelocityForwardingWithKeyV2
+	}
+	return VelocityForwardingDefault
+}
+
+// Data return the forwarding data of the version, which is
+// the HMAC-SHA256 signature of the secret followed by the signed content.
+func (v VelocityForwarding) Data(version int) []byte {
+	var content []byte
+	content = append(content, pk.VarInt(version).Encode()...)
+	content = append(content, pk.String(v.Address).Encode()...)
+	content = append(content, pk.UUID(v.Profile.ID).Encode()...)
+	content = append(content, pk.String(v.Profile.Name).Encode()...)
+	content = append(content, pk.VarInt(len(v.Profile.Properties)).Encode()...)
+	for _, p := range v.Profile.Properties {
+		content = append(content, pk.String(p.Name).Encode()...)
+		content = append(content, pk.String(p.Value).Encode()...)
+		content = append(content, pk.Boolean(p.Signature != "").Encode()...)
+		if p.Signature != "" {
+			content = append(content, pk.String(p.Signature).Encode()...)
+		}
+	}
+	if k := v.PlayerKey; k != nil && version >= VelocityForwardingWithKey && version < VelocityForwardingLazy {
+		content = append(content, pk.Long(k.Expiry.UnixNano()/int64(time.Millisecond)).Encode()...)
+		content = append(content, pk.ByteArray(k.PublicKey).Encode()...)
+		content = append(content, pk.ByteArray(k.Signature).Encode()...)
+		if version >= VelocityForwardingWithKeyV2 {
+			content = append(content, pk.Boolean(k.Holder != nil).Encode()...)
+			if k.Holder != nil {
+				content = append(content, pk.UUID(*k.Holder).Encode()...)
+			}
+		}
+	}
+
+	mac := hmac.New(sha256.New, v.Secret)
+	mac.Write(content)
+	return append(mac.Sum(nil), content...)
+}
@@ -0,0 +1,97 @@
+package net
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/Tnze/go-mc/auth"
+	"github.com/google/uuid"
+)
+
+func TestVelocityForwarding_Data(t *testing.T) {
+	fwd := VelocityForwarding{
+		Secret:  []byte("secret"),
+		Address: "127.0.0.1",
+		Profile: auth.Profile{
+			ID:   uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5"),
+			Name: "Notch",
+			Properties: []auth.Property{
+				{Name: "textures", Value: "dGV4dHVyZXM=", Signature: "c2lnbmF0dXJl"},
+			},
+		},
+	}
+	// The signature and content of version 1, as Velocity signs
+	// them and the server checks them with the same secret.
+	const (
+		signature = "ae3867912efaaf0701924648e2676364df55e7e08c9aa132e715d06556cdd5fa"
+		content   = "01093132372e302e302e31069a79f444e94726a5befca90e38aaf5054e6f746368" +
+			"010874657874757265730c64475634644856795a584d3d010c63326c6e626d463064584a6c"
+	)
+	resp, ok := fwd.OnPluginRequest(VelocityChannel, nil)
+	if !ok {
+		t.Fatal("the request is not understood")
+	}
+	if got := hex.EncodeToString(resp); got != signature+content {
+		t.Errorf("forwarding data:\ngot  %s\nwant %s", got, signature+content)
+	}
+
+	if _, ok := fwd.OnPluginRequest("bungeecord:main", nil); ok {
+		t.Error("the request of another channel is understood")
+	}
+}
+
+func TestVelocityForwarding_version(t *testing.T) {
+	holder := uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5")
+	keyV1 := &VelocityPlayerKey{PublicKey: []byte{1}, Signature: []byte{2}}
+	keyV2 := &VelocityPlayerKey{PublicKey: []byte{1}, Signature: []byte{2}, Holder: &holder}
+	for _, v := range []struct {
+		protocol, requested int
+		key                 *VelocityPlayerKey
+		want                int
+	}{
+		{protocol: 736, requested: 1, want: 1},
+		{protocol: 736, requested: 4, want: 1},
+		{protocol: 759, requested: 4, key: keyV1, want: 2},
+		{protocol: 760, requested: 2, key: keyV2, want: 1},
+		{protocol: 760, requested: 3, key: keyV2, want: 3},
+		{protocol: 760, requested: 9, key: keyV2, want: 3},
+		{protocol: 761, requested: 3, want: 1},
+		{protocol: 761, requested: 4, want: 4},
+	} {
+		fwd := VelocityForwarding{Protocol: v.protocol, PlayerKey: v.key}
+		if got := fwd.version(v.requested); got != v.want {
+			t.Errorf("protocol %d requested %d: got version %d, want %d", v.protocol, v.requested, got, v.want)
+		}
+	}
+}
+
+func TestVelocityForwarding_key(t *testing.T) {
+	holder := uuid.MustParse("069a79f4-44e9-4726-a5be-fca90e38aaf5")
+	fwd := VelocityForwarding{
+		Secret:    []byte("secret"),
+		Profile:   auth.Profile{ID: holder, Name: "Notch"},
+		Protocol:  760,
+		PlayerKey: &VelocityPlayerKey{Expiry: time.Unix(0, 0), PublicKey: []byte{1, 2}, Signature: []byte{3}, Holder: &holder},
+	}
+	resp, ok := fwd.OnPluginRequest(VelocityChannel, []byte{VelocityForwardingWithKeyV2})
+	if !ok {
+		t.Fatal("the request is not understood")
+	}
+	// the content is checked by the HMAC of the secret in the server
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(resp[sha256.Size:])
+	if !hmac.Equal(mac.Sum(nil), resp[:sha256.Size]) {
+		t.Error("the signature doesn't match the content")
+	}
+	content := resp[sha256.Size:]
+	const keyFields = "0000000000000000" + "020102" + "0103" + "01" + "069a79f444e94726a5befca90e38aaf5"
+	if content[0] != VelocityForwardingWithKeyV2 {
+		t.Errorf("forwarding version %d", content[0])
+	}
+	if got := hex.EncodeToString(content); len(got) < len(keyFields) || got[len(got)-len(keyFields):] != keyFields {
+		t.Errorf("the key isn't at the end of the content: %s", got)
+	}
+}
@@ -0,0 +1,26 @@
+// Package offline implements the player UUID of offline mode servers.
+package offline
+
+import (
+	"crypto/md5"
+
+	"github.com/google/uuid"
+)
+
+// NameUUID return the UUID of the player name in offline mode.
+//
+// It's the same as the Java code used by vanilla:
+//
+//	UUID.nameUUIDFromBytes(("OfflinePlayer:" + name).getBytes(StandardCharsets.UTF_8))
+//
+// which is a version 3 UUID without namespace.
+func NameUUID(name string) uuid.UUID {
+	var version = 3
+	var id uuid.UUID
+	h := md5.New()
+	h.Write([]byte("OfflinePlayer:" + name))
+	copy(id[:], h.Sum(nil))
+	id[6] = (id[6] & 0x0f) | uint8((version&0xf)<<4)
+	id[8] = (id[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return id
+}
@@ -0,0 +1,19 @@
+package offline
+
+import "testing"
+
+func TestNameUUID(t *testing.T) {
+	for _, v := range []struct{ name, id string }{
+		{"Notch", "b50ad385-829d-3141-a216-7e7d7539ba7f"},
+		{"jeb_", "a762f560-4fce-3236-812a-b80efff0b62b"},
+		{"Tnze", "c7b9eece-2f2e-325c-8da8-6fc8f3d0edb0"},
+	} {
+		id := NameUUID(v.name)
+		if id.String() != v.id {
+			t.Errorf("offline uuid of %s should be %s, get %s", v.name, v.id, id)
+		}
+		if id.Version() != 3 {
+			t.Errorf("offline uuid of %s should be version 3, get %d", v.name, id.Version())
+		}
+	}
+}
@@ -1,12 +1,10 @@
 package save
 
 import (
-	"bytes"
-	"compress/gzip"
-	"compress/zlib"
-	"errors"
+	"fmt"
+	"math/bits"
+
 	"github.com/Tnze/go-mc/nbt"
-	"io"
 )
 
 // Column is 16* chunk
@@ -34,14 +32,61 @@ type Column struct {
 		PosY          int32 `nbt:"yPos"`
 		Biomes        []int32
 	}
+	// Since 1.18 there is no Level compound, and the sections are at the top level
+	Sections   []Chunk `nbt:"sections"`
+	Heightmaps map[string][]int64
+	PosY       int32 `nbt:"yPos"` // the lowest section Y of the chunk
+
+	// Dimension is the vertical range of the world the column belongs to.
+	// It isn't saved in the chunk, so if it's zero, it's guessed from the
+	// DataVersion and yPos of the column, which works for vanilla worlds.
+	// Set it for dimensions of datapacks with a custom height.
+	Dimension Dimension `nbt:"-"`
+}
+
+// Dimension is the vertical range of the blocks of a dimension,
+// as the min_y and height of its dimension type.
+type Dimension struct {
+	MinY   int // the lowest Y of blocks, multiple of 16
+	Height int // the number of blocks high, multiple of 16
 }
 
+// The vanilla dimensions
+var (
+	Overworld    = Dimension{MinY: -64, Height: 384} // since 1.18
+	Nether       = Dimension{MinY: 0, Height: 256}
+	End          = Dimension{MinY: 0, Height: 256}
+	OldOverworld = Dimension{MinY: 0, Height: 256} // before 1.18
+)
+
+// MaxY return the highest Y of blocks
+func (d Dimension) MaxY() int { return d.MinY + d.Height - 1 }
+
+// Sections return the number of sections of a column
+func (d Dimension) Sections() int { return d.Height / 16 }
+
 type Chunk struct {
 	Palette     []Block
 	Y           byte
 	BlockLight  []byte
 	BlockStates []int64
 	SkyLight    []byte
+	// Since 1.18 the block palette and BlockStates are kept in this compound
+	BlockStatesData BlockStates `nbt:"block_states"`
+	// Since 1.18 biomes are stored per section in 4*4*4 cells
+	BiomesData Biomes `nbt:"biomes"`
+}
+
+// BlockStates is the paletted block container of a section since 1.18
+type BlockStates struct {
+	Palette []Block `nbt:"palette"`
+	Data    []int64 `nbt:"data"`
+}
+
+// Biomes is the paletted biome container of a section since 1.18
+type Biomes struct {
+	Palette []string `nbt:"palette"`
+	Data    []int64  `nbt:"data"`
 }
 
 type Block struct {
@@ -49,23 +94,142 @@ type Block struct {
 	Properties map[string]interface{}
 }
 
-// Load read column data from []byte
-func (c *Column) Load(data []byte) (err error) {
-	var r io.Reader = bytes.NewReader(data[1:])
+// dataVersion118 is the DataVersion of 1.18,
+// since which the overworld is 384 blocks high and start from Y -64.
+const dataVersion118 = 2860
+
+// WorldDimension return the Dimension of the column,
+// or the guessed one if it isn't set.
+func (c *Column) WorldDimension() Dimension {
+	if c.Dimension != (Dimension{}) {
+		return c.Dimension
+	}
+	if c.DataVersion < dataVersion118 {
+		return OldOverworld
+	}
+	// The nether and the end are still 256 blocks high, and their yPos is 0.
+	if c.PosY == 0 {
+		return Nether
+	}
+	return Dimension{MinY: int(c.PosY) * 16, Height: Overworld.Height}
+}
 
-	switch data[0] {
-	default:
-		err = errors.New("unknown compression")
-	case 1:
-		r, err = gzip.NewReader(r)
-	case 2:
-		r, err = zlib.NewReader(r)
+// sections return the sections of the column in either format
+func (c *Column) sections() []Chunk {
+	if c.DataVersion < dataVersion118 {
+		return c.Level.Sections
 	}
+	return c.Sections
+}
+
+// Section return the section containing the blocks of the Y coordinate,
+// which may be negative in the worlds since 1.18.
+// ok is false if y is out of the world, or the section isn't saved.
+func (c *Column) Section(y int) (s *Chunk, ok bool) {
+	d := c.WorldDimension()
+	if y < d.MinY || y > d.MaxY() {
+		return nil, false
+	}
+	sy := floorDiv(y, 16)
+	sections := c.sections()
+	for i := range sections {
+		if sections[i].SectionY() == sy {
+			return &sections[i], true
+		}
+	}
+	return nil, false
+}
 
+// GetBlock return the block at the column local x, z (0~15) and the Y
+// coordinate of the world. A zero Block is returned if it's not saved.
+func (c *Column) GetBlock(x, y, z int) Block {
+	s, ok := c.Section(y)
+	if !ok {
+		return Block{}
+	}
+	return s.GetBlock(x, y-floorDiv(y, 16)*16, z)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
+
+// Heightmap unpack the heightmap of the name, such as "WORLD_SURFACE" and
+// "MOTION_BLOCKING". Each value is the Y coordinate above the highest
+// matched block of the column, indexed by z*16+x.
+//
+// Each value takes ceil(log2(height+1)) bits, where height is the world
+// height of the Dimension of the column.
+func (c *Column) Heightmap(name string) (heights [256]int, err error) {
+	heightmaps := c.Heightmaps
+	if c.DataVersion < dataVersion118 {
+		heightmaps = c.Level.Heightmaps
+	}
+	data, ok := heightmaps[name]
+	if !ok {
+		return heights, fmt.Errorf("heightmap %q not found", name)
+	}
+	d := c.WorldDimension()
+	b := bits.Len(uint(d.Height))
+	for i := range heights {
+		v := paletteIndex(data, b, 16*16, i)
+		if v < 0 {
+			return heights, fmt.Errorf("heightmap %q too short: %d longs for %d bits", name, len(data), b)
+		}
+		heights[i] = d.MinY + v
+	}
+	return
+}
+
+// Load read column data from []byte
+func (c *Column) Load(data []byte) (err error) {
+	r, err := Decompress(data)
 	if err != nil {
 		return err
 	}
+	defer r.Close()
 
 	err = nbt.NewDecoder(r).Decode(c)
 	return
 }
+
+// SectionY return the Y index of the section, which is y>>4 of its blocks.
+// The index is a signed byte in the save, negative below Y 0.
+func (c *Chunk) SectionY() int {
+	return int(int8(c.Y))
+}
+
+// GetBlock return the block at section local position x, y, z (0~15).
+// Both the sections before 1.18 and since 1.18 are supported.
+// A zero Block is returned if the section has no block data.
+func (c *Chunk) GetBlock(x, y, z int) Block {
+	palette, data := c.Palette, c.BlockStates
+	if len(palette) == 0 {
+		palette, data = c.BlockStatesData.Palette, c.BlockStatesData.Data
+	}
+	// Unlike the network protocol, the palette is always stored in save,
+	// even if it is too large for an indirect palette.
+	b := paletteBits(len(palette), 4)
+	i := paletteIndex(data, b, 16*16*16, (y*16+z)*16+x)
+	if i < 0 || i >= len(palette) {
+		return Block{}
+	}
+	return palette[i]
+}
+
+// GetBiome return the biome resource location at section local position
+// x, y, z (0~15). Biomes are stored in a resolution of 4*4*4 blocks.
+// Only the sections since 1.18 have biomes, for the others "" is returned.
+func (c *Chunk) GetBiome(x, y, z int) string {
+	palette := c.BiomesData.Palette
+	b := paletteBits(len(palette), 0)
+	i := paletteIndex(c.BiomesData.Data, b, 4*4*4, (y/4*4+z/4)*4+x/4)
+	if i < 0 || i >= len(palette) {
+		return ""
+	}
+	return palette[i]
+}
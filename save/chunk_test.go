@@ -1,6 +1,7 @@
 package save
 
 import (
+	"fmt"
 	"github.com/Tnze/go-mc/save/region"
 	"math/rand"
 	"testing"
@@ -51,3 +52,288 @@ func BenchmarkColumn_Load(b *testing.B) {
 		}
 	}
 }
+
+// packEntries pack the palette indexes into longs
+// in the layout since 1.16, or the continuous layout before it if span is set.
+func packEntries(entries []int, bits int, span bool) []int64 {
+	perLong := 64 / bits
+	var data []int64
+	if span {
+		data = make([]int64, (len(entries)*bits+63)/64)
+	} else {
+		data = make([]int64, (len(entries)+perLong-1)/perLong)
+	}
+	for i, v := range entries {
+		var index, offset int
+		if span {
+			index, offset = i*bits/64, i*bits%64
+		} else {
+			index, offset = i/perLong, i%perLong*bits
+		}
+		data[index] |= int64(uint64(v) << uint(offset))
+		if offset+bits > 64 {
+			data[index+1] |= int64(uint64(v) >> uint(64-offset))
+		}
+	}
+	return data
+}
+
+func TestChunk_GetBlock(t *testing.T) {
+	for _, paletteLen := range []int{2, 16, 17, 33, 200, 300, 4096} {
+		palette := make([]Block, paletteLen)
+		for i := range palette {
+			palette[i] = Block{Name: fmt.Sprintf("minecraft:block_%d", i)}
+		}
+		entries := make([]int, 16*16*16)
+		for i := range entries {
+			entries[i] = (i*7 + i/16) % paletteLen
+		}
+		bits := paletteBits(paletteLen, 4)
+
+		for _, span := range []bool{false, true} {
+			data := packEntries(entries, bits, span)
+			sections := map[string]Chunk{
+				"1.16": {Palette: palette, BlockStates: data},
+				"1.18": {BlockStatesData: BlockStates{Palette: palette, Data: data}},
+			}
+			for version, c := range sections {
+				for i, want := range entries {
+					x, y, z := i%16, i/256, i/16%16
+					if got := c.GetBlock(x, y, z); got.Name != palette[want].Name {
+						t.Fatalf("%s palette %d (bits %d, span %v): GetBlock(%d, %d, %d) = %q, want %q",
+							version, paletteLen, bits, span, x, y, z, got.Name, palette[want].Name)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestChunk_GetBlock_singleValue(t *testing.T) {
+	c := Chunk{BlockStatesData: BlockStates{Palette: []Block{{Name: "minecraft:air"}}}}
+	if got := c.GetBlock(3, 4, 5); got.Name != "minecraft:air" {
+		t.Errorf("GetBlock of single-value palette = %q, want minecraft:air", got.Name)
+	}
+
+	var empty Chunk
+	if got := empty.GetBlock(0, 0, 0); got.Name != "" {
+		t.Errorf("GetBlock of empty section = %q, want zero Block", got.Name)
+	}
+}
+
+func TestChunk_GetBlock_save(t *testing.T) {
+	var c Column
+	r, err := region.Open("testdata/region/r.0.0.mca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	data, err := r.ReadSector(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Load(data); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range c.Level.Sections {
+		if s.Y != 0 {
+			continue
+		}
+		for x := 0; x < 16; x++ {
+			for z := 0; z < 16; z++ {
+				if b := s.GetBlock(x, 0, z); b.Name != "minecraft:bedrock" {
+					t.Errorf("block at (%d, 0, %d) is %q, want minecraft:bedrock", x, z, b.Name)
+				}
+			}
+		}
+		return
+	}
+	t.Error("section 0 not found")
+}
+
+func TestChunk_GetBiome(t *testing.T) {
+	// The lower half of the section is plains, and the upper half is forest
+	// except a river cell at the top.
+	palette := []string{"minecraft:plains", "minecraft:forest", "minecraft:river"}
+	entries := make([]int, 4*4*4)
+	for i := range entries {
+		if i >= 32 {
+			entries[i] = 1
+		}
+	}
+	entries[63] = 2
+	c := Chunk{BiomesData: Biomes{Palette: palette, Data: packEntries(entries, 2, false)}}
+
+	for _, tt := range []struct {
+		x, y, z int
+		want    string
+	}{
+		{0, 0, 0, "minecraft:plains"},
+		{15, 7, 15, "minecraft:plains"},
+		{0, 8, 0, "minecraft:forest"},
+		{11, 15, 15, "minecraft:forest"},
+		{12, 12, 12, "minecraft:river"},
+		{15, 15, 15, "minecraft:river"},
+	} {
+		if got := c.GetBiome(tt.x, tt.y, tt.z); got != tt.want {
+			t.Errorf("GetBiome(%d, %d, %d) = %q, want %q", tt.x, tt.y, tt.z, got, tt.want)
+		}
+	}
+}
+
+func TestChunk_GetBiome_single(t *testing.T) {
+	c := Chunk{BiomesData: Biomes{Palette: []string{"minecraft:plains"}}}
+	if got := c.GetBiome(7, 8, 9); got != "minecraft:plains" {
+		t.Errorf("GetBiome of single-biome section = %q, want minecraft:plains", got)
+	}
+
+	var old Chunk
+	if got := old.GetBiome(0, 0, 0); got != "" {
+		t.Errorf("GetBiome of section without biomes = %q, want empty", got)
+	}
+}
+
+func TestColumn_Heightmap(t *testing.T) {
+	var c Column
+	r, err := region.Open("testdata/region/r.0.0.mca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	data, err := r.ReadSector(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Load(data); err != nil {
+		t.Fatal(err)
+	}
+
+	heights, err := c.Heightmap("WORLD_SURFACE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Compare with the highest non-air block of each column
+	sections := make(map[byte]*Chunk)
+	for i := range c.Level.Sections {
+		sections[c.Level.Sections[i].Y] = &c.Level.Sections[i]
+	}
+	for z := 0; z < 16; z++ {
+		for x := 0; x < 16; x++ {
+			want := 0
+			for y := 255; y >= 0; y-- {
+				s, ok := sections[byte(y/16)]
+				if !ok {
+					continue
+				}
+				if name := s.GetBlock(x, y%16, z).Name; name != "minecraft:air" && name != "minecraft:cave_air" {
+					want = y + 1
+					break
+				}
+			}
+			if got := heights[z*16+x]; got != want {
+				t.Errorf("height of column (%d, %d) = %d, want %d", x, z, got, want)
+			}
+		}
+	}
+
+	if _, err := c.Heightmap("NOT_EXIST"); err == nil {
+		t.Error("get a not exist heightmap should be error")
+	}
+}
+
+func TestColumn_Heightmap_tall(t *testing.T) {
+	entries := make([]int, 16*16)
+	for i := range entries {
+		entries[i] = i * 3 % 385 // 0 ~ 384, need 9 bits
+	}
+	var c Column
+	c.DataVersion = 2860
+	c.PosY = -4
+	c.Heightmaps = map[string][]int64{"MOTION_BLOCKING": packEntries(entries, 9, false)}
+
+	heights, err := c.Heightmap("MOTION_BLOCKING")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range entries {
+		if heights[i] != v-64 {
+			t.Fatalf("heights[%d] = %d, want %d", i, heights[i], v-64)
+		}
+	}
+
+	c.Heightmaps["MOTION_BLOCKING"] = c.Heightmaps["MOTION_BLOCKING"][:10]
+	if _, err := c.Heightmap("MOTION_BLOCKING"); err == nil {
+		t.Error("unpack a truncated heightmap should be error")
+	}
+}
+
+func TestColumn_Section_belowZero(t *testing.T) {
+	// An overworld column of 1.18, where each section is filled with a block
+	// named by its Y index, and the sections -3 and 5 are not saved.
+	var c Column
+	c.DataVersion = 2860
+	c.PosY = -4
+	for sy := -4; sy < 20; sy++ {
+		if sy == -3 || sy == 5 {
+			continue
+		}
+		c.Sections = append(c.Sections, Chunk{
+			Y:               byte(int8(sy)),
+			BlockStatesData: BlockStates{Palette: []Block{{Name: fmt.Sprintf("section_%d", sy)}}},
+		})
+	}
+	if d := c.WorldDimension(); d != Overworld {
+		t.Errorf("guessed dimension %+v, want %+v", d, Overworld)
+	}
+
+	for _, tt := range []struct {
+		y    int
+		want string
+	}{
+		{-64, "section_-4"},
+		{-49, "section_-4"},
+		{-48, ""},
+		{-1, "section_-1"},
+		{0, "section_0"},
+		{80, ""},
+		{319, "section_19"},
+		{320, ""},
+		{-65, ""},
+	} {
+		if got := c.GetBlock(1, tt.y, 2).Name; got != tt.want {
+			t.Errorf("GetBlock(1, %d, 2) = %q, want %q", tt.y, got, tt.want)
+		}
+	}
+
+	// a custom dimension limits the range
+	c.Dimension = Dimension{MinY: -32, Height: 64}
+	if _, ok := c.Section(-64); ok {
+		t.Error("section below the custom dimension should not be ok")
+	}
+	if s, ok := c.Section(-32); !ok || s.SectionY() != -2 {
+		t.Errorf("Section(-32) = %v, %v", s, ok)
+	}
+}
+
+func TestColumn_Heightmap_dimension(t *testing.T) {
+	entries := make([]int, 16*16)
+	for i := range entries {
+		entries[i] = i * 2 // 0 ~ 510, need 10 bits for the height 512
+	}
+	c := Column{DataVersion: 2860, PosY: -8}
+	c.Dimension = Dimension{MinY: -128, Height: 512}
+	c.Heightmaps = map[string][]int64{"WORLD_SURFACE": packEntries(entries, 10, false)}
+
+	heights, err := c.Heightmap("WORLD_SURFACE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range entries {
+		if heights[i] != v-128 {
+			t.Fatalf("heights[%d] = %d, want %d", i, heights[i], v-128)
+		}
+	}
+}
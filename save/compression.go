@@ -0,0 +1,103 @@
+package save
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// Compression types of chunk data in region files,
+// which is the first byte of the data returned by region.ReadChunk.
+const (
+	GZip         byte = 1
+	Zlib         byte = 2
+	Uncompressed byte = 3 // since 1.15.1
+	LZ4          byte = 4 // since 1.20.5, not supported until registered
+)
+
+// Compression is a compression scheme of chunk data.
+type Compression struct {
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+}
+
+var (
+	compressionsMu sync.RWMutex
+	compressions   = map[byte]Compression{
+		GZip: {
+			NewReader: func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) },
+			NewWriter: func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil },
+		},
+		Zlib: {
+			NewReader: zlib.NewReader,
+			NewWriter: func(w io.Writer) (io.WriteCloser, error) { return zlib.NewWriter(w), nil },
+		},
+		Uncompressed: {
+			NewReader: func(r io.Reader) (io.ReadCloser, error) { return ioutil.NopCloser(r), nil },
+			NewWriter: func(w io.Writer) (io.WriteCloser, error) { return nopWriteCloser{w}, nil },
+		},
+	}
+)
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// RegisterCompression add or replace the compression scheme of the type.
+// It's used to support the schemes which need third-party libraries, like LZ4.
+func RegisterCompression(typ byte, c Compression) {
+	compressionsMu.Lock()
+	defer compressionsMu.Unlock()
+	compressions[typ] = c
+}
+
+func getCompression(typ byte) (Compression, error) {
+	compressionsMu.RLock()
+	defer compressionsMu.RUnlock()
+	c, ok := compressions[typ]
+	if !ok {
+		return c, fmt.Errorf("unknown compression type %d", typ)
+	}
+	return c, nil
+}
+
+// Decompress return a reader of the NBT in chunk data,
+// whose first byte is the compression type.
+func Decompress(data []byte) (io.ReadCloser, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty chunk data")
+	}
+	c, err := getCompression(data[0])
+	if err != nil {
+		return nil, err
+	}
+	return c.NewReader(bytes.NewReader(data[1:]))
+}
+
+// Compress the NBT data into chunk data with the compression type,
+// which can be written to region files by region.WriteChunk.
+func Compress(typ byte, nbtData []byte) ([]byte, error) {
+	c, err := getCompression(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(typ)
+	w, err := c.NewWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(nbtData); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
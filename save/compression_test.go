@@ -0,0 +1,90 @@
+package save
+
+import (
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/Tnze/go-mc/save/region"
+)
+
+// xorReader and xorWriter are a fake compression for testing RegisterCompression
+type xorReader struct{ r io.Reader }
+
+func (x xorReader) Read(p []byte) (int, error) {
+	n, err := x.r.Read(p)
+	for i := range p[:n] {
+		p[i] ^= 0xFF
+	}
+	return n, err
+}
+
+type xorWriter struct{ w io.Writer }
+
+func (x xorWriter) Write(p []byte) (int, error) {
+	buf := make([]byte, len(p))
+	for i := range p {
+		buf[i] = p[i] ^ 0xFF
+	}
+	return x.w.Write(buf)
+}
+
+func (x xorWriter) Close() error { return nil }
+
+func TestCompress(t *testing.T) {
+	r, err := region.Open("testdata/region/r.0.0.mca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := r.ReadChunk(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want Column
+	if err := want.Load(data); err != nil {
+		t.Fatal(err)
+	}
+	nr, err := Decompress(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nbtData, err := ioutil.ReadAll(nr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const xor = 127
+	RegisterCompression(xor, Compression{
+		NewReader: func(r io.Reader) (io.ReadCloser, error) { return ioutil.NopCloser(xorReader{r}), nil },
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) { return xorWriter{w}, nil },
+	})
+
+	for _, typ := range []byte{GZip, Zlib, Uncompressed, xor} {
+		data, err := Compress(typ, nbtData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data[0] != typ {
+			t.Errorf("compression type should be %d, get %d", typ, data[0])
+		}
+
+		var c Column
+		if err := c.Load(data); err != nil {
+			t.Fatalf("load chunk compressed by %d: %v", typ, err)
+		}
+		if !reflect.DeepEqual(c, want) {
+			t.Errorf("chunk compressed by %d changed", typ)
+		}
+	}
+
+	if _, err := Compress(LZ4, nbtData); err == nil {
+		t.Error("LZ4 isn't registered and should be error")
+	}
+	var c Column
+	if err := c.Load([]byte{LZ4, 0}); err == nil {
+		t.Error("LZ4 isn't registered and should be error")
+	}
+}
@@ -1,70 +1,264 @@
 package save
 
 import (
+	"bytes"
+	"compress/gzip"
 	"github.com/Tnze/go-mc/nbt"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 )
 
+// Level is the root compound of level.dat
 type Level struct {
-	Data struct {
-		DataVersion int32
-		NBTVersion  int32 `nbt:"version"`
-		Version     struct {
-			ID       int32 `nbt:"Id"`
-			Name     string
-			Snapshot byte
-		}
-		GameType         int32
-		Difficulty       byte
-		DifficultyLocked byte
-		HardCore         byte `nbt:"hardcore"`
-		Initialized      byte `nbt:"initialized"`
-		AllowCommands    byte `nbt:"allowCommands"`
-
-		MapFeatures      byte
-		LevelName        string
-		GeneratorName    string `nbt:"generatorName"`
-		GeneratorVersion int32  `nbt:"generatorVersion"`
-		RandomSeed       int64
-
-		SpawnX, SpawnY, SpawnZ int32
-
-		BorderCenterX, BorderCenterZ float64
-		BorderDamagePerBlock         float64
-		BorderSafeZone               float64
-		BorderSize                   float64
-		BorderSizeLerpTarget         float64
-		BorderSizeLerpTime           int64
-		BorderWarningBlocks          float64
-		BorderWarningTime            float64
-
-		GameRules map[string]string
-		DataPacks struct {
-			Enabled, Disabled []string
-		}
-		DimensionData struct {
-			TheEnd struct {
-				DragonFight struct {
-					Gateways         []int32
-					DragonKilled     byte
-					PreviouslyKilled byte
-				}
-			} `nbt:"1"`
-		}
+	Data LevelData
+}
+
+// LevelData is the world metadata stored in level.dat.
+// Unknown fields are ignored when decoding,
+// but kept for WriteLevel if it's read by ReadLevel or ReadLevelFile.
+type LevelData struct {
+	DataVersion int32
+	NBTVersion  int32 `nbt:"version"`
+	Version     struct {
+		ID       int32 `nbt:"Id"`
+		Name     string
+		Snapshot byte
+	}
+	GameType         int32
+	Difficulty       byte
+	DifficultyLocked byte
+	HardCore         byte `nbt:"hardcore"`
+	Initialized      byte `nbt:"initialized"`
+	AllowCommands    byte `nbt:"allowCommands"`
+
+	MapFeatures      byte
+	LevelName        string
+	GeneratorName    string `nbt:"generatorName"`
+	GeneratorVersion int32  `nbt:"generatorVersion"`
+	RandomSeed       int64
+
+	SpawnX, SpawnY, SpawnZ int32
+
+	BorderCenterX, BorderCenterZ float64
+	BorderDamagePerBlock         float64
+	BorderSafeZone               float64
+	BorderSize                   float64
+	BorderSizeLerpTarget         float64
+	BorderSizeLerpTime           int64
+	BorderWarningBlocks          float64
+	BorderWarningTime            float64
+
+	GameRules map[string]string
+	DataPacks struct {
+		Enabled, Disabled []string
+	}
+	DimensionData struct {
+		TheEnd struct {
+			DragonFight struct {
+				Gateways         []int32
+				DragonKilled     byte
+				PreviouslyKilled byte
+			}
+		} `nbt:"1"`
+	}
+
+	Raining          byte  `nbt:"raining"`
+	Thundering       byte  `nbt:"thundering"`
+	RainTime         int32 `nbt:"rainTime"`
+	ThunderTime      int32 `nbt:"thunderTime"`
+	ClearWeatherTime int32 `nbt:"clearWeatherTime"`
 
-		Raining          byte  `nbt:"raining"`
-		Thundering       byte  `nbt:"thundering"`
-		RainTime         int32 `nbt:"rainTime"`
-		ThunderTime      int32 `nbt:"thunderTime"`
-		ClearWeatherTime int32 `nbt:"clearWeatherTime"`
+	Time       int64
+	DayTime    int64
+	LastPlayed int64
 
-		Time       int64
-		DayTime    int64
-		LastPlayed int64
+	// WorldGenSettings is added in 1.16, replaced RandomSeed, generatorName and MapFeatures
+	WorldGenSettings struct {
+		BonusChest       byte  `nbt:"bonus_chest"`
+		GenerateFeatures byte  `nbt:"generate_features"`
+		Seed             int64 `nbt:"seed"`
+		// Dimensions is the generator settings of each dimension
+		Dimensions *nbt.Compound `nbt:"dimensions,omitempty"`
 	}
+
+	raw *nbt.Compound // the Data compound as read, see WriteLevel
 }
 
 func ReadLevel(r io.Reader) (data Level, err error) {
-	err = nbt.NewDecoder(r).Decode(&data)
+	var root nbt.Compound
+	if err = nbt.NewDecoder(r).Decode(&root); err != nil {
+		return
+	}
+	return decodeLevel(&root)
+}
+
+// decodeLevel decode the root compound of level.dat, keeping the raw tags
+func decodeLevel(root *nbt.Compound) (data Level, err error) {
+	if err = nbt.Unmarshal(root.Encode(), &data); err != nil {
+		return
+	}
+	if raw, ok := root.Get("Data"); ok {
+		data.Data.raw, _ = raw.(*nbt.Compound)
+	}
 	return
 }
+
+// ReadLevelFile open the level.dat file, which is gzip compressed, and read the world metadata.
+func ReadLevelFile(path string) (LevelData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return LevelData{}, err
+	}
+	defer f.Close()
+
+	var root nbt.Compound
+	if err := nbt.ReadCompressed(f, &root); err != nil {
+		return LevelData{}, err
+	}
+	level, err := decodeLevel(&root)
+	return level.Data, err
+}
+
+// WriteLevel gzip the level data and write it to path, such as "level.dat".
+//
+// If data is read by ReadLevel or ReadLevelFile, the tags unknown to
+// LevelData are written back as they were read, as well as their order,
+// so the data of newer versions or mods are kept. The fields of LevelData
+// missing in the read data are only added if they are not zero. For the
+// same reason, deleting a key of GameRules doesn't remove the gamerule.
+//
+// The file is replaced atomically, by writing a temporary file beside it
+// and renaming it.
+func WriteLevel(path string, data LevelData) error {
+	var buf bytes.Buffer
+	if err := nbt.Marshal(&buf, data); err != nil {
+		return err
+	}
+	var fields nbt.Compound
+	if err := nbt.Unmarshal(buf.Bytes(), &fields); err != nil {
+		return err
+	}
+	merged := &fields
+	if data.raw != nil {
+		// decode a copy, for the raw tags shouldn't be changed
+		merged = new(nbt.Compound)
+		if err := nbt.Unmarshal(data.raw.Encode(), merged); err != nil {
+			return err
+		}
+		mergeTags(merged, &fields)
+	}
+	root := nbt.NewCompound().Put("Data", merged)
+
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // fails after renamed
+	w := gzip.NewWriter(f)
+	if _, err := w.Write(root.Encode()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// mergeTags put the tags of src into dst, merging the compounds recursively.
+// The tags not in dst are skipped if they are zero.
+func mergeTags(dst, src *nbt.Compound) {
+	for _, name := range src.Names() {
+		v, _ := src.Get(name)
+		old, ok := dst.Get(name)
+		switch {
+		case !ok:
+			if !isZeroTag(v) {
+				dst.Put(name, v)
+			}
+		case old.TagType() == nbt.TagCompound && v.TagType() == nbt.TagCompound:
+			mergeTags(old.(*nbt.Compound), v.(*nbt.Compound))
+		default:
+			dst.Put(name, sameTagType(old, v))
+		}
+	}
+}
+
+// sameTagType convert v to the type of old if it's a list of numbers
+// encoded as an array, like the Gateways of the dragon fight, or an empty
+// list whose element type is lost.
+func sameTagType(old, v nbt.Tag) nbt.Tag {
+	l, ok := old.(*nbt.List)
+	if !ok {
+		return v
+	}
+	list := nbt.NewList()
+	switch a := v.(type) {
+	case *nbt.List:
+		if a.Len() == 0 && l.Len() == 0 {
+			return l
+		}
+		return v
+	case nbt.ByteArray:
+		for _, e := range a {
+			list.Add(nbt.Byte(e))
+		}
+	case nbt.IntArray:
+		for _, e := range a {
+			list.Add(nbt.Int(e))
+		}
+	case nbt.LongArray:
+		for _, e := range a {
+			list.Add(nbt.Long(e))
+		}
+	default:
+		return v
+	}
+	if list.Len() == 0 && l.Len() == 0 {
+		return l // keep the element type of the empty list
+	}
+	return list
+}
+
+// isZeroTag report if t is a zero number, an empty string, array or list,
+// or a compound of only zero tags.
+func isZeroTag(t nbt.Tag) bool {
+	switch v := t.(type) {
+	case nbt.Byte:
+		return v == 0
+	case nbt.Short:
+		return v == 0
+	case nbt.Int:
+		return v == 0
+	case nbt.Long:
+		return v == 0
+	case nbt.Float:
+		return v == 0
+	case nbt.Double:
+		return v == 0
+	case nbt.String:
+		return v == ""
+	case nbt.ByteArray:
+		return len(v) == 0
+	case nbt.IntArray:
+		return len(v) == 0
+	case nbt.LongArray:
+		return len(v) == 0
+	case *nbt.List:
+		return v.Len() == 0
+	case *nbt.Compound:
+		for _, name := range v.Names() {
+			if e, _ := v.Get(name); !isZeroTag(e) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
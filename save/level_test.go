@@ -1,9 +1,14 @@
 package save
 
 import (
+	"bytes"
 	"compress/gzip"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/Tnze/go-mc/nbt"
 )
 
 func TestLevel(t *testing.T) {
@@ -33,3 +38,193 @@ func TestLevel(t *testing.T) {
 	//	t.Errorf("player data parse error: get %v, want %v", data, want)
 	//}
 }
+
+func TestReadLevelFile(t *testing.T) {
+	data, err := ReadLevelFile("testdata/level.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.LevelName != "go-mc-test" || data.DataVersion != 1976 ||
+		data.SpawnX != -48 || data.SpawnY != 68 || data.SpawnZ != -80 ||
+		data.GameType != 1 || data.Difficulty != 2 || data.Time != 64 || data.DayTime != 64 {
+		t.Errorf("level data parse error: %+v", data)
+	}
+	if len(data.GameRules) == 0 {
+		t.Error("game rules not parsed")
+	}
+
+	if _, err := ReadLevelFile("testdata/not-exist.dat"); err == nil {
+		t.Error("read not exist file should be error")
+	}
+}
+
+func TestReadLevelFile_worldGenSettings(t *testing.T) {
+	// a 1.16 level.dat with an unknown field
+	level := map[string]interface{}{
+		"Data": map[string]interface{}{
+			"LevelName":   "1.16",
+			"DataVersion": int32(2566),
+			"WorldGenSettings": map[string]interface{}{
+				"bonus_chest": byte(1),
+				"seed":        int64(42),
+				"dimensions": map[string]interface{}{
+					"minecraft:overworld": map[string]interface{}{"type": "minecraft:overworld"},
+				},
+			},
+			"UnknownFieldFromFuture": int32(1),
+		},
+	}
+
+	f, err := ioutil.TempFile("", "level.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	w := gzip.NewWriter(f)
+	if err := nbt.Marshal(w, level); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ReadLevelFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := data.WorldGenSettings
+	if data.LevelName != "1.16" || data.DataVersion != 2566 || gen.BonusChest != 1 || gen.Seed != 42 {
+		t.Errorf("level data parse error: %+v", data)
+	}
+	if _, ok := gen.Dimensions.Get("minecraft:overworld"); !ok {
+		t.Errorf("dimensions parse error: %v", gen.Dimensions)
+	}
+}
+
+func TestWriteLevel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-mc-level")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src, err := ioutil.ReadFile("testdata/level.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "level.dat")
+	if err := ioutil.WriteFile(path, src, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ReadLevelFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data.SpawnX = 100
+	data.GameRules["doDaylightCycle"] = "false"
+	if err := WriteLevel(path, data); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadLevelFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SpawnX != 100 || got.SpawnY != data.SpawnY || got.LevelName != data.LevelName ||
+		got.GameRules["doDaylightCycle"] != "false" || len(got.GameRules) != len(data.GameRules) {
+		t.Errorf("level data not written: %+v", got)
+	}
+
+	// the other tags are the same as the original
+	readRaw := func(name string) *nbt.Compound {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		var root nbt.Compound
+		if err := nbt.ReadCompressed(f, &root); err != nil {
+			t.Fatal(err)
+		}
+		v, _ := root.Get("Data")
+		return v.(*nbt.Compound)
+	}
+	before, after := readRaw("testdata/level.dat"), readRaw(path)
+	for _, name := range []string{"SpawnX", "GameRules"} {
+		before.Remove(name)
+		after.Remove(name)
+	}
+	if !bytes.Equal(before.Encode(), after.Encode()) {
+		t.Errorf("the tags are changed:\n%v\n%v", before.Names(), after.Names())
+	}
+}
+
+func TestWriteLevel_unknown(t *testing.T) {
+	f, err := ioutil.TempFile("", "level.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	// a new level data has no unknown tags
+	var data LevelData
+	data.LevelName = "new"
+	if err := WriteLevel(f.Name(), data); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadLevelFile(f.Name()); err != nil || got.LevelName != "new" {
+		t.Fatalf("read the new level: %+v, %v", got, err)
+	}
+
+	// a 1.16 level.dat with unknown tags
+	root := nbt.NewCompound().Put("Data", nbt.NewCompound().
+		Put("LevelName", nbt.String("1.16")).
+		Put("WorldGenSettings", nbt.NewCompound().
+			Put("seed", nbt.Long(42)).
+			Put("dimensions", nbt.NewCompound().
+				Put("minecraft:overworld", nbt.NewCompound().Put("type", nbt.String("minecraft:overworld")))).
+			Put("ModdedSetting", nbt.String("kept"))).
+		Put("UnknownFieldFromFuture", nbt.NewList(nbt.Int(1), nbt.Int(2))))
+	level, err := ReadLevel(bytes.NewReader(root.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	level.Data.WorldGenSettings.Seed = 7
+	if err := WriteLevel(f.Name(), level.Data); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := os.Open(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var written nbt.Compound
+	if err := nbt.ReadCompressed(r, &written); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		path []string
+		want nbt.Tag
+	}{
+		{[]string{"Data", "LevelName"}, nbt.String("1.16")},
+		{[]string{"Data", "WorldGenSettings", "seed"}, nbt.Long(7)},
+		{[]string{"Data", "WorldGenSettings", "ModdedSetting"}, nbt.String("kept")},
+		{[]string{"Data", "WorldGenSettings", "dimensions", "minecraft:overworld", "type"}, nbt.String("minecraft:overworld")},
+		{[]string{"Data", "UnknownFieldFromFuture", "1"}, nbt.Int(2)},
+	} {
+		if v, ok := written.Get(tt.path...); !ok || v != tt.want {
+			t.Errorf("%v = %v, want %v", tt.path, v, tt.want)
+		}
+	}
+	// the zero fields missing in the read data are not added
+	for _, path := range [][]string{{"Data", "SpawnX"}, {"Data", "WorldGenSettings", "bonus_chest"}} {
+		if v, ok := written.Get(path...); ok {
+			t.Errorf("the zero %v should not be added, get %v", path, v)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package save
+
+import "math/bits"
+
+// paletteBits returns how many bits each entry takes in the packed data
+// of a paletted container with n palette entries.
+// Vanilla never stores less than minBits per entry, except the
+// single-value palette which has no data at all.
+func paletteBits(n, minBits int) int {
+	if n <= 1 {
+		return 0
+	}
+	b := bits.Len(uint(n - 1))
+	if b < minBits {
+		b = minBits
+	}
+	return b
+}
+
+// paletteIndex returns the i-th palette index of a container which holds
+// size entries packed in data, each took b bits.
+// It returns -1 if data is too short.
+//
+// Since 1.16 (20w17a) entries are no longer spread across two longs,
+// the unused high bits of each long are left as padding.
+// The two layouts have different lengths unless b divides 64, in which
+// case they are identical, so the layout is detected by the data length.
+func paletteIndex(data []int64, b, size, i int) int {
+	if b == 0 {
+		return 0
+	}
+	var index, offset int
+	if perLong := 64 / b; len(data) == (size+perLong-1)/perLong {
+		index, offset = i/perLong, i%perLong*b
+	} else {
+		index, offset = i*b/64, i*b%64
+	}
+	if index >= len(data) {
+		return -1
+	}
+	v := uint64(data[index]) >> uint(offset)
+	if offset+b > 64 {
+		if index+1 >= len(data) {
+			return -1
+		}
+		v |= uint64(data[index+1]) << uint(64-offset)
+	}
+	return int(v & (1<<uint(b) - 1))
+}
@@ -5,8 +5,10 @@ import (
 	"github.com/Tnze/go-mc/nbt"
 	"github.com/google/uuid"
 	"io"
+	"os"
 )
 
+// PlayerData is the player stored in playerdata/<uuid>.dat
 type PlayerData struct {
 	DataVersion int32
 
@@ -19,7 +21,8 @@ type PlayerData struct {
 	OnGround     byte
 
 	UUID                uuid.UUID `nbt:"-"`
-	UUIDLeast, UUIDMost int64
+	UUIDLeast, UUIDMost int64     // before 1.16
+	UUIDInts            [4]int32  `nbt:"UUID"` // since 1.16
 
 	PlayerGameType  int32 `nbt:"playerGameType"`
 	Air             int16
@@ -71,6 +74,7 @@ type PlayerData struct {
 	} `nbt:"recipeBook"`
 }
 
+// Item is an item stack in the inventory or the ender chest
 type Item struct {
 	Count byte
 	Slot  byte
@@ -78,10 +82,30 @@ type Item struct {
 	Tag   map[string]interface{} `nbt:"tag"`
 }
 
+// ReadPlayerData read the player data, which can be either
+// uncompressed or gzip compressed like the .dat files.
 func ReadPlayerData(r io.Reader) (data PlayerData, err error) {
-	err = nbt.NewDecoder(r).Decode(&data)
-	//parse UUID from two int64s
-	binary.BigEndian.PutUint64(data.UUID[:], uint64(data.UUIDMost))
-	binary.BigEndian.PutUint64(data.UUID[8:], uint64(data.UUIDLeast))
+	err = nbt.ReadCompressed(r, &data)
+	if data.UUIDInts != [4]int32{} {
+		//parse UUID from four int32s
+		for i, v := range data.UUIDInts {
+			binary.BigEndian.PutUint32(data.UUID[i*4:], uint32(v))
+		}
+	} else {
+		//parse UUID from two int64s
+		binary.BigEndian.PutUint64(data.UUID[:], uint64(data.UUIDMost))
+		binary.BigEndian.PutUint64(data.UUID[8:], uint64(data.UUIDLeast))
+	}
 	return
 }
+
+// ReadPlayerDataFile open the playerdata/<uuid>.dat file and read the player data.
+func ReadPlayerDataFile(path string) (PlayerData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return PlayerData{}, err
+	}
+	defer f.Close()
+
+	return ReadPlayerData(f)
+}
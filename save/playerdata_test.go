@@ -33,3 +33,33 @@ func TestPlayerData(t *testing.T) {
 	//	t.Errorf("player data parse error: get %v, want %v", data, want)
 	//}
 }
+
+func TestReadPlayerDataFile(t *testing.T) {
+	data, err := ReadPlayerDataFile("testdata/playerdata/58f6356e-b30c-4811-8bfc-d72a9ee99e73.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.UUID.String() != "58f6356e-b30c-4811-8bfc-d72a9ee99e73" {
+		t.Errorf("wrong UUID: %v", data.UUID)
+	}
+	if data.Pos != [3]float64{-41.19101053202165, 65, -88.8431022038145} ||
+		data.Health != 20 || data.XpLevel != 0 || data.Dimension != 0 || data.PlayerGameType != 1 {
+		t.Errorf("player data parse error: %+v", data)
+	}
+	if data.Abilities.InstantBuild != 1 || data.Abilities.MayFly != 1 || data.Abilities.WalkSpeed != 0.1 {
+		t.Errorf("wrong abilities: %+v", data.Abilities)
+	}
+	if len(data.Inventory) != 8 || len(data.EnderItems) != 2 {
+		t.Fatalf("%d items in inventory and %d in ender chest, want 8 and 2", len(data.Inventory), len(data.EnderItems))
+	}
+	if it := data.Inventory[3]; it.ID != "minecraft:diamond_pickaxe" || it.Slot != 3 || it.Count != 1 {
+		t.Errorf("wrong item: %+v", it)
+	}
+	if it := data.EnderItems[1]; it.ID != "minecraft:tipped_arrow" || it.Count != 17 || it.Tag["Potion"] != "minecraft:night_vision" {
+		t.Errorf("wrong ender item: %+v", it)
+	}
+
+	if _, err := ReadPlayerDataFile("testdata/playerdata/not-exist.dat"); err == nil {
+		t.Error("read not exist file should be error")
+	}
+}
@@ -1,10 +1,14 @@
 package region
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"time"
 )
 
@@ -17,6 +21,12 @@ type Region struct {
 	// sectors record if a sector is in used.
 	// contrary to mojang's, because false is the default value in Go.
 	sectors map[int32]bool
+
+	// headChanged is set when WriteChunk changed the offsets or timestamps
+	headChanged bool
+
+	// name of the .mca file, used to find the external chunks
+	name string
 }
 
 // In calculate chunk's coordinates relative to region
@@ -32,6 +42,7 @@ func In(cx, cy int) (int, int) {
 func Open(name string) (r *Region, err error) {
 	r = new(Region)
 	r.sectors = make(map[int32]bool)
+	r.name = name
 
 	r.f, err = os.OpenFile(name, os.O_RDWR, 0666)
 	if err != nil {
@@ -72,6 +83,7 @@ func Open(name string) (r *Region, err error) {
 func Create(name string) (r *Region, err error) {
 	r = new(Region)
 	r.sectors = make(map[int32]bool)
+	r.name = name
 
 	r.f, err = os.OpenFile(name, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
 	if err != nil {
@@ -97,8 +109,12 @@ func Create(name string) (r *Region, err error) {
 	return r, nil
 }
 
-// Close close the region file
+// Close flush the header and close the region file
 func (r *Region) Close() error {
+	if err := r.Flush(); err != nil {
+		_ = r.f.Close()
+		return err
+	}
 	return r.f.Close()
 }
 
@@ -127,65 +143,145 @@ func (r *Region) ReadSector(x, y int) (data []byte, err error) {
 
 	data = make([]byte, length)
 	_, err = io.ReadFull(r.f, data)
+	if err != nil || len(data) == 0 || data[0]&externalFlag == 0 {
+		return
+	}
 
-	return
+	// the chunk is stored in the .mcc file
+	name, err := r.externalName(y, x)
+	if err != nil {
+		return nil, err
+	}
+	external, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{data[0] &^ externalFlag}, external...), nil
+}
+
+// externalFlag is set on the compression type when the chunk is larger than 1MB
+// and is stored in the c.<x>.<z>.mcc file beside the region file.
+const externalFlag = 0x80
+
+// externalName return the path of the .mcc file of chunk at (x, z) relative to the region
+func (r *Region) externalName(x, z int) (string, error) {
+	var rx, rz int
+	if _, err := fmt.Sscanf(filepath.Base(r.name), "r.%d.%d.mca", &rx, &rz); err != nil {
+		return "", fmt.Errorf("cannot get region position from file name %q: %v", r.name, err)
+	}
+	return filepath.Join(filepath.Dir(r.name), fmt.Sprintf("c.%d.%d.mcc", rx*32+x, rz*32+z)), nil
 }
 
 // WriteSector write Chunk data into region file
 func (r *Region) WriteSector(x, y int, data []byte) error {
-	need := int32(len(data)+4)/4096 + 1
-	n, now := sectorLoc(r.offsets[x][y])
+	if err := r.writeSectors(x, y, data); err != nil {
+		return err
+	}
+	r.timestamps[x][y] = int32(time.Now().Unix())
+	// update file head
+	return r.setHead(x, y, uint32(r.offsets[x][y]), uint32(r.timestamps[x][y]))
+}
+
+// ReadChunk read the data of chunk at (x, z), which are the coordinates relative to the region.
+// The first byte of data is the compression type.
+// The data of external chunks are read from the .mcc file transparently.
+func (r *Region) ReadChunk(x, z int) ([]byte, error) {
+	return r.ReadSector(z, x)
+}
+
+// WriteChunk write the data of chunk at (x, z), which are the coordinates relative to the region.
+// The first byte of data should be the compression type.
+// Chunks larger than 1MB are stored in the external c.<x>.<z>.mcc file like the vanilla does,
+// which needs the region file named as r.<x>.<z>.mca .
+//
+// If the chunk grows beyond its sectors, it is moved to a free space, and the old sectors are reclaimed.
+// The header of region file is written by Flush or Close.
+func (r *Region) WriteChunk(x, z int, data []byte) error {
+	if err := r.writeSectors(z, x, data); err != nil {
+		return err
+	}
+	r.timestamps[z][x] = int32(time.Now().Unix())
+	r.headChanged = true
+	return nil
+}
 
-	// maximum chunk size is 1MB
+// Flush write the offsets and timestamps changed by WriteChunk into the header of region file.
+func (r *Region) Flush() error {
+	if !r.headChanged {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.BigEndian, &r.offsets); err != nil {
+		return err
+	}
+	if err := binary.Write(&buf, binary.BigEndian, &r.timestamps); err != nil {
+		return err
+	}
+	if _, err := r.f.WriteAt(buf.Bytes(), 0); err != nil {
+		return err
+	}
+	r.headChanged = false
+	return nil
+}
+
+// writeSectors write data into the sectors of offsets[i][j], allocate new sectors if needed
+func (r *Region) writeSectors(i, j int, data []byte) error {
+	need := int32(len(data)+4+4095) / 4096
+	n, now := sectorLoc(r.offsets[i][j])
+
+	// maximum chunk size is 1MB, larger chunks are stored in the .mcc file
+	external, err := r.externalName(j, i)
 	if need >= 256 {
-		return errors.New("data too large")
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(external, data[1:], 0666); err != nil {
+			return err
+		}
+		data, need = []byte{data[0] | externalFlag}, 1
+	} else if now == 1 && err == nil && r.storedExternally(n) {
+		// remove the .mcc file the chunk was stored in
+		if err := os.Remove(external); err != nil && !os.IsNotExist(err) {
+			return err
+		}
 	}
 
-	if n != 0 && now == need {
-		// we can simply overwrite the old sectors
+	if n != 0 && need <= now {
+		// we can simply overwrite the old sectors, and free the rest
+		for k := need; k < now; k++ {
+			r.sectors[n+k] = false
+		}
 	} else {
 		// we need to allocate new sectors
 
 		// mark the sectors previously used for this chunk as free
-		for i := int32(0); i < now; i++ {
-			r.sectors[n+i] = false
+		for k := int32(0); k < now; k++ {
+			r.sectors[n+k] = false
 		}
 
 		// scan for a free space large enough to store this chunk
 		n = r.findSpace(need)
-
-		// mark the sectors previously used for this chunk as used
-		now = need
-		for i := int32(0); i < need; i++ {
-			r.sectors[n+i] = true
-		}
-
-		r.offsets[x][y] = (n << 8) | (need & 0xFF)
-
-		// update file head
-		err := r.setHead(x, y, uint32(r.offsets[x][y]), uint32(time.Now().Unix()))
-		if err != nil {
-			return err
-		}
-	}
-
-	_, err := r.f.Seek(4096*int64(n), 0)
-	if err != nil {
-		return err
-	}
-	//data length
-	err = binary.Write(r.f, binary.BigEndian, int32(len(data)))
-	if err != nil {
-		return err
 	}
 
-	//data
-	_, err = r.f.Write(data)
-	if err != nil {
-		return err
+	// mark the sectors used for this chunk
+	for k := int32(0); k < need; k++ {
+		r.sectors[n+k] = true
 	}
+	r.offsets[i][j] = (n << 8) | (need & 0xFF)
+
+	// data length, data and the padding to the end of sectors
+	buf := make([]byte, 4096*need)
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	copy(buf[4:], data)
+	_, err = r.f.WriteAt(buf, 4096*int64(n))
+	return err
+}
 
-	return nil
+// storedExternally report if the compression type of the chunk at sector n has externalFlag set
+func (r *Region) storedExternally(n int32) bool {
+	var compression [1]byte
+	_, err := r.f.ReadAt(compression[:], 4096*int64(n)+4)
+	return err == nil && compression[0]&externalFlag != 0
 }
 
 // ExistSector return if a sector is exist
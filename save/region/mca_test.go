@@ -4,6 +4,10 @@ import (
 	"bytes"
 	"compress/zlib"
 	"github.com/Tnze/go-mc/nbt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -95,3 +99,213 @@ func TestCountChunks(t *testing.T) {
 	}
 	t.Logf("chunk count: %d", count)
 }
+
+func TestWriteChunk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "region")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// copy the region file
+	name := filepath.Join(dir, "r.0.-1.mca")
+	src, err := ioutil.ReadFile("../testdata/region/r.0.-1.mca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(name, src, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// read all chunks
+	var chunks [32][32][]byte
+	cx, cz := -1, -1
+	for x := 0; x < 32; x++ {
+		for z := 0; z < 32; z++ {
+			if data, err := r.ReadChunk(x, z); err == nil {
+				chunks[x][z] = data
+				if cx == -1 {
+					cx, cz = x, z
+				}
+			}
+		}
+	}
+	if cx == -1 {
+		t.Fatal("no chunk in region")
+	}
+
+	// rewrite one chunk with no compression,
+	// which makes it larger and must be moved
+	zr, err := zlib.NewReader(bytes.NewReader(chunks[cx][cz][1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nbtData interface{}
+	if err := nbt.Unmarshal(raw, &nbtData); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(2) // zlib
+	zw, _ := zlib.NewWriterLevel(&buf, zlib.NoCompression)
+	_, _ = zw.Write(raw)
+	_ = zw.Close()
+	chunks[cx][cz] = buf.Bytes()
+
+	oldOffset, oldSize := sectorLoc(r.offsets[cz][cx])
+	if err := r.WriteChunk(cx, cz, chunks[cx][cz]); err != nil {
+		t.Fatal(err)
+	}
+	if newOffset, newSize := sectorLoc(r.offsets[cz][cx]); newSize <= oldSize || newOffset == oldOffset {
+		t.Errorf("chunk should be moved: offset %d->%d, size %d->%d", oldOffset, newOffset, oldSize, newSize)
+	}
+	for i := int32(0); i < oldSize; i++ {
+		if r.sectors[oldOffset+i] {
+			t.Errorf("sector %d should be reclaimed", oldOffset+i)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// check the file
+	if info, err := os.Stat(name); err != nil {
+		t.Fatal(err)
+	} else if info.Size()%4096 != 0 {
+		t.Errorf("size of region file should be a multiple of 4096, get %d", info.Size())
+	}
+	r, err = Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	for x := 0; x < 32; x++ {
+		for z := 0; z < 32; z++ {
+			data, err := r.ReadChunk(x, z)
+			if chunks[x][z] == nil {
+				if err == nil {
+					t.Errorf("chunk (%d, %d) shouldn't exist", x, z)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, chunks[x][z]) {
+				t.Errorf("chunk (%d, %d) changed", x, z)
+			}
+		}
+	}
+
+	// decode the rewritten chunk
+	var got interface{}
+	zr, err = zlib.NewReader(bytes.NewReader(chunks[cx][cz][1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nbt.NewDecoder(zr).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, nbtData) {
+		t.Error("rewritten chunk changed")
+	}
+}
+
+func TestWriteChunk_external(t *testing.T) {
+	dir, err := ioutil.TempDir("", "region")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r, err := Create(filepath.Join(dir, "r.1.-2.mca"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a synthetic chunk larger than 1MB
+	large := make([]byte, 1+1100*1024)
+	large[0] = 2 // zlib
+	for i := range large[1:] {
+		large[i+1] = byte(i * 7)
+	}
+	if err := r.WriteChunk(3, 4, large); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// chunk (35, -60) is stored in the .mcc file
+	mcc := filepath.Join(dir, "c.35.-60.mcc")
+	if external, err := ioutil.ReadFile(mcc); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(external, large[1:]) {
+		t.Error("data in .mcc file is wrong")
+	}
+
+	r, err = Open(filepath.Join(dir, "r.1.-2.mca"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, size := sectorLoc(r.offsets[4][3]); size != 1 {
+		t.Errorf("external chunk should use 1 sector, get %d", size)
+	}
+	data, err := r.ReadChunk(3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, large) {
+		t.Error("read external chunk fail")
+	}
+
+	// shrink the chunk, the .mcc file should be removed
+	small := []byte{2, 1, 2, 3}
+	if err := r.WriteChunk(3, 4, small); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(mcc); !os.IsNotExist(err) {
+		t.Errorf(".mcc file should be removed: %v", err)
+	}
+	if data, err := r.ReadChunk(3, 4); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(data, small) {
+		t.Errorf("read chunk fail: get %v, want %v", data, small)
+	}
+}
+
+func TestWriteChunk_keepExternal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "region")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r, err := Create(filepath.Join(dir, "r.0.0.mca"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := r.WriteChunk(0, 0, []byte{2, 1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the chunk isn't stored externally, a .mcc file beside it isn't its
+	mcc := filepath.Join(dir, "c.0.0.mcc")
+	if err := ioutil.WriteFile(mcc, []byte{4, 5, 6}, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.WriteChunk(0, 0, []byte{2, 7, 8, 9}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(mcc); err != nil {
+		t.Errorf(".mcc file shouldn't be removed: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package save
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/Tnze/go-mc/save/region"
+)
+
+// Walker walks all the chunks of the region files of a world.
+// The zero value is ready to use.
+type Walker struct {
+	// Concurrency is the max number of chunks decoded at the same time.
+	// If it's 0, runtime.NumCPU() is used.
+	Concurrency int
+}
+
+// ChunkError is the error of reading or decoding a chunk in a walk
+type ChunkError struct {
+	X, Z int // the chunk coordinates
+	Err  error
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("chunk (%d, %d): %v", e.X, e.Z, e.Err)
+}
+
+func (e *ChunkError) Unwrap() error { return e.Err }
+
+// WalkErrors are the errors of the chunks and the region files failed in a walk.
+// The chunks are *ChunkError, while the region files are the errors of opening them.
+type WalkErrors []error
+
+func (e WalkErrors) Error() string {
+	if len(e) == 1 {
+		return "save: " + e[0].Error()
+	}
+	return fmt.Sprintf("save: %d errors in walk, the first: %v", len(e), e[0])
+}
+
+// WalkWorld call fn for each chunk of the world in dir, the directory
+// containing level.dat, with the default Walker. See Walker.Walk.
+func WalkWorld(dir string, fn func(cx, cz int, chunk *Column) error) error {
+	return Walker{}.Walk(dir, fn)
+}
+
+// Walk read all the chunks in dir/region/*.mca, decode them in parallel
+// and call fn with each of them and its chunk coordinates.
+//
+// The empty slots in the region files are skipped. The chunks failed
+// to read or decode don't stop the walk, but are collected and returned
+// as WalkErrors at the end. If fn returns an error, the walk stops and
+// the error is returned.
+//
+// fn is called from multiple goroutines, at most Concurrency at the same time.
+func (w Walker) Walk(dir string, fn func(cx, cz int, chunk *Column) error) error {
+	names, err := filepath.Glob(filepath.Join(dir, "region", "r.*.*.mca"))
+	if err != nil {
+		return err
+	}
+	n := w.Concurrency
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+
+	type job struct {
+		cx, cz int
+		data   []byte
+	}
+	var (
+		jobs = make(chan job)
+		stop = make(chan struct{})
+
+		mu       sync.Mutex
+		errs     WalkErrors
+		fnErr    error
+		stopOnce sync.Once
+		wg       sync.WaitGroup
+	)
+	collect := func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				select {
+				case <-stop:
+					continue // drain the jobs sent before stopping
+				default:
+				}
+				var c Column
+				if err := c.Load(j.data); err != nil {
+					collect(&ChunkError{X: j.cx, Z: j.cz, Err: err})
+					continue
+				}
+				if err := fn(j.cx, j.cz, &c); err != nil {
+					stopOnce.Do(func() { fnErr = err; close(stop) })
+				}
+			}
+		}()
+	}
+
+	// Reading the region files here, for a Region can't be read concurrently
+	// and the disk is faster read sequentially.
+	func() {
+		defer close(jobs)
+		for _, name := range names {
+			var rx, rz int
+			if _, err := fmt.Sscanf(filepath.Base(name), "r.%d.%d.mca", &rx, &rz); err != nil {
+				continue // not a region file
+			}
+			r, err := region.Open(name)
+			if err != nil {
+				collect(err)
+				continue
+			}
+			for x := 0; x < 32; x++ {
+				for z := 0; z < 32; z++ {
+					if !r.ExistSector(z, x) {
+						continue
+					}
+					cx, cz := rx*32+x, rz*32+z
+					data, err := r.ReadChunk(x, z)
+					if err != nil {
+						collect(&ChunkError{X: cx, Z: cz, Err: err})
+						continue
+					}
+					select {
+					case jobs <- job{cx, cz, data}:
+					case <-stop:
+						_ = r.Close()
+						return
+					}
+				}
+			}
+			_ = r.Close()
+		}
+	}()
+	wg.Wait()
+
+	if fnErr != nil {
+		return fnErr
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
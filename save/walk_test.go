@@ -0,0 +1,119 @@
+package save
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/Tnze/go-mc/save/region"
+)
+
+func TestWalkWorld(t *testing.T) {
+	// count the chunks of the test world
+	want := 0
+	names, _ := filepath.Glob("testdata/region/*.mca")
+	for _, name := range names {
+		r, err := region.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for x := 0; x < 32; x++ {
+			for z := 0; z < 32; z++ {
+				if r.ExistSector(x, z) {
+					want++
+				}
+			}
+		}
+		r.Close()
+	}
+
+	for _, concurrency := range []int{0, 1, 4} {
+		var (
+			mu   sync.Mutex
+			seen = make(map[[2]int]bool)
+		)
+		err := Walker{Concurrency: concurrency}.Walk("testdata", func(cx, cz int, c *Column) error {
+			if int(c.Level.PosX) != cx {
+				t.Errorf("chunk (%d, %d) has xPos %d", cx, cz, c.Level.PosX)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if seen[[2]int{cx, cz}] {
+				t.Errorf("chunk (%d, %d) is walked twice", cx, cz)
+			}
+			seen[[2]int{cx, cz}] = true
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(seen) != want {
+			t.Errorf("concurrency %d: walked %d chunks, want %d", concurrency, len(seen), want)
+		}
+	}
+
+	// stop by fn
+	stop := errors.New("stop")
+	calls := 0
+	err := Walker{Concurrency: 1}.Walk("testdata", func(cx, cz int, c *Column) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("Walk return %v after %d calls, want stop after 1", err, calls)
+	}
+}
+
+func TestWalkWorld_errors(t *testing.T) {
+	src, err := region.Open("testdata/region/r.0.0.mca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := src.ReadChunk(0, 0)
+	src.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "go-mc-walk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Mkdir(filepath.Join(dir, "region"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	r, err := region.Create(filepath.Join(dir, "region", "r.1.-2.mca"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.WriteChunk(0, 0, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.WriteChunk(3, 4, []byte{99, 1, 2, 3}); err != nil { // unknown compression
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var walked [][2]int
+	err = WalkWorld(dir, func(cx, cz int, c *Column) error {
+		walked = append(walked, [2]int{cx, cz})
+		return nil
+	})
+	if len(walked) != 1 || walked[0] != [2]int{32, -64} {
+		t.Errorf("walked %v, want the chunk (32, -64)", walked)
+	}
+	var errs WalkErrors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("want 1 error, get %v", err)
+	}
+	var ce *ChunkError
+	if !errors.As(errs[0], &ce) || ce.X != 35 || ce.Z != -60 {
+		t.Errorf("want the error of chunk (35, -60), get %v", errs[0])
+	}
+}
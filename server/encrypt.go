@@ -0,0 +1,50 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/Tnze/go-mc/auth"
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// Encrypt send the Encryption Request with the public key of keys, then
+// decrypt the shared secret of the Encryption Response and enable the
+// encryption of c. It should be called after reading the Login Start.
+//
+// The returned serverHash is what the client joined the session server with,
+// so an online-mode server authenticates the player by auth.HasJoined.
+// The serverID is empty since 1.7.
+func Encrypt(c *Conn, keys *auth.KeyPair, serverID string) (serverHash string, err error) {
+	if c.State() != data.Login {
+		return "", fmt.Errorf("server: encrypt in the %v state", c.State())
+	}
+	verifyToken, err := auth.NewVerifyToken()
+	if err != nil {
+		return "", err
+	}
+	err = c.WritePacket(pk.Marshal(0x01, pk.String(serverID), pk.ByteArray(keys.Public), pk.ByteArray(verifyToken)))
+	if err != nil {
+		return "", fmt.Errorf("server: send encryption request fail: %w", err)
+	}
+
+	p, err := c.ReadPacket()
+	if err != nil {
+		return "", fmt.Errorf("server: read encryption response fail: %w", err)
+	}
+	if p.ID != 0x01 {
+		return "", fmt.Errorf("server: packet 0x%02X isn't an encryption response", p.ID)
+	}
+	var cryptSecret, cryptToken pk.ByteArray
+	if err := p.Scan(&cryptSecret, &cryptToken); err != nil {
+		return "", fmt.Errorf("server: scan encryption response fail: %w", err)
+	}
+	sharedSecret, err := keys.DecryptResponse(cryptSecret, cryptToken, verifyToken)
+	if err != nil {
+		return "", err
+	}
+	if err := c.SetEncryption(sharedSecret); err != nil {
+		return "", err
+	}
+	return auth.ServerHash(serverID, sharedSecret, keys.Public), nil
+}
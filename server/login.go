@@ -0,0 +1,31 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/Tnze/go-mc/auth"
+	"github.com/Tnze/go-mc/data"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// LoginSuccess finish the login of c as the player of profile, by enabling
+// the compression with the threshold and sending the Login Success.
+// A negative threshold doesn't enable the compression.
+// After it returns without error, c is in the Play state.
+//
+// The encryption, if any, should be enabled before it.
+func LoginSuccess(c *Conn, profile auth.Profile, threshold int) error {
+	if c.State() != data.Login {
+		return fmt.Errorf("server: login in the %v state", c.State())
+	}
+	if threshold >= 0 {
+		if err := c.EnableCompression(threshold); err != nil {
+			return err
+		}
+	}
+	err := c.WritePacket(pk.Marshal(0x02, pk.UUID(profile.ID), pk.String(profile.Name)))
+	if err != nil {
+		return fmt.Errorf("server: send login success fail: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Tnze/go-mc/auth"
+	"github.com/Tnze/go-mc/data"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+	"github.com/Tnze/go-mc/offline"
+)
+
+func TestLoginSuccess(t *testing.T) {
+	l, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	type result struct {
+		threshold int
+		profile   auth.Profile
+		err       error
+	}
+	results := make(chan result, 1)
+	big := pk.Packet{ID: 0x22, Data: bytes.Repeat([]byte{'x'}, 1000)}
+	go func() {
+		conn, err := mcnet.DialMC(l.Addr().String())
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+		defer conn.Close()
+		threshold, profile, err := mcnet.Login(conn, auth.Profile{Name: "Steve"}, mcnet.LoginOptions{Host: "localhost", Port: 25565})
+		if err == nil {
+			err = conn.WritePacket(big)
+		}
+		results <- result{threshold, profile, err}
+		_, _ = conn.ReadPacket() // wait for closing
+	}()
+
+	c, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	p, err := c.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var name pk.String
+	if err := p.Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	profile := auth.Profile{ID: offline.NameUUID(string(name)), Name: string(name)}
+	if err := LoginSuccess(c, profile, 256); err != nil {
+		t.Fatal(err)
+	}
+	if c.State() != data.Play {
+		t.Errorf("the state after login is %v", c.State())
+	}
+
+	r := <-results
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if r.threshold != 256 || r.profile.ID != profile.ID || r.profile.Name != "Steve" {
+		t.Errorf("wrong login: threshold %d, profile %+v", r.threshold, r.profile)
+	}
+	// the packet is compressed by the client
+	if p, err := c.ReadPacket(); err != nil || !bytes.Equal(p.Data, big.Data) {
+		t.Errorf("read the compressed packet: %v", err)
+	}
+	if err := LoginSuccess(c, profile, -1); err == nil {
+		t.Error("login succeeded twice")
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	keys, err := auth.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	notch := auth.Profile{ID: offline.NameUUID("Notch"), Name: "Notch"}
+
+	// a session server remembers the hash the player joined with
+	var (
+		mu     sync.Mutex
+		joined string
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, "/join") {
+			var req struct {
+				ServerID string `json:"serverId"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			joined = req.ServerID
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if q := r.URL.Query(); q.Get("username") != notch.Name || q.Get("serverId") != joined {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(notch)
+	}))
+	defer s.Close()
+	defer func(u string) { auth.SessionURL = u }(auth.SessionURL)
+	auth.SessionURL = s.URL
+
+	l, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	errs := make(chan error, 1)
+	go func() {
+		conn, err := mcnet.DialMC(l.Addr().String())
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer conn.Close()
+		_, _, err = mcnet.Login(conn, notch, mcnet.LoginOptions{Host: "localhost", Port: 25565, AccessToken: "token"})
+		if err == nil {
+			err = conn.WritePacket(pk.Marshal(0x03, pk.String("hello")))
+		}
+		errs <- err
+		_, _ = conn.ReadPacket() // wait for closing
+	}()
+
+	c, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	p, err := c.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var name pk.String
+	if err := p.Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := Encrypt(c, keys, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile, err := auth.HasJoined(string(name), hash, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LoginSuccess(c, profile, -1); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	// the packet is encrypted by the client
+	var msg pk.String
+	if p, err := c.ReadPacket(); err != nil {
+		t.Fatal(err)
+	} else if err := p.Scan(&msg); err != nil || msg != "hello" {
+		t.Errorf("read the encrypted packet: %q, %v", msg, err)
+	}
+}
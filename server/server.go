@@ -0,0 +1,170 @@
+// Package server helps building Minecraft servers.
+//
+// A Listener accepts the connections and reads their handshakes, so a
+// server only handles the connections in the Status or Login state:
+//
+//	l, err := server.Listen(":25565")
+//	if err != nil {
+//		return err
+//	}
+//	for {
+//		c, err := l.Accept()
+//		if err != nil {
+//			return err
+//		}
+//		go func() {
+//			defer c.Close()
+//			if c.NextState == data.Status {
+//				_ = server.HandleStatus(c, status)
+//			}
+//		}()
+//	}
+package server
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Tnze/go-mc/data"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// DefaultHandshakeTimeout is used when the HandshakeTimeout of Listener is zero
+const DefaultHandshakeTimeout = 5 * time.Second
+
+// Listener is a Minecraft listener.
+// The connections returned by Accept are past the handshake.
+type Listener struct {
+	net.Listener
+
+	// HandshakeTimeout limits the time of reading the handshake.
+	// Zero means DefaultHandshakeTimeout.
+	HandshakeTimeout time.Duration
+	// Logger receives the logs of the connections, and the dropped ones.
+	// Nil means no logs.
+	Logger mcnet.Logger
+
+	once  sync.Once
+	conns chan *Conn    // the connections past the handshake
+	done  chan struct{} // closed when accepting fails
+	err   error         // the error of accepting, set before done is closed
+}
+
+// Listen listen as TCP at the addr, and accept the Minecraft connections
+func Listen(addr string) (*Listener, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Listener{Listener: l}, nil
+}
+
+// Conn is a connection accepted by Listener, with its handshake
+type Conn struct {
+	*mcnet.Conn
+
+	Protocol      int    // the protocol version of the client
+	ServerAddress string // the address the client connects, with the FML marker if any, see mcnet.ParseFMLAddress
+	ServerPort    int
+	NextState     data.State // data.Status or data.Login, the same as the State of Conn
+}
+
+// Accept wait for the next connection, and read its handshake.
+//
+// The handshakes are read in their own goroutines, so a client which is slow
+// to send its handshake doesn't delay the others.
+// The connections failed to handshake, like the legacy pings and the port
+// scanners, are closed and dropped, then Accept waits for the next one.
+// The error is only returned for failing to accept.
+func (l *Listener) Accept() (*Conn, error) {
+	l.once.Do(func() {
+		l.conns = make(chan *Conn)
+		l.done = make(chan struct{})
+		go l.acceptLoop()
+	})
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.done:
+		return nil, l.err
+	}
+}
+
+// acceptLoop accept the connections until error, and handshake each of them
+func (l *Listener) acceptLoop() {
+	for {
+		socket, err := l.Listener.Accept()
+		if err != nil {
+			l.err = err
+			close(l.done)
+			return
+		}
+		go l.serveHandshake(socket)
+	}
+}
+
+// serveHandshake read the handshake of socket, and pass the Conn to Accept
+func (l *Listener) serveHandshake(socket net.Conn) {
+	c, err := l.handshake(socket)
+	if err != nil {
+		if l.Logger != nil {
+			l.Logger.Warnf("server: drop connection from %v: %v", socket.RemoteAddr(), err)
+		}
+		_ = socket.Close()
+		return
+	}
+	select {
+	case l.conns <- c:
+	case <-l.done: // no one will accept it
+		_ = socket.Close()
+	}
+}
+
+func (l *Listener) handshake(socket net.Conn) (*Conn, error) {
+	c := &Conn{Conn: mcnet.WrapConn(socket)}
+	c.MaxPacketSize = mcnet.MaxPacketSizeServerbound
+	c.Logger = l.Logger
+
+	timeout := l.HandshakeTimeout
+	if timeout == 0 {
+		timeout = DefaultHandshakeTimeout
+	}
+	if err := socket.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	p, err := c.ReadPacket()
+	if err != nil {
+		return nil, fmt.Errorf("server: read handshake fail: %w", err)
+	}
+	if err := socket.SetReadDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+
+	var (
+		protocol pk.VarInt
+		addr     pk.String
+		port     pk.UnsignedShort
+		next     pk.VarInt
+	)
+	if p.ID != 0x00 {
+		return nil, fmt.Errorf("server: packet 0x%02X isn't a handshake", p.ID)
+	}
+	if err := p.Scan(&protocol, &addr, &port, &next); err != nil {
+		return nil, fmt.Errorf("server: scan handshake fail: %w", err)
+	}
+	switch next {
+	case 1:
+		c.NextState = data.Status
+	case 2:
+		c.NextState = data.Login
+	default:
+		return nil, fmt.Errorf("server: unknown next state %d of handshake", next)
+	}
+	c.Protocol = int(protocol)
+	c.ServerAddress = string(addr)
+	c.ServerPort = int(port)
+	return c, nil
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Tnze/go-mc/chat"
+	"github.com/Tnze/go-mc/data"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+func TestHandleStatus(t *testing.T) {
+	l, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	var status mcnet.Status
+	status.Version.Name = "1.16.1"
+	status.Version.Protocol = 736
+	status.Players.Max = 20
+	status.Description = chat.Text("A Minecraft Server")
+	status.FaviconPNG = []byte("\x89PNG\r\n\x1a\n")
+
+	errs := make(chan error, 1)
+	go func() {
+		// a connection of garbage is dropped
+		if conn, err := net.Dial("tcp", l.Addr().String()); err == nil {
+			_, _ = conn.Write([]byte{0x02, 0x7f, 0x00})
+			conn.Close()
+		}
+		resp, _, err := mcnet.PingAndList(l.Addr().String())
+		if err == nil {
+			var s mcnet.Status
+			if s, err = mcnet.UnmarshalStatus(resp); err == nil && s.Description.ClearString() != "A Minecraft Server" {
+				t.Errorf("wrong status: %s", resp)
+			} else if err == nil && string(s.FaviconPNG) != string(status.FaviconPNG) {
+				t.Errorf("wrong favicon: %q", s.FaviconPNG)
+			}
+		}
+		errs <- err
+	}()
+
+	c, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if c.NextState != data.Status || c.State() != data.Status || c.Protocol != -1 || c.ServerAddress != "127.0.0.1" {
+		t.Errorf("wrong handshake: %+v", c)
+	}
+	if err := HandleStatus(c, status); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListener_Accept_slowHandshake(t *testing.T) {
+	l, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	// a client connects but never sends the handshake
+	idle, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idle.Close()
+
+	go func() {
+		conn, err := mcnet.DialMC(l.Addr().String())
+		if err != nil {
+			return
+		}
+		_ = conn.WritePacket(pk.Marshal(0x00, pk.VarInt(736), pk.String("localhost"), pk.UnsignedShort(25565), pk.VarInt(1)))
+	}()
+
+	start := time.Now()
+	c, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if time.Since(start) > time.Second {
+		t.Errorf("Accept is blocked by the idle client for %v", time.Since(start))
+	}
+
+	l.Close()
+	if _, err := l.Accept(); err == nil {
+		t.Error("Accept should return error after the listener is closed")
+	}
+}
+
+func TestListener_Accept_login(t *testing.T) {
+	l, err := Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go func() {
+		conn, err := mcnet.DialMC(l.Addr().String())
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.WritePacket(pk.Marshal(0x00, pk.VarInt(736), pk.String("localhost\x00FML2\x00"), pk.UnsignedShort(25565), pk.VarInt(2)))
+		_ = conn.WritePacket(pk.Marshal(0x00, pk.String("Steve")))
+		_, _ = conn.ReadPacket() // wait for closing
+	}()
+
+	c, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if c.NextState != data.Login || c.Protocol != 736 || c.ServerPort != 25565 {
+		t.Errorf("wrong handshake: %+v", c)
+	}
+	if host, fml := mcnet.ParseFMLAddress(c.ServerAddress); host != "localhost" || fml != mcnet.FML2 {
+		t.Errorf("wrong server address: %q", c.ServerAddress)
+	}
+	p, err := c.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var name pk.String
+	if err := p.Scan(&name); err != nil || name != "Steve" {
+		t.Errorf("login start: %q, %v", name, err)
+	}
+	if err := HandleStatus(c, mcnet.Status{}); err == nil {
+		t.Error("HandleStatus should fail in the login state")
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Tnze/go-mc/data"
+	mcnet "github.com/Tnze/go-mc/net"
+	pk "github.com/Tnze/go-mc/net/packet"
+)
+
+// HandleStatus answer the ServerListPing of c with the status.
+// It returns after the pong is sent, or the client closes the connection
+// after getting the status. The connection isn't closed by it.
+//
+// The Version.Protocol of status can be set to c.Protocol,
+// so the clients of any version see the server compatible.
+func HandleStatus(c *Conn, status mcnet.Status) error {
+	if c.NextState != data.Status {
+		return fmt.Errorf("server: the connection is in the %v state, not status", c.NextState)
+	}
+	resp, err := mcnet.MarshalStatus(status)
+	if err != nil {
+		return err
+	}
+
+	responded := false
+	for {
+		p, err := c.ReadPacket()
+		if err != nil {
+			if responded && errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("server: read status packet fail: %w", err)
+		}
+		switch p.ID {
+		case 0x00: // Request
+			if err := c.WritePacket(pk.Marshal(0x00, pk.String(resp))); err != nil {
+				return fmt.Errorf("server: send status response fail: %w", err)
+			}
+			responded = true
+		case 0x01: // Ping
+			var payload pk.Long
+			if err := p.Scan(&payload); err != nil {
+				return fmt.Errorf("server: scan ping fail: %w", err)
+			}
+			if err := c.WritePacket(pk.Marshal(0x01, payload)); err != nil {
+				return fmt.Errorf("server: send pong fail: %w", err)
+			}
+			return nil
+		default:
+			return fmt.Errorf("server: unknown status packet 0x%02X", p.ID)
+		}
+	}
+}